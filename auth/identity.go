@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"context"
+)
+
+// Identity of the caller, taken from the verified token
+type Identity struct {
+	Subject string
+	Name    string
+	// Tenant is the organization the caller acts for, nil unless tenancy is enabled
+	Tenant *Tenant
+}
+
+// Tenant is the organization whose data a caller can see and change
+type Tenant struct {
+	OrganizationID uint
+	// Admin is set for platform admins, who act across all tenants
+	Admin bool
+}
+
+type identityKey struct{}
+
+// WithIdentity returns a copy of the context carrying the identity of the caller
+func WithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFromContext returns the identity of the caller, if the request carried a verified token
+func IdentityFromContext(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(Identity)
+	return identity, ok
+}
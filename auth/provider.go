@@ -3,10 +3,14 @@ package auth
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/coreos/go-oidc"
 	"github.com/hashicorp/go-hclog"
+	"github.com/milutindzunic/pac-backend/logging"
 	"golang.org/x/oauth2"
 	"net/http"
+	"strconv"
 	"strings"
 )
 
@@ -17,12 +21,28 @@ type OauthConfig struct {
 	ClientSecret string
 	RedirectURL  string
 	Scopes       []string
+	Tenancy      TenancyConfig
 }
 
+// TenancyConfig tells how the tenant of a caller is read from the claims of the token
+type TenancyConfig struct {
+	Enabled bool
+	// Claim holds the id of the organization of the caller
+	Claim string
+	// AdminClaim holds the roles of the caller, callers with AdminRole are platform admins
+	AdminClaim string
+	AdminRole  string
+}
+
+// ErrNoTenant is returned for valid tokens which name neither an organization nor the platform admin role
+var ErrNoTenant = errors.New("token carries no organization")
+
 type OauthProvider struct {
 	enabled      bool
+	issuer       string
 	oauth2Config *oauth2.Config
 	verifier     *oidc.IDTokenVerifier
+	tenancy      TenancyConfig
 	context      context.Context
 	logger       hclog.Logger
 }
@@ -62,14 +82,85 @@ func NewProvider(config OauthConfig, logger hclog.Logger) (*OauthProvider, error
 
 	return &OauthProvider{
 		enabled:      true,
+		issuer:       config.Issuer,
 		oauth2Config: oauth2Config,
 		verifier:     verifier,
+		tenancy:      config.Tenancy,
 		context:      ctx,
 		logger:       logger,
 	}, nil
 }
 
+// CheckDiscovery verifies that the OpenID Connect discovery document of the issuer can still be loaded
+func (p *OauthProvider) CheckDiscovery(ctx context.Context) error {
+	if !p.enabled {
+		return nil
+	}
+
+	wellKnown := strings.TrimSuffix(p.issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, wellKnown, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("discovery document returned status %s", resp.Status)
+	}
+	return nil
+}
+
+// Enabled reports whether callers are authenticated, when it is not every caller is let through
+func (p *OauthProvider) Enabled() bool {
+	return p.enabled
+}
+
+// Tenancy reports whether callers are scoped to the organization named by their token, in which case
+// reads require a token as well
+func (p *OauthProvider) Tenancy() bool {
+	return p.enabled && p.tenancy.Enabled
+}
+
+// Verify verifies the raw token and returns the identity of its bearer. With tenancy, it fails with ErrNoTenant
+// when the token does not tell the tenant of its bearer.
+func (p *OauthProvider) Verify(rawToken string) (Identity, error) {
+	token, err := p.verifier.Verify(p.context, rawToken)
+	if err != nil {
+		return Identity{}, err
+	}
+	identity := identityOf(token)
+	if p.tenancy.Enabled {
+		tenant, err := p.tenantOf(token)
+		if err != nil {
+			return Identity{}, err
+		}
+		identity.Tenant = tenant
+	}
+	return identity, nil
+}
+
 func (p *OauthProvider) Middleware(next http.Handler) http.Handler {
+	return p.authenticate(next, func(rw http.ResponseWriter, r *http.Request) {
+		http.Redirect(rw, r, p.oauth2Config.AuthCodeURL(oauthState), http.StatusFound)
+	})
+}
+
+// BearerMiddleware is like Middleware, but answers requests without a valid token with 401 Unauthorized instead of
+// redirecting them to the login page, for API clients which cannot follow the login
+func (p *OauthProvider) BearerMiddleware(next http.Handler) http.Handler {
+	return p.authenticate(next, func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(rw, "Valid access token required", http.StatusUnauthorized)
+	})
+}
+
+// authenticate passes requests with a valid token on to next, with the identity of the bearer in the context,
+// and the others to reject
+func (p *OauthProvider) authenticate(next http.Handler, reject http.HandlerFunc) http.Handler {
 	if !p.enabled {
 		// return no-op Middleware
 		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
@@ -77,16 +168,12 @@ func (p *OauthProvider) Middleware(next http.Handler) http.Handler {
 		})
 	}
 
-	oauth2Config := p.oauth2Config
-	verifier := p.verifier
-	ctx := p.context
-
 	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
 
 		rawAccessToken := r.Header.Get("Authorization")
 		if rawAccessToken == "" {
-			p.logger.Debug("Access token empty, redirecting...")
-			http.Redirect(rw, r, oauth2Config.AuthCodeURL(oauthState), http.StatusFound)
+			p.logger.Debug("Access token empty, rejecting...")
+			reject(rw, r)
 			return
 		}
 
@@ -98,16 +185,22 @@ func (p *OauthProvider) Middleware(next http.Handler) http.Handler {
 		}
 
 		p.logger.Debug("Verifying access token", "token", parts[1])
-		_, err := verifier.Verify(ctx, parts[1])
+		identity, err := p.Verify(parts[1])
 
+		if errors.Is(err, ErrNoTenant) {
+			p.logger.Warn("Access token carries no organization", "err", err)
+			http.Error(rw, "Token carries no organization", http.StatusForbidden)
+			return
+		}
 		if err != nil {
-			p.logger.Warn("Access token invalid, redirecting...", "err", err)
-			http.Redirect(rw, r, oauth2Config.AuthCodeURL(oauthState), http.StatusFound)
+			p.logger.Warn("Access token invalid, rejecting...", "err", err)
+			reject(rw, r)
 			return
 		}
 
 		p.logger.Debug("Access token valid...")
-		next.ServeHTTP(rw, r)
+		logging.SetSubject(r.Context(), identity.Subject)
+		next.ServeHTTP(rw, r.WithContext(WithIdentity(r.Context(), identity)))
 	})
 }
 
@@ -160,3 +253,60 @@ func (p *OauthProvider) CallbackHandler() http.Handler {
 		rw.Write(data)
 	})
 }
+
+// identityOf reads the subject and the display name of the caller from the token claims
+func identityOf(token *oidc.IDToken) Identity {
+	var claims struct {
+		Name              string `json:"name"`
+		PreferredUsername string `json:"preferred_username"`
+		Email             string `json:"email"`
+	}
+	_ = token.Claims(&claims)
+
+	name := claims.Name
+	if name == "" {
+		name = claims.PreferredUsername
+	}
+	if name == "" {
+		name = claims.Email
+	}
+
+	return Identity{Subject: token.Subject, Name: name}
+}
+
+// tenantOf reads the organization of the caller, and whether it is a platform admin, from the token claims.
+// The organization may be given as a number or as a string; the roles as a single string or a list of strings.
+func (p *OauthProvider) tenantOf(token *oidc.IDToken) (*Tenant, error) {
+	var claims map[string]interface{}
+	if err := token.Claims(&claims); err != nil {
+		return nil, err
+	}
+
+	tenant := &Tenant{}
+	switch roles := claims[p.tenancy.AdminClaim].(type) {
+	case string:
+		tenant.Admin = roles == p.tenancy.AdminRole
+	case []interface{}:
+		for _, role := range roles {
+			if role == p.tenancy.AdminRole {
+				tenant.Admin = true
+			}
+		}
+	}
+
+	switch organization := claims[p.tenancy.Claim].(type) {
+	case float64:
+		if organization > 0 && organization == float64(uint(organization)) {
+			tenant.OrganizationID = uint(organization)
+		}
+	case string:
+		if id, err := strconv.ParseUint(organization, 10, 0); err == nil {
+			tenant.OrganizationID = uint(id)
+		}
+	}
+
+	if tenant.OrganizationID == 0 && !tenant.Admin {
+		return nil, ErrNoTenant
+	}
+	return tenant, nil
+}
@@ -0,0 +1,27 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Backend stores the cached values and the generations of the entities they were read from.
+// Generations must never be evicted, or stale values would become valid again.
+type Backend interface {
+	// Get returns the value stored under key, if it is present and not expired
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Generations returns the current generation of each name, zero for names never bumped
+	Generations(ctx context.Context, names []string) ([]int64, error)
+	// Bump moves each name to a new, higher generation. Generations are unix nanoseconds of the last bump,
+	// incremented when two bumps fall on the same nanosecond.
+	Bump(ctx context.Context, names []string) error
+}
+
+// nextGeneration returns the generation following current, at the given time
+func nextGeneration(current int64, now time.Time) int64 {
+	if next := now.UnixNano(); next > current {
+		return next
+	}
+	return current + 1
+}
@@ -0,0 +1,166 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"github.com/hashicorp/go-hclog"
+	"github.com/milutindzunic/pac-backend/data"
+	"github.com/milutindzunic/pac-backend/logging"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// allEntities is bumped on every mutation; it versions the responses for the HTTP caching headers
+const allEntities = "all"
+
+// cascades lists the entities which a delete or restore of an entity moves along with it
+var cascades = map[string][]string{
+	"location":     {"event", "room", "track", "talk", "talkDate"},
+	"event":        {"track", "talk", "talkDate"},
+	"organization": {"event", "person", "room", "track", "talk", "talkDate"},
+	"room":         {"talkDate"},
+	"track":        {"talk", "talkDate"},
+	"talk":         {"talkDate"},
+}
+
+// Cache serves reads of the stores from the backend. Cached values are keyed by the generations of the
+// entities they were read from, so a mutation makes them unreachable by bumping the generation of the
+// mutated entities, without having to find and delete them.
+type Cache struct {
+	backend Backend
+	ttl     time.Duration
+	log     hclog.Logger
+}
+
+func New(backend Backend, ttl time.Duration, log hclog.Logger) *Cache {
+	return &Cache{backend, ttl, log}
+}
+
+// load reads the value stored under key into dst, which must be a pointer to the type returned by fetch.
+// On a miss, or when the backend fails, the value is fetched from the store and cached.
+func (c *Cache) load(ctx context.Context, entity string, key string, dependencies []string, dst interface{}, fetch func() (interface{}, error)) error {
+	log := logging.FromContext(ctx, c.log)
+
+	// reads within a transaction may see changes which are not committed yet, so they are neither served nor cached
+	if data.InTransaction(ctx) {
+		return assign(dst, fetch)
+	}
+
+	// reads scoped to a tenant are cached apart for every tenant
+	if tenant := data.TenantKey(ctx); tenant != "" {
+		key += ":" + tenant
+	}
+	// reads expanding other relations than the default ones are cached apart, and depend on the expanded entities
+	if includes := data.IncludesKey(ctx); includes != "" {
+		key += ":" + includes
+		dependencies = withIncluded(dependencies, data.IncludedEntities(ctx, entity))
+	}
+
+	generations, err := c.backend.Generations(ctx, dependencies)
+	if err != nil {
+		log.Warn("Error reading cache generations, bypassing cache", "err", err)
+		return assign(dst, fetch)
+	}
+	versioned := versionedKey(key, generations)
+
+	if value, ok, err := c.backend.Get(ctx, versioned); err != nil {
+		log.Warn("Error reading cache", "key", versioned, "err", err)
+	} else if ok {
+		if err := decode(value, dst); err == nil {
+			log.Debug("Cache hit", "key", versioned)
+			return nil
+		}
+		log.Warn("Error decoding cached value", "key", versioned, "err", err)
+	}
+
+	log.Debug("Cache miss", "key", versioned)
+	if err := assign(dst, fetch); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(dst); err != nil {
+		log.Warn("Error encoding value for cache", "key", versioned, "err", err)
+		return nil
+	}
+	if err := c.backend.Set(ctx, versioned, buf.Bytes(), c.ttl); err != nil {
+		log.Warn("Error writing cache", "key", versioned, "err", err)
+	}
+	return nil
+}
+
+// invalidate bumps the generations of the mutated entities, and of the entities moved along when cascade is set
+func (c *Cache) invalidate(ctx context.Context, entity string, cascade bool) {
+	names := []string{entity, allEntities}
+	if cascade {
+		names = append(names, cascades[entity]...)
+	}
+	// bumped once committed, so that the values read meanwhile are not cached under the new generations
+	data.AfterCommit(ctx, func() {
+		if err := c.backend.Bump(ctx, names); err != nil {
+			// cached values stay valid until they expire
+			logging.FromContext(ctx, c.log).Error("Error invalidating cache", "entities", strings.Join(names, ","), "err", err)
+		}
+	})
+}
+
+// version returns the generation of all entities, i.e. the time of the last mutation in unix nanoseconds
+func (c *Cache) version(ctx context.Context) (int64, error) {
+	generations, err := c.backend.Generations(ctx, []string{allEntities})
+	if err != nil {
+		return 0, err
+	}
+	return generations[0], nil
+}
+
+// withIncluded adds the expanded entities, named like the data package names them, to the dependencies
+func withIncluded(dependencies []string, included []string) []string {
+	merged := append([]string{}, dependencies...)
+	for _, entity := range included {
+		name := strings.ToLower(entity[:1]) + entity[1:]
+		found := false
+		for _, dependency := range merged {
+			if dependency == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, name)
+		}
+	}
+	return merged
+}
+
+func versionedKey(key string, generations []int64) string {
+	var b strings.Builder
+	b.WriteString(key)
+	for _, generation := range generations {
+		b.WriteString(fmt.Sprintf(":%d", generation))
+	}
+	return b.String()
+}
+
+func assign(dst interface{}, fetch func() (interface{}, error)) error {
+	value, err := fetch()
+	if err != nil {
+		return err
+	}
+	reflect.ValueOf(dst).Elem().Set(reflect.ValueOf(value))
+	return nil
+}
+
+// decode reads a gob encoded value into dst. gob drops empty slices, which the handlers
+// would render as null instead of [].
+func decode(value []byte, dst interface{}) error {
+	if err := gob.NewDecoder(bytes.NewReader(value)).Decode(dst); err != nil {
+		return err
+	}
+	if v := reflect.ValueOf(dst).Elem(); v.Kind() == reflect.Slice && v.IsNil() {
+		v.Set(reflect.MakeSlice(v.Type(), 0, 0))
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"github.com/milutindzunic/pac-backend/logging"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers lets browsers and CDNs cache public reads for maxAge. With a cache, responses are also versioned by
+// the time of the last mutation: it is sent as ETag and Last-Modified, and a matching If-None-Match is
+// answered with 304 Not Modified. If-Modified-Since is not honored, its resolution of one second could hide
+// a mutation made within the same second. When private is set, as the reads depend on the caller, responses are
+// only cached by browsers, per Authorization header.
+func Headers(c *Cache, maxAge time.Duration, private bool) func(http.Handler) http.Handler {
+	cacheControl := "public, max-age=" + strconv.Itoa(int(maxAge.Seconds()))
+	if private {
+		cacheControl = "private, max-age=" + strconv.Itoa(int(maxAge.Seconds()))
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Set("Cache-Control", cacheControl)
+			if private {
+				w.Header().Add("Vary", "Authorization")
+			}
+
+			if c == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			version, err := c.version(r.Context())
+			if err != nil || version == 0 {
+				// without a version, nothing tells clients whether their copy is still current
+				if err != nil {
+					logging.FromContext(r.Context(), c.log).Warn("Error reading cache version", "err", err)
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			etag := `W/"` + strconv.FormatInt(version, 36) + `"`
+			w.Header().Set("ETag", etag)
+			w.Header().Set("Last-Modified", time.Unix(0, version).UTC().Format(http.TimeFormat))
+
+			if matches(r.Header.Get("If-None-Match"), etag) {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// matches compares the entity tags weakly, as required for If-None-Match
+func matches(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
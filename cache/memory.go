@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryBackend is an in-process LRU cache with a time to live per entry.
+// It stands in for a shared backend when the service runs as a single instance.
+type MemoryBackend struct {
+	maxEntries int
+
+	mu          sync.Mutex
+	entries     map[string]*list.Element
+	lru         *list.List
+	generations map[string]int64
+}
+
+type entry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+func NewMemoryBackend(maxEntries int) *MemoryBackend {
+	return &MemoryBackend{
+		maxEntries: maxEntries,
+		entries:    map[string]*list.Element{},
+		lru:        list.New(),
+		// the data may have changed while the service was down, so responses are versioned from the start
+		generations: map[string]int64{allEntities: time.Now().UnixNano()},
+	}
+}
+
+func (m *MemoryBackend) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	element, ok := m.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	e := element.Value.(*entry)
+	if time.Now().After(e.expires) {
+		m.remove(element)
+		return nil, false, nil
+	}
+
+	m.lru.MoveToFront(element)
+	return e.value, true, nil
+}
+
+func (m *MemoryBackend) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if element, ok := m.entries[key]; ok {
+		m.remove(element)
+	}
+	m.entries[key] = m.lru.PushFront(&entry{key, value, time.Now().Add(ttl)})
+
+	for m.lru.Len() > m.maxEntries {
+		m.remove(m.lru.Back())
+	}
+	return nil
+}
+
+func (m *MemoryBackend) remove(element *list.Element) {
+	m.lru.Remove(element)
+	delete(m.entries, element.Value.(*entry).key)
+}
+
+func (m *MemoryBackend) Generations(ctx context.Context, names []string) ([]int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	generations := make([]int64, len(names))
+	for i, name := range names {
+		generations[i] = m.generations[name]
+	}
+	return generations, nil
+}
+
+func (m *MemoryBackend) Bump(ctx context.Context, names []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for _, name := range names {
+		m.generations[name] = nextGeneration(m.generations[name], now)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"context"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+	"time"
+)
+
+// bumpScript sets the generation to the current time, or increments it when it is not lower already
+var bumpScript = redis.NewScript(`
+local next = tonumber(ARGV[1])
+for _, key in ipairs(KEYS) do
+	local current = tonumber(redis.call('GET', key) or '0')
+	local generation = next
+	if current >= next then generation = current + 1 end
+	redis.call('SET', key, generation)
+end
+return 0
+`)
+
+// RedisBackend shares the cache between instances, using any server speaking the Redis protocol.
+// The generations are stored without expiry; the server should not be configured to evict keys without one.
+type RedisBackend struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewRedisBackend(client *redis.Client, prefix string) *RedisBackend {
+	return &RedisBackend{client, prefix}
+}
+
+func (b *RedisBackend) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := b.client.Get(ctx, b.prefix+key).Bytes()
+	if err == redis.Nil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+func (b *RedisBackend) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return b.client.Set(ctx, b.prefix+key, value, ttl).Err()
+}
+
+func (b *RedisBackend) Generations(ctx context.Context, names []string) ([]int64, error) {
+	values, err := b.client.MGet(ctx, b.generationKeys(names)...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	generations := make([]int64, len(names))
+	for i, value := range values {
+		if s, ok := value.(string); ok {
+			generations[i], _ = strconv.ParseInt(s, 10, 64)
+		}
+	}
+	return generations, nil
+}
+
+func (b *RedisBackend) Bump(ctx context.Context, names []string) error {
+	return bumpScript.Run(ctx, b.client, b.generationKeys(names), time.Now().UnixNano()).Err()
+}
+
+func (b *RedisBackend) generationKeys(names []string) []string {
+	keys := make([]string, len(names))
+	for i, name := range names {
+		keys[i] = b.prefix + "generation:" + name
+	}
+	return keys
+}
@@ -0,0 +1,653 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"github.com/milutindzunic/pac-backend/data"
+)
+
+// locationDependencies lists the entities read by the location store
+var locationDependencies = []string{"location"}
+
+type LocationStore struct {
+	data.LocationStore
+	cache *Cache
+}
+
+func NewLocationStore(store data.LocationStore, cache *Cache) *LocationStore {
+	return &LocationStore{store, cache}
+}
+
+func (s *LocationStore) GetLocations(ctx context.Context) ([]*data.Location, error) {
+	var locations []*data.Location
+	err := s.cache.load(ctx, "Location", "location:all", locationDependencies, &locations, func() (interface{}, error) {
+		return s.LocationStore.GetLocations(ctx)
+	})
+	return locations, err
+}
+
+func (s *LocationStore) GetLocationByID(ctx context.Context, id uint) (*data.Location, error) {
+	var location *data.Location
+	err := s.cache.load(ctx, "Location", fmt.Sprintf("location:id:%d", id), locationDependencies, &location, func() (interface{}, error) {
+		return s.LocationStore.GetLocationByID(ctx, id)
+	})
+	return location, err
+}
+
+func (s *LocationStore) GetLocationsNear(ctx context.Context, latitude, longitude, radius float64) ([]*data.Location, error) {
+	var locations []*data.Location
+	key := fmt.Sprintf("location:near:%g:%g:%g", latitude, longitude, radius)
+	err := s.cache.load(ctx, "Location", key, locationDependencies, &locations, func() (interface{}, error) {
+		return s.LocationStore.GetLocationsNear(ctx, latitude, longitude, radius)
+	})
+	return locations, err
+}
+
+func (s *LocationStore) UpdateLocation(ctx context.Context, id uint, location *data.Location) (*data.Location, error) {
+	location, err := s.LocationStore.UpdateLocation(ctx, id, location)
+	if err == nil {
+		s.cache.invalidate(ctx, "location", false)
+	}
+	return location, err
+}
+
+func (s *LocationStore) AddLocation(ctx context.Context, location *data.Location) (*data.Location, error) {
+	location, err := s.LocationStore.AddLocation(ctx, location)
+	if err == nil {
+		s.cache.invalidate(ctx, "location", false)
+	}
+	return location, err
+}
+
+func (s *LocationStore) DeleteLocationByID(ctx context.Context, id uint, cascade bool) error {
+	err := s.LocationStore.DeleteLocationByID(ctx, id, cascade)
+	if err == nil {
+		s.cache.invalidate(ctx, "location", cascade)
+	}
+	return err
+}
+
+// RestoreLocationByID invalidates the entities which may have been restored along with the location as well
+func (s *LocationStore) RestoreLocationByID(ctx context.Context, id uint) (*data.Location, error) {
+	location, err := s.LocationStore.RestoreLocationByID(ctx, id)
+	if err == nil {
+		s.cache.invalidate(ctx, "location", true)
+	}
+	return location, err
+}
+
+// eventDependencies lists the entities read by the event store
+var eventDependencies = []string{"event", "location", "organization"}
+
+type EventStore struct {
+	data.EventStore
+	cache *Cache
+}
+
+func NewEventStore(store data.EventStore, cache *Cache) *EventStore {
+	return &EventStore{store, cache}
+}
+
+func (s *EventStore) GetEvents(ctx context.Context) ([]*data.Event, error) {
+	var events []*data.Event
+	err := s.cache.load(ctx, "Event", "event:all", eventDependencies, &events, func() (interface{}, error) {
+		return s.EventStore.GetEvents(ctx)
+	})
+	return events, err
+}
+
+func (s *EventStore) GetEventByID(ctx context.Context, id uint) (*data.Event, error) {
+	var event *data.Event
+	err := s.cache.load(ctx, "Event", fmt.Sprintf("event:id:%d", id), eventDependencies, &event, func() (interface{}, error) {
+		return s.EventStore.GetEventByID(ctx, id)
+	})
+	return event, err
+}
+
+var getEventsByTalkIDDependencies = []string{"event", "location", "organization", "talkDate"}
+
+func (s *EventStore) GetEventsByTalkID(ctx context.Context, talkID uint) ([]*data.Event, error) {
+	var events []*data.Event
+	err := s.cache.load(ctx, "Event", fmt.Sprintf("event:talk:%d", talkID), getEventsByTalkIDDependencies, &events, func() (interface{}, error) {
+		return s.EventStore.GetEventsByTalkID(ctx, talkID)
+	})
+	return events, err
+}
+
+func (s *EventStore) UpdateEvent(ctx context.Context, id uint, event *data.Event) (*data.Event, error) {
+	event, err := s.EventStore.UpdateEvent(ctx, id, event)
+	if err == nil {
+		// the series of the event are expanded to its new dates
+		s.cache.invalidate(ctx, "event", true)
+	}
+	return event, err
+}
+
+func (s *EventStore) AddEvent(ctx context.Context, event *data.Event) (*data.Event, error) {
+	event, err := s.EventStore.AddEvent(ctx, event)
+	if err == nil {
+		s.cache.invalidate(ctx, "event", false)
+	}
+	return event, err
+}
+
+func (s *EventStore) DeleteEventByID(ctx context.Context, id uint, cascade bool) error {
+	err := s.EventStore.DeleteEventByID(ctx, id, cascade)
+	if err == nil {
+		s.cache.invalidate(ctx, "event", cascade)
+	}
+	return err
+}
+
+// RestoreEventByID invalidates the entities which may have been restored along with the event as well
+func (s *EventStore) RestoreEventByID(ctx context.Context, id uint) (*data.Event, error) {
+	event, err := s.EventStore.RestoreEventByID(ctx, id)
+	if err == nil {
+		s.cache.invalidate(ctx, "event", true)
+	}
+	return event, err
+}
+
+// organizationDependencies lists the entities read by the organization store
+var organizationDependencies = []string{"organization"}
+
+type OrganizationStore struct {
+	data.OrganizationStore
+	cache *Cache
+}
+
+func NewOrganizationStore(store data.OrganizationStore, cache *Cache) *OrganizationStore {
+	return &OrganizationStore{store, cache}
+}
+
+func (s *OrganizationStore) GetOrganizations(ctx context.Context) ([]*data.Organization, error) {
+	var organizations []*data.Organization
+	err := s.cache.load(ctx, "Organization", "organization:all", organizationDependencies, &organizations, func() (interface{}, error) {
+		return s.OrganizationStore.GetOrganizations(ctx)
+	})
+	return organizations, err
+}
+
+func (s *OrganizationStore) GetOrganizationByID(ctx context.Context, id uint) (*data.Organization, error) {
+	var organization *data.Organization
+	err := s.cache.load(ctx, "Organization", fmt.Sprintf("organization:id:%d", id), organizationDependencies, &organization, func() (interface{}, error) {
+		return s.OrganizationStore.GetOrganizationByID(ctx, id)
+	})
+	return organization, err
+}
+
+func (s *OrganizationStore) UpdateOrganization(ctx context.Context, id uint, organization *data.Organization) (*data.Organization, error) {
+	organization, err := s.OrganizationStore.UpdateOrganization(ctx, id, organization)
+	if err == nil {
+		s.cache.invalidate(ctx, "organization", false)
+	}
+	return organization, err
+}
+
+func (s *OrganizationStore) AddOrganization(ctx context.Context, organization *data.Organization) (*data.Organization, error) {
+	organization, err := s.OrganizationStore.AddOrganization(ctx, organization)
+	if err == nil {
+		s.cache.invalidate(ctx, "organization", false)
+	}
+	return organization, err
+}
+
+func (s *OrganizationStore) DeleteOrganizationByID(ctx context.Context, id uint, cascade bool) error {
+	err := s.OrganizationStore.DeleteOrganizationByID(ctx, id, cascade)
+	if err == nil {
+		s.cache.invalidate(ctx, "organization", cascade)
+	}
+	return err
+}
+
+// RestoreOrganizationByID invalidates the entities which may have been restored along with the organization as well
+func (s *OrganizationStore) RestoreOrganizationByID(ctx context.Context, id uint) (*data.Organization, error) {
+	organization, err := s.OrganizationStore.RestoreOrganizationByID(ctx, id)
+	if err == nil {
+		s.cache.invalidate(ctx, "organization", true)
+	}
+	return organization, err
+}
+
+// personDependencies lists the entities read by the person store
+var personDependencies = []string{"person", "organization"}
+
+type PersonStore struct {
+	data.PersonStore
+	cache *Cache
+}
+
+func NewPersonStore(store data.PersonStore, cache *Cache) *PersonStore {
+	return &PersonStore{store, cache}
+}
+
+func (s *PersonStore) GetPersons(ctx context.Context) ([]*data.Person, error) {
+	var persons []*data.Person
+	err := s.cache.load(ctx, "Person", "person:all", personDependencies, &persons, func() (interface{}, error) {
+		return s.PersonStore.GetPersons(ctx)
+	})
+	return persons, err
+}
+
+func (s *PersonStore) GetPersonByID(ctx context.Context, id uint) (*data.Person, error) {
+	var person *data.Person
+	err := s.cache.load(ctx, "Person", fmt.Sprintf("person:id:%d", id), personDependencies, &person, func() (interface{}, error) {
+		return s.PersonStore.GetPersonByID(ctx, id)
+	})
+	return person, err
+}
+
+func (s *PersonStore) GetPersonBySubject(ctx context.Context, subject string) (*data.Person, error) {
+	var person *data.Person
+	err := s.cache.load(ctx, "Person", fmt.Sprintf("person:subject:%s", subject), personDependencies, &person, func() (interface{}, error) {
+		return s.PersonStore.GetPersonBySubject(ctx, subject)
+	})
+	return person, err
+}
+
+func (s *PersonStore) UpdatePerson(ctx context.Context, id uint, person *data.Person) (*data.Person, error) {
+	person, err := s.PersonStore.UpdatePerson(ctx, id, person)
+	if err == nil {
+		s.cache.invalidate(ctx, "person", false)
+	}
+	return person, err
+}
+
+func (s *PersonStore) AddPerson(ctx context.Context, person *data.Person) (*data.Person, error) {
+	person, err := s.PersonStore.AddPerson(ctx, person)
+	if err == nil {
+		s.cache.invalidate(ctx, "person", false)
+	}
+	return person, err
+}
+
+func (s *PersonStore) DeletePersonByID(ctx context.Context, id uint, cascade bool) error {
+	err := s.PersonStore.DeletePersonByID(ctx, id, cascade)
+	if err == nil {
+		s.cache.invalidate(ctx, "person", cascade)
+	}
+	return err
+}
+
+// RestorePersonByID invalidates the entities which may have been restored along with the person as well
+func (s *PersonStore) RestorePersonByID(ctx context.Context, id uint) (*data.Person, error) {
+	person, err := s.PersonStore.RestorePersonByID(ctx, id)
+	if err == nil {
+		s.cache.invalidate(ctx, "person", true)
+	}
+	return person, err
+}
+
+// roomDependencies lists the entities read by the room store
+var roomDependencies = []string{"room", "organization", "location"}
+
+type RoomStore struct {
+	data.RoomStore
+	cache *Cache
+}
+
+func NewRoomStore(store data.RoomStore, cache *Cache) *RoomStore {
+	return &RoomStore{store, cache}
+}
+
+func (s *RoomStore) GetRooms(ctx context.Context) ([]*data.Room, error) {
+	var rooms []*data.Room
+	err := s.cache.load(ctx, "Room", "room:all", roomDependencies, &rooms, func() (interface{}, error) {
+		return s.RoomStore.GetRooms(ctx)
+	})
+	return rooms, err
+}
+
+func (s *RoomStore) GetRoomByID(ctx context.Context, id uint) (*data.Room, error) {
+	var room *data.Room
+	err := s.cache.load(ctx, "Room", fmt.Sprintf("room:id:%d", id), roomDependencies, &room, func() (interface{}, error) {
+		return s.RoomStore.GetRoomByID(ctx, id)
+	})
+	return room, err
+}
+
+func (s *RoomStore) UpdateRoom(ctx context.Context, id uint, room *data.Room) (*data.Room, error) {
+	room, err := s.RoomStore.UpdateRoom(ctx, id, room)
+	if err == nil {
+		s.cache.invalidate(ctx, "room", false)
+	}
+	return room, err
+}
+
+func (s *RoomStore) AddRoom(ctx context.Context, room *data.Room) (*data.Room, error) {
+	room, err := s.RoomStore.AddRoom(ctx, room)
+	if err == nil {
+		s.cache.invalidate(ctx, "room", false)
+	}
+	return room, err
+}
+
+func (s *RoomStore) DeleteRoomByID(ctx context.Context, id uint, cascade bool) error {
+	err := s.RoomStore.DeleteRoomByID(ctx, id, cascade)
+	if err == nil {
+		s.cache.invalidate(ctx, "room", cascade)
+	}
+	return err
+}
+
+// RestoreRoomByID invalidates the entities which may have been restored along with the room as well
+func (s *RoomStore) RestoreRoomByID(ctx context.Context, id uint) (*data.Room, error) {
+	room, err := s.RoomStore.RestoreRoomByID(ctx, id)
+	if err == nil {
+		s.cache.invalidate(ctx, "room", true)
+	}
+	return room, err
+}
+
+// topicDependencies lists the entities read by the topic store
+var topicDependencies = []string{"topic"}
+
+type TopicStore struct {
+	data.TopicStore
+	cache *Cache
+}
+
+func NewTopicStore(store data.TopicStore, cache *Cache) *TopicStore {
+	return &TopicStore{store, cache}
+}
+
+func (s *TopicStore) GetTopics(ctx context.Context) ([]*data.Topic, error) {
+	var topics []*data.Topic
+	err := s.cache.load(ctx, "Topic", "topic:all", topicDependencies, &topics, func() (interface{}, error) {
+		return s.TopicStore.GetTopics(ctx)
+	})
+	return topics, err
+}
+
+func (s *TopicStore) GetTopicByID(ctx context.Context, id uint) (*data.Topic, error) {
+	var topic *data.Topic
+	err := s.cache.load(ctx, "Topic", fmt.Sprintf("topic:id:%d", id), topicDependencies, &topic, func() (interface{}, error) {
+		return s.TopicStore.GetTopicByID(ctx, id)
+	})
+	return topic, err
+}
+
+var getTopicsByEventIDDependencies = []string{"topic", "talkDate", "talk"}
+
+func (s *TopicStore) GetTopicsByEventID(ctx context.Context, eventID uint) ([]*data.Topic, error) {
+	var topics []*data.Topic
+	err := s.cache.load(ctx, "Topic", fmt.Sprintf("topic:event:%d", eventID), getTopicsByEventIDDependencies, &topics, func() (interface{}, error) {
+		return s.TopicStore.GetTopicsByEventID(ctx, eventID)
+	})
+	return topics, err
+}
+
+func (s *TopicStore) UpdateTopic(ctx context.Context, id uint, topic *data.Topic) (*data.Topic, error) {
+	topic, err := s.TopicStore.UpdateTopic(ctx, id, topic)
+	if err == nil {
+		s.cache.invalidate(ctx, "topic", false)
+	}
+	return topic, err
+}
+
+func (s *TopicStore) AddTopic(ctx context.Context, topic *data.Topic) (*data.Topic, error) {
+	topic, err := s.TopicStore.AddTopic(ctx, topic)
+	if err == nil {
+		s.cache.invalidate(ctx, "topic", false)
+	}
+	return topic, err
+}
+
+func (s *TopicStore) DeleteTopicByID(ctx context.Context, id uint, cascade bool) error {
+	err := s.TopicStore.DeleteTopicByID(ctx, id, cascade)
+	if err == nil {
+		s.cache.invalidate(ctx, "topic", cascade)
+	}
+	return err
+}
+
+// RestoreTopicByID invalidates the entities which may have been restored along with the topic as well
+func (s *TopicStore) RestoreTopicByID(ctx context.Context, id uint) (*data.Topic, error) {
+	topic, err := s.TopicStore.RestoreTopicByID(ctx, id)
+	if err == nil {
+		s.cache.invalidate(ctx, "topic", true)
+	}
+	return topic, err
+}
+
+// trackDependencies lists the entities read by the track store
+var trackDependencies = []string{"track", "event"}
+
+type TrackStore struct {
+	data.TrackStore
+	cache *Cache
+}
+
+func NewTrackStore(store data.TrackStore, cache *Cache) *TrackStore {
+	return &TrackStore{store, cache}
+}
+
+func (s *TrackStore) GetTracks(ctx context.Context) ([]*data.Track, error) {
+	var tracks []*data.Track
+	err := s.cache.load(ctx, "Track", "track:all", trackDependencies, &tracks, func() (interface{}, error) {
+		return s.TrackStore.GetTracks(ctx)
+	})
+	return tracks, err
+}
+
+func (s *TrackStore) GetTrackByID(ctx context.Context, id uint) (*data.Track, error) {
+	var track *data.Track
+	err := s.cache.load(ctx, "Track", fmt.Sprintf("track:id:%d", id), trackDependencies, &track, func() (interface{}, error) {
+		return s.TrackStore.GetTrackByID(ctx, id)
+	})
+	return track, err
+}
+
+func (s *TrackStore) GetTracksByEventID(ctx context.Context, eventID uint) ([]*data.Track, error) {
+	var tracks []*data.Track
+	err := s.cache.load(ctx, "Track", fmt.Sprintf("track:event:%d", eventID), trackDependencies, &tracks, func() (interface{}, error) {
+		return s.TrackStore.GetTracksByEventID(ctx, eventID)
+	})
+	return tracks, err
+}
+
+func (s *TrackStore) UpdateTrack(ctx context.Context, id uint, track *data.Track) (*data.Track, error) {
+	track, err := s.TrackStore.UpdateTrack(ctx, id, track)
+	if err == nil {
+		s.cache.invalidate(ctx, "track", false)
+	}
+	return track, err
+}
+
+func (s *TrackStore) AddTrack(ctx context.Context, track *data.Track) (*data.Track, error) {
+	track, err := s.TrackStore.AddTrack(ctx, track)
+	if err == nil {
+		s.cache.invalidate(ctx, "track", false)
+	}
+	return track, err
+}
+
+func (s *TrackStore) DeleteTrackByID(ctx context.Context, id uint, cascade bool) error {
+	err := s.TrackStore.DeleteTrackByID(ctx, id, cascade)
+	if err == nil {
+		s.cache.invalidate(ctx, "track", cascade)
+	}
+	return err
+}
+
+// RestoreTrackByID invalidates the entities which may have been restored along with the track as well
+func (s *TrackStore) RestoreTrackByID(ctx context.Context, id uint) (*data.Track, error) {
+	track, err := s.TrackStore.RestoreTrackByID(ctx, id)
+	if err == nil {
+		s.cache.invalidate(ctx, "track", true)
+	}
+	return track, err
+}
+
+// talkDependencies lists the entities read by the talk store
+var talkDependencies = []string{"talk", "track", "person", "organization", "topic", "talkDate", "room", "event"}
+
+type TalkStore struct {
+	data.TalkStore
+	cache *Cache
+}
+
+func NewTalkStore(store data.TalkStore, cache *Cache) *TalkStore {
+	return &TalkStore{store, cache}
+}
+
+func (s *TalkStore) GetTalks(ctx context.Context, filter data.TalkFilter) ([]*data.Talk, error) {
+	var talks []*data.Talk
+	err := s.cache.load(ctx, "Talk", "talk:all:"+talkFilterKey(filter), talkDependencies, &talks, func() (interface{}, error) {
+		return s.TalkStore.GetTalks(ctx, filter)
+	})
+	return talks, err
+}
+
+// talkFilterKey identifies the talks selected by the filter within the cached listings
+func talkFilterKey(filter data.TalkFilter) string {
+	return fmt.Sprintf("format=%s,level=%s,language=%q,track=%d", filter.Format, filter.Level, filter.Language, filter.TrackID)
+}
+
+func (s *TalkStore) GetTalkByID(ctx context.Context, id uint) (*data.Talk, error) {
+	var talk *data.Talk
+	err := s.cache.load(ctx, "Talk", fmt.Sprintf("talk:id:%d", id), talkDependencies, &talk, func() (interface{}, error) {
+		return s.TalkStore.GetTalkByID(ctx, id)
+	})
+	return talk, err
+}
+
+var getTalksByEventIDDependencies = []string{"talk", "track", "person", "organization", "topic", "talkDate", "room", "event"}
+
+func (s *TalkStore) GetTalksByEventID(ctx context.Context, eventID uint, filter data.TalkFilter) ([]*data.Talk, error) {
+	var talks []*data.Talk
+	err := s.cache.load(ctx, "Talk", fmt.Sprintf("talk:event:%d:%s", eventID, talkFilterKey(filter)), getTalksByEventIDDependencies, &talks, func() (interface{}, error) {
+		return s.TalkStore.GetTalksByEventID(ctx, eventID, filter)
+	})
+	return talks, err
+}
+
+var getTalksByPersonIDDependencies = []string{"talk", "track", "person", "organization", "topic", "talkDate", "room", "event"}
+
+func (s *TalkStore) GetTalksByPersonID(ctx context.Context, personID uint) ([]*data.Talk, error) {
+	var talks []*data.Talk
+	err := s.cache.load(ctx, "Talk", fmt.Sprintf("talk:person:%d", personID), getTalksByPersonIDDependencies, &talks, func() (interface{}, error) {
+		return s.TalkStore.GetTalksByPersonID(ctx, personID)
+	})
+	return talks, err
+}
+
+func (s *TalkStore) UpdateTalk(ctx context.Context, id uint, talk *data.Talk) (*data.Talk, error) {
+	talk, err := s.TalkStore.UpdateTalk(ctx, id, talk)
+	if err == nil {
+		s.cache.invalidate(ctx, "talk", false)
+	}
+	return talk, err
+}
+
+func (s *TalkStore) AddTalk(ctx context.Context, talk *data.Talk) (*data.Talk, error) {
+	talk, err := s.TalkStore.AddTalk(ctx, talk)
+	if err == nil {
+		s.cache.invalidate(ctx, "talk", false)
+	}
+	return talk, err
+}
+
+func (s *TalkStore) DeleteTalkByID(ctx context.Context, id uint, cascade bool) error {
+	err := s.TalkStore.DeleteTalkByID(ctx, id, cascade)
+	if err == nil {
+		s.cache.invalidate(ctx, "talk", cascade)
+	}
+	return err
+}
+
+// RestoreTalkByID invalidates the entities which may have been restored along with the talk as well
+func (s *TalkStore) RestoreTalkByID(ctx context.Context, id uint) (*data.Talk, error) {
+	talk, err := s.TalkStore.RestoreTalkByID(ctx, id)
+	if err == nil {
+		s.cache.invalidate(ctx, "talk", true)
+	}
+	return talk, err
+}
+
+// talkDateDependencies lists the entities read by the talkDate store
+var talkDateDependencies = []string{"talkDate", "talk", "person", "organization", "topic", "room", "event", "location"}
+
+type TalkDateStore struct {
+	data.TalkDateStore
+	cache *Cache
+}
+
+func NewTalkDateStore(store data.TalkDateStore, cache *Cache) *TalkDateStore {
+	return &TalkDateStore{store, cache}
+}
+
+func (s *TalkDateStore) GetTalkDates(ctx context.Context) ([]*data.TalkDate, error) {
+	var talkDates []*data.TalkDate
+	err := s.cache.load(ctx, "TalkDate", "talkDate:all", talkDateDependencies, &talkDates, func() (interface{}, error) {
+		return s.TalkDateStore.GetTalkDates(ctx)
+	})
+	return talkDates, err
+}
+
+func (s *TalkDateStore) GetTalkDateByID(ctx context.Context, id uint) (*data.TalkDate, error) {
+	var talkDate *data.TalkDate
+	err := s.cache.load(ctx, "TalkDate", fmt.Sprintf("talkDate:id:%d", id), talkDateDependencies, &talkDate, func() (interface{}, error) {
+		return s.TalkDateStore.GetTalkDateByID(ctx, id)
+	})
+	return talkDate, err
+}
+
+var getTalkDatesByEventIDDependencies = []string{"talkDate", "talk", "person", "organization", "topic", "room", "event", "location"}
+
+func (s *TalkDateStore) GetTalkDatesByEventID(ctx context.Context, eventID uint) ([]*data.TalkDate, error) {
+	var talkDates []*data.TalkDate
+	err := s.cache.load(ctx, "TalkDate", fmt.Sprintf("talkDate:event:%d", eventID), getTalkDatesByEventIDDependencies, &talkDates, func() (interface{}, error) {
+		return s.TalkDateStore.GetTalkDatesByEventID(ctx, eventID)
+	})
+	return talkDates, err
+}
+
+func (s *TalkDateStore) GetTalkDatesBySeriesID(ctx context.Context, seriesID uint) ([]*data.TalkDate, error) {
+	var talkDates []*data.TalkDate
+	err := s.cache.load(ctx, "TalkDate", fmt.Sprintf("talkDate:series:%d", seriesID), talkDateDependencies, &talkDates, func() (interface{}, error) {
+		return s.TalkDateStore.GetTalkDatesBySeriesID(ctx, seriesID)
+	})
+	return talkDates, err
+}
+
+func (s *TalkDateStore) UpdateTalkDateSeries(ctx context.Context, id uint, talkDate *data.TalkDate) (*data.TalkDate, error) {
+	talkDate, err := s.TalkDateStore.UpdateTalkDateSeries(ctx, id, talkDate)
+	if err == nil {
+		s.cache.invalidate(ctx, "talkDate", false)
+	}
+	return talkDate, err
+}
+
+func (s *TalkDateStore) UpdateTalkDate(ctx context.Context, id uint, talkDate *data.TalkDate) (*data.TalkDate, error) {
+	talkDate, err := s.TalkDateStore.UpdateTalkDate(ctx, id, talkDate)
+	if err == nil {
+		s.cache.invalidate(ctx, "talkDate", false)
+	}
+	return talkDate, err
+}
+
+func (s *TalkDateStore) AddTalkDate(ctx context.Context, talkDate *data.TalkDate) (*data.TalkDate, error) {
+	talkDate, err := s.TalkDateStore.AddTalkDate(ctx, talkDate)
+	if err == nil {
+		s.cache.invalidate(ctx, "talkDate", false)
+	}
+	return talkDate, err
+}
+
+func (s *TalkDateStore) DeleteTalkDateByID(ctx context.Context, id uint, cascade bool) error {
+	err := s.TalkDateStore.DeleteTalkDateByID(ctx, id, cascade)
+	if err == nil {
+		s.cache.invalidate(ctx, "talkDate", cascade)
+	}
+	return err
+}
+
+// RestoreTalkDateByID invalidates the entities which may have been restored along with the talkDate as well
+func (s *TalkDateStore) RestoreTalkDateByID(ctx context.Context, id uint) (*data.TalkDate, error) {
+	talkDate, err := s.TalkDateStore.RestoreTalkDateByID(ctx, id)
+	if err == nil {
+		s.cache.invalidate(ctx, "talkDate", true)
+	}
+	return talkDate, err
+}
@@ -2,36 +2,164 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"github.com/spf13/viper"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 )
 
 type Config struct {
-	BindAddress       string
-	LogLevel          string
-	LogPersistence    bool
+	BindAddress string
+	// Server
+	ServerReadTimeout       time.Duration
+	ServerReadHeaderTimeout time.Duration
+	ServerWriteTimeout      time.Duration
+	ServerIdleTimeout       time.Duration
+	ServerMaxHeaderBytes    int
+	ServerMaxBodyBytes      int64
+	ServerHTTP2             bool
+	ServerUnixSocket        string
+	// TLS, disabled unless a certificate is configured
+	TLSCertFile           string
+	TLSKeyFile            string
+	TLSClientCAFile       string
+	TLSClientAuthOptional bool
+	TLSReloadInterval     time.Duration
+	LogLevel              string
+	LogFormat             string
+	LogPersistence        bool
 	// DB connection
-	DbDriver          string
-	DbHost            string
-	DbPort            string
-	DbName            string
-	DbUser            string
-	DbPassword        string
+	DbDriver   string
+	DbHost     string
+	DbPort     string
+	DbName     string
+	DbUser     string
+	DbPassword string
 	// Oauth
 	OAuthEnable       bool
 	OAuthIssuer       string
 	OAuthClientId     string
 	OAuthClientSecret string
 	OAuthRedirectUrl  string
+	// Tenancy
+	TenancyEnable     bool
+	TenancyClaim      string
+	TenancyAdminClaim string
+	TenancyAdminRole  string
+	// Rate limiting
+	RateLimitEnable         bool
+	RateLimitReadPerMinute  int
+	RateLimitReadBurst      int
+	RateLimitWritePerMinute int
+	RateLimitWriteBurst     int
+	RateLimitIPPerMinute    int
+	RateLimitIPBurst        int
+	// TrustedProxies lists the IPs or CIDR ranges of the proxies whose X-Forwarded-For headers are honored
+	TrustedProxies []string
+	// CORS
+	CORSAllowedOrigins   []string
+	CORSAllowedMethods   []string
+	CORSAllowedHeaders   []string
+	CORSExposedHeaders   []string
+	CORSAllowCredentials bool
+	CORSMaxAge           time.Duration
+	// Cache
+	CacheBackend       string
+	CacheTTL           time.Duration
+	CacheMaxEntries    int
+	CacheRedisAddress  string
+	CacheRedisPassword string
+	CacheRedisDB       int
+	CacheMaxAge        time.Duration
+	// Compression
+	CompressionEnable  bool
+	CompressionMinSize int
+	// GraphQL
+	GraphQLMaxDepth      int
+	GraphQLMaxComplexity int
+
+	// Batches
+	BatchMaxOperations int
+
+	// gRPC
+	GRPCEnable      bool
+	GRPCBindAddress string
+	GRPCReflection  bool
+	// Trash
+	TrashRetention     time.Duration
+	TrashPurgeInterval time.Duration
+	// Shutdown
+	ShutdownReadinessDelay time.Duration
+	ShutdownDrainTimeout   time.Duration
+	// Tracing
+	TracingExporter     string
+	TracingOtlpEndpoint string
+	TracingOtlpInsecure bool
+	TracingServiceName  string
+	TracingSampleRatio  float64
 }
 
 // Default config for running the service locally
 var Defaults = map[string]string{
-	"BIND_ADDRESS":    ":9090",
-	"LOG_LEVEL":       "DEBUG",
-	"LOG_PERSISTENCE": "true",
-	"DB_DRIVER":       "sqlite3",
-	"DB_NAME":         "test.db",
-	"ENABLE_OAUTH":    "false",
+	"BIND_ADDRESS":                ":9090",
+	"SERVER_READ_TIMEOUT":         "5s",
+	"SERVER_READ_HEADER_TIMEOUT":  "5s",
+	"SERVER_WRITE_TIMEOUT":        "10s",
+	"SERVER_IDLE_TIMEOUT":         "120s",
+	"SERVER_MAX_HEADER_BYTES":     "1048576",
+	"SERVER_MAX_BODY_BYTES":       "1048576",
+	"SERVER_HTTP2":                "true",
+	"TLS_CLIENT_AUTH_OPTIONAL":    "false",
+	"TLS_RELOAD_INTERVAL":         "30s",
+	"LOG_LEVEL":                   "DEBUG",
+	"LOG_FORMAT":                  "text",
+	"LOG_PERSISTENCE":             "true",
+	"DB_DRIVER":                   "sqlite3",
+	"DB_NAME":                     "test.db",
+	"ENABLE_OAUTH":                "false",
+	"TENANCY_ENABLE":              "false",
+	"TENANCY_CLAIM":               "organization_id",
+	"TENANCY_ADMIN_CLAIM":         "roles",
+	"TENANCY_ADMIN_ROLE":          "platform-admin",
+	"RATE_LIMIT_ENABLE":           "true",
+	"RATE_LIMIT_READ_PER_MINUTE":  "600",
+	"RATE_LIMIT_READ_BURST":       "100",
+	"RATE_LIMIT_WRITE_PER_MINUTE": "60",
+	"RATE_LIMIT_WRITE_BURST":      "20",
+	"RATE_LIMIT_IP_PER_MINUTE":    "300",
+	"RATE_LIMIT_IP_BURST":         "60",
+	"CORS_ALLOWED_ORIGINS":        "*",
+	"CORS_ALLOWED_METHODS":        "GET,POST,PUT,DELETE",
+	"CORS_ALLOWED_HEADERS":        "Authorization,Content-Type,X-Request-ID,Accept-Timezone",
+	"CORS_EXPOSED_HEADERS":        "X-Request-ID",
+	"CORS_ALLOW_CREDENTIALS":      "false",
+	"CORS_MAX_AGE":                "10m",
+	"CACHE_BACKEND":               "memory",
+	"CACHE_TTL":                   "5m",
+	"CACHE_MAX_ENTRIES":           "10000",
+	"CACHE_REDIS_ADDRESS":         "localhost:6379",
+	"CACHE_REDIS_DB":              "0",
+	"CACHE_MAX_AGE":               "30s",
+	"COMPRESSION_ENABLE":          "true",
+	"COMPRESSION_MIN_SIZE":        "1024",
+	"GRAPHQL_MAX_DEPTH":           "8",
+	"GRAPHQL_MAX_COMPLEXITY":      "5000",
+	"BATCH_MAX_OPERATIONS":        "1000",
+	"GRPC_ENABLE":                 "false",
+	"GRPC_BIND_ADDRESS":           ":9091",
+	"GRPC_REFLECTION":             "false",
+	"TRASH_RETENTION":             "720h",
+	"TRASH_PURGE_INTERVAL":        "1h",
+	"SHUTDOWN_READINESS_DELAY":    "5s",
+	"SHUTDOWN_DRAIN_TIMEOUT":      "30s",
+	"TRACING_EXPORTER":            "none",
+	"TRACING_OTLP_ENDPOINT":       "localhost:4318",
+	"TRACING_OTLP_INSECURE":       "true",
+	"TRACING_SERVICE_NAME":        "pac-backend",
+	"TRACING_SAMPLE_RATIO":        "1",
 }
 
 func LoadConfig() (*Config, error) {
@@ -39,11 +167,61 @@ func LoadConfig() (*Config, error) {
 
 	// 1) Set defaults
 	configReader.SetDefault("BIND_ADDRESS", Defaults["BIND_ADDRESS"])
+	configReader.SetDefault("SERVER_READ_TIMEOUT", Defaults["SERVER_READ_TIMEOUT"])
+	configReader.SetDefault("SERVER_READ_HEADER_TIMEOUT", Defaults["SERVER_READ_HEADER_TIMEOUT"])
+	configReader.SetDefault("SERVER_WRITE_TIMEOUT", Defaults["SERVER_WRITE_TIMEOUT"])
+	configReader.SetDefault("SERVER_IDLE_TIMEOUT", Defaults["SERVER_IDLE_TIMEOUT"])
+	configReader.SetDefault("SERVER_MAX_HEADER_BYTES", Defaults["SERVER_MAX_HEADER_BYTES"])
+	configReader.SetDefault("SERVER_MAX_BODY_BYTES", Defaults["SERVER_MAX_BODY_BYTES"])
+	configReader.SetDefault("SERVER_HTTP2", Defaults["SERVER_HTTP2"])
+	configReader.SetDefault("TLS_CLIENT_AUTH_OPTIONAL", Defaults["TLS_CLIENT_AUTH_OPTIONAL"])
+	configReader.SetDefault("TLS_RELOAD_INTERVAL", Defaults["TLS_RELOAD_INTERVAL"])
 	configReader.SetDefault("LOG_LEVEL", Defaults["LOG_LEVEL"])
+	configReader.SetDefault("LOG_FORMAT", Defaults["LOG_FORMAT"])
 	configReader.SetDefault("LOG_PERSISTENCE", Defaults["LOG_PERSISTENCE"])
 	configReader.SetDefault("DB_DRIVER", Defaults["DB_DRIVER"])
 	configReader.SetDefault("DB_NAME", Defaults["DB_NAME"])
 	configReader.SetDefault("ENABLE_OAUTH", Defaults["ENABLE_OAUTH"])
+	configReader.SetDefault("TENANCY_ENABLE", Defaults["TENANCY_ENABLE"])
+	configReader.SetDefault("TENANCY_CLAIM", Defaults["TENANCY_CLAIM"])
+	configReader.SetDefault("TENANCY_ADMIN_CLAIM", Defaults["TENANCY_ADMIN_CLAIM"])
+	configReader.SetDefault("TENANCY_ADMIN_ROLE", Defaults["TENANCY_ADMIN_ROLE"])
+	configReader.SetDefault("RATE_LIMIT_ENABLE", Defaults["RATE_LIMIT_ENABLE"])
+	configReader.SetDefault("RATE_LIMIT_READ_PER_MINUTE", Defaults["RATE_LIMIT_READ_PER_MINUTE"])
+	configReader.SetDefault("RATE_LIMIT_READ_BURST", Defaults["RATE_LIMIT_READ_BURST"])
+	configReader.SetDefault("RATE_LIMIT_WRITE_PER_MINUTE", Defaults["RATE_LIMIT_WRITE_PER_MINUTE"])
+	configReader.SetDefault("RATE_LIMIT_WRITE_BURST", Defaults["RATE_LIMIT_WRITE_BURST"])
+	configReader.SetDefault("RATE_LIMIT_IP_PER_MINUTE", Defaults["RATE_LIMIT_IP_PER_MINUTE"])
+	configReader.SetDefault("RATE_LIMIT_IP_BURST", Defaults["RATE_LIMIT_IP_BURST"])
+	configReader.SetDefault("CORS_ALLOWED_ORIGINS", Defaults["CORS_ALLOWED_ORIGINS"])
+	configReader.SetDefault("CORS_ALLOWED_METHODS", Defaults["CORS_ALLOWED_METHODS"])
+	configReader.SetDefault("CORS_ALLOWED_HEADERS", Defaults["CORS_ALLOWED_HEADERS"])
+	configReader.SetDefault("CORS_EXPOSED_HEADERS", Defaults["CORS_EXPOSED_HEADERS"])
+	configReader.SetDefault("CORS_ALLOW_CREDENTIALS", Defaults["CORS_ALLOW_CREDENTIALS"])
+	configReader.SetDefault("CORS_MAX_AGE", Defaults["CORS_MAX_AGE"])
+	configReader.SetDefault("CACHE_BACKEND", Defaults["CACHE_BACKEND"])
+	configReader.SetDefault("CACHE_TTL", Defaults["CACHE_TTL"])
+	configReader.SetDefault("CACHE_MAX_ENTRIES", Defaults["CACHE_MAX_ENTRIES"])
+	configReader.SetDefault("CACHE_REDIS_ADDRESS", Defaults["CACHE_REDIS_ADDRESS"])
+	configReader.SetDefault("CACHE_REDIS_DB", Defaults["CACHE_REDIS_DB"])
+	configReader.SetDefault("CACHE_MAX_AGE", Defaults["CACHE_MAX_AGE"])
+	configReader.SetDefault("COMPRESSION_ENABLE", Defaults["COMPRESSION_ENABLE"])
+	configReader.SetDefault("COMPRESSION_MIN_SIZE", Defaults["COMPRESSION_MIN_SIZE"])
+	configReader.SetDefault("GRAPHQL_MAX_DEPTH", Defaults["GRAPHQL_MAX_DEPTH"])
+	configReader.SetDefault("GRAPHQL_MAX_COMPLEXITY", Defaults["GRAPHQL_MAX_COMPLEXITY"])
+	configReader.SetDefault("BATCH_MAX_OPERATIONS", Defaults["BATCH_MAX_OPERATIONS"])
+	configReader.SetDefault("GRPC_ENABLE", Defaults["GRPC_ENABLE"])
+	configReader.SetDefault("GRPC_BIND_ADDRESS", Defaults["GRPC_BIND_ADDRESS"])
+	configReader.SetDefault("GRPC_REFLECTION", Defaults["GRPC_REFLECTION"])
+	configReader.SetDefault("TRASH_RETENTION", Defaults["TRASH_RETENTION"])
+	configReader.SetDefault("TRASH_PURGE_INTERVAL", Defaults["TRASH_PURGE_INTERVAL"])
+	configReader.SetDefault("SHUTDOWN_READINESS_DELAY", Defaults["SHUTDOWN_READINESS_DELAY"])
+	configReader.SetDefault("SHUTDOWN_DRAIN_TIMEOUT", Defaults["SHUTDOWN_DRAIN_TIMEOUT"])
+	configReader.SetDefault("TRACING_EXPORTER", Defaults["TRACING_EXPORTER"])
+	configReader.SetDefault("TRACING_OTLP_ENDPOINT", Defaults["TRACING_OTLP_ENDPOINT"])
+	configReader.SetDefault("TRACING_OTLP_INSECURE", Defaults["TRACING_OTLP_INSECURE"])
+	configReader.SetDefault("TRACING_SERVICE_NAME", Defaults["TRACING_SERVICE_NAME"])
+	configReader.SetDefault("TRACING_SAMPLE_RATIO", Defaults["TRACING_SAMPLE_RATIO"])
 
 	// 2) Load the environment variables
 	configReader.AutomaticEnv()
@@ -64,7 +242,22 @@ func LoadConfig() (*Config, error) {
 	config := Config{}
 
 	config.BindAddress = configReader.GetString("BIND_ADDRESS")
+	config.ServerReadTimeout = configReader.GetDuration("SERVER_READ_TIMEOUT")
+	config.ServerReadHeaderTimeout = configReader.GetDuration("SERVER_READ_HEADER_TIMEOUT")
+	config.ServerWriteTimeout = configReader.GetDuration("SERVER_WRITE_TIMEOUT")
+	config.ServerIdleTimeout = configReader.GetDuration("SERVER_IDLE_TIMEOUT")
+	config.ServerMaxHeaderBytes = configReader.GetInt("SERVER_MAX_HEADER_BYTES")
+	config.ServerMaxBodyBytes = configReader.GetInt64("SERVER_MAX_BODY_BYTES")
+	config.ServerHTTP2 = configReader.GetBool("SERVER_HTTP2")
+	config.ServerUnixSocket = configReader.GetString("SERVER_UNIX_SOCKET")
+
+	config.TLSCertFile = configReader.GetString("TLS_CERT_FILE")
+	config.TLSKeyFile = configReader.GetString("TLS_KEY_FILE")
+	config.TLSClientCAFile = configReader.GetString("TLS_CLIENT_CA_FILE")
+	config.TLSClientAuthOptional = configReader.GetBool("TLS_CLIENT_AUTH_OPTIONAL")
+	config.TLSReloadInterval = configReader.GetDuration("TLS_RELOAD_INTERVAL")
 	config.LogLevel = configReader.GetString("LOG_LEVEL")
+	config.LogFormat = configReader.GetString("LOG_FORMAT")
 	config.LogPersistence = configReader.GetBool("LOG_PERSISTENCE")
 
 	config.DbDriver = configReader.GetString("DB_DRIVER")
@@ -79,10 +272,160 @@ func LoadConfig() (*Config, error) {
 	config.OAuthClientId = configReader.GetString("OAUTH_CLIENT_ID")
 	config.OAuthClientSecret = configReader.GetString("OAUTH_CLIENT_SECRET")
 	config.OAuthRedirectUrl = configReader.GetString("OAUTH_REDIRECT_URL")
+	config.TenancyEnable = configReader.GetBool("TENANCY_ENABLE")
+	config.TenancyClaim = configReader.GetString("TENANCY_CLAIM")
+	config.TenancyAdminClaim = configReader.GetString("TENANCY_ADMIN_CLAIM")
+	config.TenancyAdminRole = configReader.GetString("TENANCY_ADMIN_ROLE")
+
+	config.RateLimitEnable = configReader.GetBool("RATE_LIMIT_ENABLE")
+	config.RateLimitReadPerMinute = configReader.GetInt("RATE_LIMIT_READ_PER_MINUTE")
+	config.RateLimitReadBurst = configReader.GetInt("RATE_LIMIT_READ_BURST")
+	config.RateLimitWritePerMinute = configReader.GetInt("RATE_LIMIT_WRITE_PER_MINUTE")
+	config.RateLimitWriteBurst = configReader.GetInt("RATE_LIMIT_WRITE_BURST")
+	config.RateLimitIPPerMinute = configReader.GetInt("RATE_LIMIT_IP_PER_MINUTE")
+	config.RateLimitIPBurst = configReader.GetInt("RATE_LIMIT_IP_BURST")
+	config.TrustedProxies = splitList(configReader.GetString("TRUSTED_PROXIES"))
+
+	config.CORSAllowedOrigins = splitList(configReader.GetString("CORS_ALLOWED_ORIGINS"))
+	config.CORSAllowedMethods = splitList(configReader.GetString("CORS_ALLOWED_METHODS"))
+	config.CORSAllowedHeaders = splitList(configReader.GetString("CORS_ALLOWED_HEADERS"))
+	config.CORSExposedHeaders = splitList(configReader.GetString("CORS_EXPOSED_HEADERS"))
+	config.CORSAllowCredentials = configReader.GetBool("CORS_ALLOW_CREDENTIALS")
+	config.CORSMaxAge = configReader.GetDuration("CORS_MAX_AGE")
+
+	config.CacheBackend = configReader.GetString("CACHE_BACKEND")
+	config.CacheTTL = configReader.GetDuration("CACHE_TTL")
+	config.CacheMaxEntries = configReader.GetInt("CACHE_MAX_ENTRIES")
+	config.CacheRedisAddress = configReader.GetString("CACHE_REDIS_ADDRESS")
+	config.CacheRedisPassword = configReader.GetString("CACHE_REDIS_PASSWORD")
+	config.CacheRedisDB = configReader.GetInt("CACHE_REDIS_DB")
+	config.CacheMaxAge = configReader.GetDuration("CACHE_MAX_AGE")
+	config.CompressionEnable = configReader.GetBool("COMPRESSION_ENABLE")
+	config.CompressionMinSize = configReader.GetInt("COMPRESSION_MIN_SIZE")
+	config.GraphQLMaxDepth = configReader.GetInt("GRAPHQL_MAX_DEPTH")
+	config.GraphQLMaxComplexity = configReader.GetInt("GRAPHQL_MAX_COMPLEXITY")
+	config.BatchMaxOperations = configReader.GetInt("BATCH_MAX_OPERATIONS")
+	config.GRPCEnable = configReader.GetBool("GRPC_ENABLE")
+	config.GRPCBindAddress = configReader.GetString("GRPC_BIND_ADDRESS")
+	config.GRPCReflection = configReader.GetBool("GRPC_REFLECTION")
+
+	config.TrashRetention = configReader.GetDuration("TRASH_RETENTION")
+	config.TrashPurgeInterval = configReader.GetDuration("TRASH_PURGE_INTERVAL")
+
+	config.ShutdownReadinessDelay = configReader.GetDuration("SHUTDOWN_READINESS_DELAY")
+	config.ShutdownDrainTimeout = configReader.GetDuration("SHUTDOWN_DRAIN_TIMEOUT")
+
+	config.TracingExporter = configReader.GetString("TRACING_EXPORTER")
+	config.TracingOtlpEndpoint = configReader.GetString("TRACING_OTLP_ENDPOINT")
+	config.TracingOtlpInsecure = configReader.GetBool("TRACING_OTLP_INSECURE")
+	config.TracingServiceName = configReader.GetString("TRACING_SERVICE_NAME")
+	config.TracingSampleRatio = configReader.GetFloat64("TRACING_SAMPLE_RATIO")
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
 
 	return &config, nil
 }
 
+// Validate reports all invalid settings at once, so the service fails at startup rather than on first use
+func (c *Config) Validate() error {
+	var problems []string
+	check := func(ok bool, problem string) {
+		if !ok {
+			problems = append(problems, problem)
+		}
+	}
+	fileExists := func(name string) bool {
+		info, err := os.Stat(name)
+		return err == nil && !info.IsDir()
+	}
+
+	check(c.ServerReadTimeout >= 0, "SERVER_READ_TIMEOUT must not be negative")
+	check(c.ServerReadHeaderTimeout >= 0, "SERVER_READ_HEADER_TIMEOUT must not be negative")
+	check(c.ServerWriteTimeout >= 0, "SERVER_WRITE_TIMEOUT must not be negative")
+	check(c.ServerIdleTimeout >= 0, "SERVER_IDLE_TIMEOUT must not be negative")
+	check(c.ServerMaxHeaderBytes > 0, "SERVER_MAX_HEADER_BYTES must be positive")
+	check(c.ServerMaxBodyBytes > 0, "SERVER_MAX_BODY_BYTES must be positive")
+	if c.ServerUnixSocket != "" {
+		info, err := os.Stat(filepath.Dir(c.ServerUnixSocket))
+		check(err == nil && info.IsDir(), "directory of SERVER_UNIX_SOCKET "+c.ServerUnixSocket+" does not exist")
+	}
+
+	check((c.TLSCertFile == "") == (c.TLSKeyFile == ""), "TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	if c.TLSCertFile != "" {
+		check(fileExists(c.TLSCertFile), "TLS_CERT_FILE "+c.TLSCertFile+" does not exist")
+		check(c.TLSKeyFile == "" || fileExists(c.TLSKeyFile), "TLS_KEY_FILE "+c.TLSKeyFile+" does not exist")
+		check(c.TLSReloadInterval > 0, "TLS_RELOAD_INTERVAL must be positive")
+	}
+	if c.TLSClientCAFile != "" {
+		check(c.TLSCertFile != "", "TLS_CLIENT_CA_FILE requires TLS_CERT_FILE and TLS_KEY_FILE")
+		check(fileExists(c.TLSClientCAFile), "TLS_CLIENT_CA_FILE "+c.TLSClientCAFile+" does not exist")
+	}
+
+	if c.TenancyEnable {
+		check(c.OAuthEnable, "TENANCY_ENABLE requires ENABLE_OAUTH, the tenant is taken from the token")
+		check(c.TenancyClaim != "", "TENANCY_CLAIM must be set when TENANCY_ENABLE is true")
+		check(c.TenancyAdminClaim != "" && c.TenancyAdminRole != "", "TENANCY_ADMIN_CLAIM and TENANCY_ADMIN_ROLE must be set when TENANCY_ENABLE is true")
+	}
+
+	if c.RateLimitEnable {
+		check(c.RateLimitReadPerMinute > 0 && c.RateLimitReadBurst > 0, "RATE_LIMIT_READ_PER_MINUTE and RATE_LIMIT_READ_BURST must be positive")
+		check(c.RateLimitWritePerMinute > 0 && c.RateLimitWriteBurst > 0, "RATE_LIMIT_WRITE_PER_MINUTE and RATE_LIMIT_WRITE_BURST must be positive")
+		check(c.RateLimitIPPerMinute > 0 && c.RateLimitIPBurst > 0, "RATE_LIMIT_IP_PER_MINUTE and RATE_LIMIT_IP_BURST must be positive")
+	}
+	for _, proxy := range c.TrustedProxies {
+		_, _, cidrErr := net.ParseCIDR(proxy)
+		check(net.ParseIP(proxy) != nil || cidrErr == nil, "TRUSTED_PROXIES entry "+proxy+" must be an IP or a CIDR range")
+	}
+
+	for _, origin := range c.CORSAllowedOrigins {
+		check(!(origin == "*" && c.CORSAllowCredentials), "CORS_ALLOWED_ORIGINS must list the origins when CORS_ALLOW_CREDENTIALS is set")
+		check(strings.Count(origin, "*") <= 1, "CORS_ALLOWED_ORIGINS entry "+origin+" must contain at most one wildcard")
+	}
+	check(c.CORSMaxAge >= 0, "CORS_MAX_AGE must not be negative")
+
+	check(c.CacheBackend == "none" || c.CacheBackend == "memory" || c.CacheBackend == "redis", "CACHE_BACKEND must be one of: [none, memory, redis], was "+c.CacheBackend)
+	if c.CacheBackend != "none" {
+		check(c.CacheTTL > 0, "CACHE_TTL must be positive")
+	}
+	if c.CacheBackend == "memory" {
+		check(c.CacheMaxEntries > 0, "CACHE_MAX_ENTRIES must be positive")
+	}
+	check(c.CacheMaxAge >= 0, "CACHE_MAX_AGE must not be negative")
+	if c.CompressionEnable {
+		check(c.CompressionMinSize >= 0, "COMPRESSION_MIN_SIZE must not be negative")
+	}
+	check(c.GraphQLMaxDepth > 0, "GRAPHQL_MAX_DEPTH must be positive")
+	check(c.GraphQLMaxComplexity > 0, "GRAPHQL_MAX_COMPLEXITY must be positive")
+	check(c.BatchMaxOperations > 0, "BATCH_MAX_OPERATIONS must be positive")
+	if c.GRPCEnable {
+		check(c.GRPCBindAddress != "", "GRPC_BIND_ADDRESS must be set when GRPC_ENABLE is true")
+		check(c.GRPCBindAddress != c.BindAddress, "GRPC_BIND_ADDRESS must differ from BIND_ADDRESS")
+	}
+
+	check(c.LogFormat == "text" || c.LogFormat == "json", "LOG_FORMAT must be one of: [text, json], was "+c.LogFormat)
+	check(c.TrashPurgeInterval > 0, "TRASH_PURGE_INTERVAL must be positive")
+	check(c.ShutdownDrainTimeout > 0, "SHUTDOWN_DRAIN_TIMEOUT must be positive")
+	check(c.ShutdownReadinessDelay >= 0, "SHUTDOWN_READINESS_DELAY must not be negative")
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid config: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// splitList splits a comma separated setting, ignoring blank entries
+func splitList(s string) []string {
+	list := []string{}
+	for _, entry := range strings.Split(s, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			list = append(list, entry)
+		}
+	}
+	return list
+}
+
 func (c *Config) String() string {
 	s, _ := json.MarshalIndent(c, "", "\t")
 	return string(s)
@@ -0,0 +1,173 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/hashicorp/go-hclog"
+	"github.com/jinzhu/gorm"
+	"github.com/milutindzunic/pac-backend/auth"
+	"github.com/milutindzunic/pac-backend/tracing"
+	"reflect"
+	"time"
+)
+
+// Actions recorded in the audit log
+const (
+	AuditCreate  = "create"
+	AuditUpdate  = "update"
+	AuditDelete  = "delete"
+	AuditRestore = "restore"
+)
+
+// AnonymousActor is recorded as the actor of mutations performed without a verified token
+const AnonymousActor = "anonymous"
+
+// AuditEntry records a single mutation of an entity performed through the stores
+type AuditEntry struct {
+	ID           uint      `json:"id" gorm:"primary_key;auto_increment"`
+	Timestamp    time.Time `json:"timestamp" gorm:"not null;index"`
+	ActorSubject string    `json:"actorSubject" gorm:"not null;index"`
+	ActorName    string    `json:"actorName"`
+	Action       string    `json:"action" gorm:"not null"`
+	Entity       string    `json:"entity" gorm:"not null;index:idx_audit_entry_entity"`
+	EntityID     uint      `json:"entityId" gorm:"not null;index:idx_audit_entry_entity"`
+	// Changes maps every changed field onto its value before and after the mutation
+	Changes json.RawMessage `json:"changes" sql:"type:text"`
+}
+
+// AuditFilter selects audit entries; zero values match all entries
+type AuditFilter struct {
+	Entity   string
+	EntityID uint
+	Actor    string
+	Since    time.Time
+	Until    time.Time
+}
+
+type AuditStore interface {
+	GetAuditEntries(ctx context.Context, filter AuditFilter) ([]*AuditEntry, error)
+}
+
+type AuditDBStore struct {
+	*gorm.DB
+	log hclog.Logger
+}
+
+func NewAuditDBStore(db *gorm.DB, log hclog.Logger) *AuditDBStore {
+	return &AuditDBStore{db, log}
+}
+
+func (db *AuditDBStore) traced(ctx context.Context) *AuditDBStore {
+	tx, log := inContext(ctx, db.DB, db.log)
+	return &AuditDBStore{tx, log}
+}
+
+func (db *AuditDBStore) GetAuditEntries(ctx context.Context, filter AuditFilter) ([]*AuditEntry, error) {
+	ctx, span := tracing.StartSpan(ctx, "AuditDBStore.GetAuditEntries")
+	defer span.End()
+	db = db.traced(ctx)
+
+	db.log.Debug("Getting audit entries...", "filter", hclog.Fmt("%+v", filter))
+
+	// entries are not recorded per tenant, so the audit log spans all of them
+	if _, ok := TenantOf(ctx); ok {
+		err := &ForbiddenError{Entity: "AuditEntry", Message: "can only be read by platform admins"}
+		db.log.Error("Not allowed to get audit entries", "err", err)
+		return []*AuditEntry{}, err
+	}
+
+	query := db.Order("timestamp").Order("id")
+	if filter.Entity != "" {
+		query = query.Where("entity = ?", filter.Entity)
+	}
+	if filter.EntityID != 0 {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.Actor != "" {
+		query = query.Where("actor_subject = ? OR actor_name = ?", filter.Actor, filter.Actor)
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("timestamp >= ?", filter.Since.UTC())
+	}
+	if !filter.Until.IsZero() {
+		query = query.Where("timestamp < ?", filter.Until.UTC())
+	}
+
+	var entries []*AuditEntry
+	if err := query.Find(&entries).Error; err != nil {
+		db.log.Error("Error getting audit entries", "err", err)
+		return []*AuditEntry{}, translateError("AuditEntry", err)
+	}
+
+	db.log.Debug("Returning audit entries", "count", len(entries))
+	return entries, nil
+}
+
+// recordAudit writes an audit entry for the mutation of an entity, within the transaction performing it.
+// before is nil for created entities and after is nil for deleted ones.
+func recordAudit(ctx context.Context, tx *gorm.DB, action string, entity string, id uint, before interface{}, after interface{}) error {
+	changes, err := diff(before, after)
+	if err != nil {
+		return err
+	}
+
+	entry := AuditEntry{
+		Timestamp:    time.Now().UTC(),
+		ActorSubject: AnonymousActor,
+		Action:       action,
+		Entity:       entity,
+		EntityID:     id,
+		Changes:      changes,
+	}
+	if identity, ok := auth.IdentityFromContext(ctx); ok {
+		entry.ActorSubject = identity.Subject
+		entry.ActorName = identity.Name
+	}
+
+	return tx.Create(&entry).Error
+}
+
+type change struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// diff compares the json representations of before and after field by field
+func diff(before interface{}, after interface{}) (json.RawMessage, error) {
+	b, err := toFields(before)
+	if err != nil {
+		return nil, err
+	}
+	a, err := toFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]change{}
+	for field, value := range a {
+		if !reflect.DeepEqual(b[field], value) {
+			changes[field] = change{b[field], value}
+		}
+	}
+	for field, value := range b {
+		if _, ok := a[field]; !ok {
+			changes[field] = change{value, nil}
+		}
+	}
+
+	return json.Marshal(changes)
+}
+
+func toFields(entity interface{}) (map[string]interface{}, error) {
+	fields := map[string]interface{}{}
+	if entity == nil || reflect.ValueOf(entity).IsNil() {
+		return fields, nil
+	}
+
+	bytes, err := json.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(bytes, &fields)
+	return fields, err
+}
@@ -0,0 +1,277 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"github.com/jinzhu/gorm"
+	"time"
+)
+
+// Dependent lists the entities of one kind which still reference an entity being deleted
+type Dependent struct {
+	Entity string `json:"entity"`
+	IDs    []uint `json:"ids"`
+}
+
+// reference is a column of another table pointing at the id of a row
+type reference struct {
+	// entity reported to the client, e.g. "talkDates"
+	entity string
+	table  string
+	column string
+	// idTable and idColumn identify the referencing entity; for join tables it is the other side of the relation
+	idTable  string
+	idColumn string
+}
+
+func (r reference) join() bool { return r.idTable != r.table }
+
+// references lists, per table, the rows which depend on a row of that table.
+// Join table rows owned by the row itself (e.g. the persons of a talk) are not dependents;
+// the database removes them together with the row.
+var references = map[string][]reference{
+	"location": {
+		{"events", "event", "location_id", "event", "id"},
+		{"rooms", "room", "location_id", "room", "id"},
+		{"talkDates", "talk_date", "location_id", "talk_date", "id"},
+	},
+	"event": {
+		{"talkDates", "talk_date", "event_id", "talk_date", "id"},
+		{"tracks", "track", "event_id", "track", "id"},
+	},
+	"organization": {
+		{"events", "event", "organization_id", "event", "id"},
+		{"persons", "person", "organization_id", "person", "id"},
+		{"rooms", "room", "organization_id", "room", "id"},
+		{"talks", "talk", "organization_id", "talk", "id"},
+	},
+	"person": {
+		{"talks", "talks_at", "person_id", "talk", "talk_id"},
+	},
+	"room": {
+		{"talkDates", "talk_date", "room_id", "talk_date", "id"},
+	},
+	"topic": {
+		{"talks", "talk_topic", "topic_id", "talk", "talk_id"},
+		{"parentTopics", "is_child_of", "child_topic_id", "topic", "topic_id"},
+	},
+	"track": {
+		{"talks", "talk", "track_id", "talk", "id"},
+	},
+	"talk": {
+		{"talkDates", "talk_date", "talk_id", "talk_date", "id"},
+	},
+	"talk_date": {
+		{"talkDates", "talk_date", "series_id", "talk_date", "id"},
+	},
+}
+
+// uniqueKey is a column whose values the rows which are not in the trash do not share, among the rows with the
+// same value of the scope column if there is one
+type uniqueKey struct {
+	column string
+	scope  string
+}
+
+// uniqueKeys lists the unique keys per table, like the unique indexes of the database.
+// Rows in the trash keep their values, so restoring one fails while another row holds them.
+var uniqueKeys = map[string][]uniqueKey{
+	"location":     {{"name", ""}},
+	"organization": {{"name", ""}},
+	"person":       {{"name", "organization_id"}, {"subject", ""}},
+}
+
+// tableEntities names the entity stored in each table, as recorded in the audit log
+var tableEntities = map[string]string{
+	"location":     "location",
+	"event":        "event",
+	"organization": "organization",
+	"person":       "person",
+	"room":         "room",
+	"topic":        "topic",
+	"track":        "track",
+	"talk":         "talk",
+	"talk_date":    "talkDate",
+}
+
+// purgeOrder lists the tables so that referencing tables come before the tables they reference
+var purgeOrder = []string{"talk_date", "talk", "track", "topic", "room", "person", "event", "organization", "location"}
+
+// deleteEntity moves the row with the given id from table to the trash. Unless cascade is set, it fails with a
+// *ConflictError listing the dependents when the row is still referenced; with cascade, the dependents
+// are moved to the trash as well, recursively, and recorded in the audit log. It is meant to be called within a transaction.
+func deleteEntity(ctx context.Context, tx *gorm.DB, entity string, table string, id uint, cascade bool) error {
+	dependents, err := findDependents(tx, table, id)
+	if err != nil {
+		return err
+	}
+	if len(dependents) > 0 && !cascade {
+		return &ConflictError{Entity: entity, Message: "is still referenced by other entities", Dependents: dependents}
+	}
+
+	// all rows deleted by a cascade share the timestamp, so they can be restored together
+	deletedAt := time.Now().UTC().Truncate(time.Second)
+	return softDelete(ctx, tx, table, id, deletedAt)
+}
+
+func softDelete(ctx context.Context, tx *gorm.DB, table string, id uint, deletedAt time.Time) error {
+	for _, ref := range references[table] {
+		if ref.join() {
+			// links are kept, they reappear when the entity is restored
+			continue
+		}
+
+		var ids []uint
+		if err := tx.Table(ref.table).Where(ref.column+" = ? AND deleted_at IS NULL", id).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		for _, dependentID := range ids {
+			if err := softDelete(ctx, tx, ref.table, dependentID, deletedAt); err != nil {
+				return err
+			}
+			if err := recordAudit(ctx, tx, AuditDelete, tableEntities[ref.table], dependentID,
+				map[string]interface{}{"deletedAt": nil}, map[string]interface{}{"deletedAt": deletedAt}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Exec("UPDATE "+table+" SET deleted_at = ? WHERE id = ?", deletedAt, id).Error
+}
+
+func findDependents(tx *gorm.DB, table string, id uint) ([]Dependent, error) {
+	var dependents []Dependent
+	for _, ref := range references[table] {
+		query := tx.Table(ref.table).Where(ref.table+"."+ref.column+" = ?", id)
+		if ref.join() {
+			query = query.Joins("JOIN " + ref.idTable + " ON " + ref.idTable + ".id = " + ref.table + "." + ref.idColumn)
+		}
+
+		var ids []uint
+		if err := query.Where(ref.idTable+".deleted_at IS NULL").Pluck(ref.table+"."+ref.idColumn, &ids).Error; err != nil {
+			return nil, err
+		}
+		if len(ids) > 0 {
+			dependents = append(dependents, Dependent{ref.entity, ids})
+		}
+	}
+	return dependents, nil
+}
+
+// restoreEntity takes the row with the given id out of the trash, together with the rows which were moved
+// to the trash by the same cascading delete. It fails with a *ConflictError when the row refers to entities
+// which are still in the trash, or when a unique value of the rows is held by another row, and with gorm.ErrRecordNotFound when the row does not belong to the tenant of the
+// caller. It is meant to be called within a transaction.
+func restoreEntity(ctx context.Context, tx *gorm.DB, entity string, table string, id uint) error {
+	var row struct {
+		DeletedAt *time.Time
+	}
+	if err := scoped(ctx, tx.Table(table), entity).Select("deleted_at").Where("id = ?", id).Scan(&row).Error; err != nil {
+		return err
+	}
+	if row.DeletedAt == nil {
+		return &ConflictError{Entity: entity, Message: "is not deleted"}
+	}
+
+	for parentTable, refs := range references {
+		for _, ref := range refs {
+			if ref.table != table || ref.join() {
+				continue
+			}
+
+			var deletedParents []uint
+			if err := tx.Table(parentTable).
+				Where("id IN ? AND deleted_at IS NOT NULL", tx.Table(table).Select(ref.column).Where("id = ?", id).SubQuery()).
+				Pluck("id", &deletedParents).Error; err != nil {
+				return err
+			}
+			if len(deletedParents) > 0 {
+				return &ConflictError{Entity: entity, Message: fmt.Sprintf("refers to %s %d which is deleted, restore it first", parentTable, deletedParents[0])}
+			}
+		}
+	}
+
+	return restore(ctx, tx, entity, table, id, *row.DeletedAt)
+}
+
+// restore takes the row and its dependents deleted at the same time out of the trash. It fails with a *ConflictError,
+// reported for the entity being restored, when one of them has the unique value of a row which is not in the trash.
+func restore(ctx context.Context, tx *gorm.DB, entity string, table string, id uint, deletedAt time.Time) error {
+	for _, key := range uniqueKeys[table] {
+		query := tx.Table(table).Where("deleted_at IS NULL AND "+key.column+" = ?", tx.Table(table).Select(key.column).Where("id = ?", id).SubQuery())
+		if key.scope != "" {
+			query = query.Where(key.scope+" = ?", tx.Table(table).Select(key.scope).Where("id = ?", id).SubQuery())
+		}
+		var taken []uint
+		if err := query.Pluck("id", &taken).Error; err != nil {
+			return err
+		}
+		if len(taken) > 0 {
+			return &ConflictError{Entity: entity, Message: fmt.Sprintf("cannot be restored, %s %d has the same %s as %s %d",
+				tableEntities[table], id, key.column, tableEntities[table], taken[0])}
+		}
+	}
+
+	if err := tx.Exec("UPDATE "+table+" SET deleted_at = NULL WHERE id = ?", id).Error; err != nil {
+		return err
+	}
+
+	for _, ref := range references[table] {
+		if ref.join() {
+			continue
+		}
+
+		var ids []uint
+		if err := tx.Table(ref.table).Where(ref.column+" = ? AND deleted_at = ?", id, deletedAt).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		for _, dependentID := range ids {
+			if err := restore(ctx, tx, entity, ref.table, dependentID, deletedAt); err != nil {
+				return err
+			}
+			if err := recordAudit(ctx, tx, AuditRestore, tableEntities[ref.table], dependentID,
+				map[string]interface{}{"deletedAt": deletedAt}, map[string]interface{}{"deletedAt": nil}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// purgeDeleted permanently removes the rows which were moved to the trash before the given time,
+// along with the join table rows linking them. Rows which are still referenced are kept.
+func purgeDeleted(tx *gorm.DB, before time.Time) (int64, error) {
+	var purged int64
+	for _, table := range purgeOrder {
+		query := tx.Table(table).Where("deleted_at IS NOT NULL AND deleted_at < ?", before)
+		for _, ref := range references[table] {
+			if !ref.join() {
+				// NULL references would make NOT IN match nothing
+				query = query.Where("id NOT IN ?", tx.Table(ref.table).Select(ref.column).Where(ref.column+" IS NOT NULL").SubQuery())
+			}
+		}
+
+		var ids []uint
+		if err := query.Pluck("id", &ids).Error; err != nil {
+			return purged, err
+		}
+		if len(ids) == 0 {
+			continue
+		}
+
+		for _, ref := range references[table] {
+			if ref.join() {
+				if err := tx.Exec("DELETE FROM "+ref.table+" WHERE "+ref.column+" IN (?)", ids).Error; err != nil {
+					return purged, err
+				}
+			}
+		}
+
+		result := tx.Exec("DELETE FROM "+table+" WHERE id IN (?)", ids)
+		if result.Error != nil {
+			return purged, result.Error
+		}
+		purged += result.RowsAffected
+	}
+	return purged, nil
+}
@@ -0,0 +1,128 @@
+package data
+
+import (
+	"database/sql/driver"
+	"errors"
+	"github.com/go-sql-driver/mysql"
+	"github.com/mattn/go-sqlite3"
+	"net"
+	"strings"
+)
+
+// Kinds of errors returned by the stores, to be checked with errors.Is
+var (
+	ErrNotFound    = errors.New("not found")
+	ErrConflict    = errors.New("conflict")
+	ErrValidation  = errors.New("validation failed")
+	ErrForbidden   = errors.New("forbidden")
+	ErrUnavailable = errors.New("unavailable")
+)
+
+// ConflictError is returned when an operation conflicts with the stored data, e.g. a duplicate unique value
+type ConflictError struct {
+	Entity     string
+	Message    string
+	Dependents []Dependent
+	Cause      error
+}
+
+func (e ConflictError) Error() string        { return e.Entity + " " + e.Message }
+func (e ConflictError) Unwrap() error        { return e.Cause }
+func (e ConflictError) Is(target error) bool { return target == ErrConflict }
+
+// ForbiddenError is returned when the caller is not allowed to perform an operation on an entity
+type ForbiddenError struct {
+	Entity  string
+	Message string
+}
+
+func (e ForbiddenError) Error() string        { return e.Entity + " " + e.Message }
+func (e ForbiddenError) Is(target error) bool { return target == ErrForbidden }
+
+// UnavailableError is returned when the database cannot be reached or is temporarily unable to serve the operation
+type UnavailableError struct {
+	Cause error
+}
+
+func (e UnavailableError) Error() string        { return "Database is unavailable" }
+func (e UnavailableError) Unwrap() error        { return e.Cause }
+func (e UnavailableError) Is(target error) bool { return target == ErrUnavailable }
+
+func (e ValidationError) Is(target error) bool { return target == ErrValidation }
+
+func (e LocationNotFoundError) Is(target error) bool     { return target == ErrNotFound }
+func (e EventNotFoundError) Is(target error) bool        { return target == ErrNotFound }
+func (e OrganizationNotFoundError) Is(target error) bool { return target == ErrNotFound }
+func (e PersonNotFoundError) Is(target error) bool       { return target == ErrNotFound }
+func (e RoomNotFoundError) Is(target error) bool         { return target == ErrNotFound }
+func (e TopicNotFoundError) Is(target error) bool        { return target == ErrNotFound }
+func (e TrackNotFoundError) Is(target error) bool        { return target == ErrNotFound }
+func (e TalkNotFoundError) Is(target error) bool         { return target == ErrNotFound }
+func (e TalkDateNotFoundError) Is(target error) bool     { return target == ErrNotFound }
+
+// translateError maps sqlite3 and mysql driver errors onto the store error kinds.
+// Errors which are not recognized are returned unchanged.
+func translateError(entity string, err error) error {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		switch {
+		case sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique || sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey:
+			return &ConflictError{Entity: entity, Message: uniqueMessage(sqliteColumn(sqliteErr.Error())), Cause: err}
+		case sqliteErr.ExtendedCode == sqlite3.ErrConstraintForeignKey || sqliteErr.ExtendedCode == sqlite3.ErrConstraintTrigger:
+			// foreign keys are emulated with triggers on sqlite
+			return &ConflictError{Entity: entity, Message: "refers to or is referenced by other entities", Cause: err}
+		case sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked || sqliteErr.Code == sqlite3.ErrCantOpen:
+			return &UnavailableError{err}
+		}
+		return err
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case 1062: // ER_DUP_ENTRY
+			return &ConflictError{Entity: entity, Message: uniqueMessage(mysqlKey(mysqlErr.Message)), Cause: err}
+		case 1451, 1452: // ER_ROW_IS_REFERENCED_2, ER_NO_REFERENCED_ROW_2
+			return &ConflictError{Entity: entity, Message: "refers to or is referenced by other entities", Cause: err}
+		case 1040, 1205, 1213: // ER_CON_COUNT_ERROR, ER_LOCK_WAIT_TIMEOUT, ER_LOCK_DEADLOCK
+			return &UnavailableError{err}
+		}
+		return err
+	}
+
+	var netErr net.Error
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, mysql.ErrInvalidConn) || errors.As(err, &netErr) {
+		return &UnavailableError{err}
+	}
+
+	return err
+}
+
+func uniqueMessage(field string) string {
+	if field == "" {
+		return "already exists"
+	}
+	return "with the same " + field + " already exists"
+}
+
+// sqliteColumn extracts the column from messages like "UNIQUE constraint failed: person.name"
+func sqliteColumn(msg string) string {
+	i := strings.LastIndex(msg, ".")
+	if i < 0 {
+		return ""
+	}
+	return msg[i+1:]
+}
+
+// mysqlKey extracts the key from messages like "Duplicate entry 'x' for key 'name'"
+func mysqlKey(msg string) string {
+	i := strings.LastIndex(msg, "for key '")
+	if i < 0 {
+		return ""
+	}
+	key := strings.TrimSuffix(msg[i+len("for key '"):], "'")
+	if j := strings.LastIndex(key, "."); j >= 0 {
+		key = key[j+1:]
+	}
+	return strings.TrimPrefix(key, "uix_")
+}
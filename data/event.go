@@ -1,30 +1,40 @@
 package data
 
 import (
+	"context"
 	"github.com/davecgh/go-spew/spew"
 	"github.com/go-playground/validator/v10"
 	"github.com/hashicorp/go-hclog"
 	"github.com/jinzhu/gorm"
+	"github.com/milutindzunic/pac-backend/tracing"
 	"time"
 )
 
 type Event struct {
 	// gorm.Model
 	ID         uint      `json:"id" gorm:"primary_key;auto_increment"`
-	Name       string    `json:"name" gorm:"not null;default:''"`
-	BeginDate  time.Time `json:"beginDate" gorm:"not null"`
-	EndDate    time.Time `json:"endDate" gorm:"not null"`
-	LocationID uint      `json:"-"`
-	Location   *Location `json:"location,omitempty" gorm:"association_autoupdate:false"`
+	Name       string    `json:"name" gorm:"not null;default:''" validate:"notblank"`
+	BeginDate  time.Time `json:"beginDate" gorm:"not null" validate:"required"`
+	EndDate    time.Time `json:"endDate" gorm:"not null" validate:"required,gtfield=BeginDate"`
+	LocationID *uint     `json:"-"`
+	Location   *Location `json:"location,omitempty" gorm:"association_autoupdate:false" validate:"-"`
+	// Timezone is the IANA time zone the times of the event are rendered in, the time zone of its location when empty
+	Timezone string `json:"timezone,omitempty" validate:"omitempty,timezone"`
+	// OrganizationID is the tenant owning the event, nullable as events created before tenancy have none
+	OrganizationID *uint         `json:"-"`
+	Organization   *Organization `json:"organization,omitempty" gorm:"association_autoupdate:false" validate:"-"`
+	DeletedAt      *time.Time    `json:"deletedAt,omitempty" sql:"index"`
 }
 
 type EventStore interface {
-	GetEvents() ([]*Event, error)
-	GetEventByID(id uint) (*Event, error)
-	UpdateEvent(id uint, event *Event) (*Event, error)
-	AddEvent(event *Event) (*Event, error)
-	DeleteEventByID(id uint) error
-	GetEventsByTalkID(talkID uint) ([]*Event, error)
+	GetEvents(ctx context.Context) ([]*Event, error)
+	GetEventByID(ctx context.Context, id uint) (*Event, error)
+	GetEventsByIDs(ctx context.Context, ids []uint) ([]*Event, error)
+	UpdateEvent(ctx context.Context, id uint, event *Event) (*Event, error)
+	AddEvent(ctx context.Context, event *Event) (*Event, error)
+	DeleteEventByID(ctx context.Context, id uint, cascade bool) error
+	RestoreEventByID(ctx context.Context, id uint) (*Event, error)
+	GetEventsByTalkID(ctx context.Context, talkID uint) ([]*Event, error)
 }
 
 type EventDBStore struct {
@@ -41,33 +51,51 @@ func (e EventNotFoundError) Error() string { return "Event not found! Cause: " +
 func (e EventNotFoundError) Unwrap() error { return e.Cause }
 
 func NewEventDBStore(db *gorm.DB, log hclog.Logger) *EventDBStore {
-	return &EventDBStore{db, validator.New(), log}
+	return &EventDBStore{db, newValidator(), log}
 }
 
-func (db *EventDBStore) GetEvents() ([]*Event, error) {
+// withTx returns a copy of the store running its queries within the transaction
+func (db *EventDBStore) withTx(tx *gorm.DB) *EventDBStore {
+	return &EventDBStore{tx, db.validate, db.log}
+}
+
+func (db *EventDBStore) traced(ctx context.Context) *EventDBStore {
+	tx, log := inContext(ctx, db.DB, db.log)
+	return &EventDBStore{tx, db.validate, log}
+}
+
+func (db *EventDBStore) GetEvents(ctx context.Context) ([]*Event, error) {
+	ctx, span := tracing.StartSpan(ctx, "EventDBStore.GetEvents")
+	defer span.End()
+	db = db.traced(ctx)
+
 	db.log.Debug("Getting all events...")
 
 	var events []*Event
-	if err := db.Preload("Location").Find(&events).Error; err != nil {
+	if err := preload(ctx, scoped(ctx, db.DB, "Event"), "Event").Find(&events).Error; err != nil {
 		db.log.Error("Error getting all events", "err", err)
-		return []*Event{}, err
+		return []*Event{}, translateError("Event", err)
 	}
 
 	db.log.Debug("Returning events", "events", spew.Sprintf("%+v", events))
 	return events, nil
 }
 
-func (db *EventDBStore) GetEventByID(id uint) (*Event, error) {
+func (db *EventDBStore) GetEventByID(ctx context.Context, id uint) (*Event, error) {
+	ctx, span := tracing.StartSpan(ctx, "EventDBStore.GetEventByID")
+	defer span.End()
+	db = db.traced(ctx)
+
 	db.log.Debug("Getting event by id...", "id", id)
 
 	var event Event
-	if err := db.Preload("Location").First(&event, id).Error; err != nil {
+	if err := preload(ctx, scoped(ctx, db.DB, "Event"), "Event").First(&event, id).Error; err != nil {
 		if gorm.IsRecordNotFoundError(err) {
 			db.log.Error("Event not found by id", "id", id)
 			return nil, &EventNotFoundError{err}
 		} else {
 			db.log.Error("Unexpected error getting event by id", "err", err)
-			return nil, err
+			return nil, translateError("Event", err)
 		}
 	}
 
@@ -75,57 +103,134 @@ func (db *EventDBStore) GetEventByID(id uint) (*Event, error) {
 	return &event, nil
 }
 
-func (db *EventDBStore) UpdateEvent(id uint, event *Event) (*Event, error) {
+// GetEventsByIDs returns the events with the given ids in a single query, unknown ids are skipped
+func (db *EventDBStore) GetEventsByIDs(ctx context.Context, ids []uint) ([]*Event, error) {
+	ctx, span := tracing.StartSpan(ctx, "EventDBStore.GetEventsByIDs")
+	defer span.End()
+	db = db.traced(ctx)
+
+	db.log.Debug("Getting events by ids...", "ids", ids)
+
+	var events []*Event
+	if err := preload(ctx, scoped(ctx, db.DB, "Event"), "Event").Where("id IN (?)", ids).Find(&events).Error; err != nil {
+		db.log.Error("Error getting events by ids", "err", err)
+		return []*Event{}, translateError("Event", err)
+	}
+
+	db.log.Debug("Returning events", "events", spew.Sprintf("%+v", events))
+	return events, nil
+}
+
+func (db *EventDBStore) UpdateEvent(ctx context.Context, id uint, event *Event) (*Event, error) {
+	ctx, span := tracing.StartSpan(ctx, "EventDBStore.UpdateEvent")
+	defer span.End()
+	db = db.traced(ctx)
+
 	db.log.Debug("Updating event...", "event", hclog.Fmt("%+v", event))
 
-	err := db.validate.Struct(event)
+	event.DeletedAt = nil
+	event.BeginDate, event.EndDate = event.BeginDate.UTC(), event.EndDate.UTC()
+	err := validateStruct(db.validate, "Event", event)
 	if err != nil {
 		db.log.Error("Error validating event", "err", err)
 		return nil, err
 	}
+	assignOptionalTenant(ctx, &event.OrganizationID, &event.Organization)
 
-	if err := db.Model(&Event{}).Where("id = ?", id).Take(&Event{}).Update(event).First(&event, id).Error; err != nil {
-		if gorm.IsRecordNotFoundError(err) {
-			db.log.Error("Event to be updated not found", "event", hclog.Fmt("%+v", event))
-			return nil, &EventNotFoundError{err}
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		before, err := db.withTx(tx).GetEventByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&Event{}).Where("id = ?", id).Update(event).Error; err != nil {
+			return err
+		}
+		if err := (&TalkDateDBStore{tx, db.validate, db.log}).fitEvent(ctx, id); err != nil {
+			return err
+		}
+		after, err := db.withTx(tx).GetEventByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		event = after
+		return recordAudit(ctx, tx, AuditUpdate, "event", id, before, after)
+	}); err != nil {
+		if _, ok := err.(*EventNotFoundError); ok {
+			db.log.Error("Event to be updated not found", "id", id)
+			return nil, err
+		} else if _, ok := err.(*ValidationError); ok {
+			db.log.Error("Error validating event", "err", err)
+			return nil, err
 		} else {
 			db.log.Error("Unexpected error updating event", "err", err)
-			return nil, err
+			return nil, translateError("Event", err)
 		}
 	}
 
 	db.log.Debug("Successfully updated event", "event", hclog.Fmt("%+v", event))
-	return db.GetEventByID(event.ID)
+	return event, nil
 }
 
-func (db *EventDBStore) AddEvent(event *Event) (*Event, error) {
+func (db *EventDBStore) AddEvent(ctx context.Context, event *Event) (*Event, error) {
+	ctx, span := tracing.StartSpan(ctx, "EventDBStore.AddEvent")
+	defer span.End()
+	db = db.traced(ctx)
+
 	db.log.Debug("Adding event...", "event", hclog.Fmt("%+v", event))
 
-	err := db.validate.Struct(event)
+	event.DeletedAt = nil
+	event.BeginDate, event.EndDate = event.BeginDate.UTC(), event.EndDate.UTC()
+	err := validateStruct(db.validate, "Event", event)
 	if err != nil {
 		db.log.Error("Error validating event", "err", err)
 		return nil, err
 	}
+	assignOptionalTenant(ctx, &event.OrganizationID, &event.Organization)
 
-	if err := db.Create(&event).Error; err != nil {
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&event).Error; err != nil {
+			return err
+		}
+		after, err := db.withTx(tx).GetEventByID(ctx, event.ID)
+		if err != nil {
+			return err
+		}
+		event = after
+		return recordAudit(ctx, tx, AuditCreate, "event", event.ID, nil, after)
+	}); err != nil {
 		db.log.Error("Unexpected error creating event", "err", err)
-		return nil, err
+		return nil, translateError("Event", err)
 	}
 
 	db.log.Debug("Successfully added event", "event", hclog.Fmt("%+v", event))
-	return db.GetEventByID(event.ID)
+	return event, nil
 }
 
-func (db *EventDBStore) DeleteEventByID(id uint) error {
-	db.log.Debug("Deleting event by id...", "id", id)
+// DeleteEventByID moves the event to the trash, see deleteEntity. Its tracks and talk dates keep it from being deleted,
+// unless they are moved to the trash along with it by cascade.
+func (db *EventDBStore) DeleteEventByID(ctx context.Context, id uint, cascade bool) error {
+	ctx, span := tracing.StartSpan(ctx, "EventDBStore.DeleteEventByID")
+	defer span.End()
+	db = db.traced(ctx)
 
-	if err := db.Model(&Event{}).Where("id = ?", id).Take(&Event{}).Delete(&Event{}).Error; err != nil {
-		if gorm.IsRecordNotFoundError(err) {
-			db.log.Error("Event not found by id", "id", id)
-			return &EventNotFoundError{err}
+	db.log.Debug("Deleting event by id...", "id", id, "cascade", cascade)
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		before, err := db.withTx(tx).GetEventByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := deleteEntity(ctx, tx, "Event", "event", id, cascade); err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, AuditDelete, "event", id, before, nil)
+	}); err != nil {
+		if _, ok := err.(*EventNotFoundError); ok {
+			db.log.Error("Event to be deleted not found", "id", id)
+			return err
 		} else {
 			db.log.Error("Unexpected error deleting event", "err", err)
-			return err
+			return translateError("Event", err)
 		}
 	}
 
@@ -133,16 +238,53 @@ func (db *EventDBStore) DeleteEventByID(id uint) error {
 	return nil
 }
 
-func (db *EventDBStore) GetEventsByTalkID(talkID uint) ([]*Event, error) {
+// RestoreEventByID takes the event out of the trash with its tracks and talk dates deleted along with it, unless its
+// location or organization is in the trash
+func (db *EventDBStore) RestoreEventByID(ctx context.Context, id uint) (*Event, error) {
+	ctx, span := tracing.StartSpan(ctx, "EventDBStore.RestoreEventByID")
+	defer span.End()
+	db = db.traced(ctx)
+
+	db.log.Debug("Restoring event by id...", "id", id)
+
+	var event *Event
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := restoreEntity(ctx, tx, "Event", "event", id); err != nil {
+			return err
+		}
+		after, err := db.withTx(tx).GetEventByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		event = after
+		return recordAudit(ctx, tx, AuditRestore, "event", id, nil, after)
+	}); err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			db.log.Error("Event not found by id", "id", id)
+			return nil, &EventNotFoundError{err}
+		} else {
+			db.log.Error("Unexpected error restoring event", "err", err)
+			return nil, translateError("Event", err)
+		}
+	}
+
+	db.log.Debug("Successfully restored event")
+	return event, nil
+}
+
+func (db *EventDBStore) GetEventsByTalkID(ctx context.Context, talkID uint) ([]*Event, error) {
+	ctx, span := tracing.StartSpan(ctx, "EventDBStore.GetEventsByTalkID")
+	defer span.End()
+	db = db.traced(ctx)
+
 	db.log.Debug("Getting event by talk id...", "talkID", talkID)
 
 	var events []*Event
-	if err := db.
-		Preload("Location").
-		Where("id IN ?", db.Table("talk_date").Select("event_id").Where("talk_id = ?", talkID).SubQuery()).
+	if err := preload(ctx, scoped(ctx, db.DB, "Event"), "Event").
+		Where("id IN ?", db.Table("talk_date").Select("event_id").Where("talk_id = ? AND deleted_at IS NULL", talkID).SubQuery()).
 		Find(&events).Error; err != nil {
 		db.log.Error("Error getting events", "err", err)
-		return []*Event{}, err
+		return []*Event{}, translateError("Event", err)
 	}
 
 	db.log.Debug("Returning events", "events", spew.Sprintf("%+v", events))
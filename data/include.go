@@ -0,0 +1,233 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"github.com/jinzhu/gorm"
+	"sort"
+	"strings"
+)
+
+// relation is a relation of an entity which can be expanded by the reads of the stores
+type relation struct {
+	// field is the name of the struct field, as passed to Preload
+	field string
+	// entity is the entity the relation points to
+	entity string
+}
+
+// relations are the relations of each entity, by their name in JSON
+var relations = map[string]map[string]relation{
+	"Location":     {},
+	"Organization": {},
+	"Event": {
+		"location":     {"Location", "Location"},
+		"organization": {"Organization", "Organization"},
+	},
+	"Person": {"organization": {"Organization", "Organization"}},
+	"Room": {
+		"organization": {"Organization", "Organization"},
+		"location":     {"Location", "Location"},
+	},
+	"Topic": {"children": {"Children", "Topic"}},
+	"Track": {"event": {"Event", "Event"}},
+	"Talk": {
+		"track":        {"Track", "Track"},
+		"persons":      {"Persons", "Person"},
+		"topics":       {"Topics", "Topic"},
+		"talkDates":    {"TalkDates", "TalkDate"},
+		"organization": {"Organization", "Organization"},
+	},
+	"TalkDate": {
+		"talk":     {"Talk", "Talk"},
+		"room":     {"Room", "Room"},
+		"event":    {"Event", "Event"},
+		"location": {"Location", "Location"},
+		"series":   {"Series", "TalkDate"},
+	},
+}
+
+// defaultIncludes are the relations expanded when the caller does not choose them,
+// the same for the list, by-id and by-relation reads of an entity
+var defaultIncludes = map[string][]string{
+	"Location":     {},
+	"Organization": {},
+	"Event":        {"location", "organization"},
+	"Person":       {"organization"},
+	"Room":         {"organization", "location"},
+	"Topic":        {"children"},
+	"Track":        {"event"},
+	"Talk":         {"track", "persons.organization", "topics.children", "talkDates.room", "talkDates.event", "organization"},
+	"TalkDate":     {"talk.persons", "talk.topics.children", "room", "event", "location", "series"},
+}
+
+type includesKey struct{}
+
+// WithIncludes returns a copy of the context choosing the relations expanded by the reads of the stores, as paths
+// of relation names like "talkDates.room", which expand every relation along the path. An empty, non-nil slice
+// expands no relations at all, while a nil slice expands the default relations of the entity.
+// The paths are expected to be checked with ParseIncludes first.
+func WithIncludes(ctx context.Context, includes []string) context.Context {
+	return context.WithValue(ctx, includesKey{}, includes)
+}
+
+// includesOf returns the relations chosen by the context, or the default relations of the entity
+func includesOf(ctx context.Context, entity string) []string {
+	if includes, ok := ctx.Value(includesKey{}).([]string); ok && includes != nil {
+		return includes
+	}
+	return defaultIncludes[entity]
+}
+
+// Limits of the include query parameter, bounding the preloads a single read runs,
+// like the depth and complexity limits of GraphQL queries
+const (
+	// maxIncludeDepth is the maximum number of relations along a path, e.g. 3 for "talk.topics.children"
+	maxIncludeDepth = 4
+	// maxIncludes is the maximum number of paths
+	maxIncludes = 16
+)
+
+// IncludeLimitError is returned by ParseIncludes when the include query parameter exceeds the limits
+// of the paths, as opposed to the *ValidationError of paths which do not name relations
+type IncludeLimitError struct {
+	ValidationError
+}
+
+func (e *IncludeLimitError) Unwrap() error { return &e.ValidationError }
+
+// ParseIncludes parses the comma separated relation paths of the include query parameter, returning
+// an *IncludeLimitError if there are too many or too deep paths, or a validation error if a path does not
+// name relations of the entity
+func ParseIncludes(entity string, raw string) ([]string, error) {
+	includes := []string{}
+	for _, path := range strings.Split(raw, ",") {
+		path = strings.TrimSpace(path)
+		if path != "" {
+			includes = append(includes, path)
+		}
+	}
+	if len(includes) > maxIncludes {
+		return nil, &IncludeLimitError{ValidationError{Entity: "Query", Errors: []FieldError{
+			{Field: "include", Rule: "max", Message: fmt.Sprintf("must not contain more than %d paths", maxIncludes)}}}}
+	}
+
+	var limitErrs, errs []FieldError
+	for _, path := range includes {
+		if depth := strings.Count(path, ".") + 1; depth > maxIncludeDepth {
+			limitErrs = append(limitErrs, FieldError{Field: "include", Rule: "max",
+				Message: fmt.Sprintf("%s is %d relations deep, more than the maximum of %d", path, depth, maxIncludeDepth)})
+			continue
+		}
+		if _, err := preloadsOf(entity, path); err != nil {
+			errs = append(errs, FieldError{Field: "include", Rule: "relation", Message: err.Error()})
+		}
+	}
+	if len(limitErrs) > 0 {
+		return nil, &IncludeLimitError{ValidationError{Entity: "Query", Errors: limitErrs}}
+	}
+	if len(errs) > 0 {
+		return nil, &ValidationError{Entity: "Query", Errors: errs}
+	}
+	return includes, nil
+}
+
+// IncludesKey identifies the relations chosen by the context, to tell apart the cached reads of different shapes.
+// It is empty for the default relations.
+func IncludesKey(ctx context.Context) string {
+	includes, ok := ctx.Value(includesKey{}).([]string)
+	if !ok || includes == nil {
+		return ""
+	}
+	sorted := append([]string{}, includes...)
+	sort.Strings(sorted)
+	return "include=" + strings.Join(sorted, ",")
+}
+
+// IncludedEntities returns the entities expanded by the reads of the entity in the context,
+// i.e. the entities whose changes change the result of the reads
+func IncludedEntities(ctx context.Context, entity string) []string {
+	seen := map[string]bool{}
+	var entities []string
+	for _, path := range includesOf(ctx, entity) {
+		current := entity
+		for _, name := range strings.Split(path, ".") {
+			r, ok := relations[current][name]
+			if !ok {
+				break
+			}
+			current = r.entity
+			if !seen[current] {
+				seen[current] = true
+				entities = append(entities, current)
+			}
+		}
+	}
+	return entities
+}
+
+// preload returns db preloading the relations of the entity chosen by the context, along with the required ones
+// which the store needs regardless of what the caller chose. Every relation is preloaded once, parents first.
+func preload(ctx context.Context, db *gorm.DB, entity string, required ...string) *gorm.DB {
+	var fields []string
+	seen := map[string]bool{}
+	for _, path := range append(append([]string{}, includesOf(ctx, entity)...), required...) {
+		preloads, err := preloadsOf(entity, path)
+		if err != nil {
+			// unreachable for checked paths, unknown relations are skipped rather than failing the read
+			continue
+		}
+		for _, field := range preloads {
+			if !seen[field] {
+				seen[field] = true
+				fields = append(fields, field)
+			}
+		}
+	}
+
+	// parents sort before their children, so they are loaded first
+	sort.Strings(fields)
+	for _, field := range fields {
+		db = db.Preload(field)
+	}
+	return db
+}
+
+// preloadsOf translates a relation path of the entity into the preloads of every relation along the path,
+// e.g. "talkDates.room" into "TalkDates" and "TalkDates.Room"
+func preloadsOf(entity string, path string) ([]string, error) {
+	names := strings.Split(path, ".")
+	if len(names) > maxIncludeDepth {
+		return nil, fmt.Errorf("%s is deeper than the maximum of %d relations", path, maxIncludeDepth)
+	}
+
+	var preloads []string
+	var field string
+	current := entity
+	for _, name := range names {
+		r, ok := relations[current][name]
+		if !ok {
+			return nil, &includeError{path, name, current}
+		}
+		if field != "" {
+			field += "."
+		}
+		field += r.field
+		preloads = append(preloads, field)
+		current = r.entity
+	}
+	return preloads, nil
+}
+
+type includeError struct {
+	path   string
+	name   string
+	entity string
+}
+
+func (e *includeError) Error() string {
+	if e.path == e.name {
+		return e.name + " is not a relation of " + e.entity
+	}
+	return e.path + ": " + e.name + " is not a relation of " + e.entity
+}
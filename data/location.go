@@ -1,30 +1,57 @@
 package data
 
 import (
+	"context"
 	"github.com/davecgh/go-spew/spew"
 	"github.com/go-playground/validator/v10"
 	"github.com/hashicorp/go-hclog"
 	"github.com/jinzhu/gorm"
+	"github.com/milutindzunic/pac-backend/tracing"
+	"math"
+	"sort"
+	"time"
 )
 
 type Location struct {
 	// gorm.Model
-	ID   uint   `json:"id" gorm:"primary_key;auto_increment"`
-	Name string `json:"name" gorm:"unique;not null;default:''"`
+	ID      uint    `json:"id" gorm:"primary_key;auto_increment"`
+	Name    string  `json:"name" gorm:"not null;default:''" validate:"notblank"`
+	Address Address `json:"address" gorm:"embedded;embedded_prefix:address_"`
+	// Latitude and Longitude are the coordinates of the venue in degrees, either both set or none
+	Latitude  *float64 `json:"latitude,omitempty" validate:"required_with=Longitude,omitempty,latitude"`
+	Longitude *float64 `json:"longitude,omitempty" validate:"required_with=Latitude,omitempty,longitude"`
+	// Timezone is the IANA time zone of the venue, in which the times of its events and talk dates are rendered
+	Timezone    string     `json:"timezone,omitempty" validate:"omitempty,timezone"`
+	Description string     `json:"description,omitempty" gorm:"type:text" validate:"max=5000"`
+	DeletedAt   *time.Time `json:"deletedAt,omitempty" sql:"index"`
+	// Distance is the distance in kilometers from the point of a nearby search, it is not stored
+	Distance *float64 `json:"distance,omitempty" gorm:"-"`
+}
+
+// Address is the postal address of a location
+type Address struct {
+	Street     string `json:"street,omitempty" validate:"max=200"`
+	PostalCode string `json:"postalCode,omitempty" validate:"max=20"`
+	City       string `json:"city,omitempty" validate:"max=100"`
+	// Country is the ISO 3166-1 alpha-2 code of the country, like "RS"
+	Country string `json:"country,omitempty" validate:"omitempty,country"`
 }
 
 type LocationStore interface {
-	GetLocations() ([]*Location, error)
-	GetLocationByID(id uint) (*Location, error)
-	UpdateLocation(id uint, loc *Location) (*Location, error)
-	AddLocation(loc *Location) (*Location, error)
-	DeleteLocationByID(id uint) error
+	GetLocations(ctx context.Context) ([]*Location, error)
+	GetLocationByID(ctx context.Context, id uint) (*Location, error)
+	GetLocationsByIDs(ctx context.Context, ids []uint) ([]*Location, error)
+	GetLocationsNear(ctx context.Context, latitude, longitude, radius float64) ([]*Location, error)
+	UpdateLocation(ctx context.Context, id uint, loc *Location) (*Location, error)
+	AddLocation(ctx context.Context, loc *Location) (*Location, error)
+	DeleteLocationByID(ctx context.Context, id uint, cascade bool) error
+	RestoreLocationByID(ctx context.Context, id uint) (*Location, error)
 }
 
 type LocationDBStore struct {
 	*gorm.DB
 	validate *validator.Validate
-	log hclog.Logger
+	log      hclog.Logger
 }
 
 type LocationNotFoundError struct {
@@ -35,23 +62,41 @@ func (e LocationNotFoundError) Error() string { return "Location not found! Caus
 func (e LocationNotFoundError) Unwrap() error { return e.Cause }
 
 func NewLocationDBStore(db *gorm.DB, log hclog.Logger) *LocationDBStore {
-	return &LocationDBStore{db, validator.New(), log}
+	return &LocationDBStore{db, newValidator(), log}
+}
+
+// withTx returns a copy of the store running its queries within the transaction
+func (db *LocationDBStore) withTx(tx *gorm.DB) *LocationDBStore {
+	return &LocationDBStore{tx, db.validate, db.log}
+}
+
+func (db *LocationDBStore) traced(ctx context.Context) *LocationDBStore {
+	tx, log := inContext(ctx, db.DB, db.log)
+	return &LocationDBStore{tx, db.validate, log}
 }
 
-func (db *LocationDBStore) GetLocations() ([]*Location, error) {
+func (db *LocationDBStore) GetLocations(ctx context.Context) ([]*Location, error) {
+	ctx, span := tracing.StartSpan(ctx, "LocationDBStore.GetLocations")
+	defer span.End()
+	db = db.traced(ctx)
+
 	db.log.Debug("Getting all locations...")
 
 	var locations []*Location
 	if err := db.Find(&locations).Error; err != nil {
 		db.log.Error("Error getting all locations", "err", err)
-		return []*Location{}, err
+		return []*Location{}, translateError("Location", err)
 	}
 
 	db.log.Debug("Returning locations", "locations", spew.Sprintf("%+v", locations))
 	return locations, nil
 }
 
-func (db *LocationDBStore) GetLocationByID(id uint) (*Location, error) {
+func (db *LocationDBStore) GetLocationByID(ctx context.Context, id uint) (*Location, error) {
+	ctx, span := tracing.StartSpan(ctx, "LocationDBStore.GetLocationByID")
+	defer span.End()
+	db = db.traced(ctx)
+
 	db.log.Debug("Getting location by id...", "id", id)
 
 	var location Location
@@ -61,7 +106,7 @@ func (db *LocationDBStore) GetLocationByID(id uint) (*Location, error) {
 			return nil, &LocationNotFoundError{err}
 		} else {
 			db.log.Error("Unexpected error getting location by id", "err", err)
-			return nil, err
+			return nil, translateError("Location", err)
 		}
 	}
 
@@ -69,22 +114,108 @@ func (db *LocationDBStore) GetLocationByID(id uint) (*Location, error) {
 	return &location, nil
 }
 
-func (db *LocationDBStore) UpdateLocation(id uint, location *Location) (*Location, error) {
+// GetLocationsByIDs returns the locations with the given ids in a single query, unknown ids are skipped
+func (db *LocationDBStore) GetLocationsByIDs(ctx context.Context, ids []uint) ([]*Location, error) {
+	ctx, span := tracing.StartSpan(ctx, "LocationDBStore.GetLocationsByIDs")
+	defer span.End()
+	db = db.traced(ctx)
+
+	db.log.Debug("Getting locations by ids...", "ids", ids)
+
+	var locations []*Location
+	if err := db.Where("id IN (?)", ids).Find(&locations).Error; err != nil {
+		db.log.Error("Error getting locations by ids", "err", err)
+		return []*Location{}, translateError("Location", err)
+	}
+
+	db.log.Debug("Returning locations", "locations", spew.Sprintf("%+v", locations))
+	return locations, nil
+}
+
+// GetLocationsNear returns the locations within radius kilometers of the point, nearest first, with their distance set.
+// Locations without coordinates are skipped.
+func (db *LocationDBStore) GetLocationsNear(ctx context.Context, latitude, longitude, radius float64) ([]*Location, error) {
+	ctx, span := tracing.StartSpan(ctx, "LocationDBStore.GetLocationsNear")
+	defer span.End()
+	db = db.traced(ctx)
+
+	db.log.Debug("Getting locations near...", "latitude", latitude, "longitude", longitude, "radius", radius)
+
+	// the latitudes within the radius are narrowed down in the database, the exact distance is computed here,
+	// which also copes with the longitudes wrapping around at the antimeridian
+	delta := radius / earthRadius * 180 / math.Pi
+	var locations []*Location
+	if err := db.Where("latitude BETWEEN ? AND ? AND longitude IS NOT NULL", latitude-delta, latitude+delta).Find(&locations).Error; err != nil {
+		db.log.Error("Error getting locations near", "err", err)
+		return []*Location{}, translateError("Location", err)
+	}
+
+	near := []*Location{}
+	for _, location := range locations {
+		distance := haversine(latitude, longitude, *location.Latitude, *location.Longitude)
+		if distance <= radius {
+			location.Distance = &distance
+			near = append(near, location)
+		}
+	}
+	sort.SliceStable(near, func(i, j int) bool { return *near[i].Distance < *near[j].Distance })
+
+	db.log.Debug("Returning locations", "locations", spew.Sprintf("%+v", near))
+	return near, nil
+}
+
+// earthRadius is the mean radius of the earth in kilometers
+const earthRadius = 6371.0
+
+// haversine returns the great-circle distance in kilometers between two points given in degrees
+func haversine(lat1, lng1, lat2, lng2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRad(lat2 - lat1)
+	dLng := toRad(lng2 - lng1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+func (db *LocationDBStore) UpdateLocation(ctx context.Context, id uint, location *Location) (*Location, error) {
+	ctx, span := tracing.StartSpan(ctx, "LocationDBStore.UpdateLocation")
+	defer span.End()
+	db = db.traced(ctx)
+
 	db.log.Debug("Updating location...", "location", hclog.Fmt("%+v", location))
 
-	err := db.validate.Struct(location)
+	if err := requirePlatformAdmin(ctx, "Location"); err != nil {
+		db.log.Error("Not allowed to update location", "err", err)
+		return nil, err
+	}
+
+	location.DeletedAt = nil
+	err := validateStruct(db.validate, "Location", location)
 	if err != nil {
 		db.log.Error("Error validating location", "err", err)
 		return nil, err
 	}
 
-	if err := db.Model(&Location{}).Where("id = ?", id).Take(&Location{}).Update(location).First(&location, id).Error; err != nil {
-		if gorm.IsRecordNotFoundError(err) {
-			db.log.Error("Location to be updated not found", "location", hclog.Fmt("%+v", location))
-			return nil, &LocationNotFoundError{err}
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		before, err := db.withTx(tx).GetLocationByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&Location{}).Where("id = ?", id).Update(location).Error; err != nil {
+			return err
+		}
+		after, err := db.withTx(tx).GetLocationByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		location = after
+		return recordAudit(ctx, tx, AuditUpdate, "location", id, before, after)
+	}); err != nil {
+		if _, ok := err.(*LocationNotFoundError); ok {
+			db.log.Error("Location to be updated not found", "id", id)
+			return nil, err
 		} else {
 			db.log.Error("Unexpected error updating location", "err", err)
-			return nil, err
+			return nil, translateError("Location", err)
 		}
 	}
 
@@ -92,37 +223,116 @@ func (db *LocationDBStore) UpdateLocation(id uint, location *Location) (*Locatio
 	return location, nil
 }
 
-func (db *LocationDBStore) AddLocation(location *Location) (*Location, error) {
+func (db *LocationDBStore) AddLocation(ctx context.Context, location *Location) (*Location, error) {
+	ctx, span := tracing.StartSpan(ctx, "LocationDBStore.AddLocation")
+	defer span.End()
+	db = db.traced(ctx)
+
 	db.log.Debug("Adding location...", "location", hclog.Fmt("%+v", location))
 
-	err := db.validate.Struct(location)
+	if err := requirePlatformAdmin(ctx, "Location"); err != nil {
+		db.log.Error("Not allowed to add location", "err", err)
+		return nil, err
+	}
+
+	location.DeletedAt = nil
+	err := validateStruct(db.validate, "Location", location)
 	if err != nil {
 		db.log.Error("Error validating location", "err", err)
 		return nil, err
 	}
 
-	if err := db.Create(&location).Error; err != nil {
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&location).Error; err != nil {
+			return err
+		}
+		after, err := db.withTx(tx).GetLocationByID(ctx, location.ID)
+		if err != nil {
+			return err
+		}
+		location = after
+		return recordAudit(ctx, tx, AuditCreate, "location", location.ID, nil, after)
+	}); err != nil {
 		db.log.Error("Unexpected error creating location", "err", err)
-		return nil, err
+		return nil, translateError("Location", err)
 	}
 
 	db.log.Debug("Successfully added location", "location", hclog.Fmt("%+v", location))
 	return location, nil
 }
 
-func (db *LocationDBStore) DeleteLocationByID(id uint) error {
-	db.log.Debug("Deleting location by id...", "id", id)
+// DeleteLocationByID moves the location to the trash, see deleteEntity. Its events, rooms and talk dates keep it
+// from being deleted, unless they are moved to the trash along with it by cascade.
+func (db *LocationDBStore) DeleteLocationByID(ctx context.Context, id uint, cascade bool) error {
+	ctx, span := tracing.StartSpan(ctx, "LocationDBStore.DeleteLocationByID")
+	defer span.End()
+	db = db.traced(ctx)
 
-	if err := db.Model(&Location{}).Where("id = ?", id).Take(&Location{}).Delete(&Location{}).Error; err != nil {
-		if gorm.IsRecordNotFoundError(err) {
-			db.log.Error("Location not found by id", "id", id)
-			return &LocationNotFoundError{err}
+	db.log.Debug("Deleting location by id...", "id", id, "cascade", cascade)
+
+	if err := requirePlatformAdmin(ctx, "Location"); err != nil {
+		db.log.Error("Not allowed to delete location", "err", err)
+		return err
+	}
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		before, err := db.withTx(tx).GetLocationByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := deleteEntity(ctx, tx, "Location", "location", id, cascade); err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, AuditDelete, "location", id, before, nil)
+	}); err != nil {
+		if _, ok := err.(*LocationNotFoundError); ok {
+			db.log.Error("Location to be deleted not found", "id", id)
+			return err
 		} else {
 			db.log.Error("Unexpected error deleting location", "err", err)
-			return err
+			return translateError("Location", err)
 		}
 	}
 
 	db.log.Debug("Successfully deleted location")
 	return nil
 }
+
+// RestoreLocationByID takes the location out of the trash with the entities deleted along with it, unless another
+// location has taken its name meanwhile
+func (db *LocationDBStore) RestoreLocationByID(ctx context.Context, id uint) (*Location, error) {
+	ctx, span := tracing.StartSpan(ctx, "LocationDBStore.RestoreLocationByID")
+	defer span.End()
+	db = db.traced(ctx)
+
+	db.log.Debug("Restoring location by id...", "id", id)
+
+	if err := requirePlatformAdmin(ctx, "Location"); err != nil {
+		db.log.Error("Not allowed to restore location", "err", err)
+		return nil, err
+	}
+
+	var location *Location
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := restoreEntity(ctx, tx, "Location", "location", id); err != nil {
+			return err
+		}
+		after, err := db.withTx(tx).GetLocationByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		location = after
+		return recordAudit(ctx, tx, AuditRestore, "location", id, nil, after)
+	}); err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			db.log.Error("Location not found by id", "id", id)
+			return nil, &LocationNotFoundError{err}
+		} else {
+			db.log.Error("Unexpected error restoring location", "err", err)
+			return nil, translateError("Location", err)
+		}
+	}
+
+	db.log.Debug("Successfully restored location")
+	return location, nil
+}
@@ -1,24 +1,30 @@
 package data
 
 import (
+	"context"
 	"github.com/davecgh/go-spew/spew"
 	"github.com/go-playground/validator/v10"
 	"github.com/hashicorp/go-hclog"
 	"github.com/jinzhu/gorm"
+	"github.com/milutindzunic/pac-backend/tracing"
+	"time"
 )
 
 type Organization struct {
 	// gorm.Model
-	ID      uint     `json:"id" gorm:"primary_key;auto_increment"`
-	Name    string   `json:"name" gorm:"unique;not null;default:''"`
+	ID        uint       `json:"id" gorm:"primary_key;auto_increment"`
+	Name      string     `json:"name" gorm:"not null;default:''" validate:"notblank"`
+	DeletedAt *time.Time `json:"deletedAt,omitempty" sql:"index"`
 }
 
 type OrganizationStore interface {
-	GetOrganizations() ([]*Organization, error)
-	GetOrganizationByID(id uint) (*Organization, error)
-	UpdateOrganization(id uint, organization *Organization) (*Organization, error)
-	AddOrganization(organization *Organization) (*Organization, error)
-	DeleteOrganizationByID(id uint) error
+	GetOrganizations(ctx context.Context) ([]*Organization, error)
+	GetOrganizationByID(ctx context.Context, id uint) (*Organization, error)
+	GetOrganizationsByIDs(ctx context.Context, ids []uint) ([]*Organization, error)
+	UpdateOrganization(ctx context.Context, id uint, organization *Organization) (*Organization, error)
+	AddOrganization(ctx context.Context, organization *Organization) (*Organization, error)
+	DeleteOrganizationByID(ctx context.Context, id uint, cascade bool) error
+	RestoreOrganizationByID(ctx context.Context, id uint) (*Organization, error)
 }
 
 type OrganizationDBStore struct {
@@ -37,33 +43,51 @@ func (e OrganizationNotFoundError) Error() string {
 func (e OrganizationNotFoundError) Unwrap() error { return e.Cause }
 
 func NewOrganizationDBStore(db *gorm.DB, log hclog.Logger) *OrganizationDBStore {
-	return &OrganizationDBStore{db, validator.New(), log}
+	return &OrganizationDBStore{db, newValidator(), log}
 }
 
-func (db *OrganizationDBStore) GetOrganizations() ([]*Organization, error) {
+// withTx returns a copy of the store running its queries within the transaction
+func (db *OrganizationDBStore) withTx(tx *gorm.DB) *OrganizationDBStore {
+	return &OrganizationDBStore{tx, db.validate, db.log}
+}
+
+func (db *OrganizationDBStore) traced(ctx context.Context) *OrganizationDBStore {
+	tx, log := inContext(ctx, db.DB, db.log)
+	return &OrganizationDBStore{tx, db.validate, log}
+}
+
+func (db *OrganizationDBStore) GetOrganizations(ctx context.Context) ([]*Organization, error) {
+	ctx, span := tracing.StartSpan(ctx, "OrganizationDBStore.GetOrganizations")
+	defer span.End()
+	db = db.traced(ctx)
+
 	db.log.Debug("Getting all organizations...")
 
 	var organizations []*Organization
-	if err := db.Find(&organizations).Error; err != nil {
+	if err := scoped(ctx, db.DB, "Organization").Find(&organizations).Error; err != nil {
 		db.log.Error("Error getting all organizations", "err", err)
-		return []*Organization{}, err
+		return []*Organization{}, translateError("Organization", err)
 	}
 
 	db.log.Debug("Returning organizations", "organizations", spew.Sprintf("%+v", organizations))
 	return organizations, nil
 }
 
-func (db *OrganizationDBStore) GetOrganizationByID(id uint) (*Organization, error) {
+func (db *OrganizationDBStore) GetOrganizationByID(ctx context.Context, id uint) (*Organization, error) {
+	ctx, span := tracing.StartSpan(ctx, "OrganizationDBStore.GetOrganizationByID")
+	defer span.End()
+	db = db.traced(ctx)
+
 	db.log.Debug("Getting organization by id...", "id", id)
 
 	var organization Organization
-	if err := db.First(&organization, id).Error; err != nil {
+	if err := scoped(ctx, db.DB, "Organization").First(&organization, id).Error; err != nil {
 		if gorm.IsRecordNotFoundError(err) {
 			db.log.Error("Organization not found by id", "id", id)
 			return nil, &OrganizationNotFoundError{err}
 		} else {
 			db.log.Error("Unexpected error getting organization by id", "err", err)
-			return nil, err
+			return nil, translateError("Organization", err)
 		}
 	}
 
@@ -71,22 +95,59 @@ func (db *OrganizationDBStore) GetOrganizationByID(id uint) (*Organization, erro
 	return &organization, nil
 }
 
-func (db *OrganizationDBStore) UpdateOrganization(id uint, organization *Organization) (*Organization, error) {
+// GetOrganizationsByIDs returns the organizations with the given ids in a single query, unknown ids are skipped
+func (db *OrganizationDBStore) GetOrganizationsByIDs(ctx context.Context, ids []uint) ([]*Organization, error) {
+	ctx, span := tracing.StartSpan(ctx, "OrganizationDBStore.GetOrganizationsByIDs")
+	defer span.End()
+	db = db.traced(ctx)
+
+	db.log.Debug("Getting organizations by ids...", "ids", ids)
+
+	var organizations []*Organization
+	if err := scoped(ctx, db.DB, "Organization").Where("id IN (?)", ids).Find(&organizations).Error; err != nil {
+		db.log.Error("Error getting organizations by ids", "err", err)
+		return []*Organization{}, translateError("Organization", err)
+	}
+
+	db.log.Debug("Returning organizations", "organizations", spew.Sprintf("%+v", organizations))
+	return organizations, nil
+}
+
+func (db *OrganizationDBStore) UpdateOrganization(ctx context.Context, id uint, organization *Organization) (*Organization, error) {
+	ctx, span := tracing.StartSpan(ctx, "OrganizationDBStore.UpdateOrganization")
+	defer span.End()
+	db = db.traced(ctx)
+
 	db.log.Debug("Updating organization...", "organization", hclog.Fmt("%+v", organization))
 
-	err := db.validate.Struct(organization)
+	organization.DeletedAt = nil
+	err := validateStruct(db.validate, "Organization", organization)
 	if err != nil {
 		db.log.Error("Error validating organization", "err", err)
 		return nil, err
 	}
 
-	if err := db.Model(&Organization{}).Where("id = ?", id).Take(&Organization{}).Update(organization).First(&organization, id).Error; err != nil {
-		if gorm.IsRecordNotFoundError(err) {
-			db.log.Error("Organization to be updated not found", "organization", hclog.Fmt("%+v", organization))
-			return nil, &OrganizationNotFoundError{err}
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		before, err := db.withTx(tx).GetOrganizationByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&Organization{}).Where("id = ?", id).Update(organization).Error; err != nil {
+			return err
+		}
+		after, err := db.withTx(tx).GetOrganizationByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		organization = after
+		return recordAudit(ctx, tx, AuditUpdate, "organization", id, before, after)
+	}); err != nil {
+		if _, ok := err.(*OrganizationNotFoundError); ok {
+			db.log.Error("Organization to be updated not found", "id", id)
+			return nil, err
 		} else {
 			db.log.Error("Unexpected error updating organization", "err", err)
-			return nil, err
+			return nil, translateError("Organization", err)
 		}
 	}
 
@@ -94,37 +155,116 @@ func (db *OrganizationDBStore) UpdateOrganization(id uint, organization *Organiz
 	return organization, nil
 }
 
-func (db *OrganizationDBStore) AddOrganization(organization *Organization) (*Organization, error) {
+func (db *OrganizationDBStore) AddOrganization(ctx context.Context, organization *Organization) (*Organization, error) {
+	ctx, span := tracing.StartSpan(ctx, "OrganizationDBStore.AddOrganization")
+	defer span.End()
+	db = db.traced(ctx)
+
 	db.log.Debug("Adding organization...", "organization", hclog.Fmt("%+v", organization))
 
-	err := db.validate.Struct(organization)
+	if err := requirePlatformAdmin(ctx, "Organization"); err != nil {
+		db.log.Error("Not allowed to add organization", "err", err)
+		return nil, err
+	}
+
+	organization.DeletedAt = nil
+	err := validateStruct(db.validate, "Organization", organization)
 	if err != nil {
 		db.log.Error("Error validating organization", "err", err)
 		return nil, err
 	}
 
-	if err := db.Create(&organization).Error; err != nil {
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&organization).Error; err != nil {
+			return err
+		}
+		after, err := db.withTx(tx).GetOrganizationByID(ctx, organization.ID)
+		if err != nil {
+			return err
+		}
+		organization = after
+		return recordAudit(ctx, tx, AuditCreate, "organization", organization.ID, nil, after)
+	}); err != nil {
 		db.log.Error("Unexpected error creating organization", "err", err)
-		return nil, err
+		return nil, translateError("Organization", err)
 	}
 
 	db.log.Debug("Successfully added organization", "organization", hclog.Fmt("%+v", organization))
 	return organization, nil
 }
 
-func (db *OrganizationDBStore) DeleteOrganizationByID(id uint) error {
-	db.log.Debug("Deleting organization by id...", "id", id)
+// DeleteOrganizationByID moves the organization to the trash, see deleteEntity. Its events, persons, rooms and talks
+// keep it from being deleted, unless they are moved to the trash along with it by cascade.
+func (db *OrganizationDBStore) DeleteOrganizationByID(ctx context.Context, id uint, cascade bool) error {
+	ctx, span := tracing.StartSpan(ctx, "OrganizationDBStore.DeleteOrganizationByID")
+	defer span.End()
+	db = db.traced(ctx)
 
-	if err := db.Model(&Organization{}).Where("id = ?", id).Take(&Organization{}).Delete(&Organization{}).Error; err != nil {
-		if gorm.IsRecordNotFoundError(err) {
-			db.log.Error("Organization not found by id", "id", id)
-			return &OrganizationNotFoundError{err}
+	db.log.Debug("Deleting organization by id...", "id", id, "cascade", cascade)
+
+	if err := requirePlatformAdmin(ctx, "Organization"); err != nil {
+		db.log.Error("Not allowed to delete organization", "err", err)
+		return err
+	}
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		before, err := db.withTx(tx).GetOrganizationByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := deleteEntity(ctx, tx, "Organization", "organization", id, cascade); err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, AuditDelete, "organization", id, before, nil)
+	}); err != nil {
+		if _, ok := err.(*OrganizationNotFoundError); ok {
+			db.log.Error("Organization to be deleted not found", "id", id)
+			return err
 		} else {
 			db.log.Error("Unexpected error deleting organization", "err", err)
-			return err
+			return translateError("Organization", err)
 		}
 	}
 
 	db.log.Debug("Successfully deleted organization")
 	return nil
 }
+
+// RestoreOrganizationByID takes the organization out of the trash with the entities deleted along with it, unless
+// another organization has taken its name, or another person the name of one of its persons
+func (db *OrganizationDBStore) RestoreOrganizationByID(ctx context.Context, id uint) (*Organization, error) {
+	ctx, span := tracing.StartSpan(ctx, "OrganizationDBStore.RestoreOrganizationByID")
+	defer span.End()
+	db = db.traced(ctx)
+
+	db.log.Debug("Restoring organization by id...", "id", id)
+
+	if err := requirePlatformAdmin(ctx, "Organization"); err != nil {
+		db.log.Error("Not allowed to restore organization", "err", err)
+		return nil, err
+	}
+
+	var organization *Organization
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := restoreEntity(ctx, tx, "Organization", "organization", id); err != nil {
+			return err
+		}
+		after, err := db.withTx(tx).GetOrganizationByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		organization = after
+		return recordAudit(ctx, tx, AuditRestore, "organization", id, nil, after)
+	}); err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			db.log.Error("Organization not found by id", "id", id)
+			return nil, &OrganizationNotFoundError{err}
+		} else {
+			db.log.Error("Unexpected error restoring organization", "err", err)
+			return nil, translateError("Organization", err)
+		}
+	}
+
+	db.log.Debug("Successfully restored organization")
+	return organization, nil
+}
@@ -1,26 +1,67 @@
 package data
 
 import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
 	"github.com/davecgh/go-spew/spew"
 	"github.com/go-playground/validator/v10"
 	"github.com/hashicorp/go-hclog"
 	"github.com/jinzhu/gorm"
+	"github.com/milutindzunic/pac-backend/tracing"
+	"time"
 )
 
 type Person struct {
 	// gorm.Model
 	ID             uint          `json:"id" gorm:"primary_key;auto_increment"`
-	Name           string        `json:"name" gorm:"unique;not null;default:''"`
+	Name           string        `json:"name" gorm:"not null;default:''" validate:"notblank"`
 	OrganizationID uint          `json:"-" gorm:"not null"`
-	Organization   *Organization `json:"organization,omitempty" gorm:"association_autoupdate:false"`
+	Organization   *Organization `json:"organization,omitempty" gorm:"association_autoupdate:false" validate:"-"`
+	// Subject is the OIDC subject of the speaker, who manages the profile of the person through the /me endpoints
+	Subject     *string     `json:"subject,omitempty" validate:"omitempty,max=255"`
+	JobTitle    string      `json:"jobTitle,omitempty" validate:"max=100"`
+	Bio         string      `json:"bio,omitempty" sql:"type:text" validate:"max=4000"`
+	PhotoURL    string      `json:"photoUrl,omitempty" validate:"omitempty,url,max=2048"`
+	SocialLinks SocialLinks `json:"socialLinks,omitempty" sql:"type:text" validate:"max=10,dive,keys,notblank,max=32,endkeys,url,max=2048"`
+	DeletedAt   *time.Time  `json:"deletedAt,omitempty" sql:"index"`
+}
+
+// SocialLinks maps the name of a network, e.g. "github", onto the URL of the profile of the speaker on it
+type SocialLinks map[string]string
+
+// Value stores the links as a JSON object
+func (l SocialLinks) Value() (driver.Value, error) {
+	if l == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(l)
+	return string(b), err
+}
+
+// Scan reads the links stored by Value
+func (l *SocialLinks) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case string:
+		return json.Unmarshal([]byte(v), l)
+	case []byte:
+		return json.Unmarshal(v, l)
+	}
+	return fmt.Errorf("cannot scan %T into SocialLinks", value)
 }
 
 type PersonStore interface {
-	GetPersons() ([]*Person, error)
-	GetPersonByID(id uint) (*Person, error)
-	UpdatePerson(id uint, person *Person) (*Person, error)
-	AddPerson(person *Person) (*Person, error)
-	DeletePersonByID(id uint) error
+	GetPersons(ctx context.Context) ([]*Person, error)
+	GetPersonByID(ctx context.Context, id uint) (*Person, error)
+	GetPersonBySubject(ctx context.Context, subject string) (*Person, error)
+	UpdatePerson(ctx context.Context, id uint, person *Person) (*Person, error)
+	AddPerson(ctx context.Context, person *Person) (*Person, error)
+	DeletePersonByID(ctx context.Context, id uint, cascade bool) error
+	RestorePersonByID(ctx context.Context, id uint) (*Person, error)
 }
 
 type PersonDBStore struct {
@@ -37,33 +78,74 @@ func (e PersonNotFoundError) Error() string { return "Person not found! Cause: "
 func (e PersonNotFoundError) Unwrap() error { return e.Cause }
 
 func NewPersonDBStore(db *gorm.DB, log hclog.Logger) *PersonDBStore {
-	return &PersonDBStore{db, validator.New(), log}
+	return &PersonDBStore{db, newValidator(), log}
+}
+
+// withTx returns a copy of the store running its queries within the transaction
+func (db *PersonDBStore) withTx(tx *gorm.DB) *PersonDBStore {
+	return &PersonDBStore{tx, db.validate, db.log}
 }
 
-func (db *PersonDBStore) GetPersons() ([]*Person, error) {
+func (db *PersonDBStore) traced(ctx context.Context) *PersonDBStore {
+	tx, log := inContext(ctx, db.DB, db.log)
+	return &PersonDBStore{tx, db.validate, log}
+}
+
+func (db *PersonDBStore) GetPersons(ctx context.Context) ([]*Person, error) {
+	ctx, span := tracing.StartSpan(ctx, "PersonDBStore.GetPersons")
+	defer span.End()
+	db = db.traced(ctx)
+
 	db.log.Debug("Getting all persons...")
 
 	var persons []*Person
-	if err := db.Preload("Organization").Find(&persons).Error; err != nil {
+	if err := preload(ctx, scoped(ctx, db.DB, "Person"), "Person").Find(&persons).Error; err != nil {
 		db.log.Error("Error getting all persons", "err", err)
-		return []*Person{}, err
+		return []*Person{}, translateError("Person", err)
 	}
 
 	db.log.Debug("Returning persons", "persons", spew.Sprintf("%+v", persons))
 	return persons, nil
 }
 
-func (db *PersonDBStore) GetPersonByID(id uint) (*Person, error) {
+func (db *PersonDBStore) GetPersonByID(ctx context.Context, id uint) (*Person, error) {
+	ctx, span := tracing.StartSpan(ctx, "PersonDBStore.GetPersonByID")
+	defer span.End()
+	db = db.traced(ctx)
+
 	db.log.Debug("Getting person by id...", "id", id)
 
 	var person Person
-	if err := db.Preload("Organization").First(&person, id).Error; err != nil {
+	if err := preload(ctx, scoped(ctx, db.DB, "Person"), "Person").First(&person, id).Error; err != nil {
 		if gorm.IsRecordNotFoundError(err) {
 			db.log.Error("Person not found by id", "id", id)
 			return nil, &PersonNotFoundError{err}
 		} else {
 			db.log.Error("Unexpected error getting person by id", "err", err)
-			return nil, err
+			return nil, translateError("Person", err)
+		}
+	}
+
+	db.log.Debug("Returning person", "person", hclog.Fmt("%+v", person))
+	return &person, nil
+}
+
+// GetPersonBySubject returns the person linked to the OIDC subject of a speaker
+func (db *PersonDBStore) GetPersonBySubject(ctx context.Context, subject string) (*Person, error) {
+	ctx, span := tracing.StartSpan(ctx, "PersonDBStore.GetPersonBySubject")
+	defer span.End()
+	db = db.traced(ctx)
+
+	db.log.Debug("Getting person by subject...", "subject", subject)
+
+	var person Person
+	if err := preload(ctx, scoped(ctx, db.DB, "Person"), "Person").Where("subject = ?", subject).First(&person).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			db.log.Error("Person not found by subject", "subject", subject)
+			return nil, &PersonNotFoundError{err}
+		} else {
+			db.log.Error("Unexpected error getting person by subject", "err", err)
+			return nil, translateError("Person", err)
 		}
 	}
 
@@ -71,60 +153,159 @@ func (db *PersonDBStore) GetPersonByID(id uint) (*Person, error) {
 	return &person, nil
 }
 
-func (db *PersonDBStore) UpdatePerson(id uint, person *Person) (*Person, error) {
+func (db *PersonDBStore) UpdatePerson(ctx context.Context, id uint, person *Person) (*Person, error) {
+	ctx, span := tracing.StartSpan(ctx, "PersonDBStore.UpdatePerson")
+	defer span.End()
+	db = db.traced(ctx)
+
 	db.log.Debug("Updating person...", "person", hclog.Fmt("%+v", person))
 
-	err := db.validate.Struct(person)
+	person.DeletedAt = nil
+	assignTenant(ctx, &person.OrganizationID, &person.Organization)
+	organizationID := person.OrganizationID
+	if person.Organization != nil {
+		organizationID = person.Organization.ID
+	}
+	err := validateStruct(db.validate, "Person", person)
+	if err == nil {
+		err = requireReference("Person", "organization", organizationID)
+	}
 	if err != nil {
 		db.log.Error("Error validating person", "err", err)
 		return nil, err
 	}
 
-	if err := db.Model(&Person{}).Where("id = ?", id).Take(&Person{}).Update(person).First(&person, id).Error; err != nil {
-		if gorm.IsRecordNotFoundError(err) {
-			db.log.Error("Person to be updated not found", "person", hclog.Fmt("%+v", person))
-			return nil, &PersonNotFoundError{err}
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		before, err := db.withTx(tx).GetPersonByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := updateRow(ctx, tx, &Person{}, id, person); err != nil {
+			return err
+		}
+		after, err := db.withTx(tx).GetPersonByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		person = after
+		return recordAudit(ctx, tx, AuditUpdate, "person", id, before, after)
+	}); err != nil {
+		if _, ok := err.(*PersonNotFoundError); ok {
+			db.log.Error("Person to be updated not found", "id", id)
+			return nil, err
 		} else {
 			db.log.Error("Unexpected error updating person", "err", err)
-			return nil, err
+			return nil, translateError("Person", err)
 		}
 	}
 
 	db.log.Debug("Successfully updated person", "person", hclog.Fmt("%+v", person))
-	return db.GetPersonByID(person.ID)
+	return person, nil
 }
 
-func (db *PersonDBStore) AddPerson(person *Person) (*Person, error) {
+func (db *PersonDBStore) AddPerson(ctx context.Context, person *Person) (*Person, error) {
+	ctx, span := tracing.StartSpan(ctx, "PersonDBStore.AddPerson")
+	defer span.End()
+	db = db.traced(ctx)
+
 	db.log.Debug("Adding person...", "person", hclog.Fmt("%+v", person))
 
-	err := db.validate.Struct(person)
+	person.DeletedAt = nil
+	assignTenant(ctx, &person.OrganizationID, &person.Organization)
+	organizationID := person.OrganizationID
+	if person.Organization != nil {
+		organizationID = person.Organization.ID
+	}
+	err := validateStruct(db.validate, "Person", person)
+	if err == nil {
+		err = requireReference("Person", "organization", organizationID)
+	}
 	if err != nil {
 		db.log.Error("Error validating person", "err", err)
 		return nil, err
 	}
 
-	if err := db.Create(&person).Error; err != nil {
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&person).Error; err != nil {
+			return err
+		}
+		after, err := db.withTx(tx).GetPersonByID(ctx, person.ID)
+		if err != nil {
+			return err
+		}
+		person = after
+		return recordAudit(ctx, tx, AuditCreate, "person", person.ID, nil, after)
+	}); err != nil {
 		db.log.Error("Unexpected error creating person", "err", err)
-		return nil, err
+		return nil, translateError("Person", err)
 	}
 
 	db.log.Debug("Successfully added person", "person", hclog.Fmt("%+v", person))
-	return db.GetPersonByID(person.ID)
+	return person, nil
 }
 
-func (db *PersonDBStore) DeletePersonByID(id uint) error {
-	db.log.Debug("Deleting person by id...", "id", id)
+// DeletePersonByID moves the person to the trash, see deleteEntity. The talks the person is in keep it from being
+// deleted; with cascade, the talks stay and lose the person until it is restored.
+func (db *PersonDBStore) DeletePersonByID(ctx context.Context, id uint, cascade bool) error {
+	ctx, span := tracing.StartSpan(ctx, "PersonDBStore.DeletePersonByID")
+	defer span.End()
+	db = db.traced(ctx)
 
-	if err := db.Model(&Person{}).Where("id = ?", id).Take(&Person{}).Delete(&Person{}).Error; err != nil {
-		if gorm.IsRecordNotFoundError(err) {
-			db.log.Error("Person not found by id", "id", id)
-			return &PersonNotFoundError{err}
+	db.log.Debug("Deleting person by id...", "id", id, "cascade", cascade)
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		before, err := db.withTx(tx).GetPersonByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := deleteEntity(ctx, tx, "Person", "person", id, cascade); err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, AuditDelete, "person", id, before, nil)
+	}); err != nil {
+		if _, ok := err.(*PersonNotFoundError); ok {
+			db.log.Error("Person to be deleted not found", "id", id)
+			return err
 		} else {
 			db.log.Error("Unexpected error deleting person", "err", err)
-			return err
+			return translateError("Person", err)
 		}
 	}
 
 	db.log.Debug("Successfully deleted person")
 	return nil
 }
+
+// RestorePersonByID takes the person out of the trash, unless its organization is in the trash or another person has
+// taken its name or subject meanwhile
+func (db *PersonDBStore) RestorePersonByID(ctx context.Context, id uint) (*Person, error) {
+	ctx, span := tracing.StartSpan(ctx, "PersonDBStore.RestorePersonByID")
+	defer span.End()
+	db = db.traced(ctx)
+
+	db.log.Debug("Restoring person by id...", "id", id)
+
+	var person *Person
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := restoreEntity(ctx, tx, "Person", "person", id); err != nil {
+			return err
+		}
+		after, err := db.withTx(tx).GetPersonByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		person = after
+		return recordAudit(ctx, tx, AuditRestore, "person", id, nil, after)
+	}); err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			db.log.Error("Person not found by id", "id", id)
+			return nil, &PersonNotFoundError{err}
+		} else {
+			db.log.Error("Unexpected error restoring person", "err", err)
+			return nil, translateError("Person", err)
+		}
+	}
+
+	db.log.Debug("Successfully restored person")
+	return person, nil
+}
@@ -0,0 +1,240 @@
+package data
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Recurrence is the subset of RFC 5545 recurrence rules the series of talk dates repeat by: every INTERVAL days
+// or weeks, on the week days of BYDAY, ending after COUNT occurrences or at UNTIL, or else with the event
+type Recurrence struct {
+	Frequency string
+	Interval  int
+	Count     int
+	ByDay     []time.Weekday
+	// until is the UNTIL of the rule as written, it is resolved in the time zone of the series
+	until string
+}
+
+const (
+	DailyFrequency  = "DAILY"
+	WeeklyFrequency = "WEEKLY"
+)
+
+// maxOccurrences bounds the occurrences of a series, whatever its rule
+const maxOccurrences = 500
+
+// dateLayout is the layout of the days, like those of the exceptions of a series
+const dateLayout = "2006-01-02"
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// ParseRecurrence parses a rule like "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=4", with or without the "RRULE:" prefix
+func ParseRecurrence(rule string) (*Recurrence, error) {
+	r := &Recurrence{Interval: 1}
+	rule = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(rule)), "RRULE:")
+	if rule == "" {
+		return nil, fmt.Errorf("empty rule")
+	}
+
+	for _, part := range strings.Split(rule, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 || kv[1] == "" {
+			return nil, fmt.Errorf("malformed part %q", part)
+		}
+		switch key, value := kv[0], kv[1]; key {
+		case "FREQ":
+			if value != DailyFrequency && value != WeeklyFrequency {
+				return nil, fmt.Errorf("unsupported FREQ %q", value)
+			}
+			r.Frequency = value
+		case "INTERVAL", "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 || n > maxOccurrences {
+				return nil, fmt.Errorf("%s must be between 1 and %d", key, maxOccurrences)
+			}
+			if key == "INTERVAL" {
+				r.Interval = n
+			} else {
+				r.Count = n
+			}
+		case "UNTIL":
+			if _, err := parseUntil(value, time.UTC); err != nil {
+				return nil, err
+			}
+			r.until = value
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				weekday, ok := weekdays[day]
+				if !ok {
+					return nil, fmt.Errorf("unsupported BYDAY %q", day)
+				}
+				r.ByDay = append(r.ByDay, weekday)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported part %s", key)
+		}
+	}
+
+	if r.Frequency == "" {
+		return nil, fmt.Errorf("FREQ is required")
+	}
+	if r.Count != 0 && r.until != "" {
+		return nil, fmt.Errorf("COUNT and UNTIL cannot be combined")
+	}
+	return r, nil
+}
+
+// parseUntil reads an UNTIL given as a UTC date-time like "20210513T220000Z", as a local date-time in the zone,
+// or as a day, which ends the series with that day
+func parseUntil(value string, zone *time.Location) (time.Time, error) {
+	if t, err := time.Parse("20060102T150405Z", value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("20060102T150405", value, zone); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("20060102", value, zone); err == nil {
+		return t.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+	}
+	return time.Time{}, fmt.Errorf("malformed UNTIL %q", value)
+}
+
+// String returns the rule in its canonical form, with the UNTIL as written
+func (r *Recurrence) String() string {
+	parts := []string{"FREQ=" + r.Frequency}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, 0, len(r.ByDay))
+		for _, weekday := range r.ByDay {
+			days = append(days, strings.ToUpper(weekday.String()[:2]))
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.until != "" {
+		parts = append(parts, "UNTIL="+r.until)
+	}
+	return strings.Join(parts, ";")
+}
+
+// Bounded returns the rule ending with the occurrence at last, in place of its COUNT or UNTIL, as written to calendars
+func (r *Recurrence) Bounded(last time.Time) *Recurrence {
+	bounded := *r
+	bounded.Count = 0
+	bounded.until = last.UTC().Format("20060102T150405Z")
+	return &bounded
+}
+
+// Occurrences returns the begin dates of the occurrences of a series beginning at start, in UTC and in the order they
+// occur, up to end. They are computed in the zone, so that they keep their time of day across daylight saving time
+// changes. As in RFC 5545, start itself is the first occurrence, whether or not it matches the rule.
+func (r *Recurrence) Occurrences(start time.Time, zone *time.Location, end time.Time) []time.Time {
+	if zone == nil {
+		zone = time.UTC
+	}
+	local := start.In(zone)
+	var until time.Time
+	if r.until != "" {
+		until, _ = parseUntil(r.until, zone)
+	}
+
+	// weeks begin on Monday, the default WKST
+	weekOffset := (int(local.Weekday()) + 6) % 7
+	year, month, day := local.Date()
+	occurrences := []time.Time{local.UTC()}
+	for days := 1; len(occurrences) < maxOccurrences && (r.Count == 0 || len(occurrences) < r.Count); days++ {
+		t := time.Date(year, month, day+days, local.Hour(), local.Minute(), local.Second(), local.Nanosecond(), zone)
+		if t.After(end) || (!until.IsZero() && t.After(until)) {
+			break
+		}
+
+		onDay := len(r.ByDay) == 0 || r.onDay(t.Weekday())
+		switch r.Frequency {
+		case DailyFrequency:
+			if days%r.Interval != 0 || !onDay {
+				continue
+			}
+		case WeeklyFrequency:
+			if len(r.ByDay) == 0 {
+				// without BYDAY, weekly series repeat on the week day they begin
+				onDay = t.Weekday() == local.Weekday()
+			}
+			if ((weekOffset+days)/7)%r.Interval != 0 || !onDay {
+				continue
+			}
+		}
+		occurrences = append(occurrences, t.UTC())
+	}
+	return occurrences
+}
+
+func (r *Recurrence) onDay(weekday time.Weekday) bool {
+	for _, day := range r.ByDay {
+		if day == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// Dates are days like "2021-05-13"
+type Dates []string
+
+// Value stores the days as a JSON array
+func (d Dates) Value() (driver.Value, error) {
+	if d == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(d)
+	return string(b), err
+}
+
+// Scan reads the days stored by Value
+func (d *Dates) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*d = nil
+		return nil
+	case string:
+		return json.Unmarshal([]byte(v), d)
+	case []byte:
+		return json.Unmarshal(v, d)
+	}
+	return fmt.Errorf("cannot scan %T into Dates", value)
+}
+
+// Equal reports whether d and other are the same days in the same order, no days being the same as an empty list
+func (d Dates) Equal(other Dates) bool {
+	if len(d) != len(other) {
+		return false
+	}
+	for i := range d {
+		if d[i] != other[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Contains reports whether t falls on one of the days in the zone
+func (d Dates) Contains(t time.Time, zone *time.Location) bool {
+	day := In(t, zone).Format(dateLayout)
+	for _, date := range d {
+		if date == day {
+			return true
+		}
+	}
+	return false
+}
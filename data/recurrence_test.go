@@ -0,0 +1,212 @@
+package data
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	tests := []struct {
+		rule    string
+		want    string
+		wantErr string
+	}{
+		{rule: "FREQ=DAILY", want: "FREQ=DAILY"},
+		{rule: "rrule:freq=weekly;byday=mo,we;count=4", want: "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=4"},
+		{rule: "FREQ=DAILY;INTERVAL=1", want: "FREQ=DAILY"},
+		{rule: "COUNT=2;INTERVAL=3;FREQ=DAILY", want: "FREQ=DAILY;INTERVAL=3;COUNT=2"},
+		{rule: "FREQ=WEEKLY;UNTIL=20210513T220000Z", want: "FREQ=WEEKLY;UNTIL=20210513T220000Z"},
+		{rule: "FREQ=WEEKLY;UNTIL=20210513T090000", want: "FREQ=WEEKLY;UNTIL=20210513T090000"},
+		{rule: "FREQ=WEEKLY;UNTIL=20210513", want: "FREQ=WEEKLY;UNTIL=20210513"},
+		{rule: " ", wantErr: "empty rule"},
+		{rule: "FREQ", wantErr: `malformed part "FREQ"`},
+		{rule: "FREQ=DAILY;COUNT=", wantErr: `malformed part "COUNT="`},
+		{rule: "FREQ=MONTHLY", wantErr: `unsupported FREQ "MONTHLY"`},
+		{rule: "FREQ=DAILY;INTERVAL=0", wantErr: "INTERVAL must be between 1 and 500"},
+		{rule: "FREQ=DAILY;COUNT=501", wantErr: "COUNT must be between 1 and 500"},
+		{rule: "FREQ=DAILY;COUNT=two", wantErr: "COUNT must be between 1 and 500"},
+		{rule: "FREQ=DAILY;UNTIL=2021-05-13", wantErr: `malformed UNTIL "2021-05-13"`},
+		{rule: "FREQ=WEEKLY;BYDAY=MO,XX", wantErr: `unsupported BYDAY "XX"`},
+		{rule: "FREQ=WEEKLY;BYDAY=1MO", wantErr: `unsupported BYDAY "1MO"`},
+		{rule: "FREQ=WEEKLY;WKST=SU", wantErr: "unsupported part WKST"},
+		{rule: "COUNT=3", wantErr: "FREQ is required"},
+		{rule: "FREQ=DAILY;COUNT=3;UNTIL=20210513", wantErr: "COUNT and UNTIL cannot be combined"},
+	}
+
+	for _, tt := range tests {
+		r, err := ParseRecurrence(tt.rule)
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("ParseRecurrence(%q) error = %v, want %s", tt.rule, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseRecurrence(%q) error = %v", tt.rule, err)
+			continue
+		}
+		if got := r.String(); got != tt.want {
+			t.Errorf("ParseRecurrence(%q) = %s, want %s", tt.rule, got, tt.want)
+		}
+	}
+}
+
+func TestOccurrences(t *testing.T) {
+	// Belgrade switches from UTC+1 to UTC+2 on Sunday 2021-03-28, the series begin on the Friday before at 09:00
+	belgrade, _ := LoadTimezone("Europe/Belgrade")
+	start := time.Date(2021, 3, 26, 9, 0, 0, 0, belgrade)
+	end := time.Date(2021, 4, 5, 0, 0, 0, 0, belgrade)
+
+	tests := []struct {
+		name string
+		rule string
+		zone *time.Location
+		end  time.Time
+		want []string
+	}{
+		{
+			name: "daily until the end of the event",
+			rule: "FREQ=DAILY",
+			zone: belgrade,
+			end:  time.Date(2021, 3, 28, 7, 0, 0, 0, time.UTC),
+			want: []string{"2021-03-26T08:00:00Z", "2021-03-27T08:00:00Z", "2021-03-28T07:00:00Z"},
+		},
+		{
+			name: "daily with count",
+			rule: "FREQ=DAILY;COUNT=4",
+			zone: belgrade,
+			want: []string{"2021-03-26T08:00:00Z", "2021-03-27T08:00:00Z", "2021-03-28T07:00:00Z", "2021-03-29T07:00:00Z"},
+		},
+		{
+			name: "every other day until a day",
+			rule: "FREQ=DAILY;INTERVAL=2;UNTIL=20210401",
+			zone: belgrade,
+			want: []string{"2021-03-26T08:00:00Z", "2021-03-28T07:00:00Z", "2021-03-30T07:00:00Z", "2021-04-01T07:00:00Z"},
+		},
+		{
+			name: "daily on week days",
+			rule: "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR;COUNT=4",
+			zone: belgrade,
+			want: []string{"2021-03-26T08:00:00Z", "2021-03-29T07:00:00Z", "2021-03-30T07:00:00Z", "2021-03-31T07:00:00Z"},
+		},
+		{
+			name: "until a UTC date-time",
+			rule: "FREQ=DAILY;UNTIL=20210329T070000Z",
+			zone: belgrade,
+			want: []string{"2021-03-26T08:00:00Z", "2021-03-27T08:00:00Z", "2021-03-28T07:00:00Z", "2021-03-29T07:00:00Z"},
+		},
+		{
+			name: "until a local date-time",
+			rule: "FREQ=DAILY;UNTIL=20210328T085959",
+			zone: belgrade,
+			want: []string{"2021-03-26T08:00:00Z", "2021-03-27T08:00:00Z"},
+		},
+		{
+			name: "weekly on the week day of the start",
+			rule: "FREQ=WEEKLY",
+			zone: belgrade,
+			want: []string{"2021-03-26T08:00:00Z", "2021-04-02T07:00:00Z"},
+		},
+		{
+			name: "weekly by day, beginning with the start",
+			rule: "FREQ=WEEKLY;BYDAY=MO,WE",
+			zone: belgrade,
+			want: []string{"2021-03-26T08:00:00Z", "2021-03-29T07:00:00Z", "2021-03-31T07:00:00Z"},
+		},
+		{
+			name: "every other week from the week of the start",
+			rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR",
+			zone: belgrade,
+			end:  time.Date(2021, 4, 13, 0, 0, 0, 0, belgrade),
+			want: []string{"2021-03-26T08:00:00Z", "2021-04-05T07:00:00Z", "2021-04-09T07:00:00Z"},
+		},
+		{
+			name: "in UTC",
+			rule: "FREQ=DAILY;COUNT=3",
+			zone: time.UTC,
+			want: []string{"2021-03-26T08:00:00Z", "2021-03-27T08:00:00Z", "2021-03-28T08:00:00Z"},
+		},
+		{
+			name: "without a zone",
+			rule: "FREQ=DAILY;UNTIL=20210328",
+			want: []string{"2021-03-26T08:00:00Z", "2021-03-27T08:00:00Z", "2021-03-28T08:00:00Z"},
+		},
+		{
+			name: "bounded by the maximum",
+			rule: "FREQ=DAILY",
+			zone: belgrade,
+			end:  start.AddDate(5, 0, 0),
+		},
+	}
+
+	for _, tt := range tests {
+		r, err := ParseRecurrence(tt.rule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		until := tt.end
+		if until.IsZero() {
+			until = end
+		}
+
+		occurrences := r.Occurrences(start, tt.zone, until)
+		if tt.want == nil {
+			// too many to list
+			if len(occurrences) != maxOccurrences {
+				t.Errorf("%s: %d occurrences, want %d", tt.name, len(occurrences), maxOccurrences)
+			}
+			continue
+		}
+		if got, want := formatTimes(occurrences), strings.Join(tt.want, " "); got != want {
+			t.Errorf("%s: occurrences = %s, want %s", tt.name, got, want)
+		}
+	}
+}
+
+func TestBounded(t *testing.T) {
+	belgrade, _ := LoadTimezone("Europe/Belgrade")
+	start := time.Date(2021, 3, 26, 9, 0, 0, 0, belgrade)
+	end := time.Date(2021, 4, 30, 0, 0, 0, 0, belgrade)
+
+	tests := []struct {
+		rule string
+		want string
+	}{
+		{rule: "FREQ=DAILY;COUNT=4", want: "FREQ=DAILY;UNTIL=20210329T070000Z"},
+		{rule: "FREQ=WEEKLY;BYDAY=MO,WE;UNTIL=20210331", want: "FREQ=WEEKLY;BYDAY=MO,WE;UNTIL=20210331T070000Z"},
+		{rule: "FREQ=DAILY;INTERVAL=2", want: "FREQ=DAILY;INTERVAL=2;UNTIL=20210429T070000Z"},
+	}
+
+	for _, tt := range tests {
+		r, err := ParseRecurrence(tt.rule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		occurrences := r.Occurrences(start, belgrade, end)
+		bounded := r.Bounded(occurrences[len(occurrences)-1])
+
+		if got := bounded.String(); got != tt.want {
+			t.Errorf("%s bounded = %s, want %s", tt.rule, got, tt.want)
+		}
+		if r.String() != tt.rule {
+			t.Errorf("%s changed to %s", tt.rule, r)
+		}
+		// the bounded rule is parsed again by calendars, and gives the same occurrences without the event
+		parsed, err := ParseRecurrence(bounded.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := formatTimes(parsed.Occurrences(start, belgrade, start.AddDate(1, 0, 0))), formatTimes(occurrences); got != want {
+			t.Errorf("%s bounded occurrences = %s, want %s", tt.rule, got, want)
+		}
+	}
+}
+
+func formatTimes(times []time.Time) string {
+	formatted := make([]string, len(times))
+	for i, t := range times {
+		formatted[i] = t.Format(time.RFC3339)
+	}
+	return strings.Join(formatted, " ")
+}
@@ -1,26 +1,35 @@
 package data
 
 import (
+	"context"
 	"github.com/davecgh/go-spew/spew"
 	"github.com/go-playground/validator/v10"
 	"github.com/hashicorp/go-hclog"
 	"github.com/jinzhu/gorm"
+	"github.com/milutindzunic/pac-backend/tracing"
+	"time"
 )
 
 type Room struct {
 	// gorm.Model
 	ID             uint          `json:"id" gorm:"primary_key;auto_increment"`
-	Name           string        `json:"name" gorm:"not null;default:''"`
+	Name           string        `json:"name" gorm:"not null;default:''" validate:"notblank"`
 	OrganizationID uint          `json:"-" gorm:"not null"`
-	Organization   *Organization `json:"organization,omitempty" gorm:"association_autoupdate:false"`
+	Organization   *Organization `json:"organization,omitempty" gorm:"association_autoupdate:false" validate:"-"`
+	// LocationID is the venue the room is in, nullable as rooms created before locations had addresses have none
+	LocationID *uint      `json:"-"`
+	Location   *Location  `json:"location,omitempty" gorm:"association_autoupdate:false" validate:"-"`
+	DeletedAt  *time.Time `json:"deletedAt,omitempty" sql:"index"`
 }
 
 type RoomStore interface {
-	GetRooms() ([]*Room, error)
-	GetRoomByID(id uint) (*Room, error)
-	UpdateRoom(id uint, room *Room) (*Room, error)
-	AddRoom(room *Room) (*Room, error)
-	DeleteRoomByID(id uint) error
+	GetRooms(ctx context.Context) ([]*Room, error)
+	GetRoomByID(ctx context.Context, id uint) (*Room, error)
+	GetRoomsByIDs(ctx context.Context, ids []uint) ([]*Room, error)
+	UpdateRoom(ctx context.Context, id uint, room *Room) (*Room, error)
+	AddRoom(ctx context.Context, room *Room) (*Room, error)
+	DeleteRoomByID(ctx context.Context, id uint, cascade bool) error
+	RestoreRoomByID(ctx context.Context, id uint) (*Room, error)
 }
 
 type RoomDBStore struct {
@@ -37,33 +46,51 @@ func (e RoomNotFoundError) Error() string { return "Room not found! Cause: " + e
 func (e RoomNotFoundError) Unwrap() error { return e.Cause }
 
 func NewRoomDBStore(db *gorm.DB, log hclog.Logger) *RoomDBStore {
-	return &RoomDBStore{db, validator.New(), log}
+	return &RoomDBStore{db, newValidator(), log}
 }
 
-func (db *RoomDBStore) GetRooms() ([]*Room, error) {
+// withTx returns a copy of the store running its queries within the transaction
+func (db *RoomDBStore) withTx(tx *gorm.DB) *RoomDBStore {
+	return &RoomDBStore{tx, db.validate, db.log}
+}
+
+func (db *RoomDBStore) traced(ctx context.Context) *RoomDBStore {
+	tx, log := inContext(ctx, db.DB, db.log)
+	return &RoomDBStore{tx, db.validate, log}
+}
+
+func (db *RoomDBStore) GetRooms(ctx context.Context) ([]*Room, error) {
+	ctx, span := tracing.StartSpan(ctx, "RoomDBStore.GetRooms")
+	defer span.End()
+	db = db.traced(ctx)
+
 	db.log.Debug("Getting all rooms...")
 
 	var rooms []*Room
-	if err := db.Preload("Organization").Find(&rooms).Error; err != nil {
+	if err := preload(ctx, scoped(ctx, db.DB, "Room"), "Room").Find(&rooms).Error; err != nil {
 		db.log.Error("Error getting all rooms", "err", err)
-		return []*Room{}, err
+		return []*Room{}, translateError("Room", err)
 	}
 
 	db.log.Debug("Returning rooms", "rooms", spew.Sprintf("%+v", rooms))
 	return rooms, nil
 }
 
-func (db *RoomDBStore) GetRoomByID(id uint) (*Room, error) {
+func (db *RoomDBStore) GetRoomByID(ctx context.Context, id uint) (*Room, error) {
+	ctx, span := tracing.StartSpan(ctx, "RoomDBStore.GetRoomByID")
+	defer span.End()
+	db = db.traced(ctx)
+
 	db.log.Debug("Getting room by id...", "id", id)
 
 	var room Room
-	if err := db.Preload("Organization").First(&room, id).Error; err != nil {
+	if err := preload(ctx, scoped(ctx, db.DB, "Room"), "Room").First(&room, id).Error; err != nil {
 		if gorm.IsRecordNotFoundError(err) {
 			db.log.Error("Room not found by id", "id", id)
 			return nil, &RoomNotFoundError{err}
 		} else {
 			db.log.Error("Unexpected error getting room by id", "err", err)
-			return nil, err
+			return nil, translateError("Room", err)
 		}
 	}
 
@@ -71,60 +98,177 @@ func (db *RoomDBStore) GetRoomByID(id uint) (*Room, error) {
 	return &room, nil
 }
 
-func (db *RoomDBStore) UpdateRoom(id uint, room *Room) (*Room, error) {
+// GetRoomsByIDs returns the rooms with the given ids in a single query, unknown ids are skipped
+func (db *RoomDBStore) GetRoomsByIDs(ctx context.Context, ids []uint) ([]*Room, error) {
+	ctx, span := tracing.StartSpan(ctx, "RoomDBStore.GetRoomsByIDs")
+	defer span.End()
+	db = db.traced(ctx)
+
+	db.log.Debug("Getting rooms by ids...", "ids", ids)
+
+	var rooms []*Room
+	if err := preload(ctx, scoped(ctx, db.DB, "Room"), "Room").Where("id IN (?)", ids).Find(&rooms).Error; err != nil {
+		db.log.Error("Error getting rooms by ids", "err", err)
+		return []*Room{}, translateError("Room", err)
+	}
+
+	db.log.Debug("Returning rooms", "rooms", spew.Sprintf("%+v", rooms))
+	return rooms, nil
+}
+
+func (db *RoomDBStore) UpdateRoom(ctx context.Context, id uint, room *Room) (*Room, error) {
+	ctx, span := tracing.StartSpan(ctx, "RoomDBStore.UpdateRoom")
+	defer span.End()
+	db = db.traced(ctx)
+
 	db.log.Debug("Updating room...", "room", hclog.Fmt("%+v", room))
 
-	err := db.validate.Struct(room)
+	room.DeletedAt = nil
+	assignTenant(ctx, &room.OrganizationID, &room.Organization)
+	organizationID := room.OrganizationID
+	if room.Organization != nil {
+		organizationID = room.Organization.ID
+	}
+	err := validateStruct(db.validate, "Room", room)
+	if err == nil {
+		err = requireReference("Room", "organization", organizationID)
+	}
 	if err != nil {
 		db.log.Error("Error validating room", "err", err)
 		return nil, err
 	}
 
-	if err := db.Model(&Room{}).Where("id = ?", id).Take(&Room{}).Update(room).First(&room, id).Error; err != nil {
-		if gorm.IsRecordNotFoundError(err) {
-			db.log.Error("Room to be updated not found", "room", hclog.Fmt("%+v", room))
-			return nil, &RoomNotFoundError{err}
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		before, err := db.withTx(tx).GetRoomByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&Room{}).Where("id = ?", id).Update(room).Error; err != nil {
+			return err
+		}
+		after, err := db.withTx(tx).GetRoomByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		room = after
+		return recordAudit(ctx, tx, AuditUpdate, "room", id, before, after)
+	}); err != nil {
+		if _, ok := err.(*RoomNotFoundError); ok {
+			db.log.Error("Room to be updated not found", "id", id)
+			return nil, err
 		} else {
 			db.log.Error("Unexpected error updating room", "err", err)
-			return nil, err
+			return nil, translateError("Room", err)
 		}
 	}
 
 	db.log.Debug("Successfully updated room", "room", hclog.Fmt("%+v", room))
-	return db.GetRoomByID(room.ID)
+	return room, nil
 }
 
-func (db *RoomDBStore) AddRoom(room *Room) (*Room, error) {
+func (db *RoomDBStore) AddRoom(ctx context.Context, room *Room) (*Room, error) {
+	ctx, span := tracing.StartSpan(ctx, "RoomDBStore.AddRoom")
+	defer span.End()
+	db = db.traced(ctx)
+
 	db.log.Debug("Adding room...", "room", hclog.Fmt("%+v", room))
 
-	err := db.validate.Struct(room)
+	room.DeletedAt = nil
+	assignTenant(ctx, &room.OrganizationID, &room.Organization)
+	organizationID := room.OrganizationID
+	if room.Organization != nil {
+		organizationID = room.Organization.ID
+	}
+	err := validateStruct(db.validate, "Room", room)
+	if err == nil {
+		err = requireReference("Room", "organization", organizationID)
+	}
 	if err != nil {
 		db.log.Error("Error validating room", "err", err)
 		return nil, err
 	}
 
-	if err := db.Create(&room).Error; err != nil {
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&room).Error; err != nil {
+			return err
+		}
+		after, err := db.withTx(tx).GetRoomByID(ctx, room.ID)
+		if err != nil {
+			return err
+		}
+		room = after
+		return recordAudit(ctx, tx, AuditCreate, "room", room.ID, nil, after)
+	}); err != nil {
 		db.log.Error("Unexpected error creating room", "err", err)
-		return nil, err
+		return nil, translateError("Room", err)
 	}
 
 	db.log.Debug("Successfully added room", "room", hclog.Fmt("%+v", room))
-	return db.GetRoomByID(room.ID)
+	return room, nil
 }
 
-func (db *RoomDBStore) DeleteRoomByID(id uint) error {
-	db.log.Debug("Deleting room by id...", "id", id)
+// DeleteRoomByID moves the room to the trash, see deleteEntity. Its talk dates keep it from being deleted, unless they
+// are moved to the trash along with it by cascade.
+func (db *RoomDBStore) DeleteRoomByID(ctx context.Context, id uint, cascade bool) error {
+	ctx, span := tracing.StartSpan(ctx, "RoomDBStore.DeleteRoomByID")
+	defer span.End()
+	db = db.traced(ctx)
 
-	if err := db.Model(&Room{}).Where("id = ?", id).Take(&Room{}).Delete(&Room{}).Error; err != nil {
-		if gorm.IsRecordNotFoundError(err) {
-			db.log.Error("Room not found by id", "id", id)
-			return &RoomNotFoundError{err}
+	db.log.Debug("Deleting room by id...", "id", id, "cascade", cascade)
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		before, err := db.withTx(tx).GetRoomByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := deleteEntity(ctx, tx, "Room", "room", id, cascade); err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, AuditDelete, "room", id, before, nil)
+	}); err != nil {
+		if _, ok := err.(*RoomNotFoundError); ok {
+			db.log.Error("Room to be deleted not found", "id", id)
+			return err
 		} else {
 			db.log.Error("Unexpected error deleting room", "err", err)
-			return err
+			return translateError("Room", err)
 		}
 	}
 
 	db.log.Debug("Successfully deleted room")
 	return nil
 }
+
+// RestoreRoomByID takes the room out of the trash with its talk dates deleted along with it, unless its location or
+// organization is in the trash
+func (db *RoomDBStore) RestoreRoomByID(ctx context.Context, id uint) (*Room, error) {
+	ctx, span := tracing.StartSpan(ctx, "RoomDBStore.RestoreRoomByID")
+	defer span.End()
+	db = db.traced(ctx)
+
+	db.log.Debug("Restoring room by id...", "id", id)
+
+	var room *Room
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := restoreEntity(ctx, tx, "Room", "room", id); err != nil {
+			return err
+		}
+		after, err := db.withTx(tx).GetRoomByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		room = after
+		return recordAudit(ctx, tx, AuditRestore, "room", id, nil, after)
+	}); err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			db.log.Error("Room not found by id", "id", id)
+			return nil, &RoomNotFoundError{err}
+		} else {
+			db.log.Error("Unexpected error restoring room", "err", err)
+			return nil, translateError("Room", err)
+		}
+	}
+
+	db.log.Debug("Successfully restored room")
+	return room, nil
+}
@@ -0,0 +1,172 @@
+package data
+
+import (
+	"context"
+	"github.com/hashicorp/go-hclog"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpandSeries(t *testing.T) {
+	// the event runs from Friday to Tuesday in Belgrade, which switches to daylight saving time on Sunday
+	belgrade, _ := LoadTimezone("Europe/Belgrade")
+	begin := time.Date(2021, 3, 26, 9, 0, 0, 0, belgrade)
+	event := Event{
+		Name:      "Spring",
+		BeginDate: time.Date(2021, 3, 26, 0, 0, 0, 0, belgrade),
+		EndDate:   time.Date(2021, 3, 31, 0, 0, 0, 0, belgrade),
+		Timezone:  "Europe/Belgrade",
+	}
+
+	tests := []struct {
+		name       string
+		recurrence string
+		exceptions Dates
+		// change is applied to the expanded series, which is expanded again, resetting its occurrences with reset
+		change  func(db *TalkDateDBStore, seriesID uint) error
+		reset   bool
+		want    []string
+		trashed []string
+	}{
+		{
+			name:       "daily within the event",
+			recurrence: "FREQ=DAILY",
+			want:       []string{"2021-03-27T08:00:00Z", "2021-03-28T07:00:00Z", "2021-03-29T07:00:00Z", "2021-03-30T07:00:00Z"},
+		},
+		{
+			name:       "with count",
+			recurrence: "FREQ=DAILY;COUNT=3",
+			want:       []string{"2021-03-27T08:00:00Z", "2021-03-28T07:00:00Z"},
+		},
+		{
+			name:       "weekly by day",
+			recurrence: "FREQ=WEEKLY;BYDAY=MO,TU",
+			want:       []string{"2021-03-29T07:00:00Z", "2021-03-30T07:00:00Z"},
+		},
+		{
+			name:       "without the exceptions",
+			recurrence: "FREQ=DAILY",
+			exceptions: Dates{"2021-03-28", "2021-03-30"},
+			want:       []string{"2021-03-27T08:00:00Z", "2021-03-29T07:00:00Z"},
+		},
+		{
+			name:       "rule narrowed",
+			recurrence: "FREQ=DAILY",
+			change: func(db *TalkDateDBStore, seriesID uint) error {
+				return db.Model(&TalkDate{}).Where("id = ?", seriesID).Update("recurrence", "FREQ=DAILY;INTERVAL=2").Error
+			},
+			want:    []string{"2021-03-28T07:00:00Z", "2021-03-30T07:00:00Z"},
+			trashed: []string{"2021-03-27T08:00:00Z", "2021-03-29T07:00:00Z"},
+		},
+		{
+			name:       "event shortened",
+			recurrence: "FREQ=DAILY",
+			change: func(db *TalkDateDBStore, seriesID uint) error {
+				return db.Model(&Event{}).Where("1 = 1").Update("end_date", time.Date(2021, 3, 29, 0, 0, 0, 0, belgrade)).Error
+			},
+			want:    []string{"2021-03-27T08:00:00Z", "2021-03-28T07:00:00Z"},
+			trashed: []string{"2021-03-29T07:00:00Z", "2021-03-30T07:00:00Z"},
+		},
+		{
+			name:       "occurrence in the trash",
+			recurrence: "FREQ=DAILY",
+			change: func(db *TalkDateDBStore, seriesID uint) error {
+				return db.Where("series_id = ? AND begin_date = ?", seriesID, time.Date(2021, 3, 28, 7, 0, 0, 0, time.UTC)).Delete(&TalkDate{}).Error
+			},
+			want:    []string{"2021-03-27T08:00:00Z", "2021-03-29T07:00:00Z", "2021-03-30T07:00:00Z"},
+			trashed: []string{"2021-03-28T07:00:00Z"},
+		},
+		{
+			name:       "occurrence moved",
+			recurrence: "FREQ=DAILY",
+			change:     moveOccurrence,
+			want:       []string{"2021-03-27T08:00:00Z", "2021-03-28T12:00:00Z", "2021-03-29T07:00:00Z", "2021-03-30T07:00:00Z"},
+		},
+		{
+			name:       "occurrence moved and reset",
+			recurrence: "FREQ=DAILY",
+			change:     moveOccurrence,
+			reset:      true,
+			want:       []string{"2021-03-27T08:00:00Z", "2021-03-28T07:00:00Z", "2021-03-29T07:00:00Z", "2021-03-30T07:00:00Z"},
+		},
+	}
+
+	for _, tt := range tests {
+		db := newSeriesDB(t)
+		e := event
+		if err := db.Create(&e).Error; err != nil {
+			t.Fatal(err)
+		}
+		talk := Talk{Title: "Series"}
+		if err := db.Create(&talk).Error; err != nil {
+			t.Fatal(err)
+		}
+		series := TalkDate{BeginDate: begin, RecurrenceDate: &begin, TalkID: talk.ID, EventID: &e.ID, Recurrence: tt.recurrence, Exceptions: tt.exceptions}
+		if err := db.Create(&series).Error; err != nil {
+			t.Fatal(err)
+		}
+
+		ctx := context.Background()
+		if err := db.expandSeries(ctx, series.ID, true); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if tt.change != nil {
+			if err := tt.change(db, series.ID); err != nil {
+				t.Fatal(err)
+			}
+			if err := db.expandSeries(ctx, series.ID, tt.reset); err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+		}
+
+		var occurrences []*TalkDate
+		if err := db.Unscoped().Where("series_id = ?", series.ID).Order("recurrence_date").Find(&occurrences).Error; err != nil {
+			t.Fatal(err)
+		}
+		var got, trashed []string
+		for _, occurrence := range occurrences {
+			if occurrence.TalkID != talk.ID || occurrence.EventID == nil || *occurrence.EventID != e.ID {
+				t.Errorf("%s: occurrence %d does not belong to the talk and the event of the series", tt.name, occurrence.ID)
+			}
+			if occurrence.DeletedAt != nil {
+				trashed = append(trashed, occurrence.RecurrenceDate.UTC().Format(time.RFC3339))
+			} else {
+				got = append(got, occurrence.BeginDate.UTC().Format(time.RFC3339))
+			}
+		}
+		if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("%s: occurrences = %v, want %v", tt.name, got, tt.want)
+		}
+		if strings.Join(trashed, " ") != strings.Join(tt.trashed, " ") {
+			t.Errorf("%s: occurrences in the trash = %v, want %v", tt.name, trashed, tt.trashed)
+		}
+	}
+}
+
+// moveOccurrence moves the occurrence of the Sunday of the series to noon
+func moveOccurrence(db *TalkDateDBStore, seriesID uint) error {
+	return db.Model(&TalkDate{}).Where("series_id = ? AND begin_date = ?", seriesID, time.Date(2021, 3, 28, 7, 0, 0, 0, time.UTC)).
+		Update("begin_date", time.Date(2021, 3, 28, 12, 0, 0, 0, time.UTC)).Error
+}
+
+// newSeriesDB returns a store on an empty database of the tables the series use
+func newSeriesDB(t *testing.T) *TalkDateDBStore {
+	t.Helper()
+	db, err := gorm.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	// every connection would open a database of its own
+	db.DB().SetMaxOpenConns(1)
+	db.SingularTable(true)
+	for _, model := range []interface{}{&Location{}, &Organization{}, &Event{}, &Person{}, &Room{}, &Topic{}, &Talk{}, &TalkDate{}, &AuditEntry{}} {
+		if err := db.AutoMigrate(model).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewTalkDateDBStore(db, hclog.NewNullLogger())
+}
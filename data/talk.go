@@ -1,46 +1,102 @@
 package data
 
 import (
+	"context"
 	"github.com/davecgh/go-spew/spew"
 	"github.com/go-playground/validator/v10"
 	"github.com/hashicorp/go-hclog"
 	"github.com/jinzhu/gorm"
+	"github.com/milutindzunic/pac-backend/tracing"
+	"time"
 )
 
 type Talk struct {
 	// gorm.Model
-	ID                uint       `json:"id" gorm:"primary_key;auto_increment"`
-	Title             string     `json:"title" gorm:"not null"`
-	DurationInMinutes uint       `json:"durationInMinutes" gorm:"not null"`
-	Language          string     `json:"language" gorm:"not null"`
-	Level             TalkLevel  `json:"level" gorm:"not null"`
-	Persons           []Person   `json:"persons,omitempty" gorm:"many2many:talks_at;association_autoupdate:false"`
-	Topics            []Topic    `json:"topics,omitempty" gorm:"many2many:talk_topic;association_autoupdate:false"`
-	TalkDates         []TalkDate `json:"talkDates,omitempty" gorm:"foreignkey:TalkID;association_autoupdate:false"`
+	ID                uint      `json:"id" gorm:"primary_key;auto_increment"`
+	Title             string    `json:"title" gorm:"not null" validate:"notblank"`
+	DurationInMinutes uint      `json:"durationInMinutes" gorm:"not null" validate:"gt=0"`
+	Language          string    `json:"language" gorm:"not null" validate:"notblank"`
+	Level             TalkLevel `json:"level" gorm:"not null" validate:"talklevel"`
+	// Format defaults to a regular talk when not given
+	Format TalkFormat `json:"format" gorm:"not null;default:'talk'" validate:"omitempty,talkformat"`
+	// Abstract is the description of the talk, in markdown
+	Abstract      string `json:"abstract,omitempty" sql:"type:text" validate:"max=10000"`
+	Prerequisites string `json:"prerequisites,omitempty" sql:"type:text" validate:"max=2000"`
+	SlidesURL     string `json:"slidesUrl,omitempty" validate:"omitempty,url,max=2048"`
+	VideoURL      string `json:"videoUrl,omitempty" validate:"omitempty,url,max=2048"`
+	RepositoryURL string `json:"repositoryUrl,omitempty" validate:"omitempty,url,max=2048"`
+	// TrackID is the track of one of the events the talk is given at, talks need not be in a track
+	TrackID   *uint      `json:"-"`
+	Track     *Track     `json:"track,omitempty" gorm:"association_autoupdate:false" validate:"-"`
+	Persons   []Person   `json:"persons,omitempty" gorm:"many2many:talks_at;association_autoupdate:false"`
+	Topics    []Topic    `json:"topics,omitempty" gorm:"many2many:talk_topic;association_autoupdate:false"`
+	TalkDates []TalkDate `json:"talkDates,omitempty" gorm:"foreignkey:TalkID;association_autoupdate:false"`
+	// OrganizationID is the tenant owning the talk, nullable as talks created before tenancy have none
+	OrganizationID *uint         `json:"-"`
+	Organization   *Organization `json:"organization,omitempty" gorm:"association_autoupdate:false" validate:"-"`
+	DeletedAt      *time.Time    `json:"deletedAt,omitempty" sql:"index"`
 }
 
 type TalkLevel string
 
 const (
 	BeginnerLevel TalkLevel = "beginner"
-	AdvancedLevel           = "advanced"
-	ExpertLevel             = "expert"
+	AdvancedLevel TalkLevel = "advanced"
+	ExpertLevel   TalkLevel = "expert"
 )
 
+type TalkFormat string
+
+const (
+	KeynoteFormat   TalkFormat = "keynote"
+	RegularFormat   TalkFormat = "talk"
+	WorkshopFormat  TalkFormat = "workshop"
+	PanelFormat     TalkFormat = "panel"
+	LightningFormat TalkFormat = "lightning"
+)
+
+// TalkFilter selects talks in the listings; zero values match all talks
+type TalkFilter struct {
+	Format   TalkFormat
+	Level    TalkLevel
+	Language string
+	TrackID  uint
+}
+
+// apply restricts the query on the talk table to the talks matching the filter
+func (f TalkFilter) apply(db *gorm.DB) *gorm.DB {
+	if f.Format != "" {
+		db = db.Where("talk.format = ?", f.Format)
+	}
+	if f.Level != "" {
+		db = db.Where("talk.level = ?", f.Level)
+	}
+	if f.Language != "" {
+		db = db.Where("talk.language = ?", f.Language)
+	}
+	if f.TrackID != 0 {
+		db = db.Where("talk.track_id = ?", f.TrackID)
+	}
+	return db
+}
+
 type TalkStore interface {
-	GetTalks() ([]*Talk, error)
-	GetTalkByID(id uint) (*Talk, error)
-	UpdateTalk(id uint, talk *Talk) (*Talk, error)
-	AddTalk(talk *Talk) (*Talk, error)
-	DeleteTalkByID(id uint) error
-	GetTalksByEventID(eventID uint) ([]*Talk, error)
-	GetTalksByPersonID(personID uint) ([]*Talk, error)
+	GetTalks(ctx context.Context, filter TalkFilter) ([]*Talk, error)
+	GetTalkByID(ctx context.Context, id uint) (*Talk, error)
+	GetTalksByIDs(ctx context.Context, ids []uint) ([]*Talk, error)
+	UpdateTalk(ctx context.Context, id uint, talk *Talk) (*Talk, error)
+	AddTalk(ctx context.Context, talk *Talk) (*Talk, error)
+	DeleteTalkByID(ctx context.Context, id uint, cascade bool) error
+	RestoreTalkByID(ctx context.Context, id uint) (*Talk, error)
+	GetTalksByEventID(ctx context.Context, eventID uint, filter TalkFilter) ([]*Talk, error)
+	GetTalksByPersonID(ctx context.Context, personID uint) ([]*Talk, error)
+	GetTalksByPersonIDs(ctx context.Context, personIDs []uint) ([]*Talk, error)
 }
 
 type TalkDBStore struct {
 	*gorm.DB
 	validate *validator.Validate
-	log hclog.Logger
+	log      hclog.Logger
 }
 
 type TalkNotFoundError struct {
@@ -51,48 +107,53 @@ func (e TalkNotFoundError) Error() string { return "Talk not found! Cause: " + e
 func (e TalkNotFoundError) Unwrap() error { return e.Cause }
 
 func NewTalkDBStore(db *gorm.DB, log hclog.Logger) *TalkDBStore {
-	return &TalkDBStore{db, validator.New(), log}
+	return &TalkDBStore{db, newValidator(), log}
 }
 
-func (db *TalkDBStore) GetTalks() ([]*Talk, error) {
-	db.log.Debug("Getting all talks...")
+// withTx returns a copy of the store running its queries within the transaction
+func (db *TalkDBStore) withTx(tx *gorm.DB) *TalkDBStore {
+	return &TalkDBStore{tx, db.validate, db.log}
+}
+
+func (db *TalkDBStore) traced(ctx context.Context) *TalkDBStore {
+	tx, log := inContext(ctx, db.DB, db.log)
+	return &TalkDBStore{tx, db.validate, log}
+}
+
+func (db *TalkDBStore) GetTalks(ctx context.Context, filter TalkFilter) ([]*Talk, error) {
+	ctx, span := tracing.StartSpan(ctx, "TalkDBStore.GetTalks")
+	defer span.End()
+	db = db.traced(ctx)
+
+	db.log.Debug("Getting all talks...", "filter", hclog.Fmt("%+v", filter))
 
 	var talks []*Talk
-	if err := db.
-		Preload("Persons").
-		Preload("Persons.Organization").
-		Preload("Topics").
-		Preload("Topics.Children").
-		Preload("TalkDates").
-		Preload("TalkDates.Room").
-		Preload("TalkDates.Event").
+	if err := filter.apply(preload(ctx, scoped(ctx, db.DB, "Talk"), "Talk")).
 		Find(&talks).Error; err != nil {
 		db.log.Error("Error getting all talks", "err", err)
-		return []*Talk{}, err
+		return []*Talk{}, translateError("Talk", err)
 	}
 
 	db.log.Debug("Returning talks", "talks", spew.Sprintf("%+v", talks))
 	return talks, nil
 }
 
-func (db *TalkDBStore) GetTalkByID(id uint) (*Talk, error) {
+func (db *TalkDBStore) GetTalkByID(ctx context.Context, id uint) (*Talk, error) {
+	ctx, span := tracing.StartSpan(ctx, "TalkDBStore.GetTalkByID")
+	defer span.End()
+	db = db.traced(ctx)
+
 	db.log.Debug("Getting talk by id...", "id", id)
 
 	var talk Talk
-	if err := db.Preload("Persons").
-		Preload("Persons.Organization").
-		Preload("Topics").
-		Preload("Topics.Children").
-		Preload("TalkDates").
-		Preload("TalkDates.Room").
-		Preload("TalkDates.Event").
+	if err := preload(ctx, scoped(ctx, db.DB, "Talk"), "Talk").
 		First(&talk, id).Error; err != nil {
 		if gorm.IsRecordNotFoundError(err) {
 			db.log.Error("Talk not found by id", "id", id)
 			return nil, &TalkNotFoundError{err}
 		} else {
 			db.log.Error("Unexpected error getting talk by id", "err", err)
-			return nil, err
+			return nil, translateError("Talk", err)
 		}
 	}
 
@@ -100,57 +161,151 @@ func (db *TalkDBStore) GetTalkByID(id uint) (*Talk, error) {
 	return &talk, nil
 }
 
-func (db *TalkDBStore) UpdateTalk(id uint, talk *Talk) (*Talk, error) {
+// GetTalksByIDs returns the talks with the given ids in a single query, unknown ids are skipped
+func (db *TalkDBStore) GetTalksByIDs(ctx context.Context, ids []uint) ([]*Talk, error) {
+	ctx, span := tracing.StartSpan(ctx, "TalkDBStore.GetTalksByIDs")
+	defer span.End()
+	db = db.traced(ctx)
+
+	db.log.Debug("Getting talks by ids...", "ids", ids)
+
+	var talks []*Talk
+	if err := preload(ctx, scoped(ctx, db.DB, "Talk"), "Talk").
+		Where("id IN (?)", ids).
+		Find(&talks).Error; err != nil {
+		db.log.Error("Error getting talks by ids", "err", err)
+		return []*Talk{}, translateError("Talk", err)
+	}
+
+	db.log.Debug("Returning talks", "talks", spew.Sprintf("%+v", talks))
+	return talks, nil
+}
+
+func (db *TalkDBStore) UpdateTalk(ctx context.Context, id uint, talk *Talk) (*Talk, error) {
+	ctx, span := tracing.StartSpan(ctx, "TalkDBStore.UpdateTalk")
+	defer span.End()
+	db = db.traced(ctx)
+
 	db.log.Debug("Updating talk...", "talk", hclog.Fmt("%+v", talk))
 
-	err := db.validate.Struct(talk)
+	talk.DeletedAt = nil
+	err := validateStruct(db.validate, "Talk", talk)
+	if err == nil {
+		err = checkReferences(ctx, db.DB, "Talk", "persons", "Person", "person", personIDs(talk.Persons)...)
+	}
+	if err == nil {
+		err = checkReferences(ctx, db.DB, "Talk", "track", "Track", "track", trackID(talk))
+	}
 	if err != nil {
 		db.log.Error("Error validating talk", "err", err)
 		return nil, err
 	}
+	assignOptionalTenant(ctx, &talk.OrganizationID, &talk.Organization)
 
-	if err := db.Model(&Talk{}).Where("id = ?", id).Take(&Talk{}).Update(talk).First(&talk, id).Error; err != nil {
-		if gorm.IsRecordNotFoundError(err) {
-			db.log.Error("Talk to be updated not found", "talk", hclog.Fmt("%+v", talk))
-			return nil, &TalkNotFoundError{err}
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		before, err := db.withTx(tx).GetTalkByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := updateRow(ctx, tx, &Talk{}, id, talk); err != nil {
+			return err
+		}
+		// the relations given replace the current ones, the ones not given are kept
+		if talk.Persons != nil {
+			if err := tx.Model(&Talk{ID: id}).Association("Persons").Replace(talk.Persons).Error; err != nil {
+				return err
+			}
+		}
+		if talk.Topics != nil {
+			if err := tx.Model(&Talk{ID: id}).Association("Topics").Replace(talk.Topics).Error; err != nil {
+				return err
+			}
+		}
+		after, err := db.withTx(tx).GetTalkByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		talk = after
+		return recordAudit(ctx, tx, AuditUpdate, "talk", id, before, after)
+	}); err != nil {
+		if _, ok := err.(*TalkNotFoundError); ok {
+			db.log.Error("Talk to be updated not found", "id", id)
+			return nil, err
 		} else {
 			db.log.Error("Unexpected error updating talk", "err", err)
-			return nil, err
+			return nil, translateError("Talk", err)
 		}
 	}
 
 	db.log.Debug("Successfully updated talk", "talk", hclog.Fmt("%+v", talk))
-	return db.GetTalkByID(talk.ID)
+	return talk, nil
 }
 
-func (db *TalkDBStore) AddTalk(talk *Talk) (*Talk, error) {
+func (db *TalkDBStore) AddTalk(ctx context.Context, talk *Talk) (*Talk, error) {
+	ctx, span := tracing.StartSpan(ctx, "TalkDBStore.AddTalk")
+	defer span.End()
+	db = db.traced(ctx)
+
 	db.log.Debug("Adding talk...", "talk", hclog.Fmt("%+v", talk))
 
-	err := db.validate.Struct(talk)
+	talk.DeletedAt = nil
+	err := validateStruct(db.validate, "Talk", talk)
+	if err == nil {
+		err = checkReferences(ctx, db.DB, "Talk", "persons", "Person", "person", personIDs(talk.Persons)...)
+	}
+	if err == nil {
+		err = checkReferences(ctx, db.DB, "Talk", "track", "Track", "track", trackID(talk))
+	}
 	if err != nil {
 		db.log.Error("Error validating talk", "err", err)
 		return nil, err
 	}
+	assignOptionalTenant(ctx, &talk.OrganizationID, &talk.Organization)
 
-	if err := db.Create(&talk).Error; err != nil {
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&talk).Error; err != nil {
+			return err
+		}
+		after, err := db.withTx(tx).GetTalkByID(ctx, talk.ID)
+		if err != nil {
+			return err
+		}
+		talk = after
+		return recordAudit(ctx, tx, AuditCreate, "talk", talk.ID, nil, after)
+	}); err != nil {
 		db.log.Error("Unexpected error creating talk", "err", err)
-		return nil, err
+		return nil, translateError("Talk", err)
 	}
 
 	db.log.Debug("Successfully added talk", "talk", hclog.Fmt("%+v", talk))
-	return db.GetTalkByID(talk.ID)
+	return talk, nil
 }
 
-func (db *TalkDBStore) DeleteTalkByID(id uint) error {
-	db.log.Debug("Deleting talk by id...", "id", id)
+// DeleteTalkByID moves the talk to the trash, see deleteEntity. Its talk dates keep it from being deleted, unless they
+// are moved to the trash along with it by cascade.
+func (db *TalkDBStore) DeleteTalkByID(ctx context.Context, id uint, cascade bool) error {
+	ctx, span := tracing.StartSpan(ctx, "TalkDBStore.DeleteTalkByID")
+	defer span.End()
+	db = db.traced(ctx)
 
-	if err := db.Model(&Talk{}).Where("id = ?", id).Take(&Talk{}).Delete(&Talk{}).Error; err != nil {
-		if gorm.IsRecordNotFoundError(err) {
-			db.log.Error("Talk not found by id", "id", id)
-			return &TalkNotFoundError{err}
+	db.log.Debug("Deleting talk by id...", "id", id, "cascade", cascade)
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		before, err := db.withTx(tx).GetTalkByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := deleteEntity(ctx, tx, "Talk", "talk", id, cascade); err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, AuditDelete, "talk", id, before, nil)
+	}); err != nil {
+		if _, ok := err.(*TalkNotFoundError); ok {
+			db.log.Error("Talk to be deleted not found", "id", id)
+			return err
 		} else {
 			db.log.Error("Unexpected error deleting talk", "err", err)
-			return err
+			return translateError("Talk", err)
 		}
 	}
 
@@ -158,43 +313,116 @@ func (db *TalkDBStore) DeleteTalkByID(id uint) error {
 	return nil
 }
 
-func (db *TalkDBStore) GetTalksByEventID(eventID uint) ([]*Talk, error) {
-	db.log.Debug("Getting talks by event id...", "eventID", eventID)
+// RestoreTalkByID takes the talk out of the trash with its talk dates deleted along with it, unless its track or
+// organization is in the trash
+func (db *TalkDBStore) RestoreTalkByID(ctx context.Context, id uint) (*Talk, error) {
+	ctx, span := tracing.StartSpan(ctx, "TalkDBStore.RestoreTalkByID")
+	defer span.End()
+	db = db.traced(ctx)
+
+	db.log.Debug("Restoring talk by id...", "id", id)
+
+	var talk *Talk
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := restoreEntity(ctx, tx, "Talk", "talk", id); err != nil {
+			return err
+		}
+		after, err := db.withTx(tx).GetTalkByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		talk = after
+		return recordAudit(ctx, tx, AuditRestore, "talk", id, nil, after)
+	}); err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			db.log.Error("Talk not found by id", "id", id)
+			return nil, &TalkNotFoundError{err}
+		} else {
+			db.log.Error("Unexpected error restoring talk", "err", err)
+			return nil, translateError("Talk", err)
+		}
+	}
+
+	db.log.Debug("Successfully restored talk")
+	return talk, nil
+}
+
+func (db *TalkDBStore) GetTalksByEventID(ctx context.Context, eventID uint, filter TalkFilter) ([]*Talk, error) {
+	ctx, span := tracing.StartSpan(ctx, "TalkDBStore.GetTalksByEventID")
+	defer span.End()
+	db = db.traced(ctx)
+
+	db.log.Debug("Getting talks by event id...", "eventID", eventID, "filter", hclog.Fmt("%+v", filter))
 
 	var talks []*Talk
-	if err := db.
+	if err := filter.apply(preload(ctx, scoped(ctx, db.DB, "Talk"), "Talk")).
 		Table("talk").
-		Preload("Persons").
-		Preload("Persons.Organization").
-		Preload("Topics").
-		Preload("Topics.Children").
-		Preload("TalkDates").
-		Preload("TalkDates.Event").
-		Preload("TalkDates.Room").
-		Where("id IN ?", db.Table("talk_date").Select("talk_id").Where("event_id = ?", eventID).SubQuery()).
+		Where("id IN ?", db.Table("talk_date").Select("talk_id").Where("event_id = ? AND deleted_at IS NULL", eventID).SubQuery()).
 		Find(&talks).Error; err != nil {
 		db.log.Error("Error getting talks", "err", err)
-		return []*Talk{}, err
+		return []*Talk{}, translateError("Talk", err)
 	}
 
 	db.log.Debug("Returning talks", "talks", spew.Sprintf("%+v", talks))
 	return talks, nil
 }
 
-func (db *TalkDBStore) GetTalksByPersonID(personID uint) ([]*Talk, error) {
+func (db *TalkDBStore) GetTalksByPersonID(ctx context.Context, personID uint) ([]*Talk, error) {
+	ctx, span := tracing.StartSpan(ctx, "TalkDBStore.GetTalksByPersonID")
+	defer span.End()
+	db = db.traced(ctx)
+
 	db.log.Debug("Getting talks by person id...", "personID", personID)
 
 	var talks []*Talk
-	if err := db.
-		Preload("Persons").
-		Preload("Persons.Organization").
-		Preload("Topics").
-		Where("id IN ?", db.Table("talks_at").Select("talk_id").Where("person_id = ?", personID).SubQuery()).
+	if err := preload(ctx, scoped(ctx, db.DB, "Talk"), "Talk").
+		Where("id IN ?", db.Table("talks_at").Select("talks_at.talk_id").Joins("JOIN person ON person.id = talks_at.person_id").Where("talks_at.person_id = ? AND person.deleted_at IS NULL", personID).SubQuery()).
+		Find(&talks).Error; err != nil {
+		db.log.Error("Error getting talks", "err", err)
+		return []*Talk{}, translateError("Talk", err)
+	}
+
+	db.log.Debug("Returning talks", "talks", spew.Sprintf("%+v", talks))
+	return talks, nil
+}
+
+// GetTalksByPersonIDs returns the talks of all the given persons in a single query, their persons are preloaded
+// so that the talks can be told apart by person
+func (db *TalkDBStore) GetTalksByPersonIDs(ctx context.Context, personIDs []uint) ([]*Talk, error) {
+	ctx, span := tracing.StartSpan(ctx, "TalkDBStore.GetTalksByPersonIDs")
+	defer span.End()
+	db = db.traced(ctx)
+
+	db.log.Debug("Getting talks by person ids...", "personIDs", personIDs)
+
+	var talks []*Talk
+	if err := preload(ctx, scoped(ctx, db.DB, "Talk"), "Talk", "persons").
+		Where("id IN ?", db.Table("talks_at").Select("talks_at.talk_id").Joins("JOIN person ON person.id = talks_at.person_id").Where("talks_at.person_id IN (?) AND person.deleted_at IS NULL", personIDs).SubQuery()).
 		Find(&talks).Error; err != nil {
 		db.log.Error("Error getting talks", "err", err)
-		return []*Talk{}, err
+		return []*Talk{}, translateError("Talk", err)
 	}
 
 	db.log.Debug("Returning talks", "talks", spew.Sprintf("%+v", talks))
 	return talks, nil
 }
+
+// personIDs returns the ids of the persons, as given by the callers creating or updating talks
+func personIDs(persons []Person) []uint {
+	ids := make([]uint, 0, len(persons))
+	for _, person := range persons {
+		ids = append(ids, person.ID)
+	}
+	return ids
+}
+
+// trackID returns the id of the track of the talk, as given by the callers creating or updating talks
+func trackID(talk *Talk) uint {
+	if talk.Track != nil {
+		return talk.Track.ID
+	}
+	if talk.TrackID != nil {
+		return *talk.TrackID
+	}
+	return 0
+}
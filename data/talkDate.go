@@ -1,34 +1,52 @@
 package data
 
 import (
+	"context"
+	"fmt"
 	"github.com/davecgh/go-spew/spew"
 	"github.com/go-playground/validator/v10"
 	"github.com/hashicorp/go-hclog"
 	"github.com/jinzhu/gorm"
+	"github.com/milutindzunic/pac-backend/tracing"
 	"time"
 )
 
 type TalkDate struct {
 	// gorm.Model
 	ID         uint      `json:"id" gorm:"primary_key;auto_increment"`
-	BeginDate  time.Time `json:"beginDate" gorm:"not null"`
+	BeginDate  time.Time `json:"beginDate" gorm:"not null" validate:"required"`
 	TalkID     uint      `json:"-"`
-	Talk       *Talk     `json:"talk,omitempty" gorm:"association_autoupdate:false"`
-	RoomID     uint      `json:"-"`
-	Room       *Room     `json:"room,omitempty" gorm:"association_autoupdate:false"`
-	EventID    uint      `json:"-"`
-	Event      *Event    `json:"event,omitempty" gorm:"association_autoupdate:false"`
-	LocationID uint      `json:"-"`
-	Location   *Location `json:"location,omitempty" gorm:"association_autoupdate:false"`
+	Talk       *Talk     `json:"talk,omitempty" gorm:"association_autoupdate:false" validate:"-"`
+	RoomID     *uint     `json:"-"`
+	Room       *Room     `json:"room,omitempty" gorm:"association_autoupdate:false" validate:"-"`
+	EventID    *uint     `json:"-"`
+	Event      *Event    `json:"event,omitempty" gorm:"association_autoupdate:false" validate:"-"`
+	LocationID *uint     `json:"-"`
+	Location   *Location `json:"location,omitempty" gorm:"association_autoupdate:false" validate:"-"`
+	// Recurrence makes the talk date the first of a series repeating by the RFC 5545 rule, like "FREQ=DAILY;COUNT=3".
+	// The other occurrences of the series are talk dates of their own, within the date range of the event.
+	Recurrence string `json:"recurrence,omitempty" validate:"omitempty,max=200,rrule"`
+	// Exceptions are the days, like "2021-05-13", on which the series has no occurrence
+	Exceptions Dates `json:"exceptions,omitempty" sql:"type:text" validate:"max=100,dive,datetime=2006-01-02"`
+	// RecurrenceDate is the begin date the rule gives the occurrence, which identifies it even when it is moved
+	RecurrenceDate *time.Time `json:"recurrenceDate,omitempty"`
+	// SeriesID is the first talk date of the series the talk date is an occurrence of
+	SeriesID  *uint      `json:"-"`
+	Series    *TalkDate  `json:"series,omitempty" gorm:"association_autoupdate:false" validate:"-"`
+	DeletedAt *time.Time `json:"deletedAt,omitempty" sql:"index"`
 }
 
 type TalkDateStore interface {
-	GetTalkDates() ([]*TalkDate, error)
-	GetTalkDateByID(id uint) (*TalkDate, error)
-	UpdateTalkDate(id uint, talkDate *TalkDate) (*TalkDate, error)
-	AddTalkDate(talkDate *TalkDate) (*TalkDate, error)
-	DeleteTalkDateByID(id uint) error
-	GetTalkDatesByEventID(eventID uint) ([]*TalkDate, error)
+	GetTalkDates(ctx context.Context) ([]*TalkDate, error)
+	GetTalkDateByID(ctx context.Context, id uint) (*TalkDate, error)
+	UpdateTalkDate(ctx context.Context, id uint, talkDate *TalkDate) (*TalkDate, error)
+	UpdateTalkDateSeries(ctx context.Context, id uint, talkDate *TalkDate) (*TalkDate, error)
+	AddTalkDate(ctx context.Context, talkDate *TalkDate) (*TalkDate, error)
+	DeleteTalkDateByID(ctx context.Context, id uint, cascade bool) error
+	RestoreTalkDateByID(ctx context.Context, id uint) (*TalkDate, error)
+	GetTalkDatesByEventID(ctx context.Context, eventID uint) ([]*TalkDate, error)
+	GetTalkDatesByEventIDs(ctx context.Context, eventIDs []uint) ([]*TalkDate, error)
+	GetTalkDatesBySeriesID(ctx context.Context, seriesID uint) ([]*TalkDate, error)
 }
 
 type TalkDateDBStore struct {
@@ -45,49 +63,53 @@ func (e TalkDateNotFoundError) Error() string { return "TalkDate not found! Caus
 func (e TalkDateNotFoundError) Unwrap() error { return e.Cause }
 
 func NewTalkDateDBStore(db *gorm.DB, log hclog.Logger) *TalkDateDBStore {
-	return &TalkDateDBStore{db, validator.New(), log}
+	return &TalkDateDBStore{db, newValidator(), log}
 }
 
-func (db *TalkDateDBStore) GetTalkDates() ([]*TalkDate, error) {
+// withTx returns a copy of the store running its queries within the transaction
+func (db *TalkDateDBStore) withTx(tx *gorm.DB) *TalkDateDBStore {
+	return &TalkDateDBStore{tx, db.validate, db.log}
+}
+
+func (db *TalkDateDBStore) traced(ctx context.Context) *TalkDateDBStore {
+	tx, log := inContext(ctx, db.DB, db.log)
+	return &TalkDateDBStore{tx, db.validate, log}
+}
+
+func (db *TalkDateDBStore) GetTalkDates(ctx context.Context) ([]*TalkDate, error) {
+	ctx, span := tracing.StartSpan(ctx, "TalkDateDBStore.GetTalkDates")
+	defer span.End()
+	db = db.traced(ctx)
+
 	db.log.Debug("Getting all talkDates...")
 
 	var talkDates []*TalkDate
-	if err := db.
-		Preload("Talk").
-		Preload("Talk.Persons").
-		Preload("Talk.Topics").
-		Preload("Talk.Topics.Children").
-		Preload("Room").
-		Preload("Event").
-		Preload("Location").
+	if err := preload(ctx, scoped(ctx, db.DB, "TalkDate"), "TalkDate").
 		Find(&talkDates).Error; err != nil {
 		db.log.Error("Error getting all talkDates", "err", err)
-		return []*TalkDate{}, err
+		return []*TalkDate{}, translateError("TalkDate", err)
 	}
 
 	db.log.Debug("Returning talkDates", "talkDates", spew.Sprintf("%+v", talkDates))
 	return talkDates, nil
 }
 
-func (db *TalkDateDBStore) GetTalkDateByID(id uint) (*TalkDate, error) {
+func (db *TalkDateDBStore) GetTalkDateByID(ctx context.Context, id uint) (*TalkDate, error) {
+	ctx, span := tracing.StartSpan(ctx, "TalkDateDBStore.GetTalkDateByID")
+	defer span.End()
+	db = db.traced(ctx)
+
 	db.log.Debug("Getting talkDate by id...", "id", id)
 
 	var talkDate TalkDate
-	if err := db.
-		Preload("Talk").
-		Preload("Talk.Persons").
-		Preload("Talk.Topics").
-		Preload("Talk.Topics.Children").
-		Preload("Room").
-		Preload("Event").
-		Preload("Location").
+	if err := preload(ctx, scoped(ctx, db.DB, "TalkDate"), "TalkDate").
 		First(&talkDate, id).Error; err != nil {
 		if gorm.IsRecordNotFoundError(err) {
 			db.log.Error("TalkDate not found by id", "id", id)
 			return nil, &TalkDateNotFoundError{err}
 		} else {
 			db.log.Error("Unexpected error getting talkDate by id", "err", err)
-			return nil, err
+			return nil, translateError("TalkDate", err)
 		}
 	}
 
@@ -95,57 +117,228 @@ func (db *TalkDateDBStore) GetTalkDateByID(id uint) (*TalkDate, error) {
 	return &talkDate, nil
 }
 
-func (db *TalkDateDBStore) UpdateTalkDate(id uint, talkDate *TalkDate) (*TalkDate, error) {
+func (db *TalkDateDBStore) UpdateTalkDate(ctx context.Context, id uint, talkDate *TalkDate) (*TalkDate, error) {
+	ctx, span := tracing.StartSpan(ctx, "TalkDateDBStore.UpdateTalkDate")
+	defer span.End()
+	db = db.traced(ctx)
+
 	db.log.Debug("Updating talkDate...", "talkDate", hclog.Fmt("%+v", talkDate))
 
-	err := db.validate.Struct(talkDate)
+	talkDate.DeletedAt = nil
+	talkDate.BeginDate = talkDate.BeginDate.UTC()
+	talkDate.RecurrenceDate, talkDate.SeriesID, talkDate.Series = nil, nil, nil
+	err := validateStruct(db.validate, "TalkDate", talkDate)
+	if err == nil && (talkDate.Recurrence != "" || talkDate.Exceptions != nil) {
+		err = db.validateSameSeries(ctx, id, talkDate)
+	}
+	if err == nil {
+		err = db.validateWithinEvent(ctx, id, talkDate)
+	}
+	if err == nil {
+		err = db.checkReferences(ctx, talkDate)
+	}
 	if err != nil {
 		db.log.Error("Error validating talkDate", "err", err)
 		return nil, err
 	}
 
-	if err := db.Model(&TalkDate{}).Where("id = ?", id).Take(&TalkDate{}).Update(talkDate).First(&talkDate, id).Error; err != nil {
-		if gorm.IsRecordNotFoundError(err) {
-			db.log.Error("TalkDate to be updated not found", "talkDate", hclog.Fmt("%+v", talkDate))
-			return nil, &TalkDateNotFoundError{err}
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		before, err := db.withTx(tx).GetTalkDateByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&TalkDate{}).Where("id = ?", id).Update(talkDate).Error; err != nil {
+			return err
+		}
+		after, err := db.withTx(tx).GetTalkDateByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		talkDate = after
+		return recordAudit(ctx, tx, AuditUpdate, "talkDate", id, before, after)
+	}); err != nil {
+		if _, ok := err.(*TalkDateNotFoundError); ok {
+			db.log.Error("TalkDate to be updated not found", "id", id)
+			return nil, err
 		} else {
 			db.log.Error("Unexpected error updating talkDate", "err", err)
-			return nil, err
+			return nil, translateError("TalkDate", err)
 		}
 	}
 
 	db.log.Debug("Successfully updated talkDate", "talkDate", hclog.Fmt("%+v", talkDate))
-	return db.GetTalkDateByID(talkDate.ID)
+	return talkDate, nil
+}
+
+// UpdateTalkDateSeries updates the series the talkDate with the id is an occurrence of, which becomes the first talk date
+// of a series if it is in none yet. The given talkDate describes the first occurrence: its rule and exceptions replace
+// those of the series, and its talk, room, event, location and time of day are given to all occurrences, replacing the
+// changes made to single ones. Occurrences the rule no longer gives are moved to the trash.
+func (db *TalkDateDBStore) UpdateTalkDateSeries(ctx context.Context, id uint, talkDate *TalkDate) (*TalkDate, error) {
+	ctx, span := tracing.StartSpan(ctx, "TalkDateDBStore.UpdateTalkDateSeries")
+	defer span.End()
+	db = db.traced(ctx)
+
+	db.log.Debug("Updating talkDate series...", "id", id, "talkDate", hclog.Fmt("%+v", talkDate))
+
+	talkDate.DeletedAt = nil
+	talkDate.BeginDate = talkDate.BeginDate.UTC()
+	talkDate.RecurrenceDate, talkDate.SeriesID, talkDate.Series = nil, nil, nil
+	if talkDate.Exceptions == nil {
+		// the exceptions of the series are replaced, none given clears them
+		talkDate.Exceptions = Dates{}
+	}
+	seriesID, err := db.seriesOf(ctx, id)
+	if err == nil {
+		err = validateStruct(db.validate, "TalkDate", talkDate)
+	}
+	if err == nil && talkDate.Recurrence == "" {
+		err = &ValidationError{Entity: "TalkDate", Errors: []FieldError{{Field: "recurrence", Rule: "required", Message: "is required"}}}
+	}
+	if err == nil {
+		err = db.validateWithinEvent(ctx, seriesID, talkDate)
+	}
+	if err == nil {
+		err = db.checkReferences(ctx, talkDate)
+	}
+	if err == nil {
+		err = db.validateSeries(ctx, seriesID, talkDate)
+	}
+	if err != nil {
+		if _, ok := err.(*TalkDateNotFoundError); ok {
+			db.log.Error("TalkDate to be updated not found", "id", id)
+		} else {
+			db.log.Error("Error validating talkDate", "err", err)
+		}
+		return nil, err
+	}
+
+	recurrenceDate := talkDate.BeginDate
+	talkDate.RecurrenceDate = &recurrenceDate
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		before, err := db.withTx(tx).GetTalkDateByID(ctx, seriesID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&TalkDate{}).Where("id = ?", seriesID).Update(talkDate).Error; err != nil {
+			return err
+		}
+		// empty exceptions are skipped by the update above
+		if err := tx.Model(&TalkDate{}).Where("id = ?", seriesID).Update("exceptions", talkDate.Exceptions).Error; err != nil {
+			return err
+		}
+		after, err := db.withTx(tx).GetTalkDateByID(ctx, seriesID)
+		if err != nil {
+			return err
+		}
+		if err := recordAudit(ctx, tx, AuditUpdate, "talkDate", seriesID, before, after); err != nil {
+			return err
+		}
+		talkDate = after
+		return db.withTx(tx).expandSeries(ctx, seriesID, true)
+	}); err != nil {
+		if _, ok := err.(*TalkDateNotFoundError); ok {
+			db.log.Error("TalkDate to be updated not found", "id", id)
+			return nil, err
+		} else {
+			db.log.Error("Unexpected error updating talkDate series", "err", err)
+			return nil, translateError("TalkDate", err)
+		}
+	}
+
+	db.log.Debug("Successfully updated talkDate series", "talkDate", hclog.Fmt("%+v", talkDate))
+	return talkDate, nil
 }
 
-func (db *TalkDateDBStore) AddTalkDate(talkDate *TalkDate) (*TalkDate, error) {
+func (db *TalkDateDBStore) AddTalkDate(ctx context.Context, talkDate *TalkDate) (*TalkDate, error) {
+	ctx, span := tracing.StartSpan(ctx, "TalkDateDBStore.AddTalkDate")
+	defer span.End()
+	db = db.traced(ctx)
+
 	db.log.Debug("Adding talkDate...", "talkDate", hclog.Fmt("%+v", talkDate))
 
-	err := db.validate.Struct(talkDate)
+	talkDate.DeletedAt = nil
+	talkDate.BeginDate = talkDate.BeginDate.UTC()
+	talkDate.RecurrenceDate, talkDate.SeriesID, talkDate.Series = nil, nil, nil
+	err := validateStruct(db.validate, "TalkDate", talkDate)
+	if err == nil {
+		err = requireReference("TalkDate", "talk", talkID(talkDate))
+	}
+	if _, ok := TenantOf(ctx); ok && err == nil && talkDate.EventID == nil && talkDate.Event == nil {
+		// the talk dates of a tenant are scoped by their event, without one it could not find them again
+		err = requireReference("TalkDate", "event", 0)
+	}
+	if err == nil {
+		err = db.validateWithinEvent(ctx, 0, talkDate)
+	}
+	if err == nil {
+		err = db.checkReferences(ctx, talkDate)
+	}
+	if err == nil && talkDate.Recurrence == "" && talkDate.Exceptions != nil {
+		err = &ValidationError{Entity: "TalkDate", Errors: []FieldError{{Field: "exceptions", Rule: "series", Message: "require a recurrence"}}}
+	}
+	if err == nil && talkDate.Recurrence != "" {
+		err = db.validateSeries(ctx, 0, talkDate)
+	}
 	if err != nil {
 		db.log.Error("Error validating talkDate", "err", err)
 		return nil, err
 	}
 
-	if err := db.Create(&talkDate).Error; err != nil {
+	if talkDate.Recurrence != "" {
+		beginDate := talkDate.BeginDate
+		talkDate.RecurrenceDate = &beginDate
+	}
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&talkDate).Error; err != nil {
+			return err
+		}
+		after, err := db.withTx(tx).GetTalkDateByID(ctx, talkDate.ID)
+		if err != nil {
+			return err
+		}
+		if err := recordAudit(ctx, tx, AuditCreate, "talkDate", after.ID, nil, after); err != nil {
+			return err
+		}
+		talkDate = after
+		if talkDate.Recurrence == "" {
+			return nil
+		}
+		return db.withTx(tx).expandSeries(ctx, talkDate.ID, true)
+	}); err != nil {
 		db.log.Error("Unexpected error creating talkDate", "err", err)
-		return nil, err
+		return nil, translateError("TalkDate", err)
 	}
 
 	db.log.Debug("Successfully added talkDate", "talkDate", hclog.Fmt("%+v", talkDate))
-	return db.GetTalkDateByID(talkDate.ID)
+	return talkDate, nil
 }
 
-func (db *TalkDateDBStore) DeleteTalkDateByID(id uint) error {
-	db.log.Debug("Deleting talkDate by id...", "id", id)
+// DeleteTalkDateByID moves the talkDate to the trash, see deleteEntity. The first talk date of a series is kept
+// by its other occurrences, unless they are moved to the trash along with it by cascade.
+func (db *TalkDateDBStore) DeleteTalkDateByID(ctx context.Context, id uint, cascade bool) error {
+	ctx, span := tracing.StartSpan(ctx, "TalkDateDBStore.DeleteTalkDateByID")
+	defer span.End()
+	db = db.traced(ctx)
 
-	if err := db.Model(&TalkDate{}).Where("id = ?", id).Take(&TalkDate{}).Delete(&TalkDate{}).Error; err != nil {
-		if gorm.IsRecordNotFoundError(err) {
-			db.log.Error("TalkDate not found by id", "id", id)
-			return &TalkDateNotFoundError{err}
+	db.log.Debug("Deleting talkDate by id...", "id", id, "cascade", cascade)
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		before, err := db.withTx(tx).GetTalkDateByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := deleteEntity(ctx, tx, "TalkDate", "talk_date", id, cascade); err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, AuditDelete, "talkDate", id, before, nil)
+	}); err != nil {
+		if _, ok := err.(*TalkDateNotFoundError); ok {
+			db.log.Error("TalkDate to be deleted not found", "id", id)
+			return err
 		} else {
 			db.log.Error("Unexpected error deleting talkDate", "err", err)
-			return err
+			return translateError("TalkDate", err)
 		}
 	}
 
@@ -153,24 +346,433 @@ func (db *TalkDateDBStore) DeleteTalkDateByID(id uint) error {
 	return nil
 }
 
-func (db *TalkDateDBStore) GetTalkDatesByEventID(eventID uint) ([]*TalkDate, error) {
+// RestoreTalkDateByID takes the talkDate out of the trash, with the occurrences of its series deleted along with it
+func (db *TalkDateDBStore) RestoreTalkDateByID(ctx context.Context, id uint) (*TalkDate, error) {
+	ctx, span := tracing.StartSpan(ctx, "TalkDateDBStore.RestoreTalkDateByID")
+	defer span.End()
+	db = db.traced(ctx)
+
+	db.log.Debug("Restoring talkDate by id...", "id", id)
+
+	var talkDate *TalkDate
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := restoreEntity(ctx, tx, "TalkDate", "talk_date", id); err != nil {
+			return err
+		}
+		after, err := db.withTx(tx).GetTalkDateByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		talkDate = after
+		return recordAudit(ctx, tx, AuditRestore, "talkDate", id, nil, after)
+	}); err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			db.log.Error("TalkDate not found by id", "id", id)
+			return nil, &TalkDateNotFoundError{err}
+		} else {
+			db.log.Error("Unexpected error restoring talkDate", "err", err)
+			return nil, translateError("TalkDate", err)
+		}
+	}
+
+	db.log.Debug("Successfully restored talkDate")
+	return talkDate, nil
+}
+
+func (db *TalkDateDBStore) GetTalkDatesByEventID(ctx context.Context, eventID uint) ([]*TalkDate, error) {
+	ctx, span := tracing.StartSpan(ctx, "TalkDateDBStore.GetTalkDatesByEventID")
+	defer span.End()
+	db = db.traced(ctx)
+
 	db.log.Debug("Getting talkDates by id...", "eventID", eventID)
 
 	var talkDates []*TalkDate
-	if err := db.
-		Preload("Talk").
-		Preload("Talk.Persons").
-		Preload("Talk.Topics").
-		Preload("Talk.Topics.Children").
-		Preload("Room").
-		Preload("Event").
-		Preload("Location").
-		Where(TalkDate{EventID:  eventID}).
+	if err := preload(ctx, scoped(ctx, db.DB, "TalkDate"), "TalkDate").
+		Where("event_id = ?", eventID).
 		Find(&talkDates).Error; err != nil {
 		db.log.Error("Error getting talkDates", "err", err)
-		return []*TalkDate{}, err
+		return []*TalkDate{}, translateError("TalkDate", err)
 	}
 
 	db.log.Debug("Returning talkDates", "talkDates", spew.Sprintf("%+v", talkDates))
 	return talkDates, nil
 }
+
+// GetTalkDatesByEventIDs returns the talkDates of all the given events in a single query
+func (db *TalkDateDBStore) GetTalkDatesByEventIDs(ctx context.Context, eventIDs []uint) ([]*TalkDate, error) {
+	ctx, span := tracing.StartSpan(ctx, "TalkDateDBStore.GetTalkDatesByEventIDs")
+	defer span.End()
+	db = db.traced(ctx)
+
+	db.log.Debug("Getting talkDates by event ids...", "eventIDs", eventIDs)
+
+	var talkDates []*TalkDate
+	if err := preload(ctx, scoped(ctx, db.DB, "TalkDate"), "TalkDate").
+		Where("event_id IN (?)", eventIDs).
+		Find(&talkDates).Error; err != nil {
+		db.log.Error("Error getting talkDates", "err", err)
+		return []*TalkDate{}, translateError("TalkDate", err)
+	}
+
+	db.log.Debug("Returning talkDates", "talkDates", spew.Sprintf("%+v", talkDates))
+	return talkDates, nil
+}
+
+// GetTalkDatesBySeriesID returns the first talk date of the series and its occurrences, in the order they begin
+func (db *TalkDateDBStore) GetTalkDatesBySeriesID(ctx context.Context, seriesID uint) ([]*TalkDate, error) {
+	ctx, span := tracing.StartSpan(ctx, "TalkDateDBStore.GetTalkDatesBySeriesID")
+	defer span.End()
+	db = db.traced(ctx)
+
+	db.log.Debug("Getting talkDates by series id...", "seriesID", seriesID)
+
+	var talkDates []*TalkDate
+	if err := preload(ctx, scoped(ctx, db.DB, "TalkDate"), "TalkDate").
+		Where("talk_date.id = ? OR talk_date.series_id = ?", seriesID, seriesID).
+		Order("talk_date.begin_date").
+		Find(&talkDates).Error; err != nil {
+		db.log.Error("Error getting talkDates", "err", err)
+		return []*TalkDate{}, translateError("TalkDate", err)
+	}
+
+	db.log.Debug("Returning talkDates", "talkDates", spew.Sprintf("%+v", talkDates))
+	return talkDates, nil
+}
+
+// seriesOf returns the id of the first talk date of the series the talk date with the id is an occurrence of,
+// which is the id itself for the first talk dates of series and for talk dates in no series
+func (db *TalkDateDBStore) seriesOf(ctx context.Context, id uint) (uint, error) {
+	var existing TalkDate
+	if err := scoped(ctx, db.DB, "TalkDate").Select("id, series_id").First(&existing, id).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return 0, &TalkDateNotFoundError{err}
+		}
+		return 0, translateError("TalkDate", err)
+	}
+	if existing.SeriesID != nil {
+		return *existing.SeriesID, nil
+	}
+	return id, nil
+}
+
+// fitEvent brings the talk dates of the event with the id in line with its stored dates, after they changed. The series
+// of the event are expanded again, while a talk date which would be left outside the event fails with a
+// *ValidationError for the event, as it has to be moved first. It is meant to be called within a transaction.
+func (db *TalkDateDBStore) fitEvent(ctx context.Context, eventID uint) error {
+	var seriesIDs []uint
+	if err := db.Model(&TalkDate{}).Where("event_id = ? AND recurrence <> '' AND series_id IS NULL", eventID).Pluck("id", &seriesIDs).Error; err != nil {
+		return err
+	}
+	for _, seriesID := range seriesIDs {
+		// the occurrences moved on their own stay where they are
+		if err := db.expandSeries(ctx, seriesID, false); err != nil {
+			return err
+		}
+	}
+
+	var event Event
+	if err := db.Select("begin_date, end_date").First(&event, eventID).Error; err != nil {
+		return err
+	}
+	var outside TalkDate
+	err := db.Where("event_id = ? AND (begin_date < ? OR begin_date > ?)", eventID, event.BeginDate, event.EndDate).
+		Order("begin_date").First(&outside).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	field := "endDate"
+	if outside.BeginDate.Before(event.BeginDate) {
+		field = "beginDate"
+	}
+	return &ValidationError{Entity: "Event", Errors: []FieldError{{
+		Field:   field,
+		Rule:    "talkdates",
+		Message: fmt.Sprintf("must keep talk date %d, which begins at %s, within the event", outside.ID, outside.BeginDate.UTC().Format(time.RFC3339)),
+	}}}
+}
+
+// expandSeries brings the occurrences of the series in line with its rule. Each day the rule gives within the event
+// gets an occurrence like the first talk date of the series, unless the occurrence of that day is in the trash,
+// and the occurrences of the other days are moved to the trash. With reset, the occurrences kept are given the time
+// of day, the talk, the room and the location of the series again. It is meant to be called within a transaction.
+func (db *TalkDateDBStore) expandSeries(ctx context.Context, seriesID uint, reset bool) error {
+	var series TalkDate
+	if err := db.First(&series, seriesID).Error; err != nil {
+		return err
+	}
+	rule, err := ParseRecurrence(series.Recurrence)
+	if err != nil {
+		return err
+	}
+	event, err := db.eventOf(ctx, seriesID, &series)
+	if err != nil || event == nil {
+		return err
+	}
+	zone, err := db.seriesZone(event, series.LocationID)
+	if err != nil {
+		return err
+	}
+
+	var occurrences []*TalkDate
+	if err := db.Unscoped().Where("series_id = ?", seriesID).Find(&occurrences).Error; err != nil {
+		return err
+	}
+	byDay := map[string]*TalkDate{}
+	for _, occurrence := range occurrences {
+		if occurrence.RecurrenceDate != nil {
+			byDay[In(*occurrence.RecurrenceDate, zone).Format(dateLayout)] = occurrence
+		}
+	}
+
+	start := series.BeginDate
+	if series.RecurrenceDate != nil {
+		start = *series.RecurrenceDate
+	}
+	kept := map[uint]bool{}
+	for _, date := range rule.Occurrences(start, zone, event.EndDate)[1:] {
+		if series.Exceptions.Contains(date, zone) {
+			continue
+		}
+		occurrence, ok := byDay[In(date, zone).Format(dateLayout)]
+		if !ok {
+			if err := db.addOccurrence(ctx, &series, date); err != nil {
+				return err
+			}
+			continue
+		}
+		kept[occurrence.ID] = true
+		if reset && occurrence.DeletedAt == nil {
+			if err := db.updateOccurrence(ctx, &series, occurrence, date); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, occurrence := range occurrences {
+		if kept[occurrence.ID] || occurrence.DeletedAt != nil {
+			continue
+		}
+		before, err := db.GetTalkDateByID(ctx, occurrence.ID)
+		if err != nil {
+			return err
+		}
+		if err := deleteEntity(ctx, db.DB, "TalkDate", "talk_date", occurrence.ID, false); err != nil {
+			return err
+		}
+		if err := recordAudit(ctx, db.DB, AuditDelete, "talkDate", occurrence.ID, before, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *TalkDateDBStore) addOccurrence(ctx context.Context, series *TalkDate, date time.Time) error {
+	occurrence := &TalkDate{
+		BeginDate:      date,
+		RecurrenceDate: &date,
+		SeriesID:       &series.ID,
+		TalkID:         series.TalkID,
+		RoomID:         series.RoomID,
+		EventID:        series.EventID,
+		LocationID:     series.LocationID,
+	}
+	if err := db.Create(occurrence).Error; err != nil {
+		return err
+	}
+	after, err := db.GetTalkDateByID(ctx, occurrence.ID)
+	if err != nil {
+		return err
+	}
+	return recordAudit(ctx, db.DB, AuditCreate, "talkDate", occurrence.ID, nil, after)
+}
+
+// SameReferences reports whether the talk dates refer to the same talk, room, event and location
+func (td *TalkDate) SameReferences(other *TalkDate) bool {
+	return td.TalkID == other.TalkID && sameReference(td.RoomID, other.RoomID) &&
+		sameReference(td.EventID, other.EventID) && sameReference(td.LocationID, other.LocationID)
+}
+
+func sameReference(a *uint, b *uint) bool {
+	return a == nil && b == nil || a != nil && b != nil && *a == *b
+}
+
+// updateOccurrence moves the occurrence to the date and gives it the talk, room, event and location of the series
+func (db *TalkDateDBStore) updateOccurrence(ctx context.Context, series *TalkDate, occurrence *TalkDate, date time.Time) error {
+	if occurrence.BeginDate.Equal(date) && occurrence.RecurrenceDate.Equal(date) && occurrence.SameReferences(series) {
+		return nil
+	}
+
+	before, err := db.GetTalkDateByID(ctx, occurrence.ID)
+	if err != nil {
+		return err
+	}
+	if err := db.Model(&TalkDate{}).Where("id = ?", occurrence.ID).Updates(map[string]interface{}{
+		"begin_date":      date,
+		"recurrence_date": date,
+		"talk_id":         series.TalkID,
+		"room_id":         series.RoomID,
+		"event_id":        series.EventID,
+		"location_id":     series.LocationID,
+	}).Error; err != nil {
+		return err
+	}
+	after, err := db.GetTalkDateByID(ctx, occurrence.ID)
+	if err != nil {
+		return err
+	}
+	return recordAudit(ctx, db.DB, AuditUpdate, "talkDate", occurrence.ID, before, after)
+}
+
+// validateWithinEvent checks that the talkDate begins within the date range of its event.
+// When updating, id is the talkDate being updated and its stored event is used if none is given.
+// Events of other tenants are reported as not existing.
+func (db *TalkDateDBStore) validateWithinEvent(ctx context.Context, id uint, talkDate *TalkDate) error {
+	event, err := db.eventOf(ctx, id, talkDate)
+	if err != nil || event == nil {
+		return err
+	}
+
+	if talkDate.BeginDate.Before(event.BeginDate) || talkDate.BeginDate.After(event.EndDate) {
+		return &ValidationError{Entity: "TalkDate", Errors: []FieldError{{
+			Field:   "beginDate",
+			Rule:    "withinevent",
+			Message: fmt.Sprintf("must be between %s and %s", event.BeginDate.Format(time.RFC3339), event.EndDate.Format(time.RFC3339)),
+		}}}
+	}
+
+	return nil
+}
+
+// validateSameSeries checks that the rule and exceptions of the talkDate with the id are those stored, as they can only
+// be changed along with the whole series. The talkDate read from the API can then be written back unchanged.
+func (db *TalkDateDBStore) validateSameSeries(ctx context.Context, id uint, talkDate *TalkDate) error {
+	var existing TalkDate
+	if err := scoped(ctx, db.DB, "TalkDate").Select("recurrence, exceptions").First(&existing, id).Error; err != nil && !gorm.IsRecordNotFoundError(err) {
+		return translateError("TalkDate", err)
+	}
+
+	if talkDate.Recurrence != "" && talkDate.Recurrence != existing.Recurrence ||
+		talkDate.Exceptions != nil && !talkDate.Exceptions.Equal(existing.Exceptions) {
+		return &ValidationError{Entity: "TalkDate", Errors: []FieldError{{
+			Field:   "recurrence",
+			Rule:    "series",
+			Message: "can only be changed along with the whole series",
+		}}}
+	}
+	return nil
+}
+
+// validateSeries checks that the series beginning with the talkDate belongs to an event, which bounds its occurrences,
+// and that its exceptions leave its first occurrence in place. id is the talkDate being updated, as for validateWithinEvent.
+func (db *TalkDateDBStore) validateSeries(ctx context.Context, id uint, talkDate *TalkDate) error {
+	event, err := db.eventOf(ctx, id, talkDate)
+	if err != nil {
+		return err
+	}
+	if event == nil {
+		return &ValidationError{Entity: "TalkDate", Errors: []FieldError{{
+			Field:   "event",
+			Rule:    "series",
+			Message: "is required for a series",
+		}}}
+	}
+
+	locationID := talkDate.LocationID
+	if talkDate.Location != nil {
+		locationID = &talkDate.Location.ID
+	}
+	zone, err := db.seriesZone(event, locationID)
+	if err != nil {
+		return err
+	}
+	if talkDate.Exceptions.Contains(talkDate.BeginDate, zone) {
+		return &ValidationError{Entity: "TalkDate", Errors: []FieldError{{
+			Field:   "exceptions",
+			Rule:    "series",
+			Message: "cannot leave out the first occurrence, move the begin date of the series instead",
+		}}}
+	}
+
+	return nil
+}
+
+// eventOf returns the event of the talkDate, or nil when it has none. When updating, id is the talkDate being updated
+// and its stored event is used if none is given. Events of other tenants are reported as not existing.
+func (db *TalkDateDBStore) eventOf(ctx context.Context, id uint, talkDate *TalkDate) (*Event, error) {
+	var eventID uint
+	if talkDate.EventID != nil {
+		eventID = *talkDate.EventID
+	}
+	if talkDate.Event != nil {
+		eventID = talkDate.Event.ID
+	}
+	if eventID == 0 && id != 0 {
+		var existing TalkDate
+		if err := scoped(ctx, db.DB, "TalkDate").Select("event_id").First(&existing, id).Error; err != nil && !gorm.IsRecordNotFoundError(err) {
+			return nil, translateError("TalkDate", err)
+		}
+		if existing.EventID != nil {
+			eventID = *existing.EventID
+		}
+	}
+	if eventID == 0 {
+		return nil, nil
+	}
+
+	var event Event
+	if err := scoped(ctx, db.DB, "Event").Preload("Location").First(&event, eventID).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, &ValidationError{Entity: "TalkDate", Errors: []FieldError{{
+				Field:   "event",
+				Rule:    "exists",
+				Message: fmt.Sprintf("refers to event %d which does not exist", eventID),
+			}}}
+		}
+		return nil, translateError("TalkDate", err)
+	}
+	return &event, nil
+}
+
+// seriesZone returns the time zone the occurrences of a series keep their time of day in: the one of its event,
+// or else the one of its location, or else UTC
+func (db *TalkDateDBStore) seriesZone(event *Event, locationID *uint) (*time.Location, error) {
+	talkDate := &TalkDate{Event: event}
+	if locationID != nil {
+		var location Location
+		if err := db.Select("timezone").First(&location, *locationID).Error; err != nil && !gorm.IsRecordNotFoundError(err) {
+			return nil, translateError("TalkDate", err)
+		}
+		talkDate.Location = &location
+	}
+	if zone := talkDate.Zone(nil); zone != nil {
+		return zone, nil
+	}
+	return time.UTC, nil
+}
+
+// checkReferences checks that the talk and the room of the talkDate belong to the tenant of the caller
+func (db *TalkDateDBStore) checkReferences(ctx context.Context, talkDate *TalkDate) error {
+	talkID, roomID := talkID(talkDate), uint(0)
+	if talkDate.RoomID != nil {
+		roomID = *talkDate.RoomID
+	}
+	if talkDate.Room != nil {
+		roomID = talkDate.Room.ID
+	}
+
+	if err := checkReferences(ctx, db.DB, "TalkDate", "talk", "Talk", "talk", talkID); err != nil {
+		return err
+	}
+	return checkReferences(ctx, db.DB, "TalkDate", "room", "Room", "room", roomID)
+}
+
+// talkID returns the id of the talk of the talkDate, as given by the callers creating or updating talk dates
+func talkID(talkDate *TalkDate) uint {
+	if talkDate.Talk != nil {
+		return talkDate.Talk.ID
+	}
+	return talkDate.TalkID
+}
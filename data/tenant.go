@@ -0,0 +1,100 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"github.com/jinzhu/gorm"
+	"github.com/milutindzunic/pac-backend/auth"
+	"strconv"
+)
+
+// tenantConditions restrict the reads of the entities owned by an organization to the rows of one tenant.
+// The conditions name their table, so they also apply to queries joining it. Locations and topics are
+// shared by all tenants and have none.
+var tenantConditions = map[string]string{
+	"Organization": "organization.id = ?",
+	"Event":        "event.organization_id = ?",
+	"Person":       "person.organization_id = ?",
+	"Room":         "room.organization_id = ?",
+	"Track":        "track.event_id IN (SELECT id FROM event WHERE organization_id = ?)",
+	"Talk":         "talk.organization_id = ?",
+	"TalkDate":     "talk_date.event_id IN (SELECT id FROM event WHERE organization_id = ?)",
+}
+
+// TenantOf returns the organization the stores are scoped to for the caller. ok is false when the stores are not
+// scoped: tenancy is disabled, the caller is a platform admin, or the call was not made on behalf of a caller.
+func TenantOf(ctx context.Context) (uint, bool) {
+	identity, ok := auth.IdentityFromContext(ctx)
+	if !ok || identity.Tenant == nil || identity.Tenant.Admin {
+		return 0, false
+	}
+	return identity.Tenant.OrganizationID, true
+}
+
+// TenantKey identifies the tenant the reads are scoped to, to tell apart the cached reads of different tenants.
+// It is empty for reads which are not scoped.
+func TenantKey(ctx context.Context) string {
+	organizationID, ok := TenantOf(ctx)
+	if !ok {
+		return ""
+	}
+	return "tenant=" + strconv.FormatUint(uint64(organizationID), 10)
+}
+
+// scoped returns db restricted to the rows of the entity which belong to the tenant of the caller.
+// Rows of other tenants are then not found, just like rows which do not exist.
+func scoped(ctx context.Context, db *gorm.DB, entity string) *gorm.DB {
+	organizationID, ok := TenantOf(ctx)
+	condition, owned := tenantConditions[entity]
+	if !ok || !owned {
+		return db
+	}
+	return db.Where(condition, organizationID)
+}
+
+// assignTenant makes an entity created or updated by a caller scoped to a tenant belong to the organization
+// of the tenant, whatever organization the caller gave
+func assignTenant(ctx context.Context, organizationID *uint, organization **Organization) {
+	if tenant, ok := TenantOf(ctx); ok {
+		*organizationID, *organization = tenant, nil
+	}
+}
+
+// assignOptionalTenant is assignTenant for the entities which may belong to no organization at all
+func assignOptionalTenant(ctx context.Context, organizationID **uint, organization **Organization) {
+	if tenant, ok := TenantOf(ctx); ok {
+		*organizationID, *organization = &tenant, nil
+	}
+}
+
+// requirePlatformAdmin fails with a *ForbiddenError when the caller is scoped to a tenant, for the mutations
+// of the entities shared by all tenants and of the tenants themselves
+func requirePlatformAdmin(ctx context.Context, entity string) error {
+	if _, ok := TenantOf(ctx); ok {
+		return &ForbiddenError{Entity: entity, Message: "can only be changed by platform admins"}
+	}
+	return nil
+}
+
+// checkReferences fails with a *ValidationError when one of the entities referenced by field does not exist
+// for the caller. Entities in the trash and entities of other tenants are reported the same way as entities
+// which do not exist, as the foreign keys only catch the latter.
+func checkReferences(ctx context.Context, tx *gorm.DB, entity string, field string, refEntity string, table string, ids ...uint) error {
+	for _, id := range ids {
+		if id == 0 {
+			continue
+		}
+		var count int
+		if err := scoped(ctx, tx.Table(table), refEntity).Where(table+".id = ? AND "+table+".deleted_at IS NULL", id).Count(&count).Error; err != nil {
+			return translateError(entity, err)
+		}
+		if count == 0 {
+			return &ValidationError{Entity: entity, Errors: []FieldError{{
+				Field:   field,
+				Rule:    "exists",
+				Message: fmt.Sprintf("refers to %s %d which does not exist", lowerFirst(refEntity), id),
+			}}}
+		}
+	}
+	return nil
+}
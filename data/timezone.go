@@ -0,0 +1,163 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// The times are stored in UTC. They are rendered in the time zone requested by the caller, or else in the time zone
+// of the event they belong to, which is the time zone of the event itself or of its location.
+
+type timezoneKey struct{}
+
+// WithTimezone returns a copy of the context rendering all times in the zone, instead of the time zones of the events
+func WithTimezone(ctx context.Context, zone *time.Location) context.Context {
+	return context.WithValue(ctx, timezoneKey{}, zone)
+}
+
+// TimezoneOf returns the time zone requested in the context, or nil when the caller did not request one
+func TimezoneOf(ctx context.Context) *time.Location {
+	zone, _ := ctx.Value(timezoneKey{}).(*time.Location)
+	return zone
+}
+
+// timezones caches the loaded time zones by name, as loading reads the zone database every time
+var timezones sync.Map
+
+// LoadTimezone returns the IANA time zone with the name. Unlike time.LoadLocation, it rejects "" and "Local",
+// which name the time zone of the server.
+func LoadTimezone(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return nil, fmt.Errorf("unknown time zone %q", name)
+	}
+	if zone, ok := timezones.Load(name); ok {
+		return zone.(*time.Location), nil
+	}
+	zone, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	timezones.Store(name, zone)
+	return zone, nil
+}
+
+// zoneNamed returns the time zone with the name, or nil for an empty or unknown name
+func zoneNamed(name string) *time.Location {
+	zone, err := LoadTimezone(name)
+	if err != nil {
+		return nil
+	}
+	return zone
+}
+
+// Zone returns the time zone the times of the event are rendered in: the requested zone, or else the time zone of the
+// event or of its location, if loaded. It returns nil when none of them is known.
+func (e *Event) Zone(requested *time.Location) *time.Location {
+	if requested != nil {
+		return requested
+	}
+	if zone := zoneNamed(e.Timezone); zone != nil {
+		return zone
+	}
+	if e.Location != nil {
+		return zoneNamed(e.Location.Timezone)
+	}
+	return nil
+}
+
+// Zone returns the time zone the begin date of the talk date is rendered in: the requested zone, or else the time zone
+// of its event or of its location, if loaded. It returns nil when none of them is known.
+func (td *TalkDate) Zone(requested *time.Location) *time.Location {
+	if requested != nil {
+		return requested
+	}
+	if td.Event != nil {
+		if zone := td.Event.Zone(nil); zone != nil {
+			return zone
+		}
+	}
+	if td.Location != nil {
+		return zoneNamed(td.Location.Timezone)
+	}
+	return nil
+}
+
+// In returns t in the zone, or in UTC when the zone is nil
+func In(t time.Time, zone *time.Location) time.Time {
+	if zone == nil {
+		return t.UTC()
+	}
+	return t.In(zone)
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Localize sets the times of the entities reachable from v to the time zone they are rendered in. The dates of events
+// and talk dates are set to their Zone, the other times, like those of deletions, to the requested zone or to UTC.
+// Only the entities reached through pointers, slices or interfaces are changed.
+func Localize(v interface{}, requested *time.Location) {
+	localize(reflect.ValueOf(v), requested, map[uintptr]bool{})
+}
+
+func localize(v reflect.Value, requested *time.Location, seen map[uintptr]bool) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() || seen[v.Pointer()] {
+			return
+		}
+		seen[v.Pointer()] = true
+		localize(v.Elem(), requested, seen)
+	case reflect.Interface:
+		if !v.IsNil() {
+			localize(v.Elem(), requested, seen)
+		}
+	case reflect.Slice, reflect.Array:
+		switch v.Type().Elem().Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Struct, reflect.Slice:
+			for i := 0; i < v.Len(); i++ {
+				localize(v.Index(i), requested, seen)
+			}
+		}
+	case reflect.Struct:
+		if v.Type() == timeType {
+			if v.CanSet() {
+				v.Set(reflect.ValueOf(In(v.Interface().(time.Time), requested)))
+			}
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if field := v.Field(i); field.CanInterface() {
+				localize(field, requested, seen)
+			}
+		}
+		if !v.CanAddr() {
+			return
+		}
+		// the related entities are localized first, the zone of a talk date may depend on its event
+		switch entity := v.Addr().Interface().(type) {
+		case *Event:
+			zone := entity.Zone(requested)
+			entity.BeginDate = In(entity.BeginDate, zone)
+			entity.EndDate = In(entity.EndDate, zone)
+		case *TalkDate:
+			zone := entity.Zone(requested)
+			localizeOccurrence(entity, zone)
+			// the first talk date of a series is rarely loaded with its event, it shares the zone of its occurrences
+			if series := entity.Series; series != nil && series.Event == nil && series.Location == nil {
+				localizeOccurrence(series, zone)
+			}
+		}
+	}
+}
+
+// localizeOccurrence sets the begin and recurrence dates of the talk date to the zone
+func localizeOccurrence(talkDate *TalkDate, zone *time.Location) {
+	talkDate.BeginDate = In(talkDate.BeginDate, zone)
+	if talkDate.RecurrenceDate != nil {
+		recurrenceDate := In(*talkDate.RecurrenceDate, zone)
+		talkDate.RecurrenceDate = &recurrenceDate
+	}
+}
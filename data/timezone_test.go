@@ -0,0 +1,98 @@
+package data
+
+import (
+	"github.com/milutindzunic/pac-backend/data/timezonetest"
+	"testing"
+	"time"
+)
+
+func TestLocalize(t *testing.T) {
+	// Belgrade switches from UTC+1 to UTC+2 at 01:00 UTC on 2021-03-28
+	beforeDST := time.Date(2021, 3, 28, 0, 59, 0, 0, time.UTC)
+	afterDST := time.Date(2021, 3, 28, 1, 0, 0, 0, time.UTC)
+	newYork, _ := LoadTimezone("America/New_York")
+
+	tests := []struct {
+		name      string
+		event     Event
+		location  *Location
+		requested *time.Location
+		want      [2]string
+	}{
+		{
+			name:  "zone of the event",
+			event: Event{Timezone: "Europe/Belgrade"},
+			want:  [2]string{"2021-03-28T01:59:00+01:00", "2021-03-28T03:00:00+02:00"},
+		},
+		{
+			name:  "zone of the location of the event",
+			event: Event{Location: &Location{Timezone: "Europe/Belgrade"}},
+			want:  [2]string{"2021-03-28T01:59:00+01:00", "2021-03-28T03:00:00+02:00"},
+		},
+		{
+			name:     "zone of the location of the talk date",
+			location: &Location{Timezone: "Europe/Belgrade"},
+			want:     [2]string{"2021-03-28T01:59:00+01:00", "2021-03-28T03:00:00+02:00"},
+		},
+		{
+			name:      "requested zone",
+			event:     Event{Timezone: "Europe/Belgrade"},
+			requested: newYork,
+			want:      [2]string{"2021-03-27T20:59:00-04:00", "2021-03-27T21:00:00-04:00"},
+		},
+		{
+			name: "no zone",
+			want: [2]string{"2021-03-28T00:59:00Z", "2021-03-28T01:00:00Z"},
+		},
+	}
+
+	timezonetest.Run(t, func(t *testing.T) {
+		for _, tt := range tests {
+			// the times are read from the database in the time zone of the server
+			event := tt.event
+			event.BeginDate, event.EndDate = beforeDST.Local(), afterDST.Local()
+			talkDates := []*TalkDate{
+				{BeginDate: beforeDST.Local(), Event: &event, Location: tt.location},
+				{BeginDate: afterDST.Local(), Event: &event, Location: tt.location},
+			}
+			if tt.event == (Event{}) {
+				talkDates[0].Event, talkDates[1].Event = nil, nil
+			}
+
+			Localize(talkDates, tt.requested)
+
+			for i, talkDate := range talkDates {
+				if got := talkDate.BeginDate.Format(time.RFC3339); got != tt.want[i] {
+					t.Errorf("%s: begin date %d = %s, want %s", tt.name, i, got, tt.want[i])
+				}
+			}
+			if talkDates[0].Event != nil {
+				if got := event.BeginDate.Format(time.RFC3339); got != tt.want[0] {
+					t.Errorf("%s: begin date of the event = %s, want %s", tt.name, got, tt.want[0])
+				}
+				if got := event.EndDate.Format(time.RFC3339); got != tt.want[1] {
+					t.Errorf("%s: end date of the event = %s, want %s", tt.name, got, tt.want[1])
+				}
+			}
+		}
+	})
+}
+
+func TestLocalizeOtherTimes(t *testing.T) {
+	deleted := time.Date(2021, 11, 7, 6, 30, 0, 0, time.UTC)
+	newYork, _ := LoadTimezone("America/New_York")
+
+	timezonetest.Run(t, func(t *testing.T) {
+		for requested, want := range map[*time.Location]string{
+			nil:     "2021-11-07T06:30:00Z",
+			newYork: "2021-11-07T01:30:00-05:00", // an hour after the switch back to standard time
+		} {
+			local := deleted.Local()
+			talk := &Talk{DeletedAt: &local}
+			Localize(talk, requested)
+			if got := talk.DeletedAt.Format(time.RFC3339); got != want {
+				t.Errorf("deletion in %v = %s, want %s", requested, got, want)
+			}
+		}
+	})
+}
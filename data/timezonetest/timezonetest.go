@@ -0,0 +1,36 @@
+// Package timezonetest runs tests in several time zones of the server, whose output must not depend on them
+package timezonetest
+
+import (
+	"sync"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+// Locals are the time zones of the server the tests run in: without daylight saving time, with it in the
+// northern and in the southern hemisphere, and with a shift of half an hour
+var Locals = []string{"UTC", "Europe/Belgrade", "America/New_York", "Australia/Lord_Howe"}
+
+// mu serializes the tests changing time.Local, which is shared by all tests of the package
+var mu sync.Mutex
+
+// Run runs test as a subtest once with each of the Locals as time.Local, and restores time.Local afterwards.
+// The tests calling Run may be parallel to each other, but not to other tests depending on time.Local.
+func Run(t *testing.T, test func(t *testing.T)) {
+	t.Helper()
+	mu.Lock()
+	defer mu.Unlock()
+
+	previous := time.Local
+	defer func() { time.Local = previous }()
+
+	for _, name := range Locals {
+		zone, err := time.LoadLocation(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		time.Local = zone
+		t.Run("TZ="+name, test)
+	}
+}
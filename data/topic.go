@@ -1,32 +1,38 @@
 package data
 
 import (
+	"context"
 	"github.com/davecgh/go-spew/spew"
 	"github.com/go-playground/validator/v10"
 	"github.com/hashicorp/go-hclog"
 	"github.com/jinzhu/gorm"
+	"github.com/milutindzunic/pac-backend/tracing"
+	"time"
 )
 
 type Topic struct {
 	// gorm.Model
-	ID       uint    `json:"id" gorm:"primary_key;auto_increment"`
-	Name     string  `json:"name" gorm:"not null;default:''"`
-	Children []Topic `json:"children,omitempty" gorm:"many2many:is_child_of;association_jointable_foreignkey:child_topic_id"`
+	ID        uint       `json:"id" gorm:"primary_key;auto_increment"`
+	Name      string     `json:"name" gorm:"not null;default:''" validate:"notblank"`
+	Children  []Topic    `json:"children,omitempty" gorm:"many2many:is_child_of;association_jointable_foreignkey:child_topic_id"`
+	DeletedAt *time.Time `json:"deletedAt,omitempty" sql:"index"`
 }
 
 type TopicStore interface {
-	GetTopics() ([]*Topic, error)
-	GetTopicByID(id uint) (*Topic, error)
-	UpdateTopic(id uint, topic *Topic) (*Topic, error)
-	AddTopic(topic *Topic) (*Topic, error)
-	DeleteTopicByID(id uint) error
-	GetTopicsByEventID(eventID uint) ([]*Topic, error)
+	GetTopics(ctx context.Context) ([]*Topic, error)
+	GetTopicByID(ctx context.Context, id uint) (*Topic, error)
+	GetTopicsByIDs(ctx context.Context, ids []uint) ([]*Topic, error)
+	UpdateTopic(ctx context.Context, id uint, topic *Topic) (*Topic, error)
+	AddTopic(ctx context.Context, topic *Topic) (*Topic, error)
+	DeleteTopicByID(ctx context.Context, id uint, cascade bool) error
+	RestoreTopicByID(ctx context.Context, id uint) (*Topic, error)
+	GetTopicsByEventID(ctx context.Context, eventID uint) ([]*Topic, error)
 }
 
 type TopicDBStore struct {
 	*gorm.DB
 	validate *validator.Validate
-	log hclog.Logger
+	log      hclog.Logger
 }
 
 type TopicNotFoundError struct {
@@ -37,33 +43,51 @@ func (e TopicNotFoundError) Error() string { return "Topic not found! Cause: " +
 func (e TopicNotFoundError) Unwrap() error { return e.Cause }
 
 func NewTopicDBStore(db *gorm.DB, log hclog.Logger) *TopicDBStore {
-	return &TopicDBStore{db, validator.New(), log}
+	return &TopicDBStore{db, newValidator(), log}
 }
 
-func (db *TopicDBStore) GetTopics() ([]*Topic, error) {
+// withTx returns a copy of the store running its queries within the transaction
+func (db *TopicDBStore) withTx(tx *gorm.DB) *TopicDBStore {
+	return &TopicDBStore{tx, db.validate, db.log}
+}
+
+func (db *TopicDBStore) traced(ctx context.Context) *TopicDBStore {
+	tx, log := inContext(ctx, db.DB, db.log)
+	return &TopicDBStore{tx, db.validate, log}
+}
+
+func (db *TopicDBStore) GetTopics(ctx context.Context) ([]*Topic, error) {
+	ctx, span := tracing.StartSpan(ctx, "TopicDBStore.GetTopics")
+	defer span.End()
+	db = db.traced(ctx)
+
 	db.log.Debug("Getting all topics...")
 
 	var topics []*Topic
-	if err := db.Preload("Children").Find(&topics).Error; err != nil {
+	if err := preload(ctx, db.DB, "Topic").Find(&topics).Error; err != nil {
 		db.log.Error("Error getting all topics", "err", err)
-		return []*Topic{}, err
+		return []*Topic{}, translateError("Topic", err)
 	}
 
 	db.log.Debug("Returning topics", "topics", spew.Sprintf("%+v", topics))
 	return topics, nil
 }
 
-func (db *TopicDBStore) GetTopicByID(id uint) (*Topic, error) {
+func (db *TopicDBStore) GetTopicByID(ctx context.Context, id uint) (*Topic, error) {
+	ctx, span := tracing.StartSpan(ctx, "TopicDBStore.GetTopicByID")
+	defer span.End()
+	db = db.traced(ctx)
+
 	db.log.Debug("Getting topic by id...", "id", id)
 
 	var topic Topic
-	if err := db.Preload("Children").First(&topic, id).Error; err != nil {
+	if err := preload(ctx, db.DB, "Topic").First(&topic, id).Error; err != nil {
 		if gorm.IsRecordNotFoundError(err) {
 			db.log.Error("Topic not found by id", "id", id)
 			return nil, &TopicNotFoundError{err}
 		} else {
 			db.log.Error("Unexpected error getting topic by id", "err", err)
-			return nil, err
+			return nil, translateError("Topic", err)
 		}
 	}
 
@@ -71,57 +95,139 @@ func (db *TopicDBStore) GetTopicByID(id uint) (*Topic, error) {
 	return &topic, nil
 }
 
-func (db *TopicDBStore) UpdateTopic(id uint, topic *Topic) (*Topic, error) {
+// GetTopicsByIDs returns the topics with the given ids in a single query, unknown ids are skipped
+func (db *TopicDBStore) GetTopicsByIDs(ctx context.Context, ids []uint) ([]*Topic, error) {
+	ctx, span := tracing.StartSpan(ctx, "TopicDBStore.GetTopicsByIDs")
+	defer span.End()
+	db = db.traced(ctx)
+
+	db.log.Debug("Getting topics by ids...", "ids", ids)
+
+	var topics []*Topic
+	if err := preload(ctx, db.DB, "Topic").Where("id IN (?)", ids).Find(&topics).Error; err != nil {
+		db.log.Error("Error getting topics by ids", "err", err)
+		return []*Topic{}, translateError("Topic", err)
+	}
+
+	db.log.Debug("Returning topics", "topics", spew.Sprintf("%+v", topics))
+	return topics, nil
+}
+
+func (db *TopicDBStore) UpdateTopic(ctx context.Context, id uint, topic *Topic) (*Topic, error) {
+	ctx, span := tracing.StartSpan(ctx, "TopicDBStore.UpdateTopic")
+	defer span.End()
+	db = db.traced(ctx)
+
 	db.log.Debug("Updating topic...", "topic", hclog.Fmt("%+v", topic))
 
-	err := db.validate.Struct(topic)
+	if err := requirePlatformAdmin(ctx, "Topic"); err != nil {
+		db.log.Error("Not allowed to update topic", "err", err)
+		return nil, err
+	}
+
+	topic.DeletedAt = nil
+	err := validateStruct(db.validate, "Topic", topic)
 	if err != nil {
 		db.log.Error("Error validating topic", "err", err)
 		return nil, err
 	}
 
-	if err := db.Model(&Topic{}).Where("id = ?", id).Take(&Topic{}).Update(topic).First(&topic, id).Error; err != nil {
-		if gorm.IsRecordNotFoundError(err) {
-			db.log.Error("Topic to be updated not found", "topic", hclog.Fmt("%+v", topic))
-			return nil, &TopicNotFoundError{err}
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		before, err := db.withTx(tx).GetTopicByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&Topic{}).Where("id = ?", id).Update(topic).Error; err != nil {
+			return err
+		}
+		after, err := db.withTx(tx).GetTopicByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		topic = after
+		return recordAudit(ctx, tx, AuditUpdate, "topic", id, before, after)
+	}); err != nil {
+		if _, ok := err.(*TopicNotFoundError); ok {
+			db.log.Error("Topic to be updated not found", "id", id)
+			return nil, err
 		} else {
 			db.log.Error("Unexpected error updating topic", "err", err)
-			return nil, err
+			return nil, translateError("Topic", err)
 		}
 	}
 
 	db.log.Debug("Successfully updated topic", "topic", hclog.Fmt("%+v", topic))
-	return db.GetTopicByID(topic.ID)
+	return topic, nil
 }
 
-func (db *TopicDBStore) AddTopic(topic *Topic) (*Topic, error) {
+func (db *TopicDBStore) AddTopic(ctx context.Context, topic *Topic) (*Topic, error) {
+	ctx, span := tracing.StartSpan(ctx, "TopicDBStore.AddTopic")
+	defer span.End()
+	db = db.traced(ctx)
+
 	db.log.Debug("Adding topic...", "topic", hclog.Fmt("%+v", topic))
 
-	err := db.validate.Struct(topic)
+	if err := requirePlatformAdmin(ctx, "Topic"); err != nil {
+		db.log.Error("Not allowed to add topic", "err", err)
+		return nil, err
+	}
+
+	topic.DeletedAt = nil
+	err := validateStruct(db.validate, "Topic", topic)
 	if err != nil {
 		db.log.Error("Error validating topic", "err", err)
 		return nil, err
 	}
 
-	if err := db.Create(&topic).Error; err != nil {
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&topic).Error; err != nil {
+			return err
+		}
+		after, err := db.withTx(tx).GetTopicByID(ctx, topic.ID)
+		if err != nil {
+			return err
+		}
+		topic = after
+		return recordAudit(ctx, tx, AuditCreate, "topic", topic.ID, nil, after)
+	}); err != nil {
 		db.log.Error("Unexpected error creating topic", "err", err)
-		return nil, err
+		return nil, translateError("Topic", err)
 	}
 
 	db.log.Debug("Successfully added topic", "topic", hclog.Fmt("%+v", topic))
-	return db.GetTopicByID(topic.ID)
+	return topic, nil
 }
 
-func (db *TopicDBStore) DeleteTopicByID(id uint) error {
-	db.log.Debug("Deleting topic by id...", "id", id)
+// DeleteTopicByID moves the topic to the trash, see deleteEntity. The talks and the parent topics it is linked to keep
+// it from being deleted; with cascade, they stay and lose the topic until it is restored.
+func (db *TopicDBStore) DeleteTopicByID(ctx context.Context, id uint, cascade bool) error {
+	ctx, span := tracing.StartSpan(ctx, "TopicDBStore.DeleteTopicByID")
+	defer span.End()
+	db = db.traced(ctx)
 
-	if err := db.Model(&Topic{}).Where("id = ?", id).Take(&Topic{}).Delete(&Topic{}).Error; err != nil {
-		if gorm.IsRecordNotFoundError(err) {
-			db.log.Error("Topic not found by id", "id", id)
-			return &TopicNotFoundError{err}
+	db.log.Debug("Deleting topic by id...", "id", id, "cascade", cascade)
+
+	if err := requirePlatformAdmin(ctx, "Topic"); err != nil {
+		db.log.Error("Not allowed to delete topic", "err", err)
+		return err
+	}
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		before, err := db.withTx(tx).GetTopicByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := deleteEntity(ctx, tx, "Topic", "topic", id, cascade); err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, AuditDelete, "topic", id, before, nil)
+	}); err != nil {
+		if _, ok := err.(*TopicNotFoundError); ok {
+			db.log.Error("Topic to be deleted not found", "id", id)
+			return err
 		} else {
 			db.log.Error("Unexpected error deleting topic", "err", err)
-			return err
+			return translateError("Topic", err)
 		}
 	}
 
@@ -129,20 +235,62 @@ func (db *TopicDBStore) DeleteTopicByID(id uint) error {
 	return nil
 }
 
-func (db *TopicDBStore) GetTopicsByEventID(eventID uint) ([]*Topic, error) {
+// RestoreTopicByID takes the topic out of the trash, with its links to talks and parent topics
+func (db *TopicDBStore) RestoreTopicByID(ctx context.Context, id uint) (*Topic, error) {
+	ctx, span := tracing.StartSpan(ctx, "TopicDBStore.RestoreTopicByID")
+	defer span.End()
+	db = db.traced(ctx)
+
+	db.log.Debug("Restoring topic by id...", "id", id)
+
+	if err := requirePlatformAdmin(ctx, "Topic"); err != nil {
+		db.log.Error("Not allowed to restore topic", "err", err)
+		return nil, err
+	}
+
+	var topic *Topic
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := restoreEntity(ctx, tx, "Topic", "topic", id); err != nil {
+			return err
+		}
+		after, err := db.withTx(tx).GetTopicByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		topic = after
+		return recordAudit(ctx, tx, AuditRestore, "topic", id, nil, after)
+	}); err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			db.log.Error("Topic not found by id", "id", id)
+			return nil, &TopicNotFoundError{err}
+		} else {
+			db.log.Error("Unexpected error restoring topic", "err", err)
+			return nil, translateError("Topic", err)
+		}
+	}
+
+	db.log.Debug("Successfully restored topic")
+	return topic, nil
+}
+
+func (db *TopicDBStore) GetTopicsByEventID(ctx context.Context, eventID uint) ([]*Topic, error) {
+	ctx, span := tracing.StartSpan(ctx, "TopicDBStore.GetTopicsByEventID")
+	defer span.End()
+	db = db.traced(ctx)
+
 	db.log.Debug("Getting topics by event id...", "eventID", eventID)
 
+	// topics are shared by all tenants, but only the talk dates of the tenant tell which ones its events use
 	var topics []*Topic
-	if err := db.
+	if err := preload(ctx, scoped(ctx, db.DB, "TalkDate"), "Topic").
 		Table("topic").
-		Select("DISTINCT *").
-		Preload("Children").
+		Select("DISTINCT topic.*").
 		Joins("JOIN talk_topic ON talk_topic.topic_id = topic.id").
 		Joins("JOIN talk_date ON talk_date.talk_id = talk_topic.talk_id").
-		Where("talk_date.event_id = ?", eventID).
+		Where("talk_date.event_id = ? AND talk_date.deleted_at IS NULL", eventID).
 		Find(&topics).Error; err != nil {
-			db.log.Error("Error getting topics", "err", err)
-			return []*Topic{}, err
+		db.log.Error("Error getting topics", "err", err)
+		return []*Topic{}, translateError("Topic", err)
 	}
 
 	db.log.Debug("Returning topics", "topics", spew.Sprintf("%+v", topics))
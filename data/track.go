@@ -0,0 +1,292 @@
+package data
+
+import (
+	"context"
+	"github.com/davecgh/go-spew/spew"
+	"github.com/go-playground/validator/v10"
+	"github.com/hashicorp/go-hclog"
+	"github.com/jinzhu/gorm"
+	"github.com/milutindzunic/pac-backend/tracing"
+	"time"
+)
+
+// Track groups the talks of an event by theme, each event has its own tracks
+type Track struct {
+	// gorm.Model
+	ID   uint   `json:"id" gorm:"primary_key;auto_increment"`
+	Name string `json:"name" gorm:"not null;default:''" validate:"notblank,max=100"`
+	// Color is the color the track is shown in, as a hex color like "#1e90ff"
+	Color     string     `json:"color,omitempty" validate:"omitempty,hexcolor"`
+	EventID   uint       `json:"-" gorm:"not null"`
+	Event     *Event     `json:"event,omitempty" gorm:"association_autoupdate:false" validate:"-"`
+	DeletedAt *time.Time `json:"deletedAt,omitempty" sql:"index"`
+}
+
+type TrackStore interface {
+	GetTracks(ctx context.Context) ([]*Track, error)
+	GetTrackByID(ctx context.Context, id uint) (*Track, error)
+	GetTracksByIDs(ctx context.Context, ids []uint) ([]*Track, error)
+	UpdateTrack(ctx context.Context, id uint, track *Track) (*Track, error)
+	AddTrack(ctx context.Context, track *Track) (*Track, error)
+	DeleteTrackByID(ctx context.Context, id uint, cascade bool) error
+	RestoreTrackByID(ctx context.Context, id uint) (*Track, error)
+	GetTracksByEventID(ctx context.Context, eventID uint) ([]*Track, error)
+}
+
+type TrackDBStore struct {
+	*gorm.DB
+	validate *validator.Validate
+	log      hclog.Logger
+}
+
+type TrackNotFoundError struct {
+	Cause error
+}
+
+func (e TrackNotFoundError) Error() string { return "Track not found! Cause: " + e.Cause.Error() }
+func (e TrackNotFoundError) Unwrap() error { return e.Cause }
+
+func NewTrackDBStore(db *gorm.DB, log hclog.Logger) *TrackDBStore {
+	return &TrackDBStore{db, newValidator(), log}
+}
+
+// withTx returns a copy of the store running its queries within the transaction
+func (db *TrackDBStore) withTx(tx *gorm.DB) *TrackDBStore {
+	return &TrackDBStore{tx, db.validate, db.log}
+}
+
+func (db *TrackDBStore) traced(ctx context.Context) *TrackDBStore {
+	tx, log := inContext(ctx, db.DB, db.log)
+	return &TrackDBStore{tx, db.validate, log}
+}
+
+func (db *TrackDBStore) GetTracks(ctx context.Context) ([]*Track, error) {
+	ctx, span := tracing.StartSpan(ctx, "TrackDBStore.GetTracks")
+	defer span.End()
+	db = db.traced(ctx)
+
+	db.log.Debug("Getting all tracks...")
+
+	var tracks []*Track
+	if err := preload(ctx, scoped(ctx, db.DB, "Track"), "Track").Find(&tracks).Error; err != nil {
+		db.log.Error("Error getting all tracks", "err", err)
+		return []*Track{}, translateError("Track", err)
+	}
+
+	db.log.Debug("Returning tracks", "tracks", spew.Sprintf("%+v", tracks))
+	return tracks, nil
+}
+
+func (db *TrackDBStore) GetTrackByID(ctx context.Context, id uint) (*Track, error) {
+	ctx, span := tracing.StartSpan(ctx, "TrackDBStore.GetTrackByID")
+	defer span.End()
+	db = db.traced(ctx)
+
+	db.log.Debug("Getting track by id...", "id", id)
+
+	var track Track
+	if err := preload(ctx, scoped(ctx, db.DB, "Track"), "Track").First(&track, id).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			db.log.Error("Track not found by id", "id", id)
+			return nil, &TrackNotFoundError{err}
+		} else {
+			db.log.Error("Unexpected error getting track by id", "err", err)
+			return nil, translateError("Track", err)
+		}
+	}
+
+	db.log.Debug("Returning track", "track", hclog.Fmt("%+v", track))
+	return &track, nil
+}
+
+// GetTracksByIDs returns the tracks with the given ids in a single query, unknown ids are skipped
+func (db *TrackDBStore) GetTracksByIDs(ctx context.Context, ids []uint) ([]*Track, error) {
+	ctx, span := tracing.StartSpan(ctx, "TrackDBStore.GetTracksByIDs")
+	defer span.End()
+	db = db.traced(ctx)
+
+	db.log.Debug("Getting tracks by ids...", "ids", ids)
+
+	var tracks []*Track
+	if err := preload(ctx, scoped(ctx, db.DB, "Track"), "Track").Where("id IN (?)", ids).Find(&tracks).Error; err != nil {
+		db.log.Error("Error getting tracks by ids", "err", err)
+		return []*Track{}, translateError("Track", err)
+	}
+
+	db.log.Debug("Returning tracks", "tracks", spew.Sprintf("%+v", tracks))
+	return tracks, nil
+}
+
+func (db *TrackDBStore) UpdateTrack(ctx context.Context, id uint, track *Track) (*Track, error) {
+	ctx, span := tracing.StartSpan(ctx, "TrackDBStore.UpdateTrack")
+	defer span.End()
+	db = db.traced(ctx)
+
+	db.log.Debug("Updating track...", "track", hclog.Fmt("%+v", track))
+
+	track.DeletedAt = nil
+	err := validateStruct(db.validate, "Track", track)
+	if err == nil {
+		err = checkReferences(ctx, db.DB, "Track", "event", "Event", "event", eventID(track))
+	}
+	if err != nil {
+		db.log.Error("Error validating track", "err", err)
+		return nil, err
+	}
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		before, err := db.withTx(tx).GetTrackByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&Track{}).Where("id = ?", id).Update(track).Error; err != nil {
+			return err
+		}
+		after, err := db.withTx(tx).GetTrackByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		track = after
+		return recordAudit(ctx, tx, AuditUpdate, "track", id, before, after)
+	}); err != nil {
+		if _, ok := err.(*TrackNotFoundError); ok {
+			db.log.Error("Track to be updated not found", "id", id)
+			return nil, err
+		} else {
+			db.log.Error("Unexpected error updating track", "err", err)
+			return nil, translateError("Track", err)
+		}
+	}
+
+	db.log.Debug("Successfully updated track", "track", hclog.Fmt("%+v", track))
+	return track, nil
+}
+
+func (db *TrackDBStore) AddTrack(ctx context.Context, track *Track) (*Track, error) {
+	ctx, span := tracing.StartSpan(ctx, "TrackDBStore.AddTrack")
+	defer span.End()
+	db = db.traced(ctx)
+
+	db.log.Debug("Adding track...", "track", hclog.Fmt("%+v", track))
+
+	track.DeletedAt = nil
+	err := validateStruct(db.validate, "Track", track)
+	if err == nil {
+		err = requireReference("Track", "event", eventID(track))
+	}
+	if err == nil {
+		err = checkReferences(ctx, db.DB, "Track", "event", "Event", "event", eventID(track))
+	}
+	if err != nil {
+		db.log.Error("Error validating track", "err", err)
+		return nil, err
+	}
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&track).Error; err != nil {
+			return err
+		}
+		after, err := db.withTx(tx).GetTrackByID(ctx, track.ID)
+		if err != nil {
+			return err
+		}
+		track = after
+		return recordAudit(ctx, tx, AuditCreate, "track", track.ID, nil, after)
+	}); err != nil {
+		db.log.Error("Unexpected error creating track", "err", err)
+		return nil, translateError("Track", err)
+	}
+
+	db.log.Debug("Successfully added track", "track", hclog.Fmt("%+v", track))
+	return track, nil
+}
+
+// DeleteTrackByID moves the track to the trash, see deleteEntity. Its talks keep it from being deleted, unless they
+// are moved to the trash along with it by cascade.
+func (db *TrackDBStore) DeleteTrackByID(ctx context.Context, id uint, cascade bool) error {
+	ctx, span := tracing.StartSpan(ctx, "TrackDBStore.DeleteTrackByID")
+	defer span.End()
+	db = db.traced(ctx)
+
+	db.log.Debug("Deleting track by id...", "id", id, "cascade", cascade)
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		before, err := db.withTx(tx).GetTrackByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := deleteEntity(ctx, tx, "Track", "track", id, cascade); err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, AuditDelete, "track", id, before, nil)
+	}); err != nil {
+		if _, ok := err.(*TrackNotFoundError); ok {
+			db.log.Error("Track to be deleted not found", "id", id)
+			return err
+		} else {
+			db.log.Error("Unexpected error deleting track", "err", err)
+			return translateError("Track", err)
+		}
+	}
+
+	db.log.Debug("Successfully deleted track")
+	return nil
+}
+
+// RestoreTrackByID takes the track out of the trash with its talks deleted along with it, unless its event is in the trash
+func (db *TrackDBStore) RestoreTrackByID(ctx context.Context, id uint) (*Track, error) {
+	ctx, span := tracing.StartSpan(ctx, "TrackDBStore.RestoreTrackByID")
+	defer span.End()
+	db = db.traced(ctx)
+
+	db.log.Debug("Restoring track by id...", "id", id)
+
+	var track *Track
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := restoreEntity(ctx, tx, "Track", "track", id); err != nil {
+			return err
+		}
+		after, err := db.withTx(tx).GetTrackByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		track = after
+		return recordAudit(ctx, tx, AuditRestore, "track", id, nil, after)
+	}); err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			db.log.Error("Track not found by id", "id", id)
+			return nil, &TrackNotFoundError{err}
+		} else {
+			db.log.Error("Unexpected error restoring track", "err", err)
+			return nil, translateError("Track", err)
+		}
+	}
+
+	db.log.Debug("Successfully restored track")
+	return track, nil
+}
+
+func (db *TrackDBStore) GetTracksByEventID(ctx context.Context, eventID uint) ([]*Track, error) {
+	ctx, span := tracing.StartSpan(ctx, "TrackDBStore.GetTracksByEventID")
+	defer span.End()
+	db = db.traced(ctx)
+
+	db.log.Debug("Getting tracks by event id...", "eventID", eventID)
+
+	var tracks []*Track
+	if err := preload(ctx, scoped(ctx, db.DB, "Track"), "Track").Where("event_id = ?", eventID).Find(&tracks).Error; err != nil {
+		db.log.Error("Error getting tracks", "err", err)
+		return []*Track{}, translateError("Track", err)
+	}
+
+	db.log.Debug("Returning tracks", "tracks", spew.Sprintf("%+v", tracks))
+	return tracks, nil
+}
+
+// eventID returns the id of the event of the track, as given by the callers creating or updating tracks
+func eventID(track *Track) uint {
+	if track.Event != nil {
+		return track.Event.ID
+	}
+	return track.EventID
+}
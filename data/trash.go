@@ -0,0 +1,116 @@
+package data
+
+import (
+	"context"
+	"github.com/hashicorp/go-hclog"
+	"github.com/jinzhu/gorm"
+	"github.com/milutindzunic/pac-backend/tracing"
+	"time"
+)
+
+// Trash holds all entities which were deleted and can still be restored
+type Trash struct {
+	Locations     []*Location     `json:"locations"`
+	Events        []*Event        `json:"events"`
+	Organizations []*Organization `json:"organizations"`
+	Persons       []*Person       `json:"persons"`
+	Rooms         []*Room         `json:"rooms"`
+	Topics        []*Topic        `json:"topics"`
+	Tracks        []*Track        `json:"tracks"`
+	Talks         []*Talk         `json:"talks"`
+	TalkDates     []*TalkDate     `json:"talkDates"`
+}
+
+type TrashStore interface {
+	GetTrash(ctx context.Context) (*Trash, error)
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+}
+
+type TrashDBStore struct {
+	*gorm.DB
+	log hclog.Logger
+}
+
+func NewTrashDBStore(db *gorm.DB, log hclog.Logger) *TrashDBStore {
+	return &TrashDBStore{db, log}
+}
+
+func (db *TrashDBStore) traced(ctx context.Context) *TrashDBStore {
+	tx, log := inContext(ctx, db.DB, db.log)
+	return &TrashDBStore{tx, log}
+}
+
+func (db *TrashDBStore) GetTrash(ctx context.Context) (*Trash, error) {
+	ctx, span := tracing.StartSpan(ctx, "TrashDBStore.GetTrash")
+	defer span.End()
+	db = db.traced(ctx)
+
+	db.log.Debug("Getting trash...")
+
+	trash := Trash{}
+	deleted := db.Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at DESC")
+	for entity, entities := range map[string]interface{}{
+		"Location": &trash.Locations, "Event": &trash.Events, "Organization": &trash.Organizations, "Person": &trash.Persons,
+		"Room": &trash.Rooms, "Topic": &trash.Topics, "Track": &trash.Tracks, "Talk": &trash.Talks, "TalkDate": &trash.TalkDates,
+	} {
+		if _, owned := tenantConditions[entity]; !owned {
+			if _, ok := TenantOf(ctx); ok {
+				// the entities shared by all tenants are deleted by platform admins only, and kept out of their trash
+				continue
+			}
+		}
+		if err := scoped(ctx, deleted, entity).Find(entities).Error; err != nil {
+			db.log.Error("Error getting trash", "err", err)
+			return nil, translateError("Trash", err)
+		}
+	}
+
+	db.log.Debug("Returning trash", "trash", hclog.Fmt("%+v", trash))
+	return &trash, nil
+}
+
+// PurgeDeleted permanently removes the entities which were moved to the trash before the given time
+func (db *TrashDBStore) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	ctx, span := tracing.StartSpan(ctx, "TrashDBStore.PurgeDeleted")
+	defer span.End()
+	db = db.traced(ctx)
+
+	db.log.Debug("Purging trash...", "before", before)
+
+	var purged int64
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		// deletion timestamps are stored in UTC
+		purged, err = purgeDeleted(tx, before.UTC())
+		return err
+	}); err != nil {
+		db.log.Error("Unexpected error purging trash", "err", err)
+		return 0, translateError("Trash", err)
+	}
+
+	db.log.Debug("Successfully purged trash", "purged", purged)
+	return purged, nil
+}
+
+// RunPurgeJob purges the entities which have been in the trash for longer than retention, every interval,
+// until the context is done.
+func RunPurgeJob(ctx context.Context, store TrashStore, retention time.Duration, interval time.Duration, log hclog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := store.PurgeDeleted(ctx, time.Now().Add(-retention))
+			if err != nil {
+				log.Error("Purge job failed", "err", err)
+				continue
+			}
+			if purged > 0 {
+				log.Info("Purge job removed entities from the trash", "purged", purged)
+			}
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package data
+
+import (
+	"context"
+	"github.com/hashicorp/go-hclog"
+	"github.com/jinzhu/gorm"
+	"github.com/milutindzunic/pac-backend/logging"
+	"github.com/milutindzunic/pac-backend/tracing"
+	"sync"
+)
+
+type txKey struct{}
+
+// txState is the transaction shared by the stores called with a context returned by Transactor.InTransaction
+type txState struct {
+	tx *gorm.DB
+
+	mu          sync.Mutex
+	afterCommit []func()
+}
+
+// Transactor runs the operations of several stores in a single transaction
+type Transactor struct {
+	db *gorm.DB
+}
+
+func NewTransactor(db *gorm.DB) *Transactor {
+	return &Transactor{db}
+}
+
+// InTransaction calls fn with a context making the stores run their statements in one transaction, which is
+// committed when fn returns nil and rolled back otherwise. The functions registered with AfterCommit during fn
+// run once the transaction is committed, and are dropped when it is rolled back.
+func (t *Transactor) InTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	state := &txState{}
+	if err := t.db.Transaction(func(tx *gorm.DB) error {
+		state.tx = tx
+		return fn(context.WithValue(ctx, txKey{}, state))
+	}); err != nil {
+		return err
+	}
+
+	for _, f := range state.afterCommit {
+		f()
+	}
+	return nil
+}
+
+// InTransaction reports whether the context runs the statements of the stores in a transaction not committed yet
+func InTransaction(ctx context.Context) bool {
+	_, ok := ctx.Value(txKey{}).(*txState)
+	return ok
+}
+
+// AfterCommit runs f once the transaction of the context is committed, or right away if there is none.
+// It defers the side effects of the stores, like invalidating caches, which must not be seen before the commit.
+func AfterCommit(ctx context.Context, f func()) {
+	state, ok := ctx.Value(txKey{}).(*txState)
+	if !ok {
+		f()
+		return
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	state.afterCommit = append(state.afterCommit, f)
+}
+
+// inContext returns db running its statements in the transaction of ctx, if any, and attributing them to the span
+// in ctx, and log attributing its lines to the request and the span. The stores make copies of themselves with
+// them at the start of every operation.
+func inContext(ctx context.Context, db *gorm.DB, log hclog.Logger) (*gorm.DB, hclog.Logger) {
+	return tracing.WithContext(txOf(ctx, db), ctx), tracing.Logger(ctx, logging.FromContext(ctx, log))
+}
+
+// txOf returns the transaction of the context, or db if there is none
+func txOf(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		return state.tx
+	}
+	return db
+}
@@ -0,0 +1,35 @@
+package data
+
+import (
+	"context"
+	"github.com/jinzhu/gorm"
+)
+
+type columnsKey struct{}
+
+// WithColumns returns a copy of the context restricting the updates of the stores to the given columns. The columns
+// are written even when their values are zero, so that callers editing a part of an entity can clear them, while
+// the other columns keep their values.
+func WithColumns(ctx context.Context, columns ...string) context.Context {
+	return context.WithValue(ctx, columnsKey{}, columns)
+}
+
+// updateRow writes the entity to the row of the model with the given id: the columns chosen by the context, or the
+// fields of the entity which are not zero. The relations are left to the caller, gorm would save them to the model.
+func updateRow(ctx context.Context, tx *gorm.DB, model interface{}, id uint, entity interface{}) error {
+	query := tx.Model(model).Set("gorm:save_associations", false).Where("id = ?", id)
+
+	columns, ok := ctx.Value(columnsKey{}).([]string)
+	if !ok {
+		return query.Update(entity).Error
+	}
+	values := map[string]interface{}{}
+	for _, field := range tx.NewScope(entity).Fields() {
+		for _, column := range columns {
+			if field.DBName == column {
+				values[column] = field.Field.Interface()
+			}
+		}
+	}
+	return query.Updates(values).Error
+}
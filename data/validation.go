@@ -0,0 +1,202 @@
+package data
+
+import (
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"math"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// FieldError describes a single validation rule that a field of an entity failed
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+	Offset  int64  `json:"offset,omitempty"`
+}
+
+// ValidationError is returned by the stores when an entity does not pass validation
+type ValidationError struct {
+	Entity string
+	Errors []FieldError
+}
+
+func (e ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		if fe.Field == "" {
+			msgs = append(msgs, fe.Message)
+		} else {
+			msgs = append(msgs, fe.Field+" "+fe.Message)
+		}
+	}
+	return e.Entity + " is invalid: " + strings.Join(msgs, "; ")
+}
+
+// IsValid reports whether the level is one of the known TalkLevel constants
+func (l TalkLevel) IsValid() bool {
+	switch l {
+	case BeginnerLevel, AdvancedLevel, ExpertLevel:
+		return true
+	}
+	return false
+}
+
+// IsValid reports whether the format is one of the known TalkFormat constants
+func (f TalkFormat) IsValid() bool {
+	switch f {
+	case KeynoteFormat, RegularFormat, WorkshopFormat, PanelFormat, LightningFormat:
+		return true
+	}
+	return false
+}
+
+// countryCode matches ISO 3166-1 alpha-2 codes like "RS", the codes themselves are not checked against the standard
+var countryCode = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// newValidator creates a validator which reports fields by their json names and knows the custom rules of the model
+func newValidator() *validator.Validate {
+	v := validator.New()
+
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return lowerFirst(f.Name)
+		}
+		return name
+	})
+
+	_ = v.RegisterValidation("notblank", func(fl validator.FieldLevel) bool {
+		return strings.TrimSpace(fl.Field().String()) != ""
+	})
+	_ = v.RegisterValidation("talklevel", func(fl validator.FieldLevel) bool {
+		return TalkLevel(fl.Field().String()).IsValid()
+	})
+	_ = v.RegisterValidation("talkformat", func(fl validator.FieldLevel) bool {
+		return TalkFormat(fl.Field().String()).IsValid()
+	})
+	_ = v.RegisterValidation("timezone", func(fl validator.FieldLevel) bool {
+		_, err := LoadTimezone(fl.Field().String())
+		return err == nil
+	})
+	_ = v.RegisterValidation("country", func(fl validator.FieldLevel) bool {
+		return countryCode.MatchString(fl.Field().String())
+	})
+	// the baked in rules panic on the nil coordinates which required_with lets through to them
+	_ = v.RegisterValidation("latitude", coordinateWithin(90), true)
+	_ = v.RegisterValidation("longitude", coordinateWithin(180), true)
+	_ = v.RegisterValidation("rrule", func(fl validator.FieldLevel) bool {
+		_, err := ParseRecurrence(fl.Field().String())
+		return err == nil
+	})
+
+	return v
+}
+
+// coordinateWithin accepts a missing coordinate, or one of at most limit degrees either way
+func coordinateWithin(limit float64) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		field := fl.Field()
+		if field.Kind() == reflect.Ptr {
+			if field.IsNil() {
+				return true
+			}
+			field = field.Elem()
+		}
+		return math.Abs(field.Float()) <= limit
+	}
+}
+
+// validateStruct validates the entity and translates the failures into a *ValidationError
+func validateStruct(v *validator.Validate, entity string, s interface{}) error {
+	err := v.Struct(s)
+	if err == nil {
+		return nil
+	}
+
+	validationErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return err
+	}
+
+	verr := &ValidationError{Entity: entity}
+	for _, fe := range validationErrors {
+		verr.Errors = append(verr.Errors, FieldError{
+			Field:   fieldPath(fe.Namespace()),
+			Rule:    fe.Tag(),
+			Message: ruleMessage(fe),
+		})
+	}
+	return verr
+}
+
+// requireReference fails with a *ValidationError when the entity refers to no entity through field, for the
+// references the database requires. An id of 0 is a reference which was not given.
+func requireReference(entity string, field string, id uint) error {
+	if id != 0 {
+		return nil
+	}
+	return &ValidationError{Entity: entity, Errors: []FieldError{{Field: field, Rule: "required", Message: "is required"}}}
+}
+
+// fieldPath strips the root struct name from a validator namespace, e.g. "Talk.title" -> "title"
+func fieldPath(namespace string) string {
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+func ruleMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "notblank":
+		return "must not be blank"
+	case "gt":
+		return "must be greater than " + fe.Param()
+	case "required_with":
+		return "is required along with " + lowerFirst(fe.Param())
+	case "gtfield":
+		return "must be after " + lowerFirst(fe.Param())
+	case "max":
+		if fe.Kind() == reflect.Map || fe.Kind() == reflect.Slice {
+			return "must have at most " + fe.Param() + " entries"
+		}
+		return "must be at most " + fe.Param() + " characters long"
+	case "url":
+		return "must be a URL"
+	case "latitude":
+		return "must be a latitude between -90 and 90"
+	case "longitude":
+		return "must be a longitude between -180 and 180"
+	case "timezone":
+		return "must be an IANA time zone like Europe/Belgrade"
+	case "country":
+		return "must be an ISO 3166-1 alpha-2 country code like RS"
+	case "rrule":
+		return "must be a recurrence rule like FREQ=DAILY;COUNT=3, repeating DAILY or WEEKLY with an optional INTERVAL, BYDAY, and COUNT or UNTIL"
+	case "datetime":
+		return "must be formatted like " + fe.Param()
+	case "hexcolor":
+		return "must be a hex color like #1e90ff"
+	case "talklevel":
+		return fmt.Sprintf("must be one of [%s, %s, %s]", BeginnerLevel, AdvancedLevel, ExpertLevel)
+	case "talkformat":
+		return fmt.Sprintf("must be one of [%s, %s, %s, %s, %s]", KeynoteFormat, RegularFormat, WorkshopFormat, PanelFormat, LightningFormat)
+	default:
+		return fmt.Sprintf("failed on the '%s' rule", fe.Tag())
+	}
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
@@ -0,0 +1,255 @@
+package database
+
+import (
+	"fmt"
+	"github.com/jinzhu/gorm"
+	"strings"
+)
+
+// foreignKey describes a column referencing the id of another table
+type foreignKey struct {
+	table    string
+	column   string
+	refTable string
+	// onDelete is either RESTRICT, or CASCADE for join table rows owned by the referenced row
+	onDelete string
+}
+
+var foreignKeys = []foreignKey{
+	{"event", "location_id", "location", "RESTRICT"},
+	{"event", "organization_id", "organization", "RESTRICT"},
+	{"person", "organization_id", "organization", "RESTRICT"},
+	{"room", "organization_id", "organization", "RESTRICT"},
+	{"room", "location_id", "location", "RESTRICT"},
+	{"talk", "organization_id", "organization", "RESTRICT"},
+	{"talk", "track_id", "track", "RESTRICT"},
+	{"track", "event_id", "event", "RESTRICT"},
+	{"talk_date", "talk_id", "talk", "RESTRICT"},
+	{"talk_date", "room_id", "room", "RESTRICT"},
+	{"talk_date", "event_id", "event", "RESTRICT"},
+	{"talk_date", "location_id", "location", "RESTRICT"},
+	{"talk_date", "series_id", "talk_date", "RESTRICT"},
+	{"talks_at", "talk_id", "talk", "CASCADE"},
+	{"talks_at", "person_id", "person", "RESTRICT"},
+	{"talk_topic", "talk_id", "talk", "CASCADE"},
+	{"talk_topic", "topic_id", "topic", "RESTRICT"},
+	{"is_child_of", "topic_id", "topic", "CASCADE"},
+	{"is_child_of", "child_topic_id", "topic", "RESTRICT"},
+}
+
+var joinTables = map[string]bool{"talks_at": true, "talk_topic": true, "is_child_of": true}
+
+// optionalReferences are the columns which may refer to nothing, they used to be stored as 0 instead of NULL
+var optionalReferences = map[string]bool{
+	"event.location_id":     true,
+	"talk_date.room_id":     true,
+	"talk_date.event_id":    true,
+	"talk_date.location_id": true,
+}
+
+// addForeignKeys enforces the references between the tables in the database itself.
+// mysql gets real foreign key constraints; sqlite cannot add constraints to existing tables,
+// so they are emulated with triggers behaving the same way.
+func addForeignKeys(db *gorm.DB) error {
+	for _, fk := range foreignKeys {
+		if joinTables[fk.table] {
+			// join table rows used to be left behind when the entities they link were deleted
+			if err := removeDanglingRows(db, fk); err != nil {
+				return err
+			}
+		}
+		if optionalReferences[fk.table+"."+fk.column] {
+			query := fmt.Sprintf("UPDATE %s SET %s = NULL WHERE %s = 0", fk.table, fk.column, fk.column)
+			if err := db.Exec(query).Error; err != nil {
+				return fmt.Errorf("error clearing the empty references of %s.%s: %w", fk.table, fk.column, err)
+			}
+		}
+
+		switch db.Dialect().GetName() {
+		case "mysql":
+			if err := db.Table(fk.table).AddForeignKey(fk.column, fk.refTable+"(id)", fk.onDelete, "RESTRICT").Error; err != nil {
+				return fmt.Errorf("error adding foreign key %s.%s: %w", fk.table, fk.column, err)
+			}
+		case "sqlite3":
+			for _, trigger := range sqliteForeignKeyTriggers(fk) {
+				if err := db.Exec(trigger).Error; err != nil {
+					return fmt.Errorf("error adding foreign key trigger %s.%s: %w", fk.table, fk.column, err)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func removeDanglingRows(db *gorm.DB, fk foreignKey) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE %s NOT IN (SELECT id FROM %s)", fk.table, fk.column, fk.refTable)
+	if err := db.Exec(query).Error; err != nil {
+		return fmt.Errorf("error removing dangling rows of %s: %w", fk.table, err)
+	}
+	return nil
+}
+
+func sqliteForeignKeyTriggers(fk foreignKey) []string {
+	name := fmt.Sprintf("fk_%s_%s_%s", fk.table, fk.column, fk.refTable)
+	// like real foreign keys, NULL refers to nothing and is always allowed
+	missingRef := fmt.Sprintf("NEW.%s IS NOT NULL AND (SELECT id FROM %s WHERE id = NEW.%s) IS NULL", fk.column, fk.refTable, fk.column)
+	abort := "SELECT RAISE(ABORT, 'FOREIGN KEY constraint failed');"
+
+	triggers := []string{
+		// the triggers are recreated, so that databases created by older versions get the current definition
+		fmt.Sprintf("DROP TRIGGER IF EXISTS %s_insert;", name),
+		fmt.Sprintf("DROP TRIGGER IF EXISTS %s_update;", name),
+		fmt.Sprintf("DROP TRIGGER IF EXISTS %s_delete;", name),
+		fmt.Sprintf("CREATE TRIGGER IF NOT EXISTS %s_insert BEFORE INSERT ON %s FOR EACH ROW WHEN %s BEGIN %s END;",
+			name, fk.table, missingRef, abort),
+		fmt.Sprintf("CREATE TRIGGER IF NOT EXISTS %s_update BEFORE UPDATE OF %s ON %s FOR EACH ROW WHEN %s BEGIN %s END;",
+			name, fk.column, fk.table, missingRef, abort),
+	}
+
+	if fk.onDelete == "CASCADE" {
+		triggers = append(triggers, fmt.Sprintf("CREATE TRIGGER IF NOT EXISTS %s_delete AFTER DELETE ON %s FOR EACH ROW BEGIN DELETE FROM %s WHERE %s = OLD.id; END;",
+			name, fk.refTable, fk.table, fk.column))
+	} else {
+		triggers = append(triggers, fmt.Sprintf("CREATE TRIGGER IF NOT EXISTS %s_delete BEFORE DELETE ON %s FOR EACH ROW WHEN (SELECT 1 FROM %s WHERE %s = OLD.id LIMIT 1) IS NOT NULL BEGIN %s END;",
+			name, fk.refTable, fk.table, fk.column, abort))
+	}
+
+	return triggers
+}
+
+// uniqueIndex makes the values of a column unique among the rows which are not in the trash, so that entities
+// can be created again with the values of deleted ones. With a scope, the values are unique among the rows with
+// the same value of the scope column only.
+type uniqueIndex struct {
+	table  string
+	column string
+	scope  string
+}
+
+var uniqueIndexes = []uniqueIndex{
+	// locations are shared by all organizations and changed by platform admins only
+	{"location", "name", ""},
+	{"organization", "name", ""},
+	// per organization, so the conflicts tell nothing about the persons of other organizations
+	{"person", "name", "organization_id"},
+	{"person", "subject", ""},
+}
+
+// legacyUniqueIndexes are the mysql indexes which made values unique among all rows, including those in the trash.
+// On sqlite, the UNIQUE constraints of the columns cannot be dropped, the tables are rebuilt without them; the
+// index of the subjects is named like the one replacing it.
+var legacyUniqueIndexes = map[string][]string{
+	"location":     {"name"},
+	"organization": {"name"},
+	"person":       {"name", "uix_person_subject"},
+}
+
+// addUniqueIndexes replaces the unique indexes over all rows by indexes over the rows which are not in the trash:
+// partial indexes on sqlite, functional indexes on mysql, which does not index NULL values either.
+// Like the foreign key triggers, the indexes are recreated, so that databases get the current definition.
+func addUniqueIndexes(db *gorm.DB) error {
+	for table, names := range legacyUniqueIndexes {
+		switch db.Dialect().GetName() {
+		case "mysql":
+			for _, name := range names {
+				if db.Dialect().HasIndex(table, name) {
+					if err := db.Table(table).RemoveIndex(name).Error; err != nil {
+						return fmt.Errorf("error removing unique index %s of %s: %w", name, table, err)
+					}
+				}
+			}
+		case "sqlite3":
+			if err := rebuildWithoutUnique(db, table); err != nil {
+				return fmt.Errorf("error removing the unique constraints of %s: %w", table, err)
+			}
+		}
+	}
+
+	for _, index := range uniqueIndexes {
+		var drop, create string
+		switch db.Dialect().GetName() {
+		case "mysql":
+			// index names are scoped to the table, the duplicate entry errors name the column
+			name := "uix_" + index.column
+			if db.Dialect().HasIndex(index.table, name) {
+				drop = fmt.Sprintf("DROP INDEX %s ON %s", name, index.table)
+			}
+			parts := fmt.Sprintf("(IF(deleted_at IS NULL, %s, NULL))", index.column)
+			if index.scope != "" {
+				parts += ", " + index.scope
+			}
+			create = fmt.Sprintf("CREATE UNIQUE INDEX %s ON %s (%s)", name, index.table, parts)
+		case "sqlite3":
+			// the column comes last, the constraint errors name the last column
+			name := "uix_" + index.table + "_" + index.column
+			columns := index.column
+			if index.scope != "" {
+				columns = index.scope + ", " + columns
+			}
+			drop = "DROP INDEX IF EXISTS " + name
+			create = fmt.Sprintf("CREATE UNIQUE INDEX %s ON %s(%s) WHERE deleted_at IS NULL", name, index.table, columns)
+		default:
+			continue
+		}
+		if drop != "" {
+			if err := db.Exec(drop).Error; err != nil {
+				return fmt.Errorf("error removing unique index of %s.%s: %w", index.table, index.column, err)
+			}
+		}
+		if err := db.Exec(create).Error; err != nil {
+			return fmt.Errorf("error adding unique index of %s.%s: %w", index.table, index.column, err)
+		}
+	}
+
+	return nil
+}
+
+// rebuildWithoutUnique recreates the sqlite table without the UNIQUE constraints of its columns, if it has any,
+// keeping its rows and indexes. The foreign key triggers are dropped, addForeignKeys creates them again.
+func rebuildWithoutUnique(db *gorm.DB, table string) error {
+	var constraints int
+	if err := db.Table("sqlite_master").Where("type = 'index' AND tbl_name = ? AND name LIKE 'sqlite_autoindex_%'", table).Count(&constraints).Error; err != nil {
+		return err
+	}
+	if constraints == 0 {
+		return nil
+	}
+
+	var create string
+	if err := db.Raw("SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?", table).Row().Scan(&create); err != nil {
+		return err
+	}
+	var indexes, triggers []string
+	if err := db.Table("sqlite_master").Where("type = 'index' AND tbl_name = ? AND sql IS NOT NULL", table).Pluck("sql", &indexes).Error; err != nil {
+		return err
+	}
+	if err := db.Table("sqlite_master").Where("type = 'trigger' AND name LIKE 'fk_%'").Pluck("name", &triggers).Error; err != nil {
+		return err
+	}
+
+	rebuilt := table + "_rebuilt"
+	create = strings.Replace(create, `CREATE TABLE "`+table+`"`, `CREATE TABLE "`+rebuilt+`"`, 1)
+	create = strings.ReplaceAll(create, " UNIQUE", "")
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		statements := []string{}
+		for _, trigger := range triggers {
+			// the triggers refer to the tables by name, renaming the rebuilt table would fail on them
+			statements = append(statements, "DROP TRIGGER IF EXISTS "+trigger)
+		}
+		statements = append(statements,
+			create,
+			fmt.Sprintf("INSERT INTO %s SELECT * FROM %s", rebuilt, table),
+			"DROP TABLE "+table,
+			fmt.Sprintf("ALTER TABLE %s RENAME TO %s", rebuilt, table),
+		)
+		statements = append(statements, indexes...)
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
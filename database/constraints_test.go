@@ -0,0 +1,72 @@
+package database
+
+import (
+	"github.com/jinzhu/gorm"
+	"strings"
+	"testing"
+)
+
+func TestAddUniqueIndexes(t *testing.T) {
+	db, err := gorm.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.DB().SetMaxOpenConns(1)
+	db.SingularTable(true)
+
+	// the location table as created before the trash, with a deleted and a live location
+	for _, statement := range []string{
+		`CREATE TABLE "location" ("id" integer primary key autoincrement,"name" varchar(255) NOT NULL UNIQUE DEFAULT '',"deleted_at" datetime )`,
+		`CREATE INDEX idx_location_deleted_at ON "location"(deleted_at)`,
+		`INSERT INTO location (id, name, deleted_at) VALUES (1, 'Belgrade', '2021-05-01 10:00:00+00:00'), (2, 'Novi Sad', NULL)`,
+	} {
+		if err := db.Exec(statement).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	autoMigrate(db)
+
+	// twice, as on every start
+	for i := 0; i < 2; i++ {
+		if err := addUniqueIndexes(db); err != nil {
+			t.Fatal(err)
+		}
+		if err := addForeignKeys(db); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var count int
+	db.Table("location").Count(&count)
+	if count != 2 {
+		t.Errorf("%d locations after the migration, want 2", count)
+	}
+	var indexes []string
+	db.Table("sqlite_master").Where("type = 'index' AND tbl_name = 'location'").Order("name").Pluck("name", &indexes)
+	if len(indexes) != 2 || indexes[0] != "idx_location_deleted_at" || indexes[1] != "uix_location_name" {
+		t.Errorf("indexes of location = %v, want the one of the deletion time and the partial unique one", indexes)
+	}
+
+	if err := db.Exec("INSERT INTO location (name) VALUES ('Belgrade')").Error; err != nil {
+		t.Errorf("creating the location with the name of a deleted one failed: %v", err)
+	}
+	if err := db.Exec("INSERT INTO location (name) VALUES ('Novi Sad')").Error; err == nil {
+		t.Error("created a second location with the name of a live one")
+	}
+	if err := db.Exec("UPDATE location SET deleted_at = NULL WHERE id = 1").Error; err == nil {
+		t.Error("restored a location with the name of a live one")
+	}
+
+	// the names of persons are unique within their organization only
+	if err := db.Exec("INSERT INTO organization (id, name) VALUES (1, 'PAC'), (2, 'Other')").Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Exec("INSERT INTO person (name, organization_id) VALUES ('Ana', 1), ('Ana', 2)").Error; err != nil {
+		t.Errorf("creating persons with the same name in different organizations failed: %v", err)
+	}
+	err = db.Exec("INSERT INTO person (name, organization_id) VALUES ('Ana', 1)").Error
+	if err == nil || !strings.HasSuffix(err.Error(), "person.name") {
+		t.Errorf("creating a second person with the same name in the organization failed with %v, want the name reported", err)
+	}
+}
@@ -1,183 +1,240 @@
 package database
 
 import (
+	"context"
 	"github.com/hashicorp/go-hclog"
 	"github.com/jinzhu/gorm"
 	"github.com/milutindzunic/pac-backend/data"
 	"time"
 )
 
-func Init(db *gorm.DB, ls data.LocationStore, es data.EventStore, os data.OrganizationStore, ps data.PersonStore, rs data.RoomStore, ts data.TopicStore, tlks data.TalkStore, tlkds data.TalkDateStore, logger hclog.Logger) {
+func Init(ctx context.Context, db *gorm.DB, ls data.LocationStore, es data.EventStore, os data.OrganizationStore, ps data.PersonStore, rs data.RoomStore, ts data.TopicStore, trs data.TrackStore, tlks data.TalkStore, tlkds data.TalkDateStore, logger hclog.Logger) {
 
 	logger.Info("Dropping all Tables...")
-	// Drop the Entity tables
-	db.DropTableIfExists(data.Event{})
-	db.DropTableIfExists(data.Location{})
-	db.DropTableIfExists(data.Organization{})
-	db.DropTableIfExists(data.Person{})
-	db.DropTableIfExists(data.Room{})
-	db.DropTableIfExists(data.Talk{})
-	db.DropTableIfExists(data.Topic{})
-	db.DropTableIfExists(data.TalkDate{})
 	// Drop the junction tables
 	db.DropTableIfExists("is_child_of")
 	db.DropTableIfExists("talk_topic")
 	db.DropTableIfExists("talks_at")
+	// Drop the Entity tables, referencing tables first
+	db.DropTableIfExists(data.TalkDate{})
+	db.DropTableIfExists(data.Talk{})
+	db.DropTableIfExists(data.Track{})
+	db.DropTableIfExists(data.Topic{})
+	db.DropTableIfExists(data.Room{})
+	db.DropTableIfExists(data.Person{})
+	db.DropTableIfExists(data.Event{})
+	db.DropTableIfExists(data.Organization{})
+	db.DropTableIfExists(data.Location{})
 
 	logger.Info("Recreating all Tables...")
 	autoMigrate(db)
+	if err := addUniqueIndexes(db); err != nil {
+		logger.Error("Error adding unique indexes", "err", err)
+	}
+	if err := addForeignKeys(db); err != nil {
+		logger.Error("Error adding foreign keys", "err", err)
+	}
 
 	logger.Info("Initializing DB with initial data...")
+	// the times of the events are given in the time zone of their locations, and stored in UTC
+	belgrade, err := data.LoadTimezone("Europe/Belgrade")
+	if err != nil {
+		logger.Error("Error loading time zone of the initial data", "err", err)
+		belgrade = time.UTC
+	}
 	// Locations
-	locationBelexpo, _ := ls.AddLocation(&data.Location{
+	locationBelexpo, _ := ls.AddLocation(ctx, &data.Location{
+		ID:          1,
+		Name:        "Belexpo Centar",
+		Address:     data.Address{Street: "Milentija Popovića 9", PostalCode: "11070", City: "Belgrade", Country: "RS"},
+		Latitude:    coordinate(44.8127),
+		Longitude:   coordinate(20.4225),
+		Timezone:    "Europe/Belgrade",
+		Description: "Congress centre in New Belgrade, close to the Sava Centar.",
+	})
+	locationHotelPlaza, _ := ls.AddLocation(ctx, &data.Location{
+		ID:          2,
+		Name:        "Hotel Plaza",
+		Address:     data.Address{Street: "Kralja Petra I 12", PostalCode: "21000", City: "Novi Sad", Country: "RS"},
+		Latitude:    coordinate(45.2557),
+		Longitude:   coordinate(19.8452),
+		Timezone:    "Europe/Belgrade",
+		Description: "Hotel in the centre of Novi Sad with conference rooms on the first floor.",
+	})
+	locationBelgradeFair, _ := ls.AddLocation(ctx, &data.Location{
+		ID:          3,
+		Name:        "Belgrade Fair Building One",
+		Address:     data.Address{Street: "Bulevar vojvode Mišića 14", PostalCode: "11000", City: "Belgrade", Country: "RS"},
+		Latitude:    coordinate(44.7925),
+		Longitude:   coordinate(20.4375),
+		Timezone:    "Europe/Belgrade",
+		Description: "The main hall of the Belgrade Fair, under its large concrete dome.",
+	})
+
+	// Organizations
+	organizationProdyna, _ := os.AddOrganization(ctx, &data.Organization{
 		ID:   1,
-		Name: "Belexpo Centar",
+		Name: "Prodyna",
 	})
-	locationHotelPlaza, _ := ls.AddLocation(&data.Location{
+	// Organizations
+	organizationGoogle, _ := os.AddOrganization(ctx, &data.Organization{
 		ID:   2,
-		Name: "Hotel Plaza",
-	})
-	locationBelgradeFair, _ := ls.AddLocation(&data.Location{
-		ID:   3,
-		Name: "Belgrade Fair Building One",
+		Name: "Google",
 	})
 
 	// Events
-	eventBestJavaConference, _ := es.AddEvent(&data.Event{
-		ID:        1,
-		Name:      "Best Java Conference",
-		BeginDate: time.Date(2021, time.Month(5), 12, 0, 0, 0, 0, time.UTC),
-		EndDate:   time.Date(2021, time.Month(5), 14, 0, 0, 0, 0, time.UTC),
-		Location:  locationBelexpo,
+	eventBestJavaConference, _ := es.AddEvent(ctx, &data.Event{
+		ID:           1,
+		Name:         "Best Java Conference",
+		BeginDate:    time.Date(2021, time.Month(5), 12, 0, 0, 0, 0, belgrade),
+		EndDate:      time.Date(2021, time.Month(5), 14, 0, 0, 0, 0, belgrade),
+		Location:     locationBelexpo,
+		Organization: organizationProdyna,
 	})
-	eventProdynaJobFair, _ := es.AddEvent(&data.Event{
-		ID:        2,
-		Name:      "Prodyna Job Fair",
-		BeginDate: time.Date(2021, time.Month(5), 2, 0, 0, 0, 0, time.UTC),
-		EndDate:   time.Date(2021, time.Month(5), 5, 0, 0, 0, 0, time.UTC),
-		Location:  locationHotelPlaza,
+	eventProdynaJobFair, _ := es.AddEvent(ctx, &data.Event{
+		ID:           2,
+		Name:         "Prodyna Job Fair",
+		BeginDate:    time.Date(2021, time.Month(5), 2, 0, 0, 0, 0, belgrade),
+		EndDate:      time.Date(2021, time.Month(5), 5, 0, 0, 0, 0, belgrade),
+		Location:     locationHotelPlaza,
+		Organization: organizationProdyna,
 	})
-	eventITConnect, _ := es.AddEvent(&data.Event{
-		ID:        3,
-		Name:      "IT Connect",
-		BeginDate: time.Date(2021, time.Month(5), 10, 0, 0, 0, 0, time.UTC),
-		EndDate:   time.Date(2021, time.Month(5), 12, 0, 0, 0, 0, time.UTC),
-		Location:  locationHotelPlaza,
+	eventITConnect, _ := es.AddEvent(ctx, &data.Event{
+		ID:           3,
+		Name:         "IT Connect",
+		BeginDate:    time.Date(2021, time.Month(5), 10, 0, 0, 0, 0, belgrade),
+		EndDate:      time.Date(2021, time.Month(5), 12, 0, 0, 0, 0, belgrade),
+		Location:     locationHotelPlaza,
+		Organization: organizationProdyna,
 	})
-	/*eventCloudnativeConference*/ _, _ = es.AddEvent(&data.Event{
-		ID:        4,
-		Name:      "Cloud Native Conference",
-		BeginDate: time.Date(2021, time.Month(5), 22, 0, 0, 0, 0, time.UTC),
-		EndDate:   time.Date(2021, time.Month(5), 23, 0, 0, 0, 0, time.UTC),
-		Location:  locationBelgradeFair,
+	/*eventCloudnativeConference*/ _, _ = es.AddEvent(ctx, &data.Event{
+		ID:           4,
+		Name:         "Cloud Native Conference",
+		BeginDate:    time.Date(2021, time.Month(5), 22, 0, 0, 0, 0, belgrade),
+		EndDate:      time.Date(2021, time.Month(5), 23, 0, 0, 0, 0, belgrade),
+		Location:     locationBelgradeFair,
+		Organization: organizationProdyna,
 	})
-	eventGoogleIO, _ := es.AddEvent(&data.Event{
-		ID:        5,
-		Name:      "Google I/O",
-		BeginDate: time.Date(2021, time.Month(6), 2, 0, 0, 0, 0, time.UTC),
-		EndDate:   time.Date(2021, time.Month(6), 5, 0, 0, 0, 0, time.UTC),
-		Location:  locationBelgradeFair,
+	eventGoogleIO, _ := es.AddEvent(ctx, &data.Event{
+		ID:           5,
+		Name:         "Google I/O",
+		BeginDate:    time.Date(2021, time.Month(6), 2, 0, 0, 0, 0, belgrade),
+		EndDate:      time.Date(2021, time.Month(6), 5, 0, 0, 0, 0, belgrade),
+		Location:     locationBelgradeFair,
+		Organization: organizationGoogle,
 	})
 
-	// Organizations
-	organizationProdyna, _ := os.AddOrganization(&data.Organization{
-		ID:   1,
-		Name: "Prodyna",
-	})
-	// Organizations
-	organizationGoogle, _ := os.AddOrganization(&data.Organization{
-		ID:   2,
-		Name: "Google",
+	// Tracks
+	trackJava, _ := trs.AddTrack(ctx, &data.Track{
+		ID:    1,
+		Name:  "Java",
+		Color: "#e76f00",
+		Event: eventBestJavaConference,
+	})
+	trackCareers, _ := trs.AddTrack(ctx, &data.Track{
+		ID:    2,
+		Name:  "Careers",
+		Color: "#2a9d8f",
+		Event: eventProdynaJobFair,
 	})
 
 	// Rooms
-	roomRed, _ := rs.AddRoom(&data.Room{
+	roomRed, _ := rs.AddRoom(ctx, &data.Room{
 		ID:           1,
 		Name:         "Red Room",
 		Organization: organizationProdyna,
 	})
-	roomWhite, _ := rs.AddRoom(&data.Room{
+	roomWhite, _ := rs.AddRoom(ctx, &data.Room{
 		ID:           2,
 		Name:         "White Room",
 		Organization: organizationProdyna,
+		Location:     locationHotelPlaza,
 	})
-	roomBlue, _ := rs.AddRoom(&data.Room{
+	roomBlue, _ := rs.AddRoom(ctx, &data.Room{
 		ID:           3,
 		Name:         "Blue Room",
 		Organization: organizationProdyna,
+		Location:     locationHotelPlaza,
 	})
-	roomGoogle, _ := rs.AddRoom(&data.Room{
+	roomGoogle, _ := rs.AddRoom(ctx, &data.Room{
 		ID:           4,
 		Name:         "Google Room",
 		Organization: organizationGoogle,
+		Location:     locationBelgradeFair,
 	})
 
 	// Topics
-	topicJava, _ := ts.AddTopic(&data.Topic{
+	topicJava, _ := ts.AddTopic(ctx, &data.Topic{
 		ID:       1,
 		Name:     "Java",
 		Children: nil,
 	})
-	topicHibernate, _ := ts.AddTopic(&data.Topic{
+	topicHibernate, _ := ts.AddTopic(ctx, &data.Topic{
 		ID:       2,
 		Name:     "Hibernate",
 		Children: []data.Topic{*topicJava},
 	})
-	topicSpring, _ := ts.AddTopic(&data.Topic{
+	topicSpring, _ := ts.AddTopic(ctx, &data.Topic{
 		ID:       3,
 		Name:     "Spring",
 		Children: []data.Topic{*topicJava, *topicHibernate},
 	})
-	topicKubernetes, _ := ts.AddTopic(&data.Topic{
+	topicKubernetes, _ := ts.AddTopic(ctx, &data.Topic{
 		ID:       4,
 		Name:     "Kubernetes",
 		Children: nil,
 	})
-	topicJavaScript, _ := ts.AddTopic(&data.Topic{
+	topicJavaScript, _ := ts.AddTopic(ctx, &data.Topic{
 		ID:       5,
 		Name:     "JavaScript",
 		Children: nil,
 	})
-	topicJobMarket, _ := ts.AddTopic(&data.Topic{
+	topicJobMarket, _ := ts.AddTopic(ctx, &data.Topic{
 		ID:       6,
 		Name:     "Job Market",
 		Children: nil,
 	})
 
 	// Persons
-	speakerDKrizic, _ := ps.AddPerson(&data.Person{
+	speakerDKrizic, _ := ps.AddPerson(ctx, &data.Person{
 		ID:           1,
 		Name:         "Darko Krizic",
 		Organization: organizationProdyna,
 	})
-	speakerGGrujic, _ := ps.AddPerson(&data.Person{
+	speakerGGrujic, _ := ps.AddPerson(ctx, &data.Person{
 		ID:           2,
 		Name:         "Goran Grujic",
 		Organization: organizationProdyna,
 	})
-	speakerMNikolic, _ := ps.AddPerson(&data.Person{
+	speakerMNikolic, _ := ps.AddPerson(ctx, &data.Person{
 		ID:           3,
 		Name:         "Milos Nikolic",
 		Organization: organizationProdyna,
 	})
-	speakerAKoblin, _ := ps.AddPerson(&data.Person{
+	speakerAKoblin, _ := ps.AddPerson(ctx, &data.Person{
 		ID:           4,
 		Name:         "Aaron Koblin",
 		Organization: organizationGoogle,
 	})
 
 	// Talks
-	talkJavaSpringAndYou, _ := tlks.AddTalk(&data.Talk{
+	talkJavaSpringAndYou, _ := tlks.AddTalk(ctx, &data.Talk{
 		ID:                1,
 		Title:             "Java, Spring, and You",
 		DurationInMinutes: 90,
 		Language:          "English",
 		Level:             data.BeginnerLevel,
+		Format:            data.WorkshopFormat,
+		Abstract:          "A hands-on introduction to building **REST services** with Spring Boot.",
+		Prerequisites:     "A laptop with JDK 11 and an IDE of your choice.",
+		RepositoryURL:     "https://github.com/spring-guides/gs-rest-service",
+		Track:             trackJava,
 		Persons:           []data.Person{*speakerDKrizic, *speakerGGrujic},
 		Topics:            []data.Topic{*topicJava, *topicSpring, *topicHibernate},
 		TalkDates:         nil,
+		Organization:      organizationProdyna,
 	})
-	talkFullStackJavaScriptOnKubernetes, _ := tlks.AddTalk(&data.Talk{
+	talkFullStackJavaScriptOnKubernetes, _ := tlks.AddTalk(ctx, &data.Talk{
 		ID:                2,
 		Title:             "Fullstack JavaScript on Kubernetes",
 		DurationInMinutes: 60,
@@ -186,8 +243,9 @@ func Init(db *gorm.DB, ls data.LocationStore, es data.EventStore, os data.Organi
 		Persons:           []data.Person{*speakerMNikolic},
 		Topics:            []data.Topic{*topicJavaScript, *topicKubernetes},
 		TalkDates:         nil,
+		Organization:      organizationProdyna,
 	})
-	talkJavaForBeginners, _ := tlks.AddTalk(&data.Talk{
+	talkJavaForBeginners, _ := tlks.AddTalk(ctx, &data.Talk{
 		ID:                3,
 		Title:             "Java for Beginners",
 		DurationInMinutes: 60,
@@ -196,81 +254,101 @@ func Init(db *gorm.DB, ls data.LocationStore, es data.EventStore, os data.Organi
 		Persons:           []data.Person{*speakerGGrujic},
 		Topics:            []data.Topic{*topicJava},
 		TalkDates:         nil,
+		Organization:      organizationProdyna,
 	})
-	talkITJobMarketToday, _ := tlks.AddTalk(&data.Talk{
+	talkITJobMarketToday, _ := tlks.AddTalk(ctx, &data.Talk{
 		ID:                4,
 		Title:             "The IT Job Market Today",
 		DurationInMinutes: 60,
 		Language:          "English",
 		Level:             data.BeginnerLevel,
+		Format:            data.KeynoteFormat,
+		Abstract:          "What companies look for in developers today, and how to get there.",
+		Track:             trackCareers,
 		Persons:           []data.Person{*speakerAKoblin},
 		Topics:            []data.Topic{*topicJava, *topicJobMarket},
 		TalkDates:         nil,
+		Organization:      organizationProdyna,
 	})
 
 	// TalkDates
-	_, _ = tlkds.AddTalkDate(&data.TalkDate{
+	_, _ = tlkds.AddTalkDate(ctx, &data.TalkDate{
 		ID:        1,
-		BeginDate: time.Date(2021, time.Month(5), 12, 14, 0, 0, 0, time.UTC),
+		BeginDate: time.Date(2021, time.Month(5), 12, 14, 0, 0, 0, belgrade),
 		Talk:      talkJavaSpringAndYou,
 		Room:      roomRed,
 		Event:     eventBestJavaConference,
 		Location:  locationBelexpo,
 	})
-	_, _ = tlkds.AddTalkDate(&data.TalkDate{
+	_, _ = tlkds.AddTalkDate(ctx, &data.TalkDate{
 		ID:        2,
-		BeginDate: time.Date(2021, time.Month(5), 2, 10, 0, 0, 0, time.UTC),
+		BeginDate: time.Date(2021, time.Month(5), 2, 10, 0, 0, 0, belgrade),
 		Talk:      talkFullStackJavaScriptOnKubernetes,
 		Room:      roomWhite,
 		Event:     eventProdynaJobFair,
 		Location:  locationHotelPlaza,
 	})
-	_, _ = tlkds.AddTalkDate(&data.TalkDate{
+	_, _ = tlkds.AddTalkDate(ctx, &data.TalkDate{
 		ID:        3,
-		BeginDate: time.Date(2021, time.Month(5), 2, 12, 0, 0, 0, time.UTC),
+		BeginDate: time.Date(2021, time.Month(5), 2, 12, 0, 0, 0, belgrade),
 		Talk:      talkJavaForBeginners,
 		Room:      roomWhite,
 		Event:     eventProdynaJobFair,
 		Location:  locationHotelPlaza,
 	})
-	_, _ = tlkds.AddTalkDate(&data.TalkDate{
+	_, _ = tlkds.AddTalkDate(ctx, &data.TalkDate{
 		ID:        4,
-		BeginDate: time.Date(2021, time.Month(5), 2, 13, 0, 0, 0, time.UTC),
+		BeginDate: time.Date(2021, time.Month(5), 2, 13, 0, 0, 0, belgrade),
 		Talk:      talkITJobMarketToday,
 		Room:      roomRed,
 		Event:     eventProdynaJobFair,
 		Location:  locationHotelPlaza,
 	})
-	_, _ = tlkds.AddTalkDate(&data.TalkDate{
+	_, _ = tlkds.AddTalkDate(ctx, &data.TalkDate{
 		ID:        5,
-		BeginDate: time.Date(2021, time.Month(5), 3, 8, 0, 0, 0, time.UTC),
+		BeginDate: time.Date(2021, time.Month(5), 3, 8, 0, 0, 0, belgrade),
 		Talk:      talkFullStackJavaScriptOnKubernetes,
 		Room:      roomWhite,
 		Event:     eventProdynaJobFair,
 		Location:  locationHotelPlaza,
 	})
-	_, _ = tlkds.AddTalkDate(&data.TalkDate{
+	_, _ = tlkds.AddTalkDate(ctx, &data.TalkDate{
 		ID:        6,
-		BeginDate: time.Date(2021, time.Month(5), 10, 14, 0, 0, 0, time.UTC),
+		BeginDate: time.Date(2021, time.Month(5), 10, 14, 0, 0, 0, belgrade),
 		Talk:      talkJavaForBeginners,
 		Room:      roomBlue,
 		Event:     eventITConnect,
 		Location:  locationHotelPlaza,
 	})
-	_, _ = tlkds.AddTalkDate(&data.TalkDate{
+	_, _ = tlkds.AddTalkDate(ctx, &data.TalkDate{
 		ID:        7,
-		BeginDate: time.Date(2021, time.Month(6), 2, 15, 0, 0, 0, time.UTC),
+		BeginDate: time.Date(2021, time.Month(6), 2, 15, 0, 0, 0, belgrade),
 		Talk:      talkITJobMarketToday,
 		Room:      roomGoogle,
 		Event:     eventGoogleIO,
 		Location:  locationBelgradeFair,
 	})
-	_, _ = tlkds.AddTalkDate(&data.TalkDate{
+	_, _ = tlkds.AddTalkDate(ctx, &data.TalkDate{
 		ID:        8,
-		BeginDate: time.Date(2021, time.Month(5), 10, 12, 0, 0, 0, time.UTC),
+		BeginDate: time.Date(2021, time.Month(5), 10, 12, 0, 0, 0, belgrade),
 		Talk:      talkITJobMarketToday,
 		Room:      roomBlue,
 		Event:     eventITConnect,
 		Location:  locationHotelPlaza,
 	})
+	// the workshop is repeated every morning of the job fair
+	_, _ = tlkds.AddTalkDate(ctx, &data.TalkDate{
+		ID:         9,
+		BeginDate:  time.Date(2021, time.Month(5), 2, 9, 0, 0, 0, belgrade),
+		Talk:       talkJavaSpringAndYou,
+		Room:       roomBlue,
+		Event:      eventProdynaJobFair,
+		Location:   locationHotelPlaza,
+		Recurrence: "FREQ=DAILY",
+	})
+}
+
+// coordinate returns a pointer to the degrees, for the optional coordinates of the locations
+func coordinate(degrees float64) *float64 {
+	return &degrees
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/milutindzunic/pac-backend/config"
 	"github.com/milutindzunic/pac-backend/data"
 	"log"
+	"time"
 )
 
 func OpenDB(cnf *config.Config) (*gorm.DB, error) {
@@ -18,7 +19,7 @@ func OpenDB(cnf *config.Config) (*gorm.DB, error) {
 		dbUrl = cnf.DbName
 		log.Println("Connecting to embedded sqlite3 database... file name: " + dbUrl)
 	case "mysql":
-		dbUrl = fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8&parseTime=True&loc=Local", cnf.DbUser, cnf.DbPassword, cnf.DbHost, cnf.DbPort, cnf.DbName)
+		dbUrl = fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8&parseTime=True&loc=UTC", cnf.DbUser, cnf.DbPassword, cnf.DbHost, cnf.DbPort, cnf.DbName)
 		log.Println("Connecting to mysql database... uri: " + dbUrl)
 	default:
 		return nil, fmt.Errorf("error! Database driver must be one of: [sqlite3, mysql], was %s", cnf.DbDriver)
@@ -34,20 +35,81 @@ func OpenDB(cnf *config.Config) (*gorm.DB, error) {
 
 	// Keep the schema up to date
 	db = autoMigrate(db)
+	if err := addUniqueIndexes(db); err != nil {
+		return nil, err
+	}
+	if err := addForeignKeys(db); err != nil {
+		return nil, err
+	}
+	if err := normalizeTimes(db); err != nil {
+		return nil, err
+	}
 
 	return db, nil
 }
 
+// models lists the entities stored in the database, in the order their tables are created
+var models = []interface{}{
+	&data.Location{},
+	&data.Organization{},
+	&data.Event{},
+	&data.Person{},
+	&data.Room{},
+	&data.Topic{},
+	&data.Track{},
+	&data.Talk{},
+	&data.TalkDate{},
+	&data.AuditEntry{},
+}
+
+// timeColumns are the columns of the times given by the callers, per table
+var timeColumns = map[string][]string{
+	"event":     {"begin_date", "end_date"},
+	"talk_date": {"begin_date", "recurrence_date"},
+}
+
+// normalizeTimes rewrites the times which sqlite stored with the offset the callers gave them in, into UTC.
+// sqlite keeps times as text, so times with different offsets do not compare in time order.
+// mysql keeps no offset; times written while the connection used the time zone of the server are not converted.
+func normalizeTimes(db *gorm.DB) error {
+	if db.Dialect().GetName() != "sqlite3" {
+		return nil
+	}
+
+	for table, columns := range timeColumns {
+		for _, column := range columns {
+			rows, err := db.Unscoped().Table(table).Select("id, "+column).Where(column+" NOT LIKE ?", "%+00:00").Rows()
+			if err != nil {
+				return fmt.Errorf("error reading times of %s.%s: %w", table, column, err)
+			}
+			times := map[uint]time.Time{}
+			for rows.Next() {
+				var id uint
+				var t time.Time
+				if err := rows.Scan(&id, &t); err != nil {
+					rows.Close()
+					return fmt.Errorf("error reading times of %s.%s: %w", table, column, err)
+				}
+				times[id] = t
+			}
+			rows.Close()
+
+			for id, t := range times {
+				if err := db.Unscoped().Table(table).Where("id = ?", id).UpdateColumn(column, t.UTC()).Error; err != nil {
+					return fmt.Errorf("error normalizing times of %s.%s: %w", table, column, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
 func autoMigrate(db *gorm.DB) *gorm.DB {
 
-	db = db.AutoMigrate(&data.Location{})
-	db = db.AutoMigrate(&data.Event{})
-	db = db.AutoMigrate(&data.Organization{})
-	db = db.AutoMigrate(&data.Person{})
-	db = db.AutoMigrate(&data.Room{})
-	db = db.AutoMigrate(&data.Topic{})
-	db = db.AutoMigrate(&data.Talk{})
-	db = db.AutoMigrate(&data.TalkDate{})
+	for _, model := range models {
+		// the returned copy is unscoped, using it would include soft deleted rows in every query
+		db.AutoMigrate(model)
+	}
 
 	return db
 }
@@ -0,0 +1,103 @@
+package database
+
+import (
+	"github.com/jinzhu/gorm"
+	"github.com/milutindzunic/pac-backend/data/timezonetest"
+	"testing"
+	"time"
+)
+
+func TestNormalizeTimes(t *testing.T) {
+	// pairs of times an hour apart on the clock of the zone, but 40 minutes apart in time, as the clock is set
+	// back from summer time in between; written with their offsets, the second one sorts first as text
+	belgrade := []time.Time{
+		time.Date(2021, 10, 31, 0, 30, 0, 0, time.UTC), // 02:30+02:00
+		time.Date(2021, 10, 31, 1, 10, 0, 0, time.UTC), // 02:10+01:00
+	}
+	newYork := []time.Time{
+		time.Date(2021, 11, 7, 5, 30, 0, 0, time.UTC), // 01:30-04:00
+		time.Date(2021, 11, 7, 6, 10, 0, 0, time.UTC), // 01:10-05:00
+	}
+
+	// the times the clock of the server is set back in between, for the zones which have them
+	timesIn := map[string][]time.Time{
+		"America/New_York":    newYork,
+		"Australia/Lord_Howe": newYork,
+	}
+
+	timezonetest.Run(t, func(t *testing.T) {
+		times, ok := timesIn[time.Local.String()]
+		if !ok {
+			times = belgrade
+		}
+
+		db := openMemory(t)
+		for i, begin := range times {
+			// written like by an earlier version, in the time zone of the server
+			if err := db.Exec("INSERT INTO talk_date (id, begin_date, talk_id) VALUES (?, ?, 1)", i+1, begin.Local()).Error; err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := db.Exec("INSERT INTO event (id, name, begin_date, end_date) VALUES (1, 'Event', ?, ?)",
+			times[0].Local(), times[1].Local()).Error; err != nil {
+			t.Fatal(err)
+		}
+
+		if err := normalizeTimes(db); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, table := range []string{"talk_date", "event"} {
+			var count int
+			if err := db.Table(table).Where("begin_date NOT LIKE ?", "%+00:00").Count(&count).Error; err != nil {
+				t.Fatal(err)
+			}
+			if count != 0 {
+				t.Errorf("%d times of %s are not in UTC", count, table)
+			}
+		}
+
+		rows, err := db.Table("talk_date").Select("id, begin_date").Order("begin_date").Rows()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+		for i := 0; rows.Next(); i++ {
+			var id int
+			var begin time.Time
+			if err := rows.Scan(&id, &begin); err != nil {
+				t.Fatal(err)
+			}
+			if id != i+1 || !begin.Equal(times[i]) {
+				t.Errorf("talk date %d in time order is %d at %s, want %d at %s", i, id, begin, i+1, times[i])
+			}
+		}
+
+		// normalizing again changes nothing
+		if err := normalizeTimes(db); err != nil {
+			t.Fatal(err)
+		}
+		var event struct{ BeginDate, EndDate time.Time }
+		if err := db.Table("event").Select("begin_date, end_date").Scan(&event).Error; err != nil {
+			t.Fatal(err)
+		}
+		if !event.BeginDate.Equal(times[0]) || !event.EndDate.Equal(times[1]) {
+			t.Errorf("event is from %s to %s, want %s to %s", event.BeginDate, event.EndDate, times[0], times[1])
+		}
+	})
+}
+
+// openMemory returns an in-memory sqlite database with the schema of the entities, but no foreign keys
+func openMemory(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	// every connection to :memory: opens a database of its own
+	db.DB().SetMaxOpenConns(1)
+	db.SingularTable(true)
+	autoMigrate(db)
+	return db
+}
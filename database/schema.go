@@ -0,0 +1,34 @@
+package database
+
+import (
+	"fmt"
+	"github.com/jinzhu/gorm"
+)
+
+// CheckSchema verifies that the migrations are current, i.e. that every table and column of the models exists
+func CheckSchema(db *gorm.DB) error {
+	dialect := db.Dialect()
+
+	for _, model := range models {
+		scope := db.NewScope(model)
+		table := scope.TableName()
+		if !dialect.HasTable(table) {
+			return fmt.Errorf("table %s is missing", table)
+		}
+		for _, field := range scope.GetModelStruct().StructFields {
+			if !field.IsNormal || field.IsIgnored {
+				continue
+			}
+			if !dialect.HasColumn(table, field.DBName) {
+				return fmt.Errorf("column %s.%s is missing", table, field.DBName)
+			}
+		}
+	}
+
+	for table := range joinTables {
+		if !dialect.HasTable(table) {
+			return fmt.Errorf("table %s is missing", table)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package graph
+
+import (
+	"errors"
+	"github.com/hashicorp/go-hclog"
+	"github.com/milutindzunic/pac-backend/data"
+)
+
+// Error codes reported in the extensions of GraphQL errors, matching the problem types of the REST API
+const (
+	codeNotFound    = "NOT_FOUND"
+	codeValidation  = "VALIDATION"
+	codeConflict    = "CONFLICT"
+	codeForbidden   = "FORBIDDEN"
+	codeUnavailable = "UNAVAILABLE"
+	codeTooComplex  = "QUERY_TOO_COMPLEX"
+	codeInternal    = "INTERNAL"
+)
+
+// Error is returned by the resolvers, its code and failed fields are reported in the extensions of the GraphQL error
+type Error struct {
+	Message    string
+	Code       string
+	Errors     []data.FieldError
+	Dependents []data.Dependent
+}
+
+func (e *Error) Error() string { return e.Message }
+
+func (e *Error) Extensions() map[string]interface{} {
+	extensions := map[string]interface{}{"code": e.Code}
+	if len(e.Errors) > 0 {
+		extensions["errors"] = e.Errors
+	}
+	if len(e.Dependents) > 0 {
+		extensions["dependents"] = e.Dependents
+	}
+	return extensions
+}
+
+// publicError maps an error returned by the stores onto the error reported to the client.
+// Unknown errors are logged and reported as internal errors, without exposing their message.
+func publicError(err error, log hclog.Logger) error {
+	if err == nil {
+		return nil
+	}
+
+	var validationErr *data.ValidationError
+	if errors.As(err, &validationErr) {
+		return &Error{Message: err.Error(), Code: codeValidation, Errors: validationErr.Errors}
+	}
+
+	var conflictErr *data.ConflictError
+	if errors.As(err, &conflictErr) {
+		return &Error{Message: err.Error(), Code: codeConflict, Dependents: conflictErr.Dependents}
+	}
+
+	switch {
+	case errors.Is(err, data.ErrNotFound):
+		return &Error{Message: err.Error(), Code: codeNotFound}
+	case errors.Is(err, data.ErrConflict):
+		return &Error{Message: err.Error(), Code: codeConflict}
+	case errors.Is(err, data.ErrForbidden):
+		return &Error{Message: err.Error(), Code: codeForbidden}
+	case errors.Is(err, data.ErrUnavailable):
+		return &Error{Message: err.Error(), Code: codeUnavailable}
+	default:
+		log.Error("Unexpected error resolving GraphQL field", "err", err)
+		return &Error{Message: "An unexpected error occurred", Code: codeInternal}
+	}
+}
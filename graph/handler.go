@@ -0,0 +1,165 @@
+package graph
+
+import (
+	"encoding/json"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/hashicorp/go-hclog"
+	"github.com/milutindzunic/pac-backend/logging"
+	"net/http"
+	"strconv"
+)
+
+// Request is a GraphQL request, sent as a JSON body of a POST or as the query parameters of a GET
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Handler serves the GraphQL API. Queries are open to everyone, like the GET endpoints of the REST API,
+// while mutations are passed through guard first, so they are authenticated and limited like the other changes.
+type Handler struct {
+	schema graphql.Schema
+	stores Stores
+	limits Limits
+	guard  func(http.Handler) http.Handler
+	log    hclog.Logger
+}
+
+func NewHandler(stores Stores, limits Limits, guard func(http.Handler) http.Handler, log hclog.Logger) (*Handler, error) {
+	schema, err := NewSchema(stores, log)
+	if err != nil {
+		return nil, err
+	}
+	return &Handler{schema, stores, limits, guard, log}, nil
+}
+
+func (h *Handler) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	log := logging.FromContext(r.Context(), h.log)
+	log.Debug("GraphQL endpoint called...")
+
+	req, err := readRequest(r)
+	if err != nil {
+		writeErrors(rw, http.StatusBadRequest, &Error{Message: err.Error(), Code: codeValidation})
+		return
+	}
+
+	doc, err := parser.Parse(parser.ParseParams{Source: req.Query})
+	if err != nil {
+		writeErrors(rw, http.StatusBadRequest, err)
+		return
+	}
+
+	validation := graphql.ValidateDocument(&h.schema, doc, nil)
+	if !validation.IsValid {
+		writeResult(rw, http.StatusBadRequest, &graphql.Result{Errors: validation.Errors})
+		return
+	}
+
+	operation := operationOf(doc, req.OperationName)
+	if operation == nil {
+		message := "operation " + req.OperationName + " not found"
+		if req.OperationName == "" {
+			message = "operationName is required for documents with several operations"
+		}
+		writeErrors(rw, http.StatusBadRequest, &Error{Message: message, Code: codeValidation})
+		return
+	}
+	if err := h.limits.check(&h.schema, doc, operation); err != nil {
+		log.Warn("Rejecting GraphQL query", "err", err)
+		writeErrors(rw, http.StatusBadRequest, err)
+		return
+	}
+
+	execute := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		result := graphql.Execute(graphql.ExecuteParams{
+			Schema:        h.schema,
+			AST:           doc,
+			OperationName: req.OperationName,
+			Args:          req.Variables,
+			Context:       withLoaders(r.Context(), newLoaders(h.stores)),
+		})
+		writeResult(rw, http.StatusOK, result)
+	})
+
+	if operation.Operation != ast.OperationTypeMutation {
+		execute.ServeHTTP(rw, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		// GET requests must be safe, so mutations are only accepted with POST
+		rw.Header().Set("Allow", http.MethodPost)
+		writeErrors(rw, http.StatusMethodNotAllowed, &Error{Message: "mutations must be sent with POST", Code: codeValidation})
+		return
+	}
+	h.guard(execute).ServeHTTP(rw, r)
+}
+
+func readRequest(r *http.Request) (*Request, error) {
+	if r.Method == http.MethodGet {
+		query := r.URL.Query()
+		req := &Request{Query: query.Get("query"), OperationName: query.Get("operationName")}
+		if variables := query.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				return nil, err
+			}
+		}
+		return req, nil
+	}
+
+	var req Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// operationOf returns the operation to execute: the one with the given name, or the only one of the document
+func operationOf(doc *ast.Document, name string) *ast.OperationDefinition {
+	var found *ast.OperationDefinition
+	for _, definition := range doc.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if name == "" {
+			if found != nil {
+				// the name is required when there are several operations
+				return nil
+			}
+			found = operation
+		} else if operation.Name != nil && operation.Name.Value == name {
+			return operation
+		}
+	}
+	return found
+}
+
+func writeErrors(rw http.ResponseWriter, status int, err error) {
+	writeResult(rw, status, &graphql.Result{Errors: []gqlerrors.FormattedError{formatError(err)}})
+}
+
+// formatError keeps the extensions of errors which are reported outside of the execution
+func formatError(err error) gqlerrors.FormattedError {
+	formatted := gqlerrors.FormatError(err)
+	if extended, ok := err.(gqlerrors.ExtendedError); ok {
+		formatted.Extensions = extended.Extensions()
+	}
+	return formatted
+}
+
+func writeResult(rw http.ResponseWriter, status int, result *graphql.Result) {
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.Header().Set("Content-Length", strconv.Itoa(len(jsonBytes)))
+	rw.WriteHeader(status)
+	_, _ = rw.Write(jsonBytes)
+}
@@ -0,0 +1,139 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/hashicorp/go-hclog"
+	"github.com/milutindzunic/pac-backend/auth"
+	"github.com/milutindzunic/pac-backend/config"
+	"github.com/milutindzunic/pac-backend/data"
+	"github.com/milutindzunic/pac-backend/database"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newStores returns the stores of a new sqlite database holding a single location
+func newStores(t *testing.T) Stores {
+	db, err := database.OpenDB(&config.Config{DbDriver: "sqlite3", DbName: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	log := hclog.NewNullLogger()
+	stores := Stores{
+		Locations:     data.NewLocationDBStore(db, log),
+		Events:        data.NewEventDBStore(db, log),
+		Organizations: data.NewOrganizationDBStore(db, log),
+		Persons:       data.NewPersonDBStore(db, log),
+		Rooms:         data.NewRoomDBStore(db, log),
+		Topics:        data.NewTopicDBStore(db, log),
+		Tracks:        data.NewTrackDBStore(db, log),
+		Talks:         data.NewTalkDBStore(db, log),
+		TalkDates:     data.NewTalkDateDBStore(db, log),
+	}
+	if _, err := stores.Locations.AddLocation(context.Background(), &data.Location{Name: "Belgrade", Address: data.Address{City: "Belgrade"}}); err != nil {
+		t.Fatal(err)
+	}
+	return stores
+}
+
+// newIssuer serves the discovery document of an OpenID Connect issuer, enough to create a provider
+func newIssuer(t *testing.T) *httptest.Server {
+	var issuer *httptest.Server
+	issuer = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(rw).Encode(map[string]string{
+			"issuer":                 issuer.URL,
+			"authorization_endpoint": issuer.URL + "/auth",
+			"token_endpoint":         issuer.URL + "/token",
+			"jwks_uri":               issuer.URL + "/keys",
+		})
+	}))
+	t.Cleanup(issuer.Close)
+	return issuer
+}
+
+func TestHandler(t *testing.T) {
+	issuer := newIssuer(t)
+	oauth, err := auth.NewProvider(auth.OauthConfig{Enabled: true, Issuer: issuer.URL, ClientID: "pac"}, hclog.NewNullLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := NewHandler(newStores(t), Limits{MaxDepth: 3, MaxComplexity: 100}, oauth.BearerMiddleware, hclog.NewNullLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		query  string
+		status int
+		// want is contained in the response
+		want string
+	}{
+		{
+			name:   "query within the limits",
+			method: "POST",
+			query:  `{ locations { name address { city } } }`,
+			status: http.StatusOK,
+			want:   `{"data":{"locations":[{"address":{"city":"Belgrade"},"name":"Belgrade"}]}}`,
+		},
+		{
+			name:   "query over the depth limit",
+			method: "POST",
+			query:  `{ events { location { address { city } } } }`,
+			status: http.StatusBadRequest,
+			want:   `"extensions":{"code":"QUERY_TOO_COMPLEX"}`,
+		},
+		{
+			name:   "fragment spread within the limits",
+			method: "POST",
+			query:  `{ locations { ...venue } } fragment venue on Location { name address { city } }`,
+			status: http.StatusOK,
+			want:   `{"data":{"locations":[{"address":{"city":"Belgrade"},"name":"Belgrade"}]}}`,
+		},
+		{
+			name:   "fragment spread over the depth limit",
+			method: "POST",
+			query:  `{ events { ...venue } } fragment venue on Event { location { address { city } } }`,
+			status: http.StatusBadRequest,
+			want:   `"message":"query depth 4 exceeds the maximum of 3"`,
+		},
+		{
+			name:   "mutation sent with GET",
+			method: "GET",
+			query:  `mutation { deleteLocation(id: 1) }`,
+			status: http.StatusMethodNotAllowed,
+			want:   `"message":"mutations must be sent with POST"`,
+		},
+		{
+			name:   "mutation without a token",
+			method: "POST",
+			query:  `mutation { deleteLocation(id: 1) }`,
+			status: http.StatusUnauthorized,
+			want:   "Valid access token required",
+		},
+	}
+
+	for _, tt := range tests {
+		var r *http.Request
+		if tt.method == "GET" {
+			r = httptest.NewRequest("GET", "/graphql?query="+url.QueryEscape(tt.query), nil)
+		} else {
+			body, _ := json.Marshal(Request{Query: tt.query})
+			r = httptest.NewRequest("POST", "/graphql", strings.NewReader(string(body)))
+		}
+		rw := httptest.NewRecorder()
+		h.ServeHTTP(rw, r)
+
+		if rw.Code != tt.status || !strings.Contains(rw.Body.String(), tt.want) {
+			t.Errorf("%s: got %d %s, want %d containing %s", tt.name, rw.Code, rw.Body, tt.status, tt.want)
+		}
+	}
+}
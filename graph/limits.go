@@ -0,0 +1,117 @@
+package graph
+
+import (
+	"fmt"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"strings"
+)
+
+// listMultiplier is the number of elements assumed for a list field, when estimating the complexity of its selections
+const listMultiplier = 10
+
+// Limits bound the queries which are executed, so that a single request cannot make the server
+// resolve the whole database over and over again
+type Limits struct {
+	// MaxDepth is the maximum nesting of the selected fields, the root fields being at depth 1
+	MaxDepth int
+	// MaxComplexity is the maximum number of fields to be resolved, estimating listMultiplier elements per list
+	MaxComplexity int
+}
+
+// check measures the operation and returns an error if it exceeds the limits. Introspection fields are not counted,
+// so that tools can always read the schema.
+func (l Limits) check(schema *graphql.Schema, doc *ast.Document, operation *ast.OperationDefinition) error {
+	m := measure{schema: schema, fragments: map[string]*ast.FragmentDefinition{}}
+	for _, definition := range doc.Definitions {
+		if fragment, ok := definition.(*ast.FragmentDefinition); ok {
+			m.fragments[fragment.Name.Value] = fragment
+		}
+	}
+
+	var root graphql.Type = schema.QueryType()
+	if operation.Operation == ast.OperationTypeMutation {
+		root = schema.MutationType()
+	}
+
+	depth, complexity := m.selectionSet(operation.SelectionSet, root)
+	if l.MaxDepth > 0 && depth > l.MaxDepth {
+		return &Error{Message: fmt.Sprintf("query depth %d exceeds the maximum of %d", depth, l.MaxDepth), Code: codeTooComplex}
+	}
+	if l.MaxComplexity > 0 && complexity > l.MaxComplexity {
+		return &Error{Message: fmt.Sprintf("query complexity %d exceeds the maximum of %d", complexity, l.MaxComplexity), Code: codeTooComplex}
+	}
+	return nil
+}
+
+type measure struct {
+	schema    *graphql.Schema
+	fragments map[string]*ast.FragmentDefinition
+}
+
+// selectionSet returns the depth and the complexity of the selections on the parent type
+func (m measure) selectionSet(set *ast.SelectionSet, parent graphql.Type) (int, int) {
+	if set == nil {
+		return 0, 0
+	}
+
+	depth, complexity := 0, 0
+	for _, selection := range set.Selections {
+		var d, c int
+		switch s := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(s.Name.Value, "__") {
+				continue
+			}
+			fieldType, list := m.fieldType(parent, s.Name.Value)
+			d, c = m.selectionSet(s.SelectionSet, fieldType)
+			if list {
+				c *= listMultiplier
+			}
+			d, c = d+1, c+1
+		case *ast.InlineFragment:
+			fragmentType := parent
+			if s.TypeCondition != nil {
+				fragmentType = m.schema.Type(s.TypeCondition.Name.Value)
+			}
+			d, c = m.selectionSet(s.SelectionSet, fragmentType)
+		case *ast.FragmentSpread:
+			// fragment cycles are rejected by the validation, which runs first
+			fragment, ok := m.fragments[s.Name.Value]
+			if !ok {
+				continue
+			}
+			d, c = m.selectionSet(fragment.SelectionSet, m.schema.Type(fragment.TypeCondition.Name.Value))
+		}
+
+		if d > depth {
+			depth = d
+		}
+		complexity += c
+	}
+	return depth, complexity
+}
+
+// fieldType returns the named type of the field of the parent type, and whether the field is a list
+func (m measure) fieldType(parent graphql.Type, name string) (graphql.Type, bool) {
+	object, ok := parent.(*graphql.Object)
+	if !ok {
+		return nil, false
+	}
+	field, ok := object.Fields()[name]
+	if !ok {
+		return nil, false
+	}
+
+	t, list := field.Type, false
+	for {
+		switch wrapper := t.(type) {
+		case *graphql.NonNull:
+			t = wrapper.OfType
+		case *graphql.List:
+			t, list = wrapper.OfType, true
+		default:
+			return t, list
+		}
+	}
+}
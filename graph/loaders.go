@@ -0,0 +1,166 @@
+package graph
+
+import (
+	"context"
+	"github.com/milutindzunic/pac-backend/data"
+	"sync"
+)
+
+// loader batches the loads of a single request. The keys requested while one level of the query is resolved are
+// queued, and fetched with a single store call once the first of their results is needed.
+type loader struct {
+	fetch func(ctx context.Context, keys []uint) (map[uint]interface{}, error)
+
+	mu      sync.Mutex
+	pending []uint
+	queued  map[uint]bool
+	results map[uint]interface{}
+	errs    map[uint]error
+}
+
+func newLoader(fetch func(ctx context.Context, keys []uint) (map[uint]interface{}, error)) *loader {
+	return &loader{fetch: fetch, queued: map[uint]bool{}, results: map[uint]interface{}{}, errs: map[uint]error{}}
+}
+
+// load queues the key and returns a thunk, which the executor calls after the other fields of the level are resolved
+func (l *loader) load(ctx context.Context, key uint) func() (interface{}, error) {
+	l.mu.Lock()
+	if _, done := l.results[key]; !done && !l.queued[key] {
+		l.queued[key] = true
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (interface{}, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if len(l.pending) > 0 {
+			keys := l.pending
+			l.pending = nil
+
+			values, err := l.fetch(ctx, keys)
+			for _, k := range keys {
+				delete(l.queued, k)
+				if err != nil {
+					l.errs[k] = err
+				}
+				l.results[k] = values[k]
+			}
+		}
+
+		return l.results[key], l.errs[key]
+	}
+}
+
+// loaders are created for every request, so that results are never shared between callers
+type loaders struct {
+	locations        *loader
+	events           *loader
+	organizations    *loader
+	rooms            *loader
+	topics           *loader
+	tracks           *loader
+	talks            *loader
+	talkDatesByEvent *loader
+	talksByPerson    *loader
+}
+
+func newLoaders(stores Stores) *loaders {
+	return &loaders{
+		locations: newLoader(func(ctx context.Context, ids []uint) (map[uint]interface{}, error) {
+			locations, err := stores.Locations.GetLocationsByIDs(ctx, ids)
+			values := map[uint]interface{}{}
+			for _, location := range locations {
+				values[location.ID] = location
+			}
+			return values, err
+		}),
+		events: newLoader(func(ctx context.Context, ids []uint) (map[uint]interface{}, error) {
+			events, err := stores.Events.GetEventsByIDs(ctx, ids)
+			values := map[uint]interface{}{}
+			for _, event := range events {
+				values[event.ID] = event
+			}
+			return values, err
+		}),
+		organizations: newLoader(func(ctx context.Context, ids []uint) (map[uint]interface{}, error) {
+			organizations, err := stores.Organizations.GetOrganizationsByIDs(ctx, ids)
+			values := map[uint]interface{}{}
+			for _, organization := range organizations {
+				values[organization.ID] = organization
+			}
+			return values, err
+		}),
+		rooms: newLoader(func(ctx context.Context, ids []uint) (map[uint]interface{}, error) {
+			rooms, err := stores.Rooms.GetRoomsByIDs(ctx, ids)
+			values := map[uint]interface{}{}
+			for _, room := range rooms {
+				values[room.ID] = room
+			}
+			return values, err
+		}),
+		topics: newLoader(func(ctx context.Context, ids []uint) (map[uint]interface{}, error) {
+			topics, err := stores.Topics.GetTopicsByIDs(ctx, ids)
+			values := map[uint]interface{}{}
+			for _, topic := range topics {
+				values[topic.ID] = topic
+			}
+			return values, err
+		}),
+		tracks: newLoader(func(ctx context.Context, ids []uint) (map[uint]interface{}, error) {
+			tracks, err := stores.Tracks.GetTracksByIDs(ctx, ids)
+			values := map[uint]interface{}{}
+			for _, track := range tracks {
+				values[track.ID] = track
+			}
+			return values, err
+		}),
+		talks: newLoader(func(ctx context.Context, ids []uint) (map[uint]interface{}, error) {
+			talks, err := stores.Talks.GetTalksByIDs(ctx, ids)
+			values := map[uint]interface{}{}
+			for _, talk := range talks {
+				values[talk.ID] = talk
+			}
+			return values, err
+		}),
+		talkDatesByEvent: newLoader(func(ctx context.Context, eventIDs []uint) (map[uint]interface{}, error) {
+			talkDates, err := stores.TalkDates.GetTalkDatesByEventIDs(ctx, eventIDs)
+			grouped := map[uint][]*data.TalkDate{}
+			for _, talkDate := range talkDates {
+				if talkDate.EventID != nil {
+					grouped[*talkDate.EventID] = append(grouped[*talkDate.EventID], talkDate)
+				}
+			}
+			values := map[uint]interface{}{}
+			for _, id := range eventIDs {
+				values[id] = append([]*data.TalkDate{}, grouped[id]...)
+			}
+			return values, err
+		}),
+		talksByPerson: newLoader(func(ctx context.Context, personIDs []uint) (map[uint]interface{}, error) {
+			talks, err := stores.Talks.GetTalksByPersonIDs(ctx, personIDs)
+			grouped := map[uint][]*data.Talk{}
+			for _, talk := range talks {
+				for _, person := range talk.Persons {
+					grouped[person.ID] = append(grouped[person.ID], talk)
+				}
+			}
+			values := map[uint]interface{}{}
+			for _, id := range personIDs {
+				values[id] = append([]*data.Talk{}, grouped[id]...)
+			}
+			return values, err
+		}),
+	}
+}
+
+type loadersKey struct{}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
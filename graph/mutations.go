@@ -0,0 +1,570 @@
+package graph
+
+import (
+	"context"
+	"github.com/graphql-go/graphql"
+	"github.com/milutindzunic/pac-backend/data"
+	"strconv"
+	"time"
+)
+
+// mutations of a single entity, mirroring its POST, PUT, DELETE and restore endpoints
+type mutations struct {
+	name   string
+	object *graphql.Object
+	input  *graphql.InputObject
+	// decode turns the input object into the entity passed to add and update
+	decode  func(ctx context.Context, input map[string]interface{}) (interface{}, error)
+	add     func(ctx context.Context, entity interface{}) (interface{}, error)
+	update  func(ctx context.Context, id uint, entity interface{}) (interface{}, error)
+	delete  func(ctx context.Context, id uint, cascade bool) error
+	restore func(ctx context.Context, id uint) (interface{}, error)
+	// series updates the whole series the entity is an occurrence of, for the entities repeated in series
+	series func(ctx context.Context, id uint, entity interface{}) (interface{}, error)
+}
+
+func (r *resolver) mutationType(t *types) *graphql.Object {
+	fields := graphql.Fields{}
+
+	for _, m := range []mutations{
+		{
+			name:   "Location",
+			object: t.location,
+			input: graphql.NewInputObject(graphql.InputObjectConfig{
+				Name: "LocationInput",
+				Fields: graphql.InputObjectConfigFieldMap{
+					"name": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+					"address": &graphql.InputObjectFieldConfig{Type: graphql.NewInputObject(graphql.InputObjectConfig{
+						Name: "AddressInput",
+						Fields: graphql.InputObjectConfigFieldMap{
+							"street":     &graphql.InputObjectFieldConfig{Type: graphql.String},
+							"postalCode": &graphql.InputObjectFieldConfig{Type: graphql.String},
+							"city":       &graphql.InputObjectFieldConfig{Type: graphql.String},
+							"country":    &graphql.InputObjectFieldConfig{Type: graphql.String},
+						},
+					})},
+					"latitude":    &graphql.InputObjectFieldConfig{Type: graphql.Float},
+					"longitude":   &graphql.InputObjectFieldConfig{Type: graphql.Float},
+					"timezone":    &graphql.InputObjectFieldConfig{Type: graphql.String},
+					"description": &graphql.InputObjectFieldConfig{Type: graphql.String},
+				},
+			}),
+			decode: func(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+				address, _ := input["address"].(map[string]interface{})
+				return &data.Location{
+					Name: stringField(input, "name"),
+					Address: data.Address{
+						Street:     stringField(address, "street"),
+						PostalCode: stringField(address, "postalCode"),
+						City:       stringField(address, "city"),
+						Country:    stringField(address, "country"),
+					},
+					Latitude:    floatField(input, "latitude"),
+					Longitude:   floatField(input, "longitude"),
+					Timezone:    stringField(input, "timezone"),
+					Description: stringField(input, "description"),
+				}, nil
+			},
+			add: func(ctx context.Context, entity interface{}) (interface{}, error) {
+				return r.stores.Locations.AddLocation(ctx, entity.(*data.Location))
+			},
+			update: func(ctx context.Context, id uint, entity interface{}) (interface{}, error) {
+				return r.stores.Locations.UpdateLocation(ctx, id, entity.(*data.Location))
+			},
+			delete: r.stores.Locations.DeleteLocationByID,
+			restore: func(ctx context.Context, id uint) (interface{}, error) {
+				return r.stores.Locations.RestoreLocationByID(ctx, id)
+			},
+		},
+		{
+			name:   "Event",
+			object: t.event,
+			input: graphql.NewInputObject(graphql.InputObjectConfig{
+				Name: "EventInput",
+				Fields: graphql.InputObjectConfigFieldMap{
+					"name":       &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+					"beginDate":  &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.DateTime)},
+					"endDate":    &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.DateTime)},
+					"locationId": &graphql.InputObjectFieldConfig{Type: graphql.ID},
+					"timezone":   &graphql.InputObjectFieldConfig{Type: graphql.String},
+					// ignored for callers scoped to a tenant, whose events belong to their organization
+					"organizationId": &graphql.InputObjectFieldConfig{Type: graphql.ID},
+				},
+			}),
+			decode: func(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+				event := &data.Event{
+					Name:      stringField(input, "name"),
+					BeginDate: timeField(input, "beginDate"),
+					EndDate:   timeField(input, "endDate"),
+					Timezone:  stringField(input, "timezone"),
+				}
+				locationID, err := idField(input, "locationId")
+				if err != nil {
+					return nil, err
+				}
+				if locationID != 0 {
+					event.Location = &data.Location{ID: locationID}
+				}
+				organizationID, err := idField(input, "organizationId")
+				if organizationID != 0 {
+					event.Organization = &data.Organization{ID: organizationID}
+				}
+				return event, err
+			},
+			add: func(ctx context.Context, entity interface{}) (interface{}, error) {
+				return r.stores.Events.AddEvent(ctx, entity.(*data.Event))
+			},
+			update: func(ctx context.Context, id uint, entity interface{}) (interface{}, error) {
+				return r.stores.Events.UpdateEvent(ctx, id, entity.(*data.Event))
+			},
+			delete: r.stores.Events.DeleteEventByID,
+			restore: func(ctx context.Context, id uint) (interface{}, error) {
+				return r.stores.Events.RestoreEventByID(ctx, id)
+			},
+		},
+		{
+			name:   "Organization",
+			object: t.organization,
+			input: graphql.NewInputObject(graphql.InputObjectConfig{
+				Name: "OrganizationInput",
+				Fields: graphql.InputObjectConfigFieldMap{
+					"name": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+			}),
+			decode: func(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+				return &data.Organization{Name: stringField(input, "name")}, nil
+			},
+			add: func(ctx context.Context, entity interface{}) (interface{}, error) {
+				return r.stores.Organizations.AddOrganization(ctx, entity.(*data.Organization))
+			},
+			update: func(ctx context.Context, id uint, entity interface{}) (interface{}, error) {
+				return r.stores.Organizations.UpdateOrganization(ctx, id, entity.(*data.Organization))
+			},
+			delete: r.stores.Organizations.DeleteOrganizationByID,
+			restore: func(ctx context.Context, id uint) (interface{}, error) {
+				return r.stores.Organizations.RestoreOrganizationByID(ctx, id)
+			},
+		},
+		{
+			name:   "Person",
+			object: t.person,
+			input: graphql.NewInputObject(graphql.InputObjectConfig{
+				Name: "PersonInput",
+				Fields: graphql.InputObjectConfigFieldMap{
+					"name":           &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+					"organizationId": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+			}),
+			decode: func(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+				organizationID, err := idField(input, "organizationId")
+				return &data.Person{Name: stringField(input, "name"), Organization: &data.Organization{ID: organizationID}}, err
+			},
+			add: func(ctx context.Context, entity interface{}) (interface{}, error) {
+				return r.stores.Persons.AddPerson(ctx, entity.(*data.Person))
+			},
+			update: func(ctx context.Context, id uint, entity interface{}) (interface{}, error) {
+				return r.stores.Persons.UpdatePerson(ctx, id, entity.(*data.Person))
+			},
+			delete: r.stores.Persons.DeletePersonByID,
+			restore: func(ctx context.Context, id uint) (interface{}, error) {
+				return r.stores.Persons.RestorePersonByID(ctx, id)
+			},
+		},
+		{
+			name:   "Room",
+			object: t.room,
+			input: graphql.NewInputObject(graphql.InputObjectConfig{
+				Name: "RoomInput",
+				Fields: graphql.InputObjectConfigFieldMap{
+					"name":           &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+					"organizationId": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"locationId":     &graphql.InputObjectFieldConfig{Type: graphql.ID},
+				},
+			}),
+			decode: func(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+				organizationID, err := idField(input, "organizationId")
+				if err != nil {
+					return nil, err
+				}
+				room := &data.Room{Name: stringField(input, "name"), Organization: &data.Organization{ID: organizationID}}
+				locationID, err := idField(input, "locationId")
+				if err != nil {
+					return nil, err
+				}
+				if locationID != 0 {
+					room.Location = &data.Location{ID: locationID}
+				}
+				return room, nil
+			},
+			add: func(ctx context.Context, entity interface{}) (interface{}, error) {
+				return r.stores.Rooms.AddRoom(ctx, entity.(*data.Room))
+			},
+			update: func(ctx context.Context, id uint, entity interface{}) (interface{}, error) {
+				return r.stores.Rooms.UpdateRoom(ctx, id, entity.(*data.Room))
+			},
+			delete: r.stores.Rooms.DeleteRoomByID,
+			restore: func(ctx context.Context, id uint) (interface{}, error) {
+				return r.stores.Rooms.RestoreRoomByID(ctx, id)
+			},
+		},
+		{
+			name:   "Topic",
+			object: t.topic,
+			input: graphql.NewInputObject(graphql.InputObjectConfig{
+				Name: "TopicInput",
+				Fields: graphql.InputObjectConfigFieldMap{
+					"name":     &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+					"childIds": &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.ID))},
+				},
+			}),
+			decode: func(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+				topic := &data.Topic{Name: stringField(input, "name")}
+				childIDs, err := idsField(input, "childIds")
+				if err != nil || len(childIDs) == 0 {
+					return topic, err
+				}
+				// the children are saved along with the topic, so they are passed as they are stored
+				children, err := r.stores.Topics.GetTopicsByIDs(ctx, childIDs)
+				if err != nil {
+					return nil, err
+				}
+				if len(children) != len(childIDs) {
+					return nil, fieldError("Topic", "childIds", "exists", "must refer to existing topics")
+				}
+				for _, child := range children {
+					topic.Children = append(topic.Children, *child)
+				}
+				return topic, nil
+			},
+			add: func(ctx context.Context, entity interface{}) (interface{}, error) {
+				return r.stores.Topics.AddTopic(ctx, entity.(*data.Topic))
+			},
+			update: func(ctx context.Context, id uint, entity interface{}) (interface{}, error) {
+				return r.stores.Topics.UpdateTopic(ctx, id, entity.(*data.Topic))
+			},
+			delete: r.stores.Topics.DeleteTopicByID,
+			restore: func(ctx context.Context, id uint) (interface{}, error) {
+				return r.stores.Topics.RestoreTopicByID(ctx, id)
+			},
+		},
+		{
+			name:   "Track",
+			object: t.track,
+			input: graphql.NewInputObject(graphql.InputObjectConfig{
+				Name: "TrackInput",
+				Fields: graphql.InputObjectConfigFieldMap{
+					"name":    &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+					"color":   &graphql.InputObjectFieldConfig{Type: graphql.String},
+					"eventId": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+			}),
+			decode: func(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+				eventID, err := idField(input, "eventId")
+				return &data.Track{Name: stringField(input, "name"), Color: stringField(input, "color"), Event: &data.Event{ID: eventID}}, err
+			},
+			add: func(ctx context.Context, entity interface{}) (interface{}, error) {
+				return r.stores.Tracks.AddTrack(ctx, entity.(*data.Track))
+			},
+			update: func(ctx context.Context, id uint, entity interface{}) (interface{}, error) {
+				return r.stores.Tracks.UpdateTrack(ctx, id, entity.(*data.Track))
+			},
+			delete: r.stores.Tracks.DeleteTrackByID,
+			restore: func(ctx context.Context, id uint) (interface{}, error) {
+				return r.stores.Tracks.RestoreTrackByID(ctx, id)
+			},
+		},
+		{
+			name:   "Talk",
+			object: t.talk,
+			input: graphql.NewInputObject(graphql.InputObjectConfig{
+				Name: "TalkInput",
+				Fields: graphql.InputObjectConfigFieldMap{
+					"title":             &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+					"durationInMinutes": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.Int)},
+					"language":          &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+					"level":             &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(t.talkLevel)},
+					"format":            &graphql.InputObjectFieldConfig{Type: t.talkFormat},
+					"abstract":          &graphql.InputObjectFieldConfig{Type: graphql.String},
+					"prerequisites":     &graphql.InputObjectFieldConfig{Type: graphql.String},
+					"slidesUrl":         &graphql.InputObjectFieldConfig{Type: graphql.String},
+					"videoUrl":          &graphql.InputObjectFieldConfig{Type: graphql.String},
+					"repositoryUrl":     &graphql.InputObjectFieldConfig{Type: graphql.String},
+					"trackId":           &graphql.InputObjectFieldConfig{Type: graphql.ID},
+					"personIds":         &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.ID))},
+					"topicIds":          &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.ID))},
+					// ignored for callers scoped to a tenant, whose talks belong to their organization
+					"organizationId": &graphql.InputObjectFieldConfig{Type: graphql.ID},
+				},
+			}),
+			decode: func(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+				duration, _ := input["durationInMinutes"].(int)
+				if duration < 0 {
+					return nil, fieldError("Talk", "durationInMinutes", "gt", "must be greater than 0")
+				}
+				level, _ := input["level"].(data.TalkLevel)
+				format, _ := input["format"].(data.TalkFormat)
+				talk := &data.Talk{
+					Title:             stringField(input, "title"),
+					DurationInMinutes: uint(duration),
+					Language:          stringField(input, "language"),
+					Level:             level,
+					Format:            format,
+					Abstract:          stringField(input, "abstract"),
+					Prerequisites:     stringField(input, "prerequisites"),
+					SlidesURL:         stringField(input, "slidesUrl"),
+					VideoURL:          stringField(input, "videoUrl"),
+					RepositoryURL:     stringField(input, "repositoryUrl"),
+				}
+
+				trackID, err := idField(input, "trackId")
+				if err != nil {
+					return nil, err
+				}
+				if trackID != 0 {
+					talk.Track = &data.Track{ID: trackID}
+				}
+
+				personIDs, err := idsField(input, "personIds")
+				if err != nil {
+					return nil, err
+				}
+				for _, id := range personIDs {
+					talk.Persons = append(talk.Persons, data.Person{ID: id})
+				}
+				topicIDs, err := idsField(input, "topicIds")
+				if err != nil {
+					return nil, err
+				}
+				for _, id := range topicIDs {
+					talk.Topics = append(talk.Topics, data.Topic{ID: id})
+				}
+				organizationID, err := idField(input, "organizationId")
+				if organizationID != 0 {
+					talk.Organization = &data.Organization{ID: organizationID}
+				}
+				return talk, err
+			},
+			add: func(ctx context.Context, entity interface{}) (interface{}, error) {
+				return r.stores.Talks.AddTalk(ctx, entity.(*data.Talk))
+			},
+			update: func(ctx context.Context, id uint, entity interface{}) (interface{}, error) {
+				return r.stores.Talks.UpdateTalk(ctx, id, entity.(*data.Talk))
+			},
+			delete: r.stores.Talks.DeleteTalkByID,
+			restore: func(ctx context.Context, id uint) (interface{}, error) {
+				return r.stores.Talks.RestoreTalkByID(ctx, id)
+			},
+		},
+		{
+			name:   "TalkDate",
+			object: t.talkDate,
+			input: graphql.NewInputObject(graphql.InputObjectConfig{
+				Name: "TalkDateInput",
+				Fields: graphql.InputObjectConfigFieldMap{
+					"beginDate":  &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.DateTime)},
+					"talkId":     &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"roomId":     &graphql.InputObjectFieldConfig{Type: graphql.ID},
+					"eventId":    &graphql.InputObjectFieldConfig{Type: graphql.ID},
+					"locationId": &graphql.InputObjectFieldConfig{Type: graphql.ID},
+					"recurrence": &graphql.InputObjectFieldConfig{Type: graphql.String},
+					"exceptions": &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+				},
+			}),
+			decode: func(ctx context.Context, input map[string]interface{}) (interface{}, error) {
+				talkDate := &data.TalkDate{
+					BeginDate:  timeField(input, "beginDate"),
+					Recurrence: stringField(input, "recurrence"),
+				}
+				if exceptions, ok := input["exceptions"].([]interface{}); ok {
+					talkDate.Exceptions = data.Dates{}
+					for _, exception := range exceptions {
+						day, _ := exception.(string)
+						talkDate.Exceptions = append(talkDate.Exceptions, day)
+					}
+				}
+				for key, set := range map[string]func(id uint){
+					"talkId":     func(id uint) { talkDate.Talk = &data.Talk{ID: id} },
+					"roomId":     func(id uint) { talkDate.Room = &data.Room{ID: id} },
+					"eventId":    func(id uint) { talkDate.Event = &data.Event{ID: id} },
+					"locationId": func(id uint) { talkDate.Location = &data.Location{ID: id} },
+				} {
+					id, err := idField(input, key)
+					if err != nil {
+						return nil, err
+					}
+					if id != 0 {
+						set(id)
+					}
+				}
+				return talkDate, nil
+			},
+			series: func(ctx context.Context, id uint, entity interface{}) (interface{}, error) {
+				return r.stores.TalkDates.UpdateTalkDateSeries(ctx, id, entity.(*data.TalkDate))
+			},
+			add: func(ctx context.Context, entity interface{}) (interface{}, error) {
+				return r.stores.TalkDates.AddTalkDate(ctx, entity.(*data.TalkDate))
+			},
+			update: func(ctx context.Context, id uint, entity interface{}) (interface{}, error) {
+				return r.stores.TalkDates.UpdateTalkDate(ctx, id, entity.(*data.TalkDate))
+			},
+			delete: r.stores.TalkDates.DeleteTalkDateByID,
+			restore: func(ctx context.Context, id uint) (interface{}, error) {
+				return r.stores.TalkDates.RestoreTalkDateByID(ctx, id)
+			},
+		},
+	} {
+		r.addMutations(fields, m)
+	}
+
+	return graphql.NewObject(graphql.ObjectConfig{Name: "Mutation", Fields: fields})
+}
+
+// addMutations adds the createX, updateX, deleteX and restoreX fields of the entity, and updateXSeries for the entities
+// repeated in series
+func (r *resolver) addMutations(fields graphql.Fields, m mutations) {
+	id := &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)}
+	input := &graphql.ArgumentConfig{Type: graphql.NewNonNull(m.input)}
+
+	fields["create"+m.name] = &graphql.Field{
+		Type: graphql.NewNonNull(m.object),
+		Args: graphql.FieldConfigArgument{"input": input},
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			entity, err := m.decode(p.Context, p.Args["input"].(map[string]interface{}))
+			if err != nil {
+				return nil, r.publicError(p, err)
+			}
+			created, err := m.add(p.Context, entity)
+			if err != nil {
+				return nil, r.publicError(p, err)
+			}
+			return created, nil
+		},
+	}
+
+	fields["update"+m.name] = &graphql.Field{
+		Type: graphql.NewNonNull(m.object),
+		Args: graphql.FieldConfigArgument{"id": id, "input": input},
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			id, err := idArg(p, "id")
+			if err != nil {
+				return nil, err
+			}
+			entity, err := m.decode(p.Context, p.Args["input"].(map[string]interface{}))
+			if err != nil {
+				return nil, r.publicError(p, err)
+			}
+			updated, err := m.update(p.Context, id, entity)
+			if err != nil {
+				return nil, r.publicError(p, err)
+			}
+			return updated, nil
+		},
+	}
+
+	if m.series != nil {
+		fields["update"+m.name+"Series"] = &graphql.Field{
+			Type: graphql.NewNonNull(m.object),
+			Args: graphql.FieldConfigArgument{"id": id, "input": input},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				id, err := idArg(p, "id")
+				if err != nil {
+					return nil, err
+				}
+				entity, err := m.decode(p.Context, p.Args["input"].(map[string]interface{}))
+				if err != nil {
+					return nil, r.publicError(p, err)
+				}
+				updated, err := m.series(p.Context, id, entity)
+				if err != nil {
+					return nil, r.publicError(p, err)
+				}
+				return updated, nil
+			},
+		}
+	}
+
+	fields["delete"+m.name] = &graphql.Field{
+		Type: graphql.NewNonNull(graphql.Boolean),
+		Args: graphql.FieldConfigArgument{
+			"id":      id,
+			"cascade": &graphql.ArgumentConfig{Type: graphql.Boolean, DefaultValue: false},
+		},
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			id, err := idArg(p, "id")
+			if err != nil {
+				return nil, err
+			}
+			cascade, _ := p.Args["cascade"].(bool)
+			if err := m.delete(p.Context, id, cascade); err != nil {
+				return nil, r.publicError(p, err)
+			}
+			return true, nil
+		},
+	}
+
+	fields["restore"+m.name] = &graphql.Field{
+		Type: graphql.NewNonNull(m.object),
+		Args: graphql.FieldConfigArgument{"id": id},
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			id, err := idArg(p, "id")
+			if err != nil {
+				return nil, err
+			}
+			restored, err := m.restore(p.Context, id)
+			if err != nil {
+				return nil, r.publicError(p, err)
+			}
+			return restored, nil
+		},
+	}
+}
+
+func stringField(input map[string]interface{}, key string) string {
+	s, _ := input[key].(string)
+	return s
+}
+
+// floatField reads an optional number from the input, returning nil when it is not set
+func floatField(input map[string]interface{}, key string) *float64 {
+	f, ok := input[key].(float64)
+	if !ok {
+		return nil
+	}
+	return &f
+}
+
+func timeField(input map[string]interface{}, key string) time.Time {
+	t, _ := input[key].(time.Time)
+	return t
+}
+
+// idField reads an optional id from the input, returning 0 when it is not set
+func idField(input map[string]interface{}, key string) (uint, error) {
+	raw, ok := input[key].(string)
+	if !ok {
+		return 0, nil
+	}
+	id, err := strconv.ParseUint(raw, 10, 32)
+	if err != nil {
+		return 0, fieldError("Input", key, "type", "must be a positive integer, was "+raw)
+	}
+	return uint(id), nil
+}
+
+func idsField(input map[string]interface{}, key string) ([]uint, error) {
+	raws, _ := input[key].([]interface{})
+	ids := make([]uint, 0, len(raws))
+	for _, raw := range raws {
+		s, _ := raw.(string)
+		id, err := strconv.ParseUint(s, 10, 32)
+		if err != nil {
+			return nil, fieldError("Input", key, "type", "must only contain positive integers, was "+s)
+		}
+		ids = append(ids, uint(id))
+	}
+	return ids, nil
+}
+
+func fieldError(entity string, field string, rule string, message string) *data.ValidationError {
+	return &data.ValidationError{Entity: entity, Errors: []data.FieldError{{Field: field, Rule: rule, Message: message}}}
+}
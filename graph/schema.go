@@ -0,0 +1,671 @@
+package graph
+
+import (
+	"errors"
+	"github.com/graphql-go/graphql"
+	"github.com/hashicorp/go-hclog"
+	"github.com/milutindzunic/pac-backend/data"
+	"github.com/milutindzunic/pac-backend/logging"
+	"strconv"
+)
+
+// Stores the schema is resolved with
+type Stores struct {
+	Locations     data.LocationStore
+	Events        data.EventStore
+	Organizations data.OrganizationStore
+	Persons       data.PersonStore
+	Rooms         data.RoomStore
+	Topics        data.TopicStore
+	Tracks        data.TrackStore
+	Talks         data.TalkStore
+	TalkDates     data.TalkDateStore
+}
+
+type resolver struct {
+	stores Stores
+	log    hclog.Logger
+}
+
+// types of the schema, created up front so that the relations between them can refer to each other
+type types struct {
+	address      *graphql.Object
+	location     *graphql.Object
+	event        *graphql.Object
+	organization *graphql.Object
+	person       *graphql.Object
+	room         *graphql.Object
+	topic        *graphql.Object
+	track        *graphql.Object
+	talk         *graphql.Object
+	talkDate     *graphql.Object
+	talkLevel    *graphql.Enum
+	talkFormat   *graphql.Enum
+}
+
+// NewSchema creates the schema of all entities and their relations. Relations which were not preloaded by the
+// stores are loaded in batches, one store call per relation and level of the query.
+func NewSchema(stores Stores, log hclog.Logger) (graphql.Schema, error) {
+	r := &resolver{stores, log}
+	t := &types{}
+
+	t.talkLevel = graphql.NewEnum(graphql.EnumConfig{
+		Name: "TalkLevel",
+		Values: graphql.EnumValueConfigMap{
+			"BEGINNER": &graphql.EnumValueConfig{Value: data.BeginnerLevel},
+			"ADVANCED": &graphql.EnumValueConfig{Value: data.AdvancedLevel},
+			"EXPERT":   &graphql.EnumValueConfig{Value: data.ExpertLevel},
+		},
+	})
+
+	t.talkFormat = graphql.NewEnum(graphql.EnumConfig{
+		Name: "TalkFormat",
+		Values: graphql.EnumValueConfigMap{
+			"KEYNOTE":   &graphql.EnumValueConfig{Value: data.KeynoteFormat},
+			"TALK":      &graphql.EnumValueConfig{Value: data.RegularFormat},
+			"WORKSHOP":  &graphql.EnumValueConfig{Value: data.WorkshopFormat},
+			"PANEL":     &graphql.EnumValueConfig{Value: data.PanelFormat},
+			"LIGHTNING": &graphql.EnumValueConfig{Value: data.LightningFormat},
+		},
+	})
+
+	t.address = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Address",
+		Fields: graphql.Fields{
+			"street":     &graphql.Field{Type: graphql.String},
+			"postalCode": &graphql.Field{Type: graphql.String},
+			"city":       &graphql.Field{Type: graphql.String},
+			"country":    &graphql.Field{Type: graphql.String},
+		},
+	})
+
+	t.location = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Location",
+		Fields: graphql.Fields{
+			"id":          &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"name":        &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"address":     &graphql.Field{Type: graphql.NewNonNull(t.address)},
+			"latitude":    &graphql.Field{Type: graphql.Float},
+			"longitude":   &graphql.Field{Type: graphql.Float},
+			"timezone":    &graphql.Field{Type: graphql.String},
+			"description": &graphql.Field{Type: graphql.String},
+			// set by the locationsNear query only
+			"distance": &graphql.Field{Type: graphql.Float},
+		},
+	})
+
+	t.organization = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Organization",
+		Fields: graphql.Fields{
+			"id":   &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"name": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		},
+	})
+
+	t.event = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Event",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":           &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+				"name":         &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"beginDate":    &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime), Resolve: r.eventBeginDate},
+				"endDate":      &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime), Resolve: r.eventEndDate},
+				"timezone":     &graphql.Field{Type: graphql.String},
+				"location":     &graphql.Field{Type: t.location, Resolve: r.eventLocation},
+				"organization": &graphql.Field{Type: t.organization, Resolve: r.eventOrganization},
+				"talkDates":    &graphql.Field{Type: listOf(t.talkDate), Resolve: r.eventTalkDates},
+			}
+		}),
+	})
+
+	t.person = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Person",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":           &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+				"name":         &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"jobTitle":     &graphql.Field{Type: graphql.String},
+				"bio":          &graphql.Field{Type: graphql.String},
+				"photoUrl":     &graphql.Field{Type: graphql.String},
+				"organization": &graphql.Field{Type: t.organization, Resolve: r.personOrganization},
+				"talks":        &graphql.Field{Type: listOf(t.talk), Resolve: r.personTalks},
+			}
+		}),
+	})
+
+	t.room = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Room",
+		Fields: graphql.Fields{
+			"id":           &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"name":         &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"organization": &graphql.Field{Type: t.organization, Resolve: r.roomOrganization},
+			"location":     &graphql.Field{Type: t.location, Resolve: r.roomLocation},
+		},
+	})
+
+	t.topic = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Topic",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":       &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+				"name":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"children": &graphql.Field{Type: listOf(t.topic), Resolve: r.topicChildren},
+			}
+		}),
+	})
+
+	t.track = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Track",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":    &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+				"name":  &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"color": &graphql.Field{Type: graphql.String},
+				"event": &graphql.Field{Type: t.event, Resolve: r.trackEvent},
+			}
+		}),
+	})
+
+	t.talk = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Talk",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":                &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+				"title":             &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"durationInMinutes": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+				"language":          &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"level":             &graphql.Field{Type: graphql.NewNonNull(t.talkLevel)},
+				"format":            &graphql.Field{Type: graphql.NewNonNull(t.talkFormat)},
+				"abstract":          &graphql.Field{Type: graphql.String},
+				"prerequisites":     &graphql.Field{Type: graphql.String},
+				"slidesUrl":         &graphql.Field{Type: graphql.String},
+				"videoUrl":          &graphql.Field{Type: graphql.String},
+				"repositoryUrl":     &graphql.Field{Type: graphql.String},
+				"track":             &graphql.Field{Type: t.track, Resolve: r.talkTrack},
+				"persons":           &graphql.Field{Type: listOf(t.person), Resolve: r.talkPersons},
+				"topics":            &graphql.Field{Type: listOf(t.topic), Resolve: r.talkTopics},
+				"talkDates":         &graphql.Field{Type: listOf(t.talkDate), Resolve: r.talkTalkDates},
+				"organization":      &graphql.Field{Type: t.organization, Resolve: r.talkOrganization},
+			}
+		}),
+	})
+
+	t.talkDate = graphql.NewObject(graphql.ObjectConfig{
+		Name: "TalkDate",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":        &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+				"beginDate": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime), Resolve: r.talkDateBeginDate},
+				"talk":      &graphql.Field{Type: t.talk, Resolve: r.talkDateTalk},
+				"room":      &graphql.Field{Type: t.room, Resolve: r.talkDateRoom},
+				"event":     &graphql.Field{Type: t.event, Resolve: r.talkDateEvent},
+				"location":  &graphql.Field{Type: t.location, Resolve: r.talkDateLocation},
+				// the rule, exceptions and occurrences of the series the talk date begins
+				"recurrence":  &graphql.Field{Type: graphql.String},
+				"exceptions":  &graphql.Field{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+				"occurrences": &graphql.Field{Type: listOf(t.talkDate), Resolve: r.talkDateOccurrences},
+				// the date the rule of its series gives the talk date, and the first talk date of that series
+				"recurrenceDate": &graphql.Field{Type: graphql.DateTime, Resolve: r.talkDateRecurrenceDate},
+				"series":         &graphql.Field{Type: t.talkDate, Resolve: r.talkDateSeries},
+			}
+		}),
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:    r.queryType(t),
+		Mutation: r.mutationType(t),
+	})
+}
+
+func (r *resolver) queryType(t *types) *graphql.Object {
+	idArgs := graphql.FieldConfigArgument{"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)}}
+
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"locations": &graphql.Field{Type: listOf(t.location), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				locations, err := r.stores.Locations.GetLocations(p.Context)
+				return locations, r.publicError(p, err)
+			}},
+			"location": &graphql.Field{Type: t.location, Args: idArgs, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				id, err := idArg(p, "id")
+				if err != nil {
+					return nil, err
+				}
+				location, err := r.stores.Locations.GetLocationByID(p.Context, id)
+				return orNull(location, r.publicError(p, err))
+			}},
+			"locationsNear": &graphql.Field{Type: listOf(t.location), Args: graphql.FieldConfigArgument{
+				"lat": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Float)},
+				"lng": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Float)},
+				// in kilometers
+				"radius": &graphql.ArgumentConfig{Type: graphql.Float, DefaultValue: 10.0},
+			}, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				latitude, _ := p.Args["lat"].(float64)
+				longitude, _ := p.Args["lng"].(float64)
+				radius, _ := p.Args["radius"].(float64)
+				locations, err := r.stores.Locations.GetLocationsNear(p.Context, latitude, longitude, radius)
+				return locations, r.publicError(p, err)
+			}},
+			"events": &graphql.Field{Type: listOf(t.event), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				events, err := r.stores.Events.GetEvents(p.Context)
+				return events, r.publicError(p, err)
+			}},
+			"event": &graphql.Field{Type: t.event, Args: idArgs, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				id, err := idArg(p, "id")
+				if err != nil {
+					return nil, err
+				}
+				event, err := r.stores.Events.GetEventByID(p.Context, id)
+				return orNull(event, r.publicError(p, err))
+			}},
+			"organizations": &graphql.Field{Type: listOf(t.organization), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				organizations, err := r.stores.Organizations.GetOrganizations(p.Context)
+				return organizations, r.publicError(p, err)
+			}},
+			"organization": &graphql.Field{Type: t.organization, Args: idArgs, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				id, err := idArg(p, "id")
+				if err != nil {
+					return nil, err
+				}
+				organization, err := r.stores.Organizations.GetOrganizationByID(p.Context, id)
+				return orNull(organization, r.publicError(p, err))
+			}},
+			"persons": &graphql.Field{Type: listOf(t.person), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				persons, err := r.stores.Persons.GetPersons(p.Context)
+				return persons, r.publicError(p, err)
+			}},
+			"person": &graphql.Field{Type: t.person, Args: idArgs, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				id, err := idArg(p, "id")
+				if err != nil {
+					return nil, err
+				}
+				person, err := r.stores.Persons.GetPersonByID(p.Context, id)
+				return orNull(person, r.publicError(p, err))
+			}},
+			"rooms": &graphql.Field{Type: listOf(t.room), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				rooms, err := r.stores.Rooms.GetRooms(p.Context)
+				return rooms, r.publicError(p, err)
+			}},
+			"room": &graphql.Field{Type: t.room, Args: idArgs, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				id, err := idArg(p, "id")
+				if err != nil {
+					return nil, err
+				}
+				room, err := r.stores.Rooms.GetRoomByID(p.Context, id)
+				return orNull(room, r.publicError(p, err))
+			}},
+			"topics": &graphql.Field{Type: listOf(t.topic), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				topics, err := r.stores.Topics.GetTopics(p.Context)
+				return topics, r.publicError(p, err)
+			}},
+			"topic": &graphql.Field{Type: t.topic, Args: idArgs, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				id, err := idArg(p, "id")
+				if err != nil {
+					return nil, err
+				}
+				topic, err := r.stores.Topics.GetTopicByID(p.Context, id)
+				return orNull(topic, r.publicError(p, err))
+			}},
+			"tracks": &graphql.Field{Type: listOf(t.track), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				tracks, err := r.stores.Tracks.GetTracks(p.Context)
+				return tracks, r.publicError(p, err)
+			}},
+			"track": &graphql.Field{Type: t.track, Args: idArgs, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				id, err := idArg(p, "id")
+				if err != nil {
+					return nil, err
+				}
+				track, err := r.stores.Tracks.GetTrackByID(p.Context, id)
+				return orNull(track, r.publicError(p, err))
+			}},
+			"talks": &graphql.Field{Type: listOf(t.talk), Args: graphql.FieldConfigArgument{
+				"format":   &graphql.ArgumentConfig{Type: t.talkFormat},
+				"level":    &graphql.ArgumentConfig{Type: t.talkLevel},
+				"language": &graphql.ArgumentConfig{Type: graphql.String},
+				"trackId":  &graphql.ArgumentConfig{Type: graphql.ID},
+			}, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				filter := data.TalkFilter{}
+				filter.Format, _ = p.Args["format"].(data.TalkFormat)
+				filter.Level, _ = p.Args["level"].(data.TalkLevel)
+				filter.Language, _ = p.Args["language"].(string)
+				if _, ok := p.Args["trackId"]; ok {
+					trackID, err := idArg(p, "trackId")
+					if err != nil {
+						return nil, err
+					}
+					filter.TrackID = trackID
+				}
+				talks, err := r.stores.Talks.GetTalks(p.Context, filter)
+				return talks, r.publicError(p, err)
+			}},
+			"talk": &graphql.Field{Type: t.talk, Args: idArgs, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				id, err := idArg(p, "id")
+				if err != nil {
+					return nil, err
+				}
+				talk, err := r.stores.Talks.GetTalkByID(p.Context, id)
+				return orNull(talk, r.publicError(p, err))
+			}},
+			"talkDates": &graphql.Field{Type: listOf(t.talkDate), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				talkDates, err := r.stores.TalkDates.GetTalkDates(p.Context)
+				return talkDates, r.publicError(p, err)
+			}},
+			"talkDate": &graphql.Field{Type: t.talkDate, Args: idArgs, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				id, err := idArg(p, "id")
+				if err != nil {
+					return nil, err
+				}
+				talkDate, err := r.stores.TalkDates.GetTalkDateByID(p.Context, id)
+				return orNull(talkDate, r.publicError(p, err))
+			}},
+			"talkDateSeries": &graphql.Field{Type: listOf(t.talkDate), Args: idArgs, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				id, err := idArg(p, "id")
+				if err != nil {
+					return nil, err
+				}
+				talkDates, err := r.stores.TalkDates.GetTalkDatesBySeriesID(p.Context, id)
+				return talkDates, r.publicError(p, err)
+			}},
+		},
+	})
+}
+
+// eventBeginDate and eventEndDate render the dates in the requested time zone, or else in the time zone of the event
+func (r *resolver) eventBeginDate(p graphql.ResolveParams) (interface{}, error) {
+	event := p.Source.(*data.Event)
+	return data.In(event.BeginDate, event.Zone(data.TimezoneOf(p.Context))), nil
+}
+
+func (r *resolver) eventEndDate(p graphql.ResolveParams) (interface{}, error) {
+	event := p.Source.(*data.Event)
+	return data.In(event.EndDate, event.Zone(data.TimezoneOf(p.Context))), nil
+}
+
+func (r *resolver) eventLocation(p graphql.ResolveParams) (interface{}, error) {
+	event := p.Source.(*data.Event)
+	if event.Location != nil {
+		return event.Location, nil
+	}
+	if event.LocationID == nil {
+		return nil, nil
+	}
+	return r.load(p, loadersFrom(p.Context).locations, *event.LocationID, nil)
+}
+
+// eventOrganization resolves to null for events created before tenancy, which belong to no organization
+func (r *resolver) eventOrganization(p graphql.ResolveParams) (interface{}, error) {
+	event := p.Source.(*data.Event)
+	if event.Organization != nil {
+		return event.Organization, nil
+	}
+	if event.OrganizationID == nil {
+		return nil, nil
+	}
+	return r.load(p, loadersFrom(p.Context).organizations, *event.OrganizationID, nil)
+}
+
+func (r *resolver) eventTalkDates(p graphql.ResolveParams) (interface{}, error) {
+	event := p.Source.(*data.Event)
+	return r.load(p, loadersFrom(p.Context).talkDatesByEvent, event.ID, nil)
+}
+
+func (r *resolver) personOrganization(p graphql.ResolveParams) (interface{}, error) {
+	person := p.Source.(*data.Person)
+	if person.Organization != nil {
+		return person.Organization, nil
+	}
+	return r.load(p, loadersFrom(p.Context).organizations, person.OrganizationID, nil)
+}
+
+func (r *resolver) personTalks(p graphql.ResolveParams) (interface{}, error) {
+	person := p.Source.(*data.Person)
+	return r.load(p, loadersFrom(p.Context).talksByPerson, person.ID, nil)
+}
+
+func (r *resolver) roomOrganization(p graphql.ResolveParams) (interface{}, error) {
+	room := p.Source.(*data.Room)
+	if room.Organization != nil {
+		return room.Organization, nil
+	}
+	return r.load(p, loadersFrom(p.Context).organizations, room.OrganizationID, nil)
+}
+
+func (r *resolver) roomLocation(p graphql.ResolveParams) (interface{}, error) {
+	room := p.Source.(*data.Room)
+	if room.Location != nil {
+		return room.Location, nil
+	}
+	if room.LocationID == nil {
+		return nil, nil
+	}
+	return r.load(p, loadersFrom(p.Context).locations, *room.LocationID, nil)
+}
+
+// topicChildren loads the topic again when its children were not preloaded, as for the children of children
+func (r *resolver) topicChildren(p graphql.ResolveParams) (interface{}, error) {
+	topic := p.Source.(*data.Topic)
+	if topic.Children != nil {
+		return topicPointers(topic.Children), nil
+	}
+	return r.load(p, loadersFrom(p.Context).topics, topic.ID, func(v interface{}) interface{} {
+		return topicPointers(v.(*data.Topic).Children)
+	})
+}
+
+func (r *resolver) trackEvent(p graphql.ResolveParams) (interface{}, error) {
+	track := p.Source.(*data.Track)
+	if track.Event != nil {
+		return track.Event, nil
+	}
+	return r.load(p, loadersFrom(p.Context).events, track.EventID, nil)
+}
+
+// talkTrack resolves to null for talks which are in no track
+func (r *resolver) talkTrack(p graphql.ResolveParams) (interface{}, error) {
+	talk := p.Source.(*data.Talk)
+	if talk.Track != nil {
+		return talk.Track, nil
+	}
+	if talk.TrackID == nil {
+		return nil, nil
+	}
+	return r.load(p, loadersFrom(p.Context).tracks, *talk.TrackID, nil)
+}
+
+// talkPersons, talkTopics and talkTalkDates load the talk again when the relation was not preloaded,
+// as for talks reached through their talk dates or persons
+func (r *resolver) talkPersons(p graphql.ResolveParams) (interface{}, error) {
+	talk := p.Source.(*data.Talk)
+	if talk.Persons != nil {
+		return personPointers(talk.Persons), nil
+	}
+	return r.load(p, loadersFrom(p.Context).talks, talk.ID, func(v interface{}) interface{} {
+		return personPointers(v.(*data.Talk).Persons)
+	})
+}
+
+func (r *resolver) talkTopics(p graphql.ResolveParams) (interface{}, error) {
+	talk := p.Source.(*data.Talk)
+	if talk.Topics != nil {
+		return topicPointers(talk.Topics), nil
+	}
+	return r.load(p, loadersFrom(p.Context).talks, talk.ID, func(v interface{}) interface{} {
+		return topicPointers(v.(*data.Talk).Topics)
+	})
+}
+
+func (r *resolver) talkTalkDates(p graphql.ResolveParams) (interface{}, error) {
+	talk := p.Source.(*data.Talk)
+	if talk.TalkDates != nil {
+		return talkDatePointers(talk.TalkDates), nil
+	}
+	return r.load(p, loadersFrom(p.Context).talks, talk.ID, func(v interface{}) interface{} {
+		return talkDatePointers(v.(*data.Talk).TalkDates)
+	})
+}
+
+// talkOrganization resolves to null for talks created before tenancy, which belong to no organization
+func (r *resolver) talkOrganization(p graphql.ResolveParams) (interface{}, error) {
+	talk := p.Source.(*data.Talk)
+	if talk.Organization != nil {
+		return talk.Organization, nil
+	}
+	if talk.OrganizationID == nil {
+		return nil, nil
+	}
+	return r.load(p, loadersFrom(p.Context).organizations, *talk.OrganizationID, nil)
+}
+
+// talkDateBeginDate renders the begin date in the requested time zone, or else in the time zone of the talk date
+func (r *resolver) talkDateBeginDate(p graphql.ResolveParams) (interface{}, error) {
+	talkDate := p.Source.(*data.TalkDate)
+	return data.In(talkDate.BeginDate, talkDate.Zone(data.TimezoneOf(p.Context))), nil
+}
+
+// talkDateRecurrenceDate renders the recurrence date like the begin date
+func (r *resolver) talkDateRecurrenceDate(p graphql.ResolveParams) (interface{}, error) {
+	talkDate := p.Source.(*data.TalkDate)
+	if talkDate.RecurrenceDate == nil {
+		return nil, nil
+	}
+	return data.In(*talkDate.RecurrenceDate, talkDate.Zone(data.TimezoneOf(p.Context))), nil
+}
+
+// talkDateOccurrences resolves to the talk dates of the series the talk date begins, itself included,
+// and to null for talk dates beginning no series
+func (r *resolver) talkDateOccurrences(p graphql.ResolveParams) (interface{}, error) {
+	talkDate := p.Source.(*data.TalkDate)
+	if talkDate.Recurrence == "" {
+		return nil, nil
+	}
+	talkDates, err := r.stores.TalkDates.GetTalkDatesBySeriesID(p.Context, talkDate.ID)
+	return talkDates, r.publicError(p, err)
+}
+
+// talkDateSeries resolves to null for talk dates which are no occurrence of a series
+func (r *resolver) talkDateSeries(p graphql.ResolveParams) (interface{}, error) {
+	talkDate := p.Source.(*data.TalkDate)
+	if talkDate.Series != nil {
+		return talkDate.Series, nil
+	}
+	if talkDate.SeriesID == nil {
+		return nil, nil
+	}
+	series, err := r.stores.TalkDates.GetTalkDateByID(p.Context, *talkDate.SeriesID)
+	return orNull(series, r.publicError(p, err))
+}
+
+func (r *resolver) talkDateTalk(p graphql.ResolveParams) (interface{}, error) {
+	talkDate := p.Source.(*data.TalkDate)
+	if talkDate.Talk != nil {
+		return talkDate.Talk, nil
+	}
+	return r.load(p, loadersFrom(p.Context).talks, talkDate.TalkID, nil)
+}
+
+func (r *resolver) talkDateRoom(p graphql.ResolveParams) (interface{}, error) {
+	talkDate := p.Source.(*data.TalkDate)
+	if talkDate.Room != nil {
+		return talkDate.Room, nil
+	}
+	if talkDate.RoomID == nil {
+		return nil, nil
+	}
+	return r.load(p, loadersFrom(p.Context).rooms, *talkDate.RoomID, nil)
+}
+
+func (r *resolver) talkDateEvent(p graphql.ResolveParams) (interface{}, error) {
+	talkDate := p.Source.(*data.TalkDate)
+	if talkDate.Event != nil {
+		return talkDate.Event, nil
+	}
+	if talkDate.EventID == nil {
+		return nil, nil
+	}
+	return r.load(p, loadersFrom(p.Context).events, *talkDate.EventID, nil)
+}
+
+func (r *resolver) talkDateLocation(p graphql.ResolveParams) (interface{}, error) {
+	talkDate := p.Source.(*data.TalkDate)
+	if talkDate.Location != nil {
+		return talkDate.Location, nil
+	}
+	if talkDate.LocationID == nil {
+		return nil, nil
+	}
+	return r.load(p, loadersFrom(p.Context).locations, *talkDate.LocationID, nil)
+}
+
+// load resolves to the value the loader finds for the key, passed through then if given.
+// A zero key is an unset foreign key and resolves to null.
+func (r *resolver) load(p graphql.ResolveParams, l *loader, key uint, then func(interface{}) interface{}) (interface{}, error) {
+	if key == 0 {
+		return nil, nil
+	}
+
+	thunk := l.load(p.Context, key)
+	return func() (interface{}, error) {
+		v, err := thunk()
+		if err != nil {
+			return nil, r.publicError(p, err)
+		}
+		if v == nil || then == nil {
+			return v, nil
+		}
+		return then(v), nil
+	}, nil
+}
+
+func (r *resolver) publicError(p graphql.ResolveParams, err error) error {
+	return publicError(err, logging.FromContext(p.Context, r.log))
+}
+
+// orNull resolves entities which were not found to null, as GraphQL clients expect from a lookup by id
+func orNull(v interface{}, err error) (interface{}, error) {
+	var gqlErr *Error
+	if errors.As(err, &gqlErr) && gqlErr.Code == codeNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func idArg(p graphql.ResolveParams, name string) (uint, error) {
+	raw, _ := p.Args[name].(string)
+	id, err := strconv.ParseUint(raw, 10, 32)
+	if err != nil {
+		return 0, &Error{Message: name + " must be a positive integer, was " + raw, Code: codeValidation}
+	}
+	return uint(id), nil
+}
+
+func listOf(t graphql.Type) graphql.Type {
+	return graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(t)))
+}
+
+func personPointers(persons []data.Person) []*data.Person {
+	pointers := make([]*data.Person, len(persons))
+	for i := range persons {
+		pointers[i] = &persons[i]
+	}
+	return pointers
+}
+
+func topicPointers(topics []data.Topic) []*data.Topic {
+	pointers := make([]*data.Topic, len(topics))
+	for i := range topics {
+		pointers[i] = &topics[i]
+	}
+	return pointers
+}
+
+func talkDatePointers(talkDates []data.TalkDate) []*data.TalkDate {
+	pointers := make([]*data.TalkDate, len(talkDates))
+	for i := range talkDates {
+		pointers[i] = &talkDates[i]
+	}
+	return pointers
+}
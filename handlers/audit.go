@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"github.com/hashicorp/go-hclog"
+	"github.com/milutindzunic/pac-backend/data"
+	"github.com/milutindzunic/pac-backend/logging"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type AuditHandler struct {
+	log   hclog.Logger
+	store data.AuditStore
+}
+
+func NewAuditHandler(store data.AuditStore, log hclog.Logger) *AuditHandler {
+	return &AuditHandler{log, store}
+}
+
+func (lh *AuditHandler) GetAuditEntries(rw http.ResponseWriter, r *http.Request) {
+	filter, err := readAuditFilter(r)
+	if err != nil {
+		writeProblem(err, rw, r)
+		return
+	}
+
+	entries, err := lh.store.GetAuditEntries(r.Context(), filter)
+	if err != nil {
+		writeProblem(err, rw, r)
+		return
+	}
+
+	err = writeJSONWithStatus(entries, rw, r, http.StatusOK)
+	if err != nil {
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
+		return
+	}
+}
+
+// ExportAuditEntries writes the audit entries matching the filter as a CSV file, for compliance archiving
+func (lh *AuditHandler) ExportAuditEntries(rw http.ResponseWriter, r *http.Request) {
+	filter, err := readAuditFilter(r)
+	if err != nil {
+		writeProblem(err, rw, r)
+		return
+	}
+
+	entries, err := lh.store.GetAuditEntries(r.Context(), filter)
+	if err != nil {
+		writeProblem(err, rw, r)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "text/csv")
+	rw.Header().Set("Content-Disposition", `attachment; filename="audit.csv"`)
+	rw.WriteHeader(http.StatusOK)
+
+	w := csv.NewWriter(rw)
+	_ = w.Write([]string{"id", "timestamp", "actorSubject", "actorName", "action", "entity", "entityId", "changes"})
+	for _, e := range entries {
+		_ = w.Write([]string{
+			strconv.FormatUint(uint64(e.ID), 10),
+			e.Timestamp.UTC().Format(time.RFC3339Nano),
+			e.ActorSubject,
+			e.ActorName,
+			e.Action,
+			e.Entity,
+			strconv.FormatUint(uint64(e.EntityID), 10),
+			string(e.Changes),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		logging.FromContext(r.Context(), lh.log).Error("Error writing audit export", "err", err)
+	}
+}
+
+// readAuditFilter reads the entity, id, actor, since and until query parameters
+func readAuditFilter(r *http.Request) (data.AuditFilter, error) {
+	query := r.URL.Query()
+	filter := data.AuditFilter{
+		Entity: query.Get("entity"),
+		Actor:  query.Get("actor"),
+	}
+
+	verr := &data.ValidationError{Entity: "Query"}
+	if id := query.Get("id"); id != "" {
+		parsed, err := strconv.ParseUint(id, 10, 32)
+		if err != nil {
+			verr.Errors = append(verr.Errors, data.FieldError{Field: "id", Rule: "type", Message: "must be a positive number"})
+		}
+		filter.EntityID = uint(parsed)
+	}
+	for param, dst := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		if value := query.Get(param); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				verr.Errors = append(verr.Errors, data.FieldError{Field: param, Rule: "format", Message: "must be an RFC3339 timestamp"})
+			}
+			*dst = parsed
+		}
+	}
+
+	if len(verr.Errors) > 0 {
+		return filter, verr
+	}
+	return filter, nil
+}
@@ -0,0 +1,276 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/hashicorp/go-hclog"
+	"github.com/milutindzunic/pac-backend/data"
+	"github.com/milutindzunic/pac-backend/logging"
+	"github.com/milutindzunic/pac-backend/problem"
+	"net/http"
+	"reflect"
+	"strconv"
+)
+
+// Operations of a batch
+const (
+	batchCreate = "create"
+	batchUpdate = "update"
+	batchDelete = "delete"
+)
+
+// BatchRequest is the body of a batch request. The operations run in order; when atomic is set they all run
+// in a single transaction and are rolled back together if one fails, otherwise each one succeeds or fails on its own.
+type BatchRequest struct {
+	Atomic     bool             `json:"atomic"`
+	Operations []BatchOperation `json:"operations"`
+}
+
+// BatchOperation is a create, update or delete of a single entity, taking the same body as the single-item endpoints
+type BatchOperation struct {
+	Op      string          `json:"op"`
+	ID      uint            `json:"id,omitempty"`
+	Cascade bool            `json:"cascade,omitempty"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// BatchResponse reports the outcome of every operation of a batch, in the order of the request
+type BatchResponse struct {
+	Atomic    bool          `json:"atomic"`
+	Succeeded int           `json:"succeeded"`
+	Failed    int           `json:"failed"`
+	Results   []BatchResult `json:"results"`
+}
+
+// BatchResult is the outcome of an operation: the status the single-item endpoint would have answered with,
+// along with the written entity or the problem
+type BatchResult struct {
+	Index  int              `json:"index"`
+	Op     string           `json:"op"`
+	Status int              `json:"status"`
+	ID     uint             `json:"id,omitempty"`
+	Data   interface{}      `json:"data,omitempty"`
+	Error  *problem.Problem `json:"error,omitempty"`
+}
+
+// BatchOperations are the single-item operations of an entity which its batches run
+type BatchOperations struct {
+	Entity string
+	// New returns a pointer to an empty entity, which the data of create and update operations is read into
+	New    func() interface{}
+	Create func(ctx context.Context, entity interface{}) (interface{}, error)
+	Update func(ctx context.Context, id uint, entity interface{}) (interface{}, error)
+	Delete func(ctx context.Context, id uint, cascade bool) error
+}
+
+// errBatchAborted rolls back the transaction of an atomic batch when one of its operations fails
+var errBatchAborted = errors.New("batch aborted")
+
+type BatchHandler struct {
+	log           hclog.Logger
+	transactor    *data.Transactor
+	maxOperations int
+}
+
+func NewBatchHandler(transactor *data.Transactor, maxOperations int, log hclog.Logger) *BatchHandler {
+	return &BatchHandler{log, transactor, maxOperations}
+}
+
+// For returns the handler of the batches of the entity. The operations run through the same stores as the
+// single-item endpoints, so they are validated and checked for conflicts the same way.
+func (bh *BatchHandler) For(ops BatchOperations) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		log := logging.FromContext(r.Context(), bh.log)
+
+		req := &BatchRequest{}
+		if err := readJSON(r.Body, req); err != nil {
+			log.Error("Error deserializing batch", "err", err)
+			writeProblem(err, rw, r)
+			return
+		}
+		if err := bh.validate(req); err != nil {
+			writeProblem(err, rw, r)
+			return
+		}
+
+		var resp *BatchResponse
+		var status int
+		if req.Atomic {
+			var err error
+			resp, status, err = bh.runAtomic(r.Context(), ops, req)
+			if err != nil {
+				log.Error("Error committing batch", "err", err)
+				writeProblem(err, rw, r)
+				return
+			}
+		} else {
+			resp, status = bh.runEach(r.Context(), ops, req)
+		}
+
+		if err := writeJSONWithStatus(resp, rw, r, status); err != nil {
+			log.Error("Error serializing entity", err)
+		}
+	})
+}
+
+// validate checks the batch as a whole, before any of its operations runs
+func (bh *BatchHandler) validate(req *BatchRequest) error {
+	var errs []data.FieldError
+	switch {
+	case len(req.Operations) == 0:
+		errs = append(errs, data.FieldError{Field: "operations", Rule: "required", Message: "must contain at least one operation"})
+	case len(req.Operations) > bh.maxOperations:
+		errs = append(errs, data.FieldError{Field: "operations", Rule: "max", Message: "must contain at most " + strconv.Itoa(bh.maxOperations) + " operations"})
+	}
+
+	for i, op := range req.Operations {
+		field := fmt.Sprintf("operations[%d]", i)
+		switch op.Op {
+		case batchCreate:
+			if len(op.Data) == 0 {
+				errs = append(errs, data.FieldError{Field: field + ".data", Rule: "required", Message: "is required to create an entity"})
+			}
+		case batchUpdate:
+			if op.ID == 0 {
+				errs = append(errs, data.FieldError{Field: field + ".id", Rule: "required", Message: "is required to update an entity"})
+			}
+			if len(op.Data) == 0 {
+				errs = append(errs, data.FieldError{Field: field + ".data", Rule: "required", Message: "is required to update an entity"})
+			}
+		case batchDelete:
+			if op.ID == 0 {
+				errs = append(errs, data.FieldError{Field: field + ".id", Rule: "required", Message: "is required to delete an entity"})
+			}
+		default:
+			errs = append(errs, data.FieldError{Field: field + ".op", Rule: "oneof", Message: "must be one of: [create, update, delete]"})
+		}
+	}
+
+	if len(errs) > 0 {
+		return &data.ValidationError{Entity: "Batch", Errors: errs}
+	}
+	return nil
+}
+
+// runEach runs every operation on its own. The batch is answered with 200 if all of them succeeded,
+// and with 207 otherwise.
+func (bh *BatchHandler) runEach(ctx context.Context, ops BatchOperations, req *BatchRequest) (*BatchResponse, int) {
+	resp := &BatchResponse{Atomic: false, Results: make([]BatchResult, 0, len(req.Operations))}
+	for i, op := range req.Operations {
+		result := bh.run(ctx, ops, i, op)
+		if result.Error != nil {
+			resp.Failed++
+		} else {
+			resp.Succeeded++
+		}
+		resp.Results = append(resp.Results, result)
+	}
+
+	if resp.Failed > 0 {
+		return resp, http.StatusMultiStatus
+	}
+	return resp, http.StatusOK
+}
+
+// runAtomic runs the operations in a single transaction, stopping at the first failure. The batch is then answered
+// with the status of the failed operation, while the other ones are reported as 424 Failed Dependency, as they were
+// rolled back or not run at all. The returned error is set if the transaction could not be committed.
+func (bh *BatchHandler) runAtomic(ctx context.Context, ops BatchOperations, req *BatchRequest) (*BatchResponse, int, error) {
+	resp := &BatchResponse{Atomic: true, Results: make([]BatchResult, 0, len(req.Operations))}
+	failed := -1
+
+	err := bh.transactor.InTransaction(ctx, func(ctx context.Context) error {
+		for i, op := range req.Operations {
+			result := bh.run(ctx, ops, i, op)
+			resp.Results = append(resp.Results, result)
+			if result.Error != nil {
+				failed = i
+				return errBatchAborted
+			}
+		}
+		return nil
+	})
+	if err != nil && err != errBatchAborted {
+		return nil, 0, err
+	}
+
+	if failed < 0 {
+		resp.Succeeded = len(resp.Results)
+		return resp, http.StatusOK, nil
+	}
+
+	for i, op := range req.Operations {
+		if i == failed {
+			continue
+		}
+		detail := fmt.Sprintf("Operation %d failed, the batch was rolled back", failed)
+		if i > failed {
+			detail = fmt.Sprintf("Operation %d failed, the operation was not run", failed)
+		}
+		aborted := &problem.Problem{Type: "/problems/batch-aborted", Title: "Batch aborted", Status: http.StatusFailedDependency, Detail: detail}
+		result := BatchResult{Index: i, Op: op.Op, Status: http.StatusFailedDependency, Error: aborted}
+		if i < failed {
+			resp.Results[i] = result
+		} else {
+			resp.Results = append(resp.Results, result)
+		}
+	}
+	resp.Failed = len(resp.Results)
+	return resp, resp.Results[failed].Status, nil
+}
+
+// run runs a single operation, reporting its outcome like the single-item endpoint would
+func (bh *BatchHandler) run(ctx context.Context, ops BatchOperations, index int, op BatchOperation) BatchResult {
+	result := BatchResult{Index: index, Op: op.Op}
+
+	var entity interface{}
+	var err error
+	switch op.Op {
+	case batchCreate:
+		entity = ops.New()
+		if err = readJSON(bytes.NewReader(op.Data), entity); err == nil {
+			entity, err = ops.Create(ctx, entity)
+		}
+		result.Status = http.StatusCreated
+	case batchUpdate:
+		entity = ops.New()
+		if err = readJSON(bytes.NewReader(op.Data), entity); err == nil {
+			entity, err = ops.Update(ctx, op.ID, entity)
+		}
+		result.Status = http.StatusOK
+	case batchDelete:
+		err = ops.Delete(ctx, op.ID, op.Cascade)
+		result.ID = op.ID
+		result.Status = http.StatusNoContent
+	}
+
+	if err != nil {
+		problem := problemFor(err)
+		if problem.Status == http.StatusInternalServerError {
+			logging.FromContext(ctx, bh.log).Error("Unexpected error running batch operation", "entity", ops.Entity, "index", index, "err", err)
+		}
+		return BatchResult{Index: index, Op: op.Op, Status: problem.Status, ID: op.ID, Error: &problem}
+	}
+
+	if entity != nil {
+		result.ID = entityID(entity)
+		result.Data = entity
+	}
+	return result
+}
+
+// entityID returns the ID field of the entity, which is a pointer to one of the structs of the data package
+func entityID(entity interface{}) uint {
+	v := reflect.Indirect(reflect.ValueOf(entity))
+	if v.Kind() != reflect.Struct {
+		return 0
+	}
+	id := v.FieldByName("ID")
+	if !id.IsValid() || id.Kind() != reflect.Uint {
+		return 0
+	}
+	return uint(id.Uint())
+}
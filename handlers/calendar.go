@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/milutindzunic/pac-backend/data"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// calendarProductID identifies the application as the author of the calendars, as PRODID
+const calendarProductID = "-//PAC//PAC Backend//EN"
+
+// icalTime is the layout of the UTC date-times of calendars
+const icalTime = "20060102T150405Z"
+
+// writeCalendar writes the talk dates as an RFC 5545 calendar with an event per talk date. A series is written as a
+// single event repeating by its rule, along with the occurrences which were moved or changed and, as exceptions,
+// the days which have no occurrence. The talks, rooms, events and locations of the talk dates are expected to be loaded.
+func writeCalendar(name string, talkDates []*data.TalkDate, rw http.ResponseWriter, status int) error {
+	c := &calendar{stamp: time.Now().UTC()}
+	c.line("BEGIN", "VCALENDAR")
+	c.line("VERSION", "2.0")
+	c.line("PRODID", calendarProductID)
+	c.line("CALSCALE", "GREGORIAN")
+	if name != "" {
+		c.line("X-WR-CALNAME", escapeText(name))
+	}
+
+	occurrences := map[uint][]*data.TalkDate{}
+	for _, talkDate := range talkDates {
+		if talkDate.SeriesID != nil {
+			occurrences[*talkDate.SeriesID] = append(occurrences[*talkDate.SeriesID], talkDate)
+		}
+	}
+	for _, talkDate := range talkDates {
+		switch {
+		case talkDate.Recurrence != "":
+			c.series(talkDate, occurrences[talkDate.ID])
+		case talkDate.SeriesID != nil && hasTalkDate(talkDates, *talkDate.SeriesID):
+			// written along with the first talk date of the series
+		default:
+			c.event(talkDate, talkDate.ID, nil)
+		}
+	}
+
+	c.line("END", "VCALENDAR")
+
+	rw.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	rw.WriteHeader(status)
+	_, err := rw.Write([]byte(c.String()))
+	return err
+}
+
+type calendar struct {
+	strings.Builder
+	stamp time.Time
+}
+
+// series writes the series beginning with the talk date, and the occurrences of it which differ from the rule
+func (c *calendar) series(first *data.TalkDate, occurrences []*data.TalkDate) {
+	rule, err := data.ParseRecurrence(first.Recurrence)
+	if err != nil || first.Event == nil || first.RecurrenceDate == nil {
+		c.event(first, first.ID, nil)
+		for _, occurrence := range occurrences {
+			c.event(occurrence, occurrence.ID, nil)
+		}
+		return
+	}
+
+	zone := first.Zone(nil)
+	dates := rule.Occurrences(*first.RecurrenceDate, zone, first.Event.EndDate)
+	given := map[int64]bool{}
+	for _, date := range dates {
+		given[date.Unix()] = true
+	}
+	active := map[int64]bool{first.RecurrenceDate.Unix(): true}
+	for _, occurrence := range occurrences {
+		if occurrence.RecurrenceDate != nil {
+			active[occurrence.RecurrenceDate.Unix()] = true
+		}
+	}
+
+	var exceptions []string
+	for _, date := range dates {
+		if !active[date.Unix()] {
+			exceptions = append(exceptions, date.Format(icalTime))
+		}
+	}
+
+	// the series begins where its rule does, also when its first talk date was moved on its own
+	start := *first
+	start.BeginDate = *first.RecurrenceDate
+	c.event(&start, first.ID, func() {
+		// calendars repeat the rule in UTC, a series changing its UTC time of day with daylight saving time is written
+		// date by date
+		bounded := rule.Bounded(dates[len(dates)-1])
+		if sameTimes(bounded.Occurrences(dates[0], time.UTC, dates[len(dates)-1]), dates) {
+			c.line("RRULE", bounded.String())
+		} else if len(dates) > 1 {
+			rdates := make([]string, 0, len(dates)-1)
+			for _, date := range dates[1:] {
+				rdates = append(rdates, date.Format(icalTime))
+			}
+			c.line("RDATE", strings.Join(rdates, ","))
+		}
+		if len(exceptions) > 0 {
+			c.line("EXDATE", strings.Join(exceptions, ","))
+		}
+	})
+
+	sort.Slice(occurrences, func(i, j int) bool { return occurrences[i].BeginDate.Before(occurrences[j].BeginDate) })
+	for _, occurrence := range append([]*data.TalkDate{first}, occurrences...) {
+		if occurrence.RecurrenceDate == nil || !given[occurrence.RecurrenceDate.Unix()] {
+			// not given by the rule, like the occurrences written before series followed the changes of their event
+			c.event(occurrence, occurrence.ID, nil)
+			continue
+		}
+		if occurrence.BeginDate.Equal(*occurrence.RecurrenceDate) && occurrence.SameReferences(first) {
+			continue
+		}
+		// occurrences changed on their own replace the ones the rule gives
+		c.event(occurrence, first.ID, func() {
+			c.line("RECURRENCE-ID", occurrence.RecurrenceDate.UTC().Format(icalTime))
+		})
+	}
+}
+
+// event writes a VEVENT for the talk date, identified by the talk date with the uid, with the properties written by
+// extra, if any
+func (c *calendar) event(talkDate *data.TalkDate, uid uint, extra func()) {
+	c.line("BEGIN", "VEVENT")
+	c.line("UID", fmt.Sprintf("talkdate-%d@pac-backend", uid))
+	c.line("DTSTAMP", c.stamp.Format(icalTime))
+	c.line("DTSTART", talkDate.BeginDate.UTC().Format(icalTime))
+	if talkDate.Talk != nil {
+		if talkDate.Talk.DurationInMinutes > 0 {
+			end := talkDate.BeginDate.Add(time.Duration(talkDate.Talk.DurationInMinutes) * time.Minute)
+			c.line("DTEND", end.UTC().Format(icalTime))
+		}
+		c.line("SUMMARY", escapeText(talkDate.Talk.Title))
+	}
+	var place []string
+	if talkDate.Room != nil {
+		place = append(place, talkDate.Room.Name)
+	}
+	if talkDate.Location != nil {
+		place = append(place, talkDate.Location.Name)
+	}
+	if len(place) > 0 {
+		c.line("LOCATION", escapeText(strings.Join(place, ", ")))
+	}
+	if extra != nil {
+		extra()
+	}
+	c.line("END", "VEVENT")
+}
+
+// line writes a content line, folded into lines of at most 75 octets
+func (c *calendar) line(name string, value string) {
+	line := name + ":" + value
+	// the folded lines begin with a space
+	for limit := 75; len(line) > limit; limit = 74 {
+		cut := limit
+		// lines are not folded within a UTF-8 sequence
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		c.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+	}
+	c.WriteString(line + "\r\n")
+}
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// escapeText escapes the characters with a meaning in the TEXT values of calendars
+func escapeText(text string) string {
+	return textEscaper.Replace(text)
+}
+
+func sameTimes(a []time.Time, b []time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasTalkDate(talkDates []*data.TalkDate, id uint) bool {
+	for _, talkDate := range talkDates {
+		if talkDate.ID == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"github.com/milutindzunic/pac-backend/data"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestWriteCalendar(t *testing.T) {
+	belgrade, _ := data.LoadTimezone("Europe/Belgrade")
+	at := func(day, hour int) *time.Time {
+		t := time.Date(2021, 3, day, hour, 0, 0, 0, belgrade)
+		return &t
+	}
+	event := &data.Event{ID: 1, Name: "Spring", BeginDate: *at(26, 0), EndDate: *at(31, 0), Timezone: "Europe/Belgrade"}
+	room := &data.Room{ID: 1, Name: "Sala 1"}
+	location := &data.Location{ID: 1, Name: "Novi Sad, Srbija"}
+	escaped := &data.Talk{ID: 1, Title: "Concurrency in Go; channels, mutexes & a \\ backslash\nin two lines", DurationInMinutes: 45}
+	// two octets per letter, the lines are folded between the letters
+	cyrillic := &data.Talk{ID: 2, Title: "Кеширање и трансакције у дистрибуираним системима: шта може да пође наопако", DurationInMinutes: 30}
+	seriesID, dstSeriesID := uint(2), uint(5)
+
+	talkDates := []*data.TalkDate{
+		{ID: 1, BeginDate: *at(26, 11), Talk: escaped, Room: room, Location: location, Event: event},
+		// a series in the same UTC time of day, with an occurrence moved and one deleted
+		{ID: 2, BeginDate: *at(28, 9), RecurrenceDate: at(28, 9), Recurrence: "FREQ=DAILY", Talk: cyrillic, Room: room, Event: event},
+		{ID: 3, BeginDate: *at(29, 14), RecurrenceDate: at(29, 9), SeriesID: &seriesID, Talk: cyrillic, Room: room, Event: event},
+		// a series changing its UTC time of day with daylight saving time
+		{ID: 5, BeginDate: *at(27, 18), RecurrenceDate: at(27, 18), Recurrence: "FREQ=DAILY;COUNT=2", Talk: escaped, Event: event},
+		{ID: 6, BeginDate: *at(28, 18), RecurrenceDate: at(28, 18), SeriesID: &dstSeriesID, Talk: escaped, Event: event},
+	}
+
+	rw := httptest.NewRecorder()
+	if err := writeCalendar("Spring; talks, 2021", talkDates, rw, http.StatusOK); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := rw.Header().Get("Content-Type"); got != "text/calendar; charset=utf-8" {
+		t.Errorf("Content-Type = %s", got)
+	}
+	want, err := ioutil.ReadFile("testdata/calendar.ics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the calendars are stamped with the time they are written
+	got := regexp.MustCompile(`DTSTAMP:\d{8}T\d{6}Z`).ReplaceAllString(rw.Body.String(), "DTSTAMP:20210301T000000Z")
+	if got != string(want) {
+		t.Errorf("calendar =\n%s\nwant\n%s", got, want)
+	}
+}
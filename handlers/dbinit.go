@@ -17,11 +17,12 @@ type DBInitHandler struct {
 	personStore       data.PersonStore
 	roomStore         data.RoomStore
 	topicStore        data.TopicStore
+	trackStore        data.TrackStore
 	talkStore         data.TalkStore
 	talkDateStore     data.TalkDateStore
 }
 
-func NewDBInitHandler(db *gorm.DB, ls data.LocationStore, es data.EventStore, os data.OrganizationStore, ps data.PersonStore, rs data.RoomStore, ts data.TopicStore, tlks data.TalkStore, tlkds data.TalkDateStore, logger hclog.Logger) *DBInitHandler {
+func NewDBInitHandler(db *gorm.DB, ls data.LocationStore, es data.EventStore, os data.OrganizationStore, ps data.PersonStore, rs data.RoomStore, ts data.TopicStore, trs data.TrackStore, tlks data.TalkStore, tlkds data.TalkDateStore, logger hclog.Logger) *DBInitHandler {
 	return &DBInitHandler{
 		db:                db,
 		logger:            logger,
@@ -31,6 +32,7 @@ func NewDBInitHandler(db *gorm.DB, ls data.LocationStore, es data.EventStore, os
 		personStore:       ps,
 		roomStore:         rs,
 		topicStore:        ts,
+		trackStore:        trs,
 		talkStore:         tlks,
 		talkDateStore:     tlkds,
 	}
@@ -39,7 +41,7 @@ func NewDBInitHandler(db *gorm.DB, ls data.LocationStore, es data.EventStore, os
 func (ih *DBInitHandler) Handle(rw http.ResponseWriter, r *http.Request) {
 	ih.logger.Debug("Init database endpoint called...")
 
-	database.Init(ih.db, ih.locationStore, ih.eventStore, ih.organizationStore, ih.personStore, ih.roomStore, ih.topicStore, ih.talkStore, ih.talkDateStore, ih.logger)
+	database.Init(r.Context(), ih.db, ih.locationStore, ih.eventStore, ih.organizationStore, ih.personStore, ih.roomStore, ih.topicStore, ih.trackStore, ih.talkStore, ih.talkDateStore, ih.logger)
 
 	rw.WriteHeader(http.StatusNoContent)
 }
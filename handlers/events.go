@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"context"
 	"github.com/hashicorp/go-hclog"
 	"github.com/milutindzunic/pac-backend/data"
+	"github.com/milutindzunic/pac-backend/logging"
 	"net/http"
 )
 
@@ -17,15 +19,21 @@ func NewEventsHandler(store data.EventStore, log hclog.Logger) *EventsHandler {
 
 func (lh *EventsHandler) GetEvents(rw http.ResponseWriter, r *http.Request) {
 
-	events, err := lh.store.GetEvents()
+	ctx, fields, err := readSelection(r, "Event")
 	if err != nil {
-		writeJSONErrorWithStatus("Error getting all entities", err.Error(), rw, http.StatusInternalServerError)
+		writeProblem(err, rw, r)
 		return
 	}
 
-	err = writeJSONWithStatus(events, rw, http.StatusOK)
+	events, err := lh.store.GetEvents(ctx)
 	if err != nil {
-		lh.log.Error("Error serializing entity", err)
+		writeProblem(err, rw, r)
+		return
+	}
+
+	err = writeJSONWithStatus(fields.apply(events), rw, r, http.StatusOK)
+	if err != nil {
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
 		return
 	}
 }
@@ -33,21 +41,21 @@ func (lh *EventsHandler) GetEvents(rw http.ResponseWriter, r *http.Request) {
 func (lh *EventsHandler) GetEvent(rw http.ResponseWriter, r *http.Request) {
 	id := readId(r)
 
-	event, err := lh.store.GetEventByID(id)
+	ctx, fields, err := readSelection(r, "Event")
 	if err != nil {
-		switch err.(type) {
-		case *data.EventNotFoundError:
-			writeJSONErrorWithStatus("Entity not found", err.Error(), rw, http.StatusNotFound)
-			return
-		default:
-			writeJSONErrorWithStatus("Unexpected error occurred", err.Error(), rw, http.StatusInternalServerError)
-			return
-		}
+		writeProblem(err, rw, r)
+		return
 	}
 
-	err = writeJSONWithStatus(event, rw, http.StatusOK)
+	event, err := lh.store.GetEventByID(ctx, id)
 	if err != nil {
-		lh.log.Error("Error serializing entity", err)
+		writeProblem(err, rw, r)
+		return
+	}
+
+	err = writeJSONWithStatus(fields.apply(event), rw, r, http.StatusOK)
+	if err != nil {
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
 		return
 	}
 }
@@ -57,20 +65,20 @@ func (lh *EventsHandler) CreateEvent(rw http.ResponseWriter, r *http.Request) {
 	event := &data.Event{}
 	err := readJSON(r.Body, event)
 	if err != nil {
-		lh.log.Error("Error deserializing entity", err)
-		writeJSONErrorWithStatus("Error deserializing entity", err.Error(), rw, http.StatusBadRequest)
+		logging.FromContext(r.Context(), lh.log).Error("Error deserializing entity", "err", err)
+		writeProblem(err, rw, r)
 		return
 	}
 
-	event, err = lh.store.AddEvent(event)
+	event, err = lh.store.AddEvent(r.Context(), event)
 	if err != nil {
-		writeJSONErrorWithStatus("Error creating entity", err.Error(), rw, http.StatusBadRequest)
+		writeProblem(err, rw, r)
 		return
 	}
 
-	err = writeJSONWithStatus(event, rw, http.StatusCreated)
+	err = writeJSONWithStatus(event, rw, r, http.StatusCreated)
 	if err != nil {
-		lh.log.Error("Error serializing entity", err)
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
 		return
 	}
 }
@@ -81,26 +89,20 @@ func (lh *EventsHandler) UpdateEvent(rw http.ResponseWriter, r *http.Request) {
 	event := &data.Event{}
 	err := readJSON(r.Body, event)
 	if err != nil {
-		lh.log.Error("Error deserializing entity", err)
-		writeJSONErrorWithStatus("Error deserializing entity", err.Error(), rw, http.StatusBadRequest)
+		logging.FromContext(r.Context(), lh.log).Error("Error deserializing entity", "err", err)
+		writeProblem(err, rw, r)
 		return
 	}
 
-	event, err = lh.store.UpdateEvent(id, event)
+	event, err = lh.store.UpdateEvent(r.Context(), id, event)
 	if err != nil {
-		switch err.(type) {
-		case *data.EventNotFoundError:
-			writeJSONErrorWithStatus("Entity not found", err.Error(), rw, http.StatusNotFound)
-			return
-		default:
-			writeJSONErrorWithStatus("Unexpected error occurred", err.Error(), rw, http.StatusInternalServerError)
-			return
-		}
+		writeProblem(err, rw, r)
+		return
 	}
 
-	err = writeJSONWithStatus(event, rw, http.StatusOK)
+	err = writeJSONWithStatus(event, rw, r, http.StatusOK)
 	if err != nil {
-		lh.log.Error("Error serializing entity", err)
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
 		return
 	}
 }
@@ -108,33 +110,64 @@ func (lh *EventsHandler) UpdateEvent(rw http.ResponseWriter, r *http.Request) {
 func (lh *EventsHandler) DeleteEvent(rw http.ResponseWriter, r *http.Request) {
 	id := readId(r)
 
-	err := lh.store.DeleteEventByID(id)
+	err := lh.store.DeleteEventByID(r.Context(), id, readCascade(r))
 	if err != nil {
-		switch err.(type) {
-		case *data.EventNotFoundError:
-			writeJSONErrorWithStatus("Entity not found", err.Error(), rw, http.StatusNotFound)
-			return
-		default:
-			writeJSONErrorWithStatus("Unexpected error occurred", err.Error(), rw, http.StatusInternalServerError)
-			return
-		}
+		writeProblem(err, rw, r)
+		return
 	}
 
 	rw.WriteHeader(http.StatusNoContent)
 }
 
+func (lh *EventsHandler) RestoreEvent(rw http.ResponseWriter, r *http.Request) {
+	id := readId(r)
+
+	event, err := lh.store.RestoreEventByID(r.Context(), id)
+	if err != nil {
+		writeProblem(err, rw, r)
+		return
+	}
+
+	err = writeJSONWithStatus(event, rw, r, http.StatusOK)
+	if err != nil {
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
+		return
+	}
+}
+
 func (lh *EventsHandler) GetEventsByTalkID(rw http.ResponseWriter, r *http.Request) {
 	talkID := readId(r)
 
-	events, err := lh.store.GetEventsByTalkID(talkID)
+	ctx, fields, err := readSelection(r, "Event")
 	if err != nil {
-		writeJSONErrorWithStatus("Error getting entities", err.Error(), rw, http.StatusInternalServerError)
+		writeProblem(err, rw, r)
 		return
 	}
 
-	err = writeJSONWithStatus(events, rw, http.StatusOK)
+	events, err := lh.store.GetEventsByTalkID(ctx, talkID)
 	if err != nil {
-		lh.log.Error("Error serializing entity", err)
+		writeProblem(err, rw, r)
 		return
 	}
-}
\ No newline at end of file
+
+	err = writeJSONWithStatus(fields.apply(events), rw, r, http.StatusOK)
+	if err != nil {
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
+		return
+	}
+}
+
+// BatchOperations returns the operations run by the batches of events
+func (lh *EventsHandler) BatchOperations() BatchOperations {
+	return BatchOperations{
+		Entity: "Event",
+		New:    func() interface{} { return &data.Event{} },
+		Create: func(ctx context.Context, entity interface{}) (interface{}, error) {
+			return lh.store.AddEvent(ctx, entity.(*data.Event))
+		},
+		Update: func(ctx context.Context, id uint, entity interface{}) (interface{}, error) {
+			return lh.store.UpdateEvent(ctx, id, entity.(*data.Event))
+		},
+		Delete: lh.store.DeleteEventByID,
+	}
+}
@@ -1,33 +1,162 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"github.com/hashicorp/go-hclog"
 	"github.com/jinzhu/gorm"
+	"github.com/milutindzunic/pac-backend/logging"
 	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// checkTimeout bounds each dependency check, so a hanging dependency cannot stall the probes
+const checkTimeout = 2 * time.Second
+
+// HealthCheck verifies that a dependency needed to serve requests is available
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// CheckResult is the outcome of a single HealthCheck
+type CheckResult struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// HealthReport lists the outcome of every dependency check
+type HealthReport struct {
+	Status       string        `json:"status"`
+	ShuttingDown bool          `json:"shuttingDown,omitempty"`
+	Checks       []CheckResult `json:"checks"`
+}
+
+const (
+	statusUp   = "up"
+	statusDown = "down"
 )
 
 type HealthHandler struct {
-	log hclog.Logger
-	db  *gorm.DB
+	log          hclog.Logger
+	db           *gorm.DB
+	checks       []HealthCheck
+	shuttingDown int32
+}
+
+// NewHealthHandler creates a handler checking the database connection and the additional checks
+func NewHealthHandler(db *gorm.DB, log hclog.Logger, checks ...HealthCheck) *HealthHandler {
+	hh := &HealthHandler{log: log, db: db}
+	hh.checks = append([]HealthCheck{{"database", hh.pingDatabase}}, checks...)
+	return hh
 }
 
-func NewHealthHandler(db *gorm.DB, log hclog.Logger) *HealthHandler {
-	return &HealthHandler{log, db}
+// SetShuttingDown makes the readiness probe fail, so the load balancer stops routing requests to the instance
+func (hh *HealthHandler) SetShuttingDown() {
+	atomic.StoreInt32(&hh.shuttingDown, 1)
+}
+
+func (hh *HealthHandler) isShuttingDown() bool {
+	return atomic.LoadInt32(&hh.shuttingDown) == 1
 }
 
 func (hh *HealthHandler) Handle(rw http.ResponseWriter, r *http.Request) {
-	hh.log.Debug("Health endpoint called...")
+	log := logging.FromContext(r.Context(), hh.log)
+	log.Debug("Health endpoint called...")
 
-	if err := hh.db.DB().Ping(); err != nil {
-		hh.log.Error("Error! Cannot ping database!", "err", err)
+	if err := hh.pingDatabase(r.Context()); err != nil {
+		log.Error("Error! Cannot ping database!", "err", err)
 		writeUnhealthy(rw)
 		return
 	}
 
-	hh.log.Debug("Returning healthy!")
+	log.Debug("Returning healthy!")
+	writeHealthy(rw)
+}
+
+// Live reports that the process is alive and able to serve requests, without checking any dependency
+func (hh *HealthHandler) Live(rw http.ResponseWriter, r *http.Request) {
 	writeHealthy(rw)
 }
 
+// Ready reports whether all dependencies are available and the instance is not shutting down
+func (hh *HealthHandler) Ready(rw http.ResponseWriter, r *http.Request) {
+	report := hh.runChecks(r.Context())
+	if report.Status != statusUp {
+		logging.FromContext(r.Context(), hh.log).Warn("Not ready", "report", hclog.Fmt("%+v", report))
+		writeUnhealthy(rw)
+		return
+	}
+	writeHealthy(rw)
+}
+
+// Report returns the outcome and latency of every dependency check
+func (hh *HealthHandler) Report(rw http.ResponseWriter, r *http.Request) {
+	report := hh.runChecks(r.Context())
+
+	status := http.StatusOK
+	if report.Status != statusUp {
+		status = http.StatusServiceUnavailable
+	}
+
+	err := writeJSONWithStatus(report, rw, r, status)
+	if err != nil {
+		logging.FromContext(r.Context(), hh.log).Error("Error serializing entity", err)
+		return
+	}
+}
+
+func (hh *HealthHandler) runChecks(ctx context.Context) HealthReport {
+	results := make([]CheckResult, len(hh.checks))
+
+	var wg sync.WaitGroup
+	for i, check := range hh.checks {
+		wg.Add(1)
+		go func(i int, check HealthCheck) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+			defer cancel()
+
+			start := time.Now()
+			err := check.Check(ctx)
+			results[i] = CheckResult{
+				Name:      check.Name,
+				Status:    statusUp,
+				LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+			}
+			if err != nil {
+				results[i].Status = statusDown
+				results[i].Error = err.Error()
+			}
+		}(i, check)
+	}
+	wg.Wait()
+
+	report := HealthReport{Status: statusUp, ShuttingDown: hh.isShuttingDown(), Checks: results}
+	if report.ShuttingDown {
+		report.Status = statusDown
+	}
+	for _, result := range results {
+		if result.Status != statusUp {
+			report.Status = statusDown
+		}
+	}
+	return report
+}
+
+func (hh *HealthHandler) pingDatabase(ctx context.Context) error {
+	sqlDB := hh.db.DB()
+	if sqlDB == nil {
+		return errors.New("database is not connected")
+	}
+	return sqlDB.PingContext(ctx)
+}
+
 func writeHealthy(rw http.ResponseWriter) {
 	rw.Header().Set("Content-Type", "text/plain")
 	rw.WriteHeader(http.StatusOK)
@@ -36,6 +165,6 @@ func writeHealthy(rw http.ResponseWriter) {
 
 func writeUnhealthy(rw http.ResponseWriter) {
 	rw.Header().Set("Content-Type", "text/plain")
-	rw.WriteHeader(http.StatusInternalServerError)
+	rw.WriteHeader(http.StatusServiceUnavailable)
 	_, _ = rw.Write([]byte("."))
 }
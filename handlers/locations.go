@@ -1,9 +1,13 @@
 package handlers
 
 import (
+	"context"
+	"fmt"
 	"github.com/hashicorp/go-hclog"
 	"github.com/milutindzunic/pac-backend/data"
+	"github.com/milutindzunic/pac-backend/logging"
 	"net/http"
+	"strconv"
 )
 
 type LocationsHandler struct {
@@ -17,15 +21,21 @@ func NewLocationsHandler(store data.LocationStore, log hclog.Logger) *LocationsH
 
 func (lh *LocationsHandler) GetLocations(rw http.ResponseWriter, r *http.Request) {
 
-	locations, err := lh.store.GetLocations()
+	ctx, fields, err := readSelection(r, "Location")
 	if err != nil {
-		writeJSONErrorWithStatus("Error getting all entities", err.Error(), rw, http.StatusInternalServerError)
+		writeProblem(err, rw, r)
 		return
 	}
 
-	err = writeJSONWithStatus(locations, rw, http.StatusOK)
+	locations, err := lh.store.GetLocations(ctx)
 	if err != nil {
-		lh.log.Error("Error serializing entity", err)
+		writeProblem(err, rw, r)
+		return
+	}
+
+	err = writeJSONWithStatus(fields.apply(locations), rw, r, http.StatusOK)
+	if err != nil {
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
 		return
 	}
 }
@@ -33,44 +43,112 @@ func (lh *LocationsHandler) GetLocations(rw http.ResponseWriter, r *http.Request
 func (lh *LocationsHandler) GetLocation(rw http.ResponseWriter, r *http.Request) {
 	id := readId(r)
 
-	location, err := lh.store.GetLocationByID(id)
+	ctx, fields, err := readSelection(r, "Location")
 	if err != nil {
-		switch err.(type) {
-		case *data.LocationNotFoundError:
-			writeJSONErrorWithStatus("Entity not found", err.Error(), rw, http.StatusNotFound)
-			return
-		default:
-			writeJSONErrorWithStatus("Unexpected error occurred", err.Error(), rw, http.StatusInternalServerError)
-			return
-		}
+		writeProblem(err, rw, r)
+		return
+	}
+
+	location, err := lh.store.GetLocationByID(ctx, id)
+	if err != nil {
+		writeProblem(err, rw, r)
+		return
 	}
 
-	err = writeJSONWithStatus(location, rw, http.StatusOK)
+	err = writeJSONWithStatus(fields.apply(location), rw, r, http.StatusOK)
 	if err != nil {
-		lh.log.Error("Error serializing entity", err)
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
 		return
 	}
 }
 
+// GetLocationsNear returns the locations within the radius of the point given by the lat and lng query parameters,
+// nearest first
+func (lh *LocationsHandler) GetLocationsNear(rw http.ResponseWriter, r *http.Request) {
+
+	ctx, fields, err := readSelection(r, "Location")
+	if err != nil {
+		writeProblem(err, rw, r)
+		return
+	}
+
+	latitude, longitude, radius, err := readNear(r)
+	if err != nil {
+		writeProblem(err, rw, r)
+		return
+	}
+
+	locations, err := lh.store.GetLocationsNear(ctx, latitude, longitude, radius)
+	if err != nil {
+		writeProblem(err, rw, r)
+		return
+	}
+
+	err = writeJSONWithStatus(fields.apply(locations), rw, r, http.StatusOK)
+	if err != nil {
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
+		return
+	}
+}
+
+// defaultRadius is the radius of the nearby search in kilometers when the radius query parameter is not set
+const defaultRadius = 10.0
+
+// maxRadius is half the circumference of the earth in kilometers, which covers every point
+const maxRadius = 20038.0
+
+// readNear parses the point and the radius in kilometers of the nearby search
+func readNear(r *http.Request) (latitude, longitude, radius float64, err error) {
+	query := r.URL.Query()
+	verr := &data.ValidationError{Entity: "Query"}
+
+	// number parses the query parameter, reporting it when it is not within the bounds;
+	// a parameter without a fallback is required
+	number := func(param string, min, max float64, fallback float64) float64 {
+		value := query.Get(param)
+		if value == "" {
+			if fallback == 0 {
+				verr.Errors = append(verr.Errors, data.FieldError{Field: param, Rule: "required", Message: "is required"})
+			}
+			return fallback
+		}
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			verr.Errors = append(verr.Errors, data.FieldError{Field: param, Rule: "type", Message: "must be a number"})
+		} else if parsed < min || parsed > max {
+			verr.Errors = append(verr.Errors, data.FieldError{Field: param, Rule: "range", Message: fmt.Sprintf("must be between %g and %g", min, max)})
+		}
+		return parsed
+	}
+	latitude = number("lat", -90, 90, 0)
+	longitude = number("lng", -180, 180, 0)
+	radius = number("radius", 0.001, maxRadius, defaultRadius)
+
+	if len(verr.Errors) > 0 {
+		return 0, 0, 0, verr
+	}
+	return latitude, longitude, radius, nil
+}
+
 func (lh *LocationsHandler) CreateLocation(rw http.ResponseWriter, r *http.Request) {
 
 	location := &data.Location{}
 	err := readJSON(r.Body, location)
 	if err != nil {
-		lh.log.Error("Error deserializing entity", err)
-		writeJSONErrorWithStatus("Error deserializing entity", err.Error(), rw, http.StatusBadRequest)
+		logging.FromContext(r.Context(), lh.log).Error("Error deserializing entity", "err", err)
+		writeProblem(err, rw, r)
 		return
 	}
 
-	location, err = lh.store.AddLocation(location)
+	location, err = lh.store.AddLocation(r.Context(), location)
 	if err != nil {
-		writeJSONErrorWithStatus("Error creating entity", err.Error(), rw, http.StatusBadRequest)
+		writeProblem(err, rw, r)
 		return
 	}
 
-	err = writeJSONWithStatus(location, rw, http.StatusCreated)
+	err = writeJSONWithStatus(location, rw, r, http.StatusCreated)
 	if err != nil {
-		lh.log.Error("Error serializing entity", err)
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
 		return
 	}
 }
@@ -81,26 +159,20 @@ func (lh *LocationsHandler) UpdateLocation(rw http.ResponseWriter, r *http.Reque
 	location := &data.Location{}
 	err := readJSON(r.Body, location)
 	if err != nil {
-		lh.log.Error("Error deserializing entity", err)
-		writeJSONErrorWithStatus("Error deserializing entity", err.Error(), rw, http.StatusBadRequest)
+		logging.FromContext(r.Context(), lh.log).Error("Error deserializing entity", "err", err)
+		writeProblem(err, rw, r)
 		return
 	}
 
-	location, err = lh.store.UpdateLocation(id, location)
+	location, err = lh.store.UpdateLocation(r.Context(), id, location)
 	if err != nil {
-		switch err.(type) {
-		case *data.LocationNotFoundError:
-			writeJSONErrorWithStatus("Entity not found", err.Error(), rw, http.StatusNotFound)
-			return
-		default:
-			writeJSONErrorWithStatus("Unexpected error occurred", err.Error(), rw, http.StatusInternalServerError)
-			return
-		}
+		writeProblem(err, rw, r)
+		return
 	}
 
-	err = writeJSONWithStatus(location, rw, http.StatusOK)
+	err = writeJSONWithStatus(location, rw, r, http.StatusOK)
 	if err != nil {
-		lh.log.Error("Error serializing entity", err)
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
 		return
 	}
 }
@@ -108,17 +180,42 @@ func (lh *LocationsHandler) UpdateLocation(rw http.ResponseWriter, r *http.Reque
 func (lh *LocationsHandler) DeleteLocation(rw http.ResponseWriter, r *http.Request) {
 	id := readId(r)
 
-	err := lh.store.DeleteLocationByID(id)
+	err := lh.store.DeleteLocationByID(r.Context(), id, readCascade(r))
 	if err != nil {
-		switch err.(type) {
-		case *data.LocationNotFoundError:
-			writeJSONErrorWithStatus("Entity not found", err.Error(), rw, http.StatusNotFound)
-			return
-		default:
-			writeJSONErrorWithStatus("Unexpected error occurred", err.Error(), rw, http.StatusInternalServerError)
-			return
-		}
+		writeProblem(err, rw, r)
+		return
 	}
 
 	rw.WriteHeader(http.StatusNoContent)
 }
+
+func (lh *LocationsHandler) RestoreLocation(rw http.ResponseWriter, r *http.Request) {
+	id := readId(r)
+
+	location, err := lh.store.RestoreLocationByID(r.Context(), id)
+	if err != nil {
+		writeProblem(err, rw, r)
+		return
+	}
+
+	err = writeJSONWithStatus(location, rw, r, http.StatusOK)
+	if err != nil {
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
+		return
+	}
+}
+
+// BatchOperations returns the operations run by the batches of locations
+func (lh *LocationsHandler) BatchOperations() BatchOperations {
+	return BatchOperations{
+		Entity: "Location",
+		New:    func() interface{} { return &data.Location{} },
+		Create: func(ctx context.Context, entity interface{}) (interface{}, error) {
+			return lh.store.AddLocation(ctx, entity.(*data.Location))
+		},
+		Update: func(ctx context.Context, id uint, entity interface{}) (interface{}, error) {
+			return lh.store.UpdateLocation(ctx, id, entity.(*data.Location))
+		},
+		Delete: lh.store.DeleteLocationByID,
+	}
+}
@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"context"
+	"github.com/hashicorp/go-hclog"
+	"github.com/milutindzunic/pac-backend/auth"
+	"github.com/milutindzunic/pac-backend/data"
+	"github.com/milutindzunic/pac-backend/logging"
+	"net/http"
+)
+
+// SpeakerProfile is the part of a person which speakers edit themselves. The organization and the linked subject
+// are managed by admins through the persons endpoints.
+type SpeakerProfile struct {
+	Name        string           `json:"name"`
+	JobTitle    string           `json:"jobTitle"`
+	Bio         string           `json:"bio"`
+	PhotoURL    string           `json:"photoUrl"`
+	SocialLinks data.SocialLinks `json:"socialLinks"`
+}
+
+// speakerProfileColumns are the columns of the person written from a SpeakerProfile, cleared when left out
+var speakerProfileColumns = []string{"name", "job_title", "bio", "photo_url", "social_links"}
+
+// SpeakerTalk is the part of a talk which its speakers edit themselves. The speakers of the talk, its format,
+// track and dates are managed by admins through the talks and talk dates endpoints.
+type SpeakerTalk struct {
+	Title             string         `json:"title"`
+	DurationInMinutes uint           `json:"durationInMinutes"`
+	Language          string         `json:"language"`
+	Level             data.TalkLevel `json:"level"`
+	Topics            []data.Topic   `json:"topics"`
+	Abstract          string         `json:"abstract"`
+	Prerequisites     string         `json:"prerequisites"`
+	SlidesURL         string         `json:"slidesUrl"`
+	VideoURL          string         `json:"videoUrl"`
+	RepositoryURL     string         `json:"repositoryUrl"`
+}
+
+// speakerTalkColumns are the columns of the talk written from a SpeakerTalk, cleared when left out
+var speakerTalkColumns = []string{"title", "duration_in_minutes", "language", "level", "abstract", "prerequisites",
+	"slides_url", "video_url", "repository_url"}
+
+// MeHandler serves the self-service endpoints of speakers, which act on the person linked to the subject
+// of the token of the caller and on the talks of that person
+type MeHandler struct {
+	log         hclog.Logger
+	transactor  *data.Transactor
+	personStore data.PersonStore
+	talkStore   data.TalkStore
+}
+
+func NewMeHandler(transactor *data.Transactor, personStore data.PersonStore, talkStore data.TalkStore, log hclog.Logger) *MeHandler {
+	return &MeHandler{log, transactor, personStore, talkStore}
+}
+
+func (mh *MeHandler) GetSpeaker(rw http.ResponseWriter, r *http.Request) {
+
+	ctx, fields, err := readSelection(r, "Person")
+	if err != nil {
+		writeProblem(err, rw, r)
+		return
+	}
+
+	person, err := mh.speaker(ctx)
+	if err != nil {
+		writeProblem(err, rw, r)
+		return
+	}
+
+	err = writeJSONWithStatus(fields.apply(person), rw, r, http.StatusOK)
+	if err != nil {
+		logging.FromContext(r.Context(), mh.log).Error("Error serializing entity", err)
+		return
+	}
+}
+
+func (mh *MeHandler) UpdateSpeaker(rw http.ResponseWriter, r *http.Request) {
+
+	profile := &SpeakerProfile{}
+	err := readJSON(r.Body, profile)
+	if err != nil {
+		logging.FromContext(r.Context(), mh.log).Error("Error deserializing entity", "err", err)
+		writeProblem(err, rw, r)
+		return
+	}
+
+	var person *data.Person
+	err = mh.transactor.InTransaction(r.Context(), func(ctx context.Context) error {
+		speaker, err := mh.speaker(ctx)
+		if err != nil {
+			return err
+		}
+		person, err = mh.personStore.UpdatePerson(data.WithColumns(ctx, speakerProfileColumns...), speaker.ID, &data.Person{
+			Name:           profile.Name,
+			OrganizationID: speaker.OrganizationID,
+			JobTitle:       profile.JobTitle,
+			Bio:            profile.Bio,
+			PhotoURL:       profile.PhotoURL,
+			SocialLinks:    profile.SocialLinks,
+		})
+		return err
+	})
+	if err != nil {
+		writeProblem(err, rw, r)
+		return
+	}
+
+	err = writeJSONWithStatus(person, rw, r, http.StatusOK)
+	if err != nil {
+		logging.FromContext(r.Context(), mh.log).Error("Error serializing entity", err)
+		return
+	}
+}
+
+func (mh *MeHandler) GetTalks(rw http.ResponseWriter, r *http.Request) {
+
+	ctx, fields, err := readSelection(r, "Talk")
+	if err != nil {
+		writeProblem(err, rw, r)
+		return
+	}
+
+	speaker, err := mh.speaker(r.Context())
+	if err != nil {
+		writeProblem(err, rw, r)
+		return
+	}
+
+	talks, err := mh.talkStore.GetTalksByPersonID(ctx, speaker.ID)
+	if err != nil {
+		writeProblem(err, rw, r)
+		return
+	}
+
+	err = writeJSONWithStatus(fields.apply(talks), rw, r, http.StatusOK)
+	if err != nil {
+		logging.FromContext(r.Context(), mh.log).Error("Error serializing entity", err)
+		return
+	}
+}
+
+// UpdateTalk updates a talk of the speaker, the talks of other speakers cannot be changed through it
+func (mh *MeHandler) UpdateTalk(rw http.ResponseWriter, r *http.Request) {
+	id := readId(r)
+
+	speakerTalk := &SpeakerTalk{}
+	err := readJSON(r.Body, speakerTalk)
+	if err != nil {
+		logging.FromContext(r.Context(), mh.log).Error("Error deserializing entity", "err", err)
+		writeProblem(err, rw, r)
+		return
+	}
+
+	var talk *data.Talk
+	err = mh.transactor.InTransaction(r.Context(), func(ctx context.Context) error {
+		speaker, err := mh.speaker(ctx)
+		if err != nil {
+			return err
+		}
+		// the speakers are always loaded, whatever relations the caller chose
+		current, err := mh.talkStore.GetTalkByID(data.WithIncludes(ctx, []string{"persons"}), id)
+		if err != nil {
+			return err
+		}
+		if !hasSpeaker(current, speaker.ID) {
+			return &data.ForbiddenError{Entity: "Talk", Message: "can only be changed by its speakers"}
+		}
+		// the columns left out are cleared, while the topics are kept unless given
+		talk, err = mh.talkStore.UpdateTalk(data.WithColumns(ctx, speakerTalkColumns...), id, &data.Talk{
+			Title:             speakerTalk.Title,
+			DurationInMinutes: speakerTalk.DurationInMinutes,
+			Language:          speakerTalk.Language,
+			Level:             speakerTalk.Level,
+			Topics:            speakerTalk.Topics,
+			Abstract:          speakerTalk.Abstract,
+			Prerequisites:     speakerTalk.Prerequisites,
+			SlidesURL:         speakerTalk.SlidesURL,
+			VideoURL:          speakerTalk.VideoURL,
+			RepositoryURL:     speakerTalk.RepositoryURL,
+		})
+		return err
+	})
+	if err != nil {
+		writeProblem(err, rw, r)
+		return
+	}
+
+	err = writeJSONWithStatus(talk, rw, r, http.StatusOK)
+	if err != nil {
+		logging.FromContext(r.Context(), mh.log).Error("Error serializing entity", err)
+		return
+	}
+}
+
+// speaker returns the person linked to the subject of the caller
+func (mh *MeHandler) speaker(ctx context.Context) (*data.Person, error) {
+	identity, ok := auth.IdentityFromContext(ctx)
+	if !ok {
+		return nil, &data.ForbiddenError{Entity: "Speaker", Message: "can only be managed with a verified token"}
+	}
+	return mh.personStore.GetPersonBySubject(ctx, identity.Subject)
+}
+
+func hasSpeaker(talk *data.Talk, personID uint) bool {
+	for _, person := range talk.Persons {
+		if person.ID == personID {
+			return true
+		}
+	}
+	return false
+}
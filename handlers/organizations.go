@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"context"
 	"github.com/hashicorp/go-hclog"
 	"github.com/milutindzunic/pac-backend/data"
+	"github.com/milutindzunic/pac-backend/logging"
 	"net/http"
 )
 
@@ -17,15 +19,21 @@ func NewOrganizationsHandler(store data.OrganizationStore, log hclog.Logger) *Or
 
 func (lh *OrganizationsHandler) GetOrganizations(rw http.ResponseWriter, r *http.Request) {
 
-	organizations, err := lh.store.GetOrganizations()
+	ctx, fields, err := readSelection(r, "Organization")
 	if err != nil {
-		writeJSONErrorWithStatus("Error getting all entities", err.Error(), rw, http.StatusInternalServerError)
+		writeProblem(err, rw, r)
 		return
 	}
 
-	err = writeJSONWithStatus(organizations, rw, http.StatusOK)
+	organizations, err := lh.store.GetOrganizations(ctx)
 	if err != nil {
-		lh.log.Error("Error serializing entity", err)
+		writeProblem(err, rw, r)
+		return
+	}
+
+	err = writeJSONWithStatus(fields.apply(organizations), rw, r, http.StatusOK)
+	if err != nil {
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
 		return
 	}
 }
@@ -33,21 +41,21 @@ func (lh *OrganizationsHandler) GetOrganizations(rw http.ResponseWriter, r *http
 func (lh *OrganizationsHandler) GetOrganization(rw http.ResponseWriter, r *http.Request) {
 	id := readId(r)
 
-	organization, err := lh.store.GetOrganizationByID(id)
+	ctx, fields, err := readSelection(r, "Organization")
 	if err != nil {
-		switch err.(type) {
-		case *data.OrganizationNotFoundError:
-			writeJSONErrorWithStatus("Entity not found", err.Error(), rw, http.StatusNotFound)
-			return
-		default:
-			writeJSONErrorWithStatus("Unexpected error occurred", err.Error(), rw, http.StatusInternalServerError)
-			return
-		}
+		writeProblem(err, rw, r)
+		return
 	}
 
-	err = writeJSONWithStatus(organization, rw, http.StatusOK)
+	organization, err := lh.store.GetOrganizationByID(ctx, id)
 	if err != nil {
-		lh.log.Error("Error serializing entity", err)
+		writeProblem(err, rw, r)
+		return
+	}
+
+	err = writeJSONWithStatus(fields.apply(organization), rw, r, http.StatusOK)
+	if err != nil {
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
 		return
 	}
 }
@@ -57,20 +65,20 @@ func (lh *OrganizationsHandler) CreateOrganization(rw http.ResponseWriter, r *ht
 	organization := &data.Organization{}
 	err := readJSON(r.Body, organization)
 	if err != nil {
-		lh.log.Error("Error deserializing entity", err)
-		writeJSONErrorWithStatus("Error deserializing entity", err.Error(), rw, http.StatusBadRequest)
+		logging.FromContext(r.Context(), lh.log).Error("Error deserializing entity", "err", err)
+		writeProblem(err, rw, r)
 		return
 	}
 
-	organization, err = lh.store.AddOrganization(organization)
+	organization, err = lh.store.AddOrganization(r.Context(), organization)
 	if err != nil {
-		writeJSONErrorWithStatus("Error creating entity", err.Error(), rw, http.StatusBadRequest)
+		writeProblem(err, rw, r)
 		return
 	}
 
-	err = writeJSONWithStatus(organization, rw, http.StatusCreated)
+	err = writeJSONWithStatus(organization, rw, r, http.StatusCreated)
 	if err != nil {
-		lh.log.Error("Error serializing entity", err)
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
 		return
 	}
 }
@@ -81,26 +89,20 @@ func (lh *OrganizationsHandler) UpdateOrganization(rw http.ResponseWriter, r *ht
 	organization := &data.Organization{}
 	err := readJSON(r.Body, organization)
 	if err != nil {
-		lh.log.Error("Error deserializing entity", err)
-		writeJSONErrorWithStatus("Error deserializing entity", err.Error(), rw, http.StatusBadRequest)
+		logging.FromContext(r.Context(), lh.log).Error("Error deserializing entity", "err", err)
+		writeProblem(err, rw, r)
 		return
 	}
 
-	organization, err = lh.store.UpdateOrganization(id, organization)
+	organization, err = lh.store.UpdateOrganization(r.Context(), id, organization)
 	if err != nil {
-		switch err.(type) {
-		case *data.OrganizationNotFoundError:
-			writeJSONErrorWithStatus("Entity not found", err.Error(), rw, http.StatusNotFound)
-			return
-		default:
-			writeJSONErrorWithStatus("Unexpected error occurred", err.Error(), rw, http.StatusInternalServerError)
-			return
-		}
+		writeProblem(err, rw, r)
+		return
 	}
 
-	err = writeJSONWithStatus(organization, rw, http.StatusOK)
+	err = writeJSONWithStatus(organization, rw, r, http.StatusOK)
 	if err != nil {
-		lh.log.Error("Error serializing entity", err)
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
 		return
 	}
 }
@@ -108,17 +110,42 @@ func (lh *OrganizationsHandler) UpdateOrganization(rw http.ResponseWriter, r *ht
 func (lh *OrganizationsHandler) DeleteOrganization(rw http.ResponseWriter, r *http.Request) {
 	id := readId(r)
 
-	err := lh.store.DeleteOrganizationByID(id)
+	err := lh.store.DeleteOrganizationByID(r.Context(), id, readCascade(r))
 	if err != nil {
-		switch err.(type) {
-		case *data.OrganizationNotFoundError:
-			writeJSONErrorWithStatus("Entity not found", err.Error(), rw, http.StatusNotFound)
-			return
-		default:
-			writeJSONErrorWithStatus("Unexpected error occurred", err.Error(), rw, http.StatusInternalServerError)
-			return
-		}
+		writeProblem(err, rw, r)
+		return
 	}
 
 	rw.WriteHeader(http.StatusNoContent)
 }
+
+func (lh *OrganizationsHandler) RestoreOrganization(rw http.ResponseWriter, r *http.Request) {
+	id := readId(r)
+
+	organization, err := lh.store.RestoreOrganizationByID(r.Context(), id)
+	if err != nil {
+		writeProblem(err, rw, r)
+		return
+	}
+
+	err = writeJSONWithStatus(organization, rw, r, http.StatusOK)
+	if err != nil {
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
+		return
+	}
+}
+
+// BatchOperations returns the operations run by the batches of organizations
+func (lh *OrganizationsHandler) BatchOperations() BatchOperations {
+	return BatchOperations{
+		Entity: "Organization",
+		New:    func() interface{} { return &data.Organization{} },
+		Create: func(ctx context.Context, entity interface{}) (interface{}, error) {
+			return lh.store.AddOrganization(ctx, entity.(*data.Organization))
+		},
+		Update: func(ctx context.Context, id uint, entity interface{}) (interface{}, error) {
+			return lh.store.UpdateOrganization(ctx, id, entity.(*data.Organization))
+		},
+		Delete: lh.store.DeleteOrganizationByID,
+	}
+}
@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"context"
 	"github.com/hashicorp/go-hclog"
 	"github.com/milutindzunic/pac-backend/data"
+	"github.com/milutindzunic/pac-backend/logging"
 	"net/http"
 )
 
@@ -17,15 +19,21 @@ func NewPersonsHandler(store data.PersonStore, log hclog.Logger) *PersonsHandler
 
 func (lh *PersonsHandler) GetPersons(rw http.ResponseWriter, r *http.Request) {
 
-	persons, err := lh.store.GetPersons()
+	ctx, fields, err := readSelection(r, "Person")
 	if err != nil {
-		writeJSONErrorWithStatus("Error getting all entities", err.Error(), rw, http.StatusInternalServerError)
+		writeProblem(err, rw, r)
 		return
 	}
 
-	err = writeJSONWithStatus(persons, rw, http.StatusOK)
+	persons, err := lh.store.GetPersons(ctx)
 	if err != nil {
-		lh.log.Error("Error serializing entity", err)
+		writeProblem(err, rw, r)
+		return
+	}
+
+	err = writeJSONWithStatus(fields.apply(persons), rw, r, http.StatusOK)
+	if err != nil {
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
 		return
 	}
 }
@@ -33,21 +41,21 @@ func (lh *PersonsHandler) GetPersons(rw http.ResponseWriter, r *http.Request) {
 func (lh *PersonsHandler) GetPerson(rw http.ResponseWriter, r *http.Request) {
 	id := readId(r)
 
-	person, err := lh.store.GetPersonByID(id)
+	ctx, fields, err := readSelection(r, "Person")
 	if err != nil {
-		switch err.(type) {
-		case *data.PersonNotFoundError:
-			writeJSONErrorWithStatus("Entity not found", err.Error(), rw, http.StatusNotFound)
-			return
-		default:
-			writeJSONErrorWithStatus("Unexpected error occurred", err.Error(), rw, http.StatusInternalServerError)
-			return
-		}
+		writeProblem(err, rw, r)
+		return
 	}
 
-	err = writeJSONWithStatus(person, rw, http.StatusOK)
+	person, err := lh.store.GetPersonByID(ctx, id)
 	if err != nil {
-		lh.log.Error("Error serializing entity", err)
+		writeProblem(err, rw, r)
+		return
+	}
+
+	err = writeJSONWithStatus(fields.apply(person), rw, r, http.StatusOK)
+	if err != nil {
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
 		return
 	}
 }
@@ -57,20 +65,20 @@ func (lh *PersonsHandler) CreatePerson(rw http.ResponseWriter, r *http.Request)
 	person := &data.Person{}
 	err := readJSON(r.Body, person)
 	if err != nil {
-		lh.log.Error("Error deserializing entity", err)
-		writeJSONErrorWithStatus("Error deserializing entity", err.Error(), rw, http.StatusBadRequest)
+		logging.FromContext(r.Context(), lh.log).Error("Error deserializing entity", "err", err)
+		writeProblem(err, rw, r)
 		return
 	}
 
-	person, err = lh.store.AddPerson(person)
+	person, err = lh.store.AddPerson(r.Context(), person)
 	if err != nil {
-		writeJSONErrorWithStatus("Error creating entity", err.Error(), rw, http.StatusBadRequest)
+		writeProblem(err, rw, r)
 		return
 	}
 
-	err = writeJSONWithStatus(person, rw, http.StatusCreated)
+	err = writeJSONWithStatus(person, rw, r, http.StatusCreated)
 	if err != nil {
-		lh.log.Error("Error serializing entity", err)
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
 		return
 	}
 }
@@ -81,26 +89,20 @@ func (lh *PersonsHandler) UpdatePerson(rw http.ResponseWriter, r *http.Request)
 	person := &data.Person{}
 	err := readJSON(r.Body, person)
 	if err != nil {
-		lh.log.Error("Error deserializing entity", err)
-		writeJSONErrorWithStatus("Error deserializing entity", err.Error(), rw, http.StatusBadRequest)
+		logging.FromContext(r.Context(), lh.log).Error("Error deserializing entity", "err", err)
+		writeProblem(err, rw, r)
 		return
 	}
 
-	person, err = lh.store.UpdatePerson(id, person)
+	person, err = lh.store.UpdatePerson(r.Context(), id, person)
 	if err != nil {
-		switch err.(type) {
-		case *data.PersonNotFoundError:
-			writeJSONErrorWithStatus("Entity not found", err.Error(), rw, http.StatusNotFound)
-			return
-		default:
-			writeJSONErrorWithStatus("Unexpected error occurred", err.Error(), rw, http.StatusInternalServerError)
-			return
-		}
+		writeProblem(err, rw, r)
+		return
 	}
 
-	err = writeJSONWithStatus(person, rw, http.StatusOK)
+	err = writeJSONWithStatus(person, rw, r, http.StatusOK)
 	if err != nil {
-		lh.log.Error("Error serializing entity", err)
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
 		return
 	}
 }
@@ -108,17 +110,42 @@ func (lh *PersonsHandler) UpdatePerson(rw http.ResponseWriter, r *http.Request)
 func (lh *PersonsHandler) DeletePerson(rw http.ResponseWriter, r *http.Request) {
 	id := readId(r)
 
-	err := lh.store.DeletePersonByID(id)
+	err := lh.store.DeletePersonByID(r.Context(), id, readCascade(r))
 	if err != nil {
-		switch err.(type) {
-		case *data.PersonNotFoundError:
-			writeJSONErrorWithStatus("Entity not found", err.Error(), rw, http.StatusNotFound)
-			return
-		default:
-			writeJSONErrorWithStatus("Unexpected error occurred", err.Error(), rw, http.StatusInternalServerError)
-			return
-		}
+		writeProblem(err, rw, r)
+		return
 	}
 
 	rw.WriteHeader(http.StatusNoContent)
 }
+
+func (lh *PersonsHandler) RestorePerson(rw http.ResponseWriter, r *http.Request) {
+	id := readId(r)
+
+	person, err := lh.store.RestorePersonByID(r.Context(), id)
+	if err != nil {
+		writeProblem(err, rw, r)
+		return
+	}
+
+	err = writeJSONWithStatus(person, rw, r, http.StatusOK)
+	if err != nil {
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
+		return
+	}
+}
+
+// BatchOperations returns the operations run by the batches of persons
+func (lh *PersonsHandler) BatchOperations() BatchOperations {
+	return BatchOperations{
+		Entity: "Person",
+		New:    func() interface{} { return &data.Person{} },
+		Create: func(ctx context.Context, entity interface{}) (interface{}, error) {
+			return lh.store.AddPerson(ctx, entity.(*data.Person))
+		},
+		Update: func(ctx context.Context, id uint, entity interface{}) (interface{}, error) {
+			return lh.store.UpdatePerson(ctx, id, entity.(*data.Person))
+		},
+		Delete: lh.store.DeletePersonByID,
+	}
+}
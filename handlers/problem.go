@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/milutindzunic/pac-backend/problem"
+	"net/http"
+)
+
+// problemFor maps an error returned by readJSON, readSelection or the stores onto the problem describing it to the client.
+// The errors of the stores are mapped by the problem package.
+func problemFor(err error) problem.Problem {
+	var decodeErr *DecodeError
+	if errors.As(err, &decodeErr) {
+		if decodeErr.Malformed {
+			return problem.Problem{Type: "/problems/malformed-body", Title: "Malformed request body", Status: http.StatusBadRequest, Detail: err.Error(), Errors: decodeErr.Errors}
+		}
+		return problem.Problem{Type: "/problems/validation", Title: "Validation failed", Status: http.StatusUnprocessableEntity, Detail: err.Error(), Errors: decodeErr.Errors}
+	}
+
+	var queryErr *QueryError
+	if errors.As(err, &queryErr) {
+		return problem.Problem{Type: "/problems/invalid-query", Title: "Invalid query parameter", Status: http.StatusBadRequest, Detail: err.Error(), Errors: queryErr.Errors}
+	}
+
+	return problem.For(err)
+}
+
+// writeProblem writes the problem describing err as the response
+func writeProblem(err error, rw http.ResponseWriter, r *http.Request) {
+	problem.Write(problemFor(err), rw, r)
+}
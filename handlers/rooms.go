@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"context"
 	"github.com/hashicorp/go-hclog"
 	"github.com/milutindzunic/pac-backend/data"
+	"github.com/milutindzunic/pac-backend/logging"
 	"net/http"
 )
 
@@ -17,15 +19,21 @@ func NewRoomsHandler(store data.RoomStore, log hclog.Logger) *RoomsHandler {
 
 func (lh *RoomsHandler) GetRooms(rw http.ResponseWriter, r *http.Request) {
 
-	rooms, err := lh.store.GetRooms()
+	ctx, fields, err := readSelection(r, "Room")
 	if err != nil {
-		writeJSONErrorWithStatus("Error getting all entities", err.Error(), rw, http.StatusInternalServerError)
+		writeProblem(err, rw, r)
 		return
 	}
 
-	err = writeJSONWithStatus(rooms, rw, http.StatusOK)
+	rooms, err := lh.store.GetRooms(ctx)
 	if err != nil {
-		lh.log.Error("Error serializing entity", err)
+		writeProblem(err, rw, r)
+		return
+	}
+
+	err = writeJSONWithStatus(fields.apply(rooms), rw, r, http.StatusOK)
+	if err != nil {
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
 		return
 	}
 }
@@ -33,21 +41,21 @@ func (lh *RoomsHandler) GetRooms(rw http.ResponseWriter, r *http.Request) {
 func (lh *RoomsHandler) GetRoom(rw http.ResponseWriter, r *http.Request) {
 	id := readId(r)
 
-	room, err := lh.store.GetRoomByID(id)
+	ctx, fields, err := readSelection(r, "Room")
 	if err != nil {
-		switch err.(type) {
-		case *data.RoomNotFoundError:
-			writeJSONErrorWithStatus("Entity not found", err.Error(), rw, http.StatusNotFound)
-			return
-		default:
-			writeJSONErrorWithStatus("Unexpected error occurred", err.Error(), rw, http.StatusInternalServerError)
-			return
-		}
+		writeProblem(err, rw, r)
+		return
 	}
 
-	err = writeJSONWithStatus(room, rw, http.StatusOK)
+	room, err := lh.store.GetRoomByID(ctx, id)
 	if err != nil {
-		lh.log.Error("Error serializing entity", err)
+		writeProblem(err, rw, r)
+		return
+	}
+
+	err = writeJSONWithStatus(fields.apply(room), rw, r, http.StatusOK)
+	if err != nil {
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
 		return
 	}
 }
@@ -57,20 +65,20 @@ func (lh *RoomsHandler) CreateRoom(rw http.ResponseWriter, r *http.Request) {
 	room := &data.Room{}
 	err := readJSON(r.Body, room)
 	if err != nil {
-		lh.log.Error("Error deserializing entity", err)
-		writeJSONErrorWithStatus("Error deserializing entity", err.Error(), rw, http.StatusBadRequest)
+		logging.FromContext(r.Context(), lh.log).Error("Error deserializing entity", "err", err)
+		writeProblem(err, rw, r)
 		return
 	}
 
-	room, err = lh.store.AddRoom(room)
+	room, err = lh.store.AddRoom(r.Context(), room)
 	if err != nil {
-		writeJSONErrorWithStatus("Error creating entity", err.Error(), rw, http.StatusBadRequest)
+		writeProblem(err, rw, r)
 		return
 	}
 
-	err = writeJSONWithStatus(room, rw, http.StatusCreated)
+	err = writeJSONWithStatus(room, rw, r, http.StatusCreated)
 	if err != nil {
-		lh.log.Error("Error serializing entity", err)
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
 		return
 	}
 }
@@ -81,26 +89,20 @@ func (lh *RoomsHandler) UpdateRoom(rw http.ResponseWriter, r *http.Request) {
 	room := &data.Room{}
 	err := readJSON(r.Body, room)
 	if err != nil {
-		lh.log.Error("Error deserializing entity", err)
-		writeJSONErrorWithStatus("Error deserializing entity", err.Error(), rw, http.StatusBadRequest)
+		logging.FromContext(r.Context(), lh.log).Error("Error deserializing entity", "err", err)
+		writeProblem(err, rw, r)
 		return
 	}
 
-	room, err = lh.store.UpdateRoom(id, room)
+	room, err = lh.store.UpdateRoom(r.Context(), id, room)
 	if err != nil {
-		switch err.(type) {
-		case *data.RoomNotFoundError:
-			writeJSONErrorWithStatus("Entity not found", err.Error(), rw, http.StatusNotFound)
-			return
-		default:
-			writeJSONErrorWithStatus("Unexpected error occurred", err.Error(), rw, http.StatusInternalServerError)
-			return
-		}
+		writeProblem(err, rw, r)
+		return
 	}
 
-	err = writeJSONWithStatus(room, rw, http.StatusOK)
+	err = writeJSONWithStatus(room, rw, r, http.StatusOK)
 	if err != nil {
-		lh.log.Error("Error serializing entity", err)
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
 		return
 	}
 }
@@ -108,17 +110,42 @@ func (lh *RoomsHandler) UpdateRoom(rw http.ResponseWriter, r *http.Request) {
 func (lh *RoomsHandler) DeleteRoom(rw http.ResponseWriter, r *http.Request) {
 	id := readId(r)
 
-	err := lh.store.DeleteRoomByID(id)
+	err := lh.store.DeleteRoomByID(r.Context(), id, readCascade(r))
 	if err != nil {
-		switch err.(type) {
-		case *data.RoomNotFoundError:
-			writeJSONErrorWithStatus("Entity not found", err.Error(), rw, http.StatusNotFound)
-			return
-		default:
-			writeJSONErrorWithStatus("Unexpected error occurred", err.Error(), rw, http.StatusInternalServerError)
-			return
-		}
+		writeProblem(err, rw, r)
+		return
 	}
 
 	rw.WriteHeader(http.StatusNoContent)
 }
+
+func (lh *RoomsHandler) RestoreRoom(rw http.ResponseWriter, r *http.Request) {
+	id := readId(r)
+
+	room, err := lh.store.RestoreRoomByID(r.Context(), id)
+	if err != nil {
+		writeProblem(err, rw, r)
+		return
+	}
+
+	err = writeJSONWithStatus(room, rw, r, http.StatusOK)
+	if err != nil {
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
+		return
+	}
+}
+
+// BatchOperations returns the operations run by the batches of rooms
+func (lh *RoomsHandler) BatchOperations() BatchOperations {
+	return BatchOperations{
+		Entity: "Room",
+		New:    func() interface{} { return &data.Room{} },
+		Create: func(ctx context.Context, entity interface{}) (interface{}, error) {
+			return lh.store.AddRoom(ctx, entity.(*data.Room))
+		},
+		Update: func(ctx context.Context, id uint, entity interface{}) (interface{}, error) {
+			return lh.store.UpdateRoom(ctx, id, entity.(*data.Room))
+		},
+		Delete: lh.store.DeleteRoomByID,
+	}
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/milutindzunic/pac-backend/data"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// QueryError is returned when the query parameters of a request are invalid
+type QueryError struct {
+	data.ValidationError
+}
+
+// entityTypes are the types of the entities whose reads accept the fields and include query parameters
+var entityTypes = map[string]reflect.Type{
+	"Location":     reflect.TypeOf(data.Location{}),
+	"Event":        reflect.TypeOf(data.Event{}),
+	"Organization": reflect.TypeOf(data.Organization{}),
+	"Person":       reflect.TypeOf(data.Person{}),
+	"Room":         reflect.TypeOf(data.Room{}),
+	"Topic":        reflect.TypeOf(data.Topic{}),
+	"Track":        reflect.TypeOf(data.Track{}),
+	"Talk":         reflect.TypeOf(data.Talk{}),
+	"TalkDate":     reflect.TypeOf(data.TalkDate{}),
+}
+
+// fieldSet lists the attributes to be written for each entity, nil writes all of them
+type fieldSet map[string]bool
+
+// readSelection reads the include and fields query parameters of a read of the entity. The relations to expand
+// are returned in the context passed to the store, the attributes to write in the field set.
+// The id and the included relations are always written.
+func readSelection(r *http.Request, entity string) (context.Context, fieldSet, error) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	var includes []string
+	if _, ok := query["include"]; ok {
+		var err error
+		includes, err = data.ParseIncludes(entity, query.Get("include"))
+		if err != nil {
+			var limitErr *data.IncludeLimitError
+			if errors.As(err, &limitErr) {
+				// too many or too deep paths are unprocessable, like a value exceeding the maximum of a field
+				return nil, nil, err
+			}
+			validationErr := err.(*data.ValidationError)
+			return nil, nil, &QueryError{*validationErr}
+		}
+		ctx = data.WithIncludes(ctx, includes)
+	}
+
+	if _, ok := query["fields"]; !ok {
+		return ctx, nil, nil
+	}
+
+	names := jsonNames(entityTypes[entity])
+	fields := fieldSet{"id": true}
+	var errs []data.FieldError
+	for _, field := range strings.Split(query.Get("fields"), ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if !contains(names, field) {
+			errs = append(errs, data.FieldError{Field: "fields", Rule: "attribute", Message: field + " is not an attribute of " + entity})
+			continue
+		}
+		fields[field] = true
+	}
+	if len(errs) > 0 {
+		return nil, nil, &QueryError{data.ValidationError{Entity: "Query", Errors: errs}}
+	}
+	for _, include := range includes {
+		fields[strings.Split(include, ".")[0]] = true
+	}
+	return ctx, fields, nil
+}
+
+// apply returns v, an entity or a slice of entities, to be written with only the fields of the set
+func (f fieldSet) apply(v interface{}) interface{} {
+	if f == nil {
+		return v
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		return sparseEntity{v, f}
+	}
+	entities := make([]sparseEntity, rv.Len())
+	for i := range entities {
+		entities[i] = sparseEntity{rv.Index(i).Interface(), f}
+	}
+	return entities
+}
+
+// sparseEntity marshals an entity with only the fields of the set, in the order of its struct fields
+type sparseEntity struct {
+	entity interface{}
+	fields fieldSet
+}
+
+func (s sparseEntity) MarshalJSON() ([]byte, error) {
+	full, err := json.Marshal(s.entity)
+	if err != nil {
+		return nil, err
+	}
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(full, &object); err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for _, name := range jsonNames(reflect.TypeOf(s.entity)) {
+		value, ok := object[name]
+		if !ok || !s.fields[name] {
+			continue
+		}
+		if b.Len() > 1 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(name)
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// jsonNames returns the names of the fields of the struct type t, or of the struct t points to, as written in JSON
+func jsonNames(t reflect.Type) []string {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name == "-" || t.Field(i).PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = t.Field(i).Name
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"context"
 	"github.com/hashicorp/go-hclog"
 	"github.com/milutindzunic/pac-backend/data"
+	"github.com/milutindzunic/pac-backend/logging"
 	"net/http"
 )
 
@@ -17,15 +19,21 @@ func NewTalkDatesHandler(store data.TalkDateStore, log hclog.Logger) *TalkDatesH
 
 func (lh *TalkDatesHandler) GetTalkDates(rw http.ResponseWriter, r *http.Request) {
 
-	talkDates, err := lh.store.GetTalkDates()
+	ctx, fields, err := readSelection(r, "TalkDate")
 	if err != nil {
-		writeJSONErrorWithStatus("Error getting all entities", err.Error(), rw, http.StatusInternalServerError)
+		writeProblem(err, rw, r)
 		return
 	}
 
-	err = writeJSONWithStatus(talkDates, rw, http.StatusOK)
+	talkDates, err := lh.store.GetTalkDates(ctx)
 	if err != nil {
-		lh.log.Error("Error serializing entity", err)
+		writeProblem(err, rw, r)
+		return
+	}
+
+	err = writeJSONWithStatus(fields.apply(talkDates), rw, r, http.StatusOK)
+	if err != nil {
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
 		return
 	}
 }
@@ -33,21 +41,21 @@ func (lh *TalkDatesHandler) GetTalkDates(rw http.ResponseWriter, r *http.Request
 func (lh *TalkDatesHandler) GetTalkDate(rw http.ResponseWriter, r *http.Request) {
 	id := readId(r)
 
-	talkDate, err := lh.store.GetTalkDateByID(id)
+	ctx, fields, err := readSelection(r, "TalkDate")
 	if err != nil {
-		switch err.(type) {
-		case *data.TalkDateNotFoundError:
-			writeJSONErrorWithStatus("Entity not found", err.Error(), rw, http.StatusNotFound)
-			return
-		default:
-			writeJSONErrorWithStatus("Unexpected error occurred", err.Error(), rw, http.StatusInternalServerError)
-			return
-		}
+		writeProblem(err, rw, r)
+		return
+	}
+
+	talkDate, err := lh.store.GetTalkDateByID(ctx, id)
+	if err != nil {
+		writeProblem(err, rw, r)
+		return
 	}
 
-	err = writeJSONWithStatus(talkDate, rw, http.StatusOK)
+	err = writeJSONWithStatus(fields.apply(talkDate), rw, r, http.StatusOK)
 	if err != nil {
-		lh.log.Error("Error serializing entity", err)
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
 		return
 	}
 }
@@ -57,50 +65,55 @@ func (lh *TalkDatesHandler) CreateTalkDate(rw http.ResponseWriter, r *http.Reque
 	talkDate := &data.TalkDate{}
 	err := readJSON(r.Body, talkDate)
 	if err != nil {
-		lh.log.Error("Error deserializing entity", err)
-		writeJSONErrorWithStatus("Error deserializing entity", err.Error(), rw, http.StatusBadRequest)
+		logging.FromContext(r.Context(), lh.log).Error("Error deserializing entity", "err", err)
+		writeProblem(err, rw, r)
 		return
 	}
 
-	talkDate, err = lh.store.AddTalkDate(talkDate)
+	talkDate, err = lh.store.AddTalkDate(r.Context(), talkDate)
 	if err != nil {
-		writeJSONErrorWithStatus("Error creating entity", err.Error(), rw, http.StatusBadRequest)
+		writeProblem(err, rw, r)
 		return
 	}
 
-	err = writeJSONWithStatus(talkDate, rw, http.StatusCreated)
+	err = writeJSONWithStatus(talkDate, rw, r, http.StatusCreated)
 	if err != nil {
-		lh.log.Error("Error serializing entity", err)
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
 		return
 	}
 }
 
+// UpdateTalkDate updates the single talk date, or with scope=series the whole series it is an occurrence of
 func (lh *TalkDatesHandler) UpdateTalkDate(rw http.ResponseWriter, r *http.Request) {
 	id := readId(r)
 
+	series, err := readSeriesScope(r)
+	if err != nil {
+		writeProblem(err, rw, r)
+		return
+	}
+
 	talkDate := &data.TalkDate{}
-	err := readJSON(r.Body, talkDate)
+	err = readJSON(r.Body, talkDate)
 	if err != nil {
-		lh.log.Error("Error deserializing entity", err)
-		writeJSONErrorWithStatus("Error deserializing entity", err.Error(), rw, http.StatusBadRequest)
+		logging.FromContext(r.Context(), lh.log).Error("Error deserializing entity", "err", err)
+		writeProblem(err, rw, r)
 		return
 	}
 
-	talkDate, err = lh.store.UpdateTalkDate(id, talkDate)
+	if series {
+		talkDate, err = lh.store.UpdateTalkDateSeries(r.Context(), id, talkDate)
+	} else {
+		talkDate, err = lh.store.UpdateTalkDate(r.Context(), id, talkDate)
+	}
 	if err != nil {
-		switch err.(type) {
-		case *data.TalkDateNotFoundError:
-			writeJSONErrorWithStatus("Entity not found", err.Error(), rw, http.StatusNotFound)
-			return
-		default:
-			writeJSONErrorWithStatus("Unexpected error occurred", err.Error(), rw, http.StatusInternalServerError)
-			return
-		}
+		writeProblem(err, rw, r)
+		return
 	}
 
-	err = writeJSONWithStatus(talkDate, rw, http.StatusOK)
+	err = writeJSONWithStatus(talkDate, rw, r, http.StatusOK)
 	if err != nil {
-		lh.log.Error("Error serializing entity", err)
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
 		return
 	}
 }
@@ -108,33 +121,125 @@ func (lh *TalkDatesHandler) UpdateTalkDate(rw http.ResponseWriter, r *http.Reque
 func (lh *TalkDatesHandler) DeleteTalkDate(rw http.ResponseWriter, r *http.Request) {
 	id := readId(r)
 
-	err := lh.store.DeleteTalkDateByID(id)
+	err := lh.store.DeleteTalkDateByID(r.Context(), id, readCascade(r))
 	if err != nil {
-		switch err.(type) {
-		case *data.TalkDateNotFoundError:
-			writeJSONErrorWithStatus("Entity not found", err.Error(), rw, http.StatusNotFound)
-			return
-		default:
-			writeJSONErrorWithStatus("Unexpected error occurred", err.Error(), rw, http.StatusInternalServerError)
-			return
-		}
+		writeProblem(err, rw, r)
+		return
 	}
 
 	rw.WriteHeader(http.StatusNoContent)
 }
 
+func (lh *TalkDatesHandler) RestoreTalkDate(rw http.ResponseWriter, r *http.Request) {
+	id := readId(r)
+
+	talkDate, err := lh.store.RestoreTalkDateByID(r.Context(), id)
+	if err != nil {
+		writeProblem(err, rw, r)
+		return
+	}
+
+	err = writeJSONWithStatus(talkDate, rw, r, http.StatusOK)
+	if err != nil {
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
+		return
+	}
+}
+
 func (lh *TalkDatesHandler) GetTalkDatesByEventID(rw http.ResponseWriter, r *http.Request) {
 	eventID := readId(r)
 
-	talkDates, err := lh.store.GetTalkDatesByEventID(eventID)
+	ctx, fields, err := readSelection(r, "TalkDate")
+	if err != nil {
+		writeProblem(err, rw, r)
+		return
+	}
+
+	talkDates, err := lh.store.GetTalkDatesByEventID(ctx, eventID)
 	if err != nil {
-		writeJSONErrorWithStatus("Error getting entities", err.Error(), rw, http.StatusInternalServerError)
+		writeProblem(err, rw, r)
 		return
 	}
 
-	err = writeJSONWithStatus(talkDates, rw, http.StatusOK)
+	err = writeJSONWithStatus(fields.apply(talkDates), rw, r, http.StatusOK)
 	if err != nil {
-		lh.log.Error("Error serializing entity", err)
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
 		return
 	}
-}
\ No newline at end of file
+}
+
+func (lh *TalkDatesHandler) GetTalkDatesBySeriesID(rw http.ResponseWriter, r *http.Request) {
+	seriesID := readId(r)
+
+	ctx, fields, err := readSelection(r, "TalkDate")
+	if err != nil {
+		writeProblem(err, rw, r)
+		return
+	}
+
+	talkDates, err := lh.store.GetTalkDatesBySeriesID(ctx, seriesID)
+	if err != nil {
+		writeProblem(err, rw, r)
+		return
+	}
+
+	err = writeJSONWithStatus(fields.apply(talkDates), rw, r, http.StatusOK)
+	if err != nil {
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
+		return
+	}
+}
+
+// GetCalendarByEventID returns the talk dates of the event as an iCalendar, to be subscribed to by calendar apps
+func (lh *TalkDatesHandler) GetCalendarByEventID(rw http.ResponseWriter, r *http.Request) {
+	eventID := readId(r)
+
+	// the calendar always needs the talks, rooms and time zones of the talk dates
+	ctx := data.WithIncludes(r.Context(), []string{"talk", "room", "event.location", "location"})
+	talkDates, err := lh.store.GetTalkDatesByEventID(ctx, eventID)
+	if err != nil {
+		writeProblem(err, rw, r)
+		return
+	}
+
+	var name string
+	if len(talkDates) > 0 && talkDates[0].Event != nil {
+		name = talkDates[0].Event.Name
+	}
+	err = writeCalendar(name, talkDates, rw, http.StatusOK)
+	if err != nil {
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing calendar", err)
+		return
+	}
+}
+
+// readSeriesScope reads the scope query parameter of the updates of talk dates, which is either "occurrence",
+// the default, or "series"
+func readSeriesScope(r *http.Request) (bool, error) {
+	switch r.URL.Query().Get("scope") {
+	case "", "occurrence":
+		return false, nil
+	case "series":
+		return true, nil
+	}
+	return false, &data.ValidationError{Entity: "Query", Errors: []data.FieldError{{
+		Field:   "scope",
+		Rule:    "oneof",
+		Message: "must be one of [occurrence, series]",
+	}}}
+}
+
+// BatchOperations returns the operations run by the batches of talk dates
+func (lh *TalkDatesHandler) BatchOperations() BatchOperations {
+	return BatchOperations{
+		Entity: "TalkDate",
+		New:    func() interface{} { return &data.TalkDate{} },
+		Create: func(ctx context.Context, entity interface{}) (interface{}, error) {
+			return lh.store.AddTalkDate(ctx, entity.(*data.TalkDate))
+		},
+		Update: func(ctx context.Context, id uint, entity interface{}) (interface{}, error) {
+			return lh.store.UpdateTalkDate(ctx, id, entity.(*data.TalkDate))
+		},
+		Delete: lh.store.DeleteTalkDateByID,
+	}
+}
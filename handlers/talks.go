@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"context"
 	"github.com/hashicorp/go-hclog"
 	"github.com/milutindzunic/pac-backend/data"
+	"github.com/milutindzunic/pac-backend/logging"
 	"net/http"
+	"strconv"
 )
 
 type TalksHandler struct {
@@ -17,15 +20,27 @@ func NewTalksHandler(store data.TalkStore, log hclog.Logger) *TalksHandler {
 
 func (lh *TalksHandler) GetTalks(rw http.ResponseWriter, r *http.Request) {
 
-	talks, err := lh.store.GetTalks()
+	ctx, fields, err := readSelection(r, "Talk")
 	if err != nil {
-		writeJSONErrorWithStatus("Error getting all entities", err.Error(), rw, http.StatusInternalServerError)
+		writeProblem(err, rw, r)
 		return
 	}
 
-	err = writeJSONWithStatus(talks, rw, http.StatusOK)
+	filter, err := readTalkFilter(r)
 	if err != nil {
-		lh.log.Error("Error serializing entity", err)
+		writeProblem(err, rw, r)
+		return
+	}
+
+	talks, err := lh.store.GetTalks(ctx, filter)
+	if err != nil {
+		writeProblem(err, rw, r)
+		return
+	}
+
+	err = writeJSONWithStatus(fields.apply(talks), rw, r, http.StatusOK)
+	if err != nil {
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
 		return
 	}
 }
@@ -33,21 +48,21 @@ func (lh *TalksHandler) GetTalks(rw http.ResponseWriter, r *http.Request) {
 func (lh *TalksHandler) GetTalk(rw http.ResponseWriter, r *http.Request) {
 	id := readId(r)
 
-	talk, err := lh.store.GetTalkByID(id)
+	ctx, fields, err := readSelection(r, "Talk")
 	if err != nil {
-		switch err.(type) {
-		case *data.TalkNotFoundError:
-			writeJSONErrorWithStatus("Entity not found", err.Error(), rw, http.StatusNotFound)
-			return
-		default:
-			writeJSONErrorWithStatus("Unexpected error occurred", err.Error(), rw, http.StatusInternalServerError)
-			return
-		}
+		writeProblem(err, rw, r)
+		return
+	}
+
+	talk, err := lh.store.GetTalkByID(ctx, id)
+	if err != nil {
+		writeProblem(err, rw, r)
+		return
 	}
 
-	err = writeJSONWithStatus(talk, rw, http.StatusOK)
+	err = writeJSONWithStatus(fields.apply(talk), rw, r, http.StatusOK)
 	if err != nil {
-		lh.log.Error("Error serializing entity", err)
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
 		return
 	}
 }
@@ -57,20 +72,20 @@ func (lh *TalksHandler) CreateTalk(rw http.ResponseWriter, r *http.Request) {
 	talk := &data.Talk{}
 	err := readJSON(r.Body, talk)
 	if err != nil {
-		lh.log.Error("Error deserializing entity", err)
-		writeJSONErrorWithStatus("Error deserializing entity", err.Error(), rw, http.StatusBadRequest)
+		logging.FromContext(r.Context(), lh.log).Error("Error deserializing entity", "err", err)
+		writeProblem(err, rw, r)
 		return
 	}
 
-	talk, err = lh.store.AddTalk(talk)
+	talk, err = lh.store.AddTalk(r.Context(), talk)
 	if err != nil {
-		writeJSONErrorWithStatus("Error creating entity", err.Error(), rw, http.StatusBadRequest)
+		writeProblem(err, rw, r)
 		return
 	}
 
-	err = writeJSONWithStatus(talk, rw, http.StatusCreated)
+	err = writeJSONWithStatus(talk, rw, r, http.StatusCreated)
 	if err != nil {
-		lh.log.Error("Error serializing entity", err)
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
 		return
 	}
 }
@@ -81,26 +96,20 @@ func (lh *TalksHandler) UpdateTalk(rw http.ResponseWriter, r *http.Request) {
 	talk := &data.Talk{}
 	err := readJSON(r.Body, talk)
 	if err != nil {
-		lh.log.Error("Error deserializing entity", err)
-		writeJSONErrorWithStatus("Error deserializing entity", err.Error(), rw, http.StatusBadRequest)
+		logging.FromContext(r.Context(), lh.log).Error("Error deserializing entity", "err", err)
+		writeProblem(err, rw, r)
 		return
 	}
 
-	talk, err = lh.store.UpdateTalk(id, talk)
+	talk, err = lh.store.UpdateTalk(r.Context(), id, talk)
 	if err != nil {
-		switch err.(type) {
-		case *data.TalkNotFoundError:
-			writeJSONErrorWithStatus("Entity not found", err.Error(), rw, http.StatusNotFound)
-			return
-		default:
-			writeJSONErrorWithStatus("Unexpected error occurred", err.Error(), rw, http.StatusInternalServerError)
-			return
-		}
+		writeProblem(err, rw, r)
+		return
 	}
 
-	err = writeJSONWithStatus(talk, rw, http.StatusOK)
+	err = writeJSONWithStatus(talk, rw, r, http.StatusOK)
 	if err != nil {
-		lh.log.Error("Error serializing entity", err)
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
 		return
 	}
 }
@@ -108,33 +117,55 @@ func (lh *TalksHandler) UpdateTalk(rw http.ResponseWriter, r *http.Request) {
 func (lh *TalksHandler) DeleteTalk(rw http.ResponseWriter, r *http.Request) {
 	id := readId(r)
 
-	err := lh.store.DeleteTalkByID(id)
+	err := lh.store.DeleteTalkByID(r.Context(), id, readCascade(r))
 	if err != nil {
-		switch err.(type) {
-		case *data.TalkNotFoundError:
-			writeJSONErrorWithStatus("Entity not found", err.Error(), rw, http.StatusNotFound)
-			return
-		default:
-			writeJSONErrorWithStatus("Unexpected error occurred", err.Error(), rw, http.StatusInternalServerError)
-			return
-		}
+		writeProblem(err, rw, r)
+		return
 	}
 
 	rw.WriteHeader(http.StatusNoContent)
 }
 
+func (lh *TalksHandler) RestoreTalk(rw http.ResponseWriter, r *http.Request) {
+	id := readId(r)
+
+	talk, err := lh.store.RestoreTalkByID(r.Context(), id)
+	if err != nil {
+		writeProblem(err, rw, r)
+		return
+	}
+
+	err = writeJSONWithStatus(talk, rw, r, http.StatusOK)
+	if err != nil {
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
+		return
+	}
+}
+
 func (lh *TalksHandler) GetTalksByEventID(rw http.ResponseWriter, r *http.Request) {
 	eventID := readId(r)
 
-	talks, err := lh.store.GetTalksByEventID(eventID)
+	ctx, fields, err := readSelection(r, "Talk")
+	if err != nil {
+		writeProblem(err, rw, r)
+		return
+	}
+
+	filter, err := readTalkFilter(r)
+	if err != nil {
+		writeProblem(err, rw, r)
+		return
+	}
+
+	talks, err := lh.store.GetTalksByEventID(ctx, eventID, filter)
 	if err != nil {
-		writeJSONErrorWithStatus("Error getting entities", err.Error(), rw, http.StatusInternalServerError)
+		writeProblem(err, rw, r)
 		return
 	}
 
-	err = writeJSONWithStatus(talks, rw, http.StatusOK)
+	err = writeJSONWithStatus(fields.apply(talks), rw, r, http.StatusOK)
 	if err != nil {
-		lh.log.Error("Error serializing entity", err)
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
 		return
 	}
 }
@@ -142,15 +173,66 @@ func (lh *TalksHandler) GetTalksByEventID(rw http.ResponseWriter, r *http.Reques
 func (lh *TalksHandler) GetTalksByPersonID(rw http.ResponseWriter, r *http.Request) {
 	personID := readId(r)
 
-	talks, err := lh.store.GetTalksByPersonID(personID)
+	ctx, fields, err := readSelection(r, "Talk")
+	if err != nil {
+		writeProblem(err, rw, r)
+		return
+	}
+
+	talks, err := lh.store.GetTalksByPersonID(ctx, personID)
 	if err != nil {
-		writeJSONErrorWithStatus("Error getting entities", err.Error(), rw, http.StatusInternalServerError)
+		writeProblem(err, rw, r)
 		return
 	}
 
-	err = writeJSONWithStatus(talks, rw, http.StatusOK)
+	err = writeJSONWithStatus(fields.apply(talks), rw, r, http.StatusOK)
 	if err != nil {
-		lh.log.Error("Error serializing entity", err)
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
 		return
 	}
 }
+
+// BatchOperations returns the operations run by the batches of talks
+func (lh *TalksHandler) BatchOperations() BatchOperations {
+	return BatchOperations{
+		Entity: "Talk",
+		New:    func() interface{} { return &data.Talk{} },
+		Create: func(ctx context.Context, entity interface{}) (interface{}, error) {
+			return lh.store.AddTalk(ctx, entity.(*data.Talk))
+		},
+		Update: func(ctx context.Context, id uint, entity interface{}) (interface{}, error) {
+			return lh.store.UpdateTalk(ctx, id, entity.(*data.Talk))
+		},
+		Delete: lh.store.DeleteTalkByID,
+	}
+}
+
+// readTalkFilter reads the format, level, language and track query parameters of the talk listings
+func readTalkFilter(r *http.Request) (data.TalkFilter, error) {
+	query := r.URL.Query()
+	filter := data.TalkFilter{
+		Format:   data.TalkFormat(query.Get("format")),
+		Level:    data.TalkLevel(query.Get("level")),
+		Language: query.Get("language"),
+	}
+
+	verr := &data.ValidationError{Entity: "Query"}
+	if filter.Format != "" && !filter.Format.IsValid() {
+		verr.Errors = append(verr.Errors, data.FieldError{Field: "format", Rule: "talkformat", Message: "must be a known talk format"})
+	}
+	if filter.Level != "" && !filter.Level.IsValid() {
+		verr.Errors = append(verr.Errors, data.FieldError{Field: "level", Rule: "talklevel", Message: "must be a known talk level"})
+	}
+	if track := query.Get("track"); track != "" {
+		parsed, err := strconv.ParseUint(track, 10, 32)
+		if err != nil {
+			verr.Errors = append(verr.Errors, data.FieldError{Field: "track", Rule: "type", Message: "must be a positive number"})
+		}
+		filter.TrackID = uint(parsed)
+	}
+
+	if len(verr.Errors) > 0 {
+		return filter, verr
+	}
+	return filter, nil
+}
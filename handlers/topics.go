@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"context"
 	"github.com/hashicorp/go-hclog"
 	"github.com/milutindzunic/pac-backend/data"
+	"github.com/milutindzunic/pac-backend/logging"
 	"net/http"
 )
 
@@ -17,15 +19,21 @@ func NewTopicsHandler(store data.TopicStore, log hclog.Logger) *TopicsHandler {
 
 func (lh *TopicsHandler) GetTopics(rw http.ResponseWriter, r *http.Request) {
 
-	topics, err := lh.store.GetTopics()
+	ctx, fields, err := readSelection(r, "Topic")
 	if err != nil {
-		writeJSONErrorWithStatus("Error getting all entities", err.Error(), rw, http.StatusInternalServerError)
+		writeProblem(err, rw, r)
 		return
 	}
 
-	err = writeJSONWithStatus(topics, rw, http.StatusOK)
+	topics, err := lh.store.GetTopics(ctx)
 	if err != nil {
-		lh.log.Error("Error serializing entity", err)
+		writeProblem(err, rw, r)
+		return
+	}
+
+	err = writeJSONWithStatus(fields.apply(topics), rw, r, http.StatusOK)
+	if err != nil {
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
 		return
 	}
 }
@@ -33,21 +41,21 @@ func (lh *TopicsHandler) GetTopics(rw http.ResponseWriter, r *http.Request) {
 func (lh *TopicsHandler) GetTopic(rw http.ResponseWriter, r *http.Request) {
 	id := readId(r)
 
-	topic, err := lh.store.GetTopicByID(id)
+	ctx, fields, err := readSelection(r, "Topic")
 	if err != nil {
-		switch err.(type) {
-		case *data.TopicNotFoundError:
-			writeJSONErrorWithStatus("Entity not found", err.Error(), rw, http.StatusNotFound)
-			return
-		default:
-			writeJSONErrorWithStatus("Unexpected error occurred", err.Error(), rw, http.StatusInternalServerError)
-			return
-		}
+		writeProblem(err, rw, r)
+		return
 	}
 
-	err = writeJSONWithStatus(topic, rw, http.StatusOK)
+	topic, err := lh.store.GetTopicByID(ctx, id)
 	if err != nil {
-		lh.log.Error("Error serializing entity", err)
+		writeProblem(err, rw, r)
+		return
+	}
+
+	err = writeJSONWithStatus(fields.apply(topic), rw, r, http.StatusOK)
+	if err != nil {
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
 		return
 	}
 }
@@ -57,20 +65,20 @@ func (lh *TopicsHandler) CreateTopic(rw http.ResponseWriter, r *http.Request) {
 	topic := &data.Topic{}
 	err := readJSON(r.Body, topic)
 	if err != nil {
-		lh.log.Error("Error deserializing entity", err)
-		writeJSONErrorWithStatus("Error deserializing entity", err.Error(), rw, http.StatusBadRequest)
+		logging.FromContext(r.Context(), lh.log).Error("Error deserializing entity", "err", err)
+		writeProblem(err, rw, r)
 		return
 	}
 
-	topic, err = lh.store.AddTopic(topic)
+	topic, err = lh.store.AddTopic(r.Context(), topic)
 	if err != nil {
-		writeJSONErrorWithStatus("Error creating entity", err.Error(), rw, http.StatusBadRequest)
+		writeProblem(err, rw, r)
 		return
 	}
 
-	err = writeJSONWithStatus(topic, rw, http.StatusCreated)
+	err = writeJSONWithStatus(topic, rw, r, http.StatusCreated)
 	if err != nil {
-		lh.log.Error("Error serializing entity", err)
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
 		return
 	}
 }
@@ -81,26 +89,20 @@ func (lh *TopicsHandler) UpdateTopic(rw http.ResponseWriter, r *http.Request) {
 	topic := &data.Topic{}
 	err := readJSON(r.Body, topic)
 	if err != nil {
-		lh.log.Error("Error deserializing entity", err)
-		writeJSONErrorWithStatus("Error deserializing entity", err.Error(), rw, http.StatusBadRequest)
+		logging.FromContext(r.Context(), lh.log).Error("Error deserializing entity", "err", err)
+		writeProblem(err, rw, r)
 		return
 	}
 
-	topic, err = lh.store.UpdateTopic(id, topic)
+	topic, err = lh.store.UpdateTopic(r.Context(), id, topic)
 	if err != nil {
-		switch err.(type) {
-		case *data.TopicNotFoundError:
-			writeJSONErrorWithStatus("Entity not found", err.Error(), rw, http.StatusNotFound)
-			return
-		default:
-			writeJSONErrorWithStatus("Unexpected error occurred", err.Error(), rw, http.StatusInternalServerError)
-			return
-		}
+		writeProblem(err, rw, r)
+		return
 	}
 
-	err = writeJSONWithStatus(topic, rw, http.StatusOK)
+	err = writeJSONWithStatus(topic, rw, r, http.StatusOK)
 	if err != nil {
-		lh.log.Error("Error serializing entity", err)
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
 		return
 	}
 }
@@ -108,33 +110,64 @@ func (lh *TopicsHandler) UpdateTopic(rw http.ResponseWriter, r *http.Request) {
 func (lh *TopicsHandler) DeleteTopic(rw http.ResponseWriter, r *http.Request) {
 	id := readId(r)
 
-	err := lh.store.DeleteTopicByID(id)
+	err := lh.store.DeleteTopicByID(r.Context(), id, readCascade(r))
 	if err != nil {
-		switch err.(type) {
-		case *data.TopicNotFoundError:
-			writeJSONErrorWithStatus("Entity not found", err.Error(), rw, http.StatusNotFound)
-			return
-		default:
-			writeJSONErrorWithStatus("Unexpected error occurred", err.Error(), rw, http.StatusInternalServerError)
-			return
-		}
+		writeProblem(err, rw, r)
+		return
 	}
 
 	rw.WriteHeader(http.StatusNoContent)
 }
 
+func (lh *TopicsHandler) RestoreTopic(rw http.ResponseWriter, r *http.Request) {
+	id := readId(r)
+
+	topic, err := lh.store.RestoreTopicByID(r.Context(), id)
+	if err != nil {
+		writeProblem(err, rw, r)
+		return
+	}
+
+	err = writeJSONWithStatus(topic, rw, r, http.StatusOK)
+	if err != nil {
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
+		return
+	}
+}
+
 func (lh *TopicsHandler) GetTopicsByEventID(rw http.ResponseWriter, r *http.Request) {
 	eventID := readId(r)
 
-	events, err := lh.store.GetTopicsByEventID(eventID)
+	ctx, fields, err := readSelection(r, "Topic")
 	if err != nil {
-		writeJSONErrorWithStatus("Error getting entities", err.Error(), rw, http.StatusInternalServerError)
+		writeProblem(err, rw, r)
 		return
 	}
 
-	err = writeJSONWithStatus(events, rw, http.StatusOK)
+	events, err := lh.store.GetTopicsByEventID(ctx, eventID)
 	if err != nil {
-		lh.log.Error("Error serializing entity", err)
+		writeProblem(err, rw, r)
 		return
 	}
+
+	err = writeJSONWithStatus(fields.apply(events), rw, r, http.StatusOK)
+	if err != nil {
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
+		return
+	}
+}
+
+// BatchOperations returns the operations run by the batches of topics
+func (lh *TopicsHandler) BatchOperations() BatchOperations {
+	return BatchOperations{
+		Entity: "Topic",
+		New:    func() interface{} { return &data.Topic{} },
+		Create: func(ctx context.Context, entity interface{}) (interface{}, error) {
+			return lh.store.AddTopic(ctx, entity.(*data.Topic))
+		},
+		Update: func(ctx context.Context, id uint, entity interface{}) (interface{}, error) {
+			return lh.store.UpdateTopic(ctx, id, entity.(*data.Topic))
+		},
+		Delete: lh.store.DeleteTopicByID,
+	}
 }
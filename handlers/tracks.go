@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"context"
+	"github.com/hashicorp/go-hclog"
+	"github.com/milutindzunic/pac-backend/data"
+	"github.com/milutindzunic/pac-backend/logging"
+	"net/http"
+)
+
+type TracksHandler struct {
+	log   hclog.Logger
+	store data.TrackStore
+}
+
+func NewTracksHandler(store data.TrackStore, log hclog.Logger) *TracksHandler {
+	return &TracksHandler{log, store}
+}
+
+func (lh *TracksHandler) GetTracks(rw http.ResponseWriter, r *http.Request) {
+
+	ctx, fields, err := readSelection(r, "Track")
+	if err != nil {
+		writeProblem(err, rw, r)
+		return
+	}
+
+	tracks, err := lh.store.GetTracks(ctx)
+	if err != nil {
+		writeProblem(err, rw, r)
+		return
+	}
+
+	err = writeJSONWithStatus(fields.apply(tracks), rw, r, http.StatusOK)
+	if err != nil {
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
+		return
+	}
+}
+
+func (lh *TracksHandler) GetTrack(rw http.ResponseWriter, r *http.Request) {
+	id := readId(r)
+
+	ctx, fields, err := readSelection(r, "Track")
+	if err != nil {
+		writeProblem(err, rw, r)
+		return
+	}
+
+	track, err := lh.store.GetTrackByID(ctx, id)
+	if err != nil {
+		writeProblem(err, rw, r)
+		return
+	}
+
+	err = writeJSONWithStatus(fields.apply(track), rw, r, http.StatusOK)
+	if err != nil {
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
+		return
+	}
+}
+
+func (lh *TracksHandler) GetTracksByEventID(rw http.ResponseWriter, r *http.Request) {
+	eventID := readId(r)
+
+	ctx, fields, err := readSelection(r, "Track")
+	if err != nil {
+		writeProblem(err, rw, r)
+		return
+	}
+
+	tracks, err := lh.store.GetTracksByEventID(ctx, eventID)
+	if err != nil {
+		writeProblem(err, rw, r)
+		return
+	}
+
+	err = writeJSONWithStatus(fields.apply(tracks), rw, r, http.StatusOK)
+	if err != nil {
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
+		return
+	}
+}
+
+func (lh *TracksHandler) CreateTrack(rw http.ResponseWriter, r *http.Request) {
+
+	track := &data.Track{}
+	err := readJSON(r.Body, track)
+	if err != nil {
+		logging.FromContext(r.Context(), lh.log).Error("Error deserializing entity", "err", err)
+		writeProblem(err, rw, r)
+		return
+	}
+
+	track, err = lh.store.AddTrack(r.Context(), track)
+	if err != nil {
+		writeProblem(err, rw, r)
+		return
+	}
+
+	err = writeJSONWithStatus(track, rw, r, http.StatusCreated)
+	if err != nil {
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
+		return
+	}
+}
+
+func (lh *TracksHandler) UpdateTrack(rw http.ResponseWriter, r *http.Request) {
+	id := readId(r)
+
+	track := &data.Track{}
+	err := readJSON(r.Body, track)
+	if err != nil {
+		logging.FromContext(r.Context(), lh.log).Error("Error deserializing entity", "err", err)
+		writeProblem(err, rw, r)
+		return
+	}
+
+	track, err = lh.store.UpdateTrack(r.Context(), id, track)
+	if err != nil {
+		writeProblem(err, rw, r)
+		return
+	}
+
+	err = writeJSONWithStatus(track, rw, r, http.StatusOK)
+	if err != nil {
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
+		return
+	}
+}
+
+func (lh *TracksHandler) DeleteTrack(rw http.ResponseWriter, r *http.Request) {
+	id := readId(r)
+
+	err := lh.store.DeleteTrackByID(r.Context(), id, readCascade(r))
+	if err != nil {
+		writeProblem(err, rw, r)
+		return
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+func (lh *TracksHandler) RestoreTrack(rw http.ResponseWriter, r *http.Request) {
+	id := readId(r)
+
+	track, err := lh.store.RestoreTrackByID(r.Context(), id)
+	if err != nil {
+		writeProblem(err, rw, r)
+		return
+	}
+
+	err = writeJSONWithStatus(track, rw, r, http.StatusOK)
+	if err != nil {
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
+		return
+	}
+}
+
+// BatchOperations returns the operations run by the batches of tracks
+func (lh *TracksHandler) BatchOperations() BatchOperations {
+	return BatchOperations{
+		Entity: "Track",
+		New:    func() interface{} { return &data.Track{} },
+		Create: func(ctx context.Context, entity interface{}) (interface{}, error) {
+			return lh.store.AddTrack(ctx, entity.(*data.Track))
+		},
+		Update: func(ctx context.Context, id uint, entity interface{}) (interface{}, error) {
+			return lh.store.UpdateTrack(ctx, id, entity.(*data.Track))
+		},
+		Delete: lh.store.DeleteTrackByID,
+	}
+}
@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"github.com/hashicorp/go-hclog"
+	"github.com/milutindzunic/pac-backend/data"
+	"github.com/milutindzunic/pac-backend/logging"
+	"net/http"
+)
+
+type TrashHandler struct {
+	log   hclog.Logger
+	store data.TrashStore
+}
+
+func NewTrashHandler(store data.TrashStore, log hclog.Logger) *TrashHandler {
+	return &TrashHandler{log, store}
+}
+
+func (lh *TrashHandler) GetTrash(rw http.ResponseWriter, r *http.Request) {
+
+	trash, err := lh.store.GetTrash(r.Context())
+	if err != nil {
+		writeProblem(err, rw, r)
+		return
+	}
+
+	err = writeJSONWithStatus(trash, rw, r, http.StatusOK)
+	if err != nil {
+		logging.FromContext(r.Context(), lh.log).Error("Error serializing entity", err)
+		return
+	}
+}
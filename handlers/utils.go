@@ -1,18 +1,25 @@
 package handlers
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
 	"errors"
 	"github.com/gorilla/mux"
+	"github.com/milutindzunic/pac-backend/data"
 	"io"
 	"net/http"
+	"reflect"
 	"strconv"
+	"strings"
+	"time"
 )
 
-// ErrorResponse is a generic error message returned by the server
-type ErrorResponse struct {
-	Message string `json:"Message"`
-	Cause   string `json:"Cause"`
+// DecodeError is returned by readJSON when the request body cannot be deserialized.
+// Malformed reports whether the body was not valid JSON at all, as opposed to valid JSON not matching the entity.
+type DecodeError struct {
+	Malformed bool
+	data.ValidationError
 }
 
 func readJSON(rc io.Reader, dst interface{}) error {
@@ -21,19 +28,53 @@ func readJSON(rc io.Reader, dst interface{}) error {
 	dec.DisallowUnknownFields()
 
 	err := dec.Decode(&dst)
+	if err == nil {
+		return nil
+	}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var maxBytesErr *http.MaxBytesError
 	switch {
-		case err == io.EOF:
-			// empty body
-			return errors.New("cannot deserialize empty body")
-		case err != nil:
-			// TODO better error handling
-			return err
+	case errors.As(err, &maxBytesErr):
+		// reported as is, the body was cut off rather than malformed
+		return err
+	case err == io.EOF:
+		// empty body
+		return newDecodeError(true, data.FieldError{Rule: "required", Message: "cannot deserialize empty body"})
+	case err == io.ErrUnexpectedEOF:
+		return newDecodeError(true, data.FieldError{Rule: "syntax", Message: "unexpected end of JSON input"})
+	case errors.As(err, &syntaxErr):
+		return newDecodeError(true, data.FieldError{Rule: "syntax", Message: syntaxErr.Error(), Offset: syntaxErr.Offset})
+	case errors.As(err, &typeErr):
+		return newDecodeError(false, data.FieldError{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Message: "must be of type " + typeErr.Type.String() + ", was " + typeErr.Value,
+			Offset:  typeErr.Offset,
+		})
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// the decoder does not export a dedicated error type for unknown fields
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), "\"")
+		return newDecodeError(false, data.FieldError{Field: field, Rule: "unknown", Message: "is not a known field"})
+	default:
+		var parseErr *time.ParseError
+		if errors.As(err, &parseErr) {
+			return newDecodeError(false, data.FieldError{Rule: "format", Message: "invalid time " + parseErr.Value + ", must be RFC3339"})
+		}
+		return newDecodeError(true, data.FieldError{Rule: "decode", Message: err.Error()})
 	}
+}
 
-	return nil
+func newDecodeError(malformed bool, fe data.FieldError) *DecodeError {
+	return &DecodeError{malformed, data.ValidationError{Entity: "Request body", Errors: []data.FieldError{fe}}}
 }
 
-func writeJSONWithStatus(i interface{}, rw http.ResponseWriter, status int) error {
+// writeJSONWithStatus writes i with the status, with its times in the time zone requested by the caller,
+// or else in the time zones of their events
+func writeJSONWithStatus(i interface{}, rw http.ResponseWriter, r *http.Request, status int) error {
+
+	localize(i, data.TimezoneOf(r.Context()))
 
 	err := writeJson(i, rw, status)
 	if err != nil {
@@ -43,14 +84,35 @@ func writeJSONWithStatus(i interface{}, rw http.ResponseWriter, status int) erro
 	return nil
 }
 
-func writeJSONErrorWithStatus(message string, cause string, rw http.ResponseWriter, status int) {
-
-	errorResponse := ErrorResponse{message, cause}
-	writeJson(errorResponse, rw, status)
+// localize sets the times of the entities in i, including the entities of sparse entities, which data.Localize
+// cannot reach through their unexported fields
+func localize(i interface{}, zone *time.Location) {
+	switch v := i.(type) {
+	case sparseEntity:
+		data.Localize(v.entity, zone)
+	case []sparseEntity:
+		for _, entity := range v {
+			data.Localize(entity.entity, zone)
+		}
+	default:
+		data.Localize(i, zone)
+	}
 }
 
+// streamBufferSize is the amount of encoded JSON collected before it is written to the response
+const streamBufferSize = 32 * 1024
+
+var marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// writeJson writes i with the status. Collections are encoded one element at a time, so a large response
+// is never held in memory as a whole; other values are marshalled first, so their Content-Length can be set.
 func writeJson(i interface{}, rw http.ResponseWriter, status int) error {
 
+	v := reflect.ValueOf(i)
+	if v.Kind() == reflect.Slice && !v.IsNil() && v.Type().Elem().Kind() != reflect.Uint8 && !v.Type().Implements(marshalerType) {
+		return writeJsonArray(v, rw, status)
+	}
+
 	jsonBytes, err := json.Marshal(i)
 	if err != nil {
 		rw.WriteHeader(http.StatusInternalServerError)
@@ -58,15 +120,60 @@ func writeJson(i interface{}, rw http.ResponseWriter, status int) error {
 	}
 
 	rw.Header().Set("Content-Type", "application/json")
+	rw.Header().Set("Content-Length", strconv.Itoa(len(jsonBytes)))
 
 	rw.WriteHeader(status)
-	responseLength, err := rw.Write(jsonBytes)
-	if err != nil {
-		return err
+	_, err = rw.Write(jsonBytes)
+	return err
+}
+
+// writeJsonArray streams the elements of the slice v as a JSON array. The first element is encoded before the
+// status is sent, so a collection that cannot be serialized is still answered with an error status.
+// The elements are encoded one after the other into the same buffer, which only grows to the largest of them.
+func writeJsonArray(v reflect.Value, rw http.ResponseWriter, status int) error {
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	encode := func(i int) ([]byte, error) {
+		buf.Reset()
+		// the elements of a slice are addressable; encoding through a pointer neither copies them, nor misses
+		// the marshalers with pointer receivers, which json.Marshal of the whole slice would call too
+		if err := enc.Encode(v.Index(i).Addr().Interface()); err != nil {
+			return nil, err
+		}
+		// unlike json.Marshal, the encoder ends the value with a newline
+		return bytes.TrimSuffix(buf.Bytes(), []byte{'\n'}), nil
 	}
 
-	rw.Header().Set("Content-Length", strconv.Itoa(responseLength))
-	return nil
+	var first []byte
+	if v.Len() > 0 {
+		var err error
+		first, err = encode(0)
+		if err != nil {
+			rw.WriteHeader(http.StatusInternalServerError)
+			return err
+		}
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+
+	bw := bufio.NewWriterSize(rw, streamBufferSize)
+	_ = bw.WriteByte('[')
+	_, _ = bw.Write(first)
+	for i := 1; i < v.Len(); i++ {
+		elementBytes, err := encode(i)
+		if err != nil {
+			// the status has been sent already, the truncated body tells the client that the response is incomplete
+			_ = bw.Flush()
+			return err
+		}
+		_ = bw.WriteByte(',')
+		_, _ = bw.Write(elementBytes)
+	}
+	_ = bw.WriteByte(']')
+
+	return bw.Flush()
 }
 
 func readId(r *http.Request) uint {
@@ -80,3 +187,8 @@ func readId(r *http.Request) uint {
 
 	return uint(id)
 }
+
+// readCascade reads the cascade query parameter of delete requests
+func readCascade(r *http.Request) bool {
+	return r.URL.Query().Get("cascade") == "true"
+}
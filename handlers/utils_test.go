@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/milutindzunic/pac-backend/data"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// seedTalks returns n talks with their persons, topics and talk dates, like a listing with includes returns them
+func seedTalks(n int) []data.Talk {
+	begin := time.Date(2021, 5, 12, 9, 0, 0, 0, time.UTC)
+	talks := make([]data.Talk, n)
+	for i := range talks {
+		id := uint(i + 1)
+		talks[i] = data.Talk{
+			ID:                id,
+			Title:             fmt.Sprintf("Talk %d", id),
+			DurationInMinutes: 45,
+			Language:          "English",
+			Level:             data.AdvancedLevel,
+			Format:            data.TalkFormat("talk"),
+			Abstract:          strings.Repeat("An abstract of the talk, in markdown. ", 20),
+			SlidesURL:         fmt.Sprintf("https://example.com/talks/%d/slides", id),
+			Persons: []data.Person{
+				{ID: id, Name: fmt.Sprintf("Person %d", id), JobTitle: "Engineer", Bio: strings.Repeat("A bio. ", 30)},
+			},
+			Topics:    []data.Topic{{ID: 1, Name: "Go"}, {ID: 2, Name: "Databases"}},
+			TalkDates: []data.TalkDate{{ID: id, BeginDate: begin.Add(time.Duration(i) * time.Hour)}},
+		}
+	}
+	return talks
+}
+
+// discardResponseWriter is a response writer dropping the body, so only the memory of the encoding is measured
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (w *discardResponseWriter) Header() http.Header         { return w.header }
+func (w *discardResponseWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *discardResponseWriter) WriteHeader(int)             {}
+
+func TestWriteJsonArrayMatchesMarshal(t *testing.T) {
+	for _, talks := range [][]data.Talk{seedTalks(100), {}} {
+		want, err := json.Marshal(talks)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rw := httptest.NewRecorder()
+		if err := writeJsonArray(reflect.ValueOf(talks), rw, http.StatusOK); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(rw.Body.Bytes(), want) {
+			t.Errorf("streamed %d talks as %.100s..., want %.100s...", len(talks), rw.Body.String(), want)
+		}
+	}
+}
+
+// The benchmarks compare streaming a large collection with marshalling it as a whole, which holds the
+// complete response in memory: go test -bench WriteJson -benchmem ./handlers
+const benchmarkTalks = 10000
+
+func BenchmarkWriteJsonArray(b *testing.B) {
+	talks := reflect.ValueOf(seedTalks(benchmarkTalks))
+	rw := &discardResponseWriter{header: http.Header{}}
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := writeJsonArray(talks, rw, http.StatusOK); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkWriteJsonMarshal(b *testing.B) {
+	talks := seedTalks(benchmarkTalks)
+	rw := &discardResponseWriter{header: http.Header{}}
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		jsonBytes, err := json.Marshal(talks)
+		if err != nil {
+			b.Fatal(err)
+		}
+		_, _ = rw.Write(jsonBytes)
+	}
+}
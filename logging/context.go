@@ -0,0 +1,48 @@
+package logging
+
+import (
+	"context"
+	"github.com/hashicorp/go-hclog"
+)
+
+type loggerKey struct{}
+type requestIDKey struct{}
+type subjectKey struct{}
+
+// WithLogger returns a copy of the context carrying the request-scoped logger
+func WithLogger(ctx context.Context, log hclog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, log)
+}
+
+// FromContext returns the request-scoped logger, or fallback outside of a request
+func FromContext(ctx context.Context, fallback hclog.Logger) hclog.Logger {
+	if log, ok := ctx.Value(loggerKey{}).(hclog.Logger); ok {
+		return log
+	}
+	return fallback
+}
+
+// WithRequestID returns a copy of the context carrying the id of the request
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the id of the request, or an empty string outside of a request
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// WithSubjectSlot returns a copy of the context in which SetSubject can record the authenticated caller.
+// The slot is shared by all contexts derived from it, so the access log sees the subject set further down the chain.
+func WithSubjectSlot(ctx context.Context) (context.Context, *string) {
+	slot := new(string)
+	return context.WithValue(ctx, subjectKey{}, slot), slot
+}
+
+// SetSubject records the subject of the authenticated caller for the access log
+func SetSubject(ctx context.Context, subject string) {
+	if slot, ok := ctx.Value(subjectKey{}).(*string); ok {
+		*slot = subject
+	}
+}
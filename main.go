@@ -7,18 +7,26 @@ import (
 	"github.com/hashicorp/go-hclog"
 	"github.com/justinas/alice"
 	"github.com/milutindzunic/pac-backend/auth"
+	"github.com/milutindzunic/pac-backend/cache"
 	"github.com/milutindzunic/pac-backend/config"
 	"github.com/milutindzunic/pac-backend/data"
 	"github.com/milutindzunic/pac-backend/database"
+	"github.com/milutindzunic/pac-backend/graph"
 	"github.com/milutindzunic/pac-backend/handlers"
 	"github.com/milutindzunic/pac-backend/middleware"
 	"github.com/milutindzunic/pac-backend/middleware/metrics"
+	"github.com/milutindzunic/pac-backend/rpc"
+	"github.com/milutindzunic/pac-backend/server"
+	"github.com/milutindzunic/pac-backend/tracing"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
+	"io"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
-	"time"
+	"sync"
+	"syscall"
 )
 
 func main() {
@@ -35,16 +43,40 @@ func main() {
 	logger := hclog.New(&hclog.LoggerOptions{
 		Output:          os.Stdout,
 		Level:           hclog.LevelFromString(cnf.LogLevel),
+		JSONFormat:      cnf.LogFormat == "json",
 		IncludeLocation: true,
 	})
 
-	// connect to database, defer closing
+	// access log lines are always structured, one per request
+	accessLogger := hclog.New(&hclog.LoggerOptions{
+		Name:       "access",
+		Output:     os.Stdout,
+		Level:      hclog.Info,
+		JSONFormat: true,
+	})
+
+	// set up tracing, defer flushing the remaining spans
+	shutdownTracing, err := tracing.Setup(tracing.Config{
+		Exporter:     cnf.TracingExporter,
+		OtlpEndpoint: cnf.TracingOtlpEndpoint,
+		OtlpInsecure: cnf.TracingOtlpInsecure,
+		ServiceName:  cnf.TracingServiceName,
+		SampleRatio:  cnf.TracingSampleRatio,
+	})
+	if err != nil {
+		logger.Error("Failed to set up tracing", "err", err)
+		panic(err)
+	}
+	defer shutdownTracing(context.Background())
+
+	// connect to database, closed last on shutdown
 	db, err := database.OpenDB(cnf)
 	if err != nil {
 		logger.Error("Failed to connect to database", "err", err)
 		panic(err)
 	}
-	defer db.Close()
+	metrics.InstrumentDB(db)
+	tracing.InstrumentDB(db)
 
 	// create stores
 	var locationStore data.LocationStore = data.NewLocationDBStore(db, logger)
@@ -53,23 +85,60 @@ func main() {
 	var personStore data.PersonStore = data.NewPersonDBStore(db, logger)
 	var roomStore data.RoomStore = data.NewRoomDBStore(db, logger)
 	var topicStore data.TopicStore = data.NewTopicDBStore(db, logger)
+	var trackStore data.TrackStore = data.NewTrackDBStore(db, logger)
 	var talkStore data.TalkStore = data.NewTalkDBStore(db, logger)
 	var talkDateStore data.TalkDateStore = data.NewTalkDateDBStore(db, logger)
+	var trashStore data.TrashStore = data.NewTrashDBStore(db, logger)
+	var auditStore data.AuditStore = data.NewAuditDBStore(db, logger)
+
+	// cache the reads of the stores, invalidated by the mutations made through them
+	var storeCache *cache.Cache
+	healthChecks := []handlers.HealthCheck{
+		{Name: "migrations", Check: func(ctx context.Context) error { return database.CheckSchema(db) }},
+	}
+	switch cnf.CacheBackend {
+	case "memory":
+		storeCache = cache.New(cache.NewMemoryBackend(cnf.CacheMaxEntries), cnf.CacheTTL, logger)
+	case "redis":
+		client := redis.NewClient(&redis.Options{Addr: cnf.CacheRedisAddress, Password: cnf.CacheRedisPassword, DB: cnf.CacheRedisDB})
+		defer client.Close()
+		storeCache = cache.New(cache.NewRedisBackend(client, "pac-backend:"), cnf.CacheTTL, logger)
+		healthChecks = append(healthChecks, handlers.HealthCheck{Name: "cache", Check: func(ctx context.Context) error { return client.Ping(ctx).Err() }})
+	}
+	if storeCache != nil {
+		locationStore = cache.NewLocationStore(locationStore, storeCache)
+		eventStore = cache.NewEventStore(eventStore, storeCache)
+		organizationStore = cache.NewOrganizationStore(organizationStore, storeCache)
+		personStore = cache.NewPersonStore(personStore, storeCache)
+		roomStore = cache.NewRoomStore(roomStore, storeCache)
+		topicStore = cache.NewTopicStore(topicStore, storeCache)
+		trackStore = cache.NewTrackStore(trackStore, storeCache)
+		talkStore = cache.NewTalkStore(talkStore, storeCache)
+		talkDateStore = cache.NewTalkDateStore(talkDateStore, storeCache)
+	}
+
+	// publish the changes of the talk dates to the schedule streams of the gRPC API
+	schedule := rpc.NewSchedule()
+	talkDateStore = rpc.NewScheduleTalkDateStore(talkDateStore, schedule)
 
 	// create handlers
-	hh := handlers.NewHealthHandler(db, logger)
 	lh := handlers.NewLocationsHandler(locationStore, logger)
 	eh := handlers.NewEventsHandler(eventStore, logger)
 	oh := handlers.NewOrganizationsHandler(organizationStore, logger)
 	ph := handlers.NewPersonsHandler(personStore, logger)
 	rh := handlers.NewRoomsHandler(roomStore, logger)
 	th := handlers.NewTopicsHandler(topicStore, logger)
+	trkh := handlers.NewTracksHandler(trackStore, logger)
 	tkh := handlers.NewTalksHandler(talkStore, logger)
 	tdh := handlers.NewTalkDatesHandler(talkDateStore, logger)
-	ih := handlers.NewDBInitHandler(db, locationStore, eventStore, organizationStore, personStore, roomStore, topicStore, talkStore, talkDateStore, logger)
+	trh := handlers.NewTrashHandler(trashStore, logger)
+	ah := handlers.NewAuditHandler(auditStore, logger)
+	bh := handlers.NewBatchHandler(data.NewTransactor(db), cnf.BatchMaxOperations, logger)
+	mh := handlers.NewMeHandler(data.NewTransactor(db), personStore, talkStore, logger)
+	ih := handlers.NewDBInitHandler(db, locationStore, eventStore, organizationStore, personStore, roomStore, topicStore, trackStore, talkStore, talkDateStore, logger)
 
 	// Database init moved to endpoint, ran here for testing purposes
-	// database.Init(db, locationStore, eventStore, organizationStore, personStore, roomStore, topicStore, talkStore, talkDateStore, logger)
+	// database.Init(context.Background(), db, locationStore, eventStore, organizationStore, personStore, roomStore, topicStore, trackStore, talkStore, talkDateStore, logger)
 
 	// Authentication
 	oauth, err := auth.NewProvider(auth.OauthConfig{
@@ -79,81 +148,208 @@ func main() {
 		ClientSecret: cnf.OAuthClientSecret,
 		RedirectURL:  cnf.OAuthRedirectUrl,
 		Scopes:       []string{oidc.ScopeOpenID, "profile", "email"},
+		Tenancy: auth.TenancyConfig{
+			Enabled:    cnf.TenancyEnable,
+			Claim:      cnf.TenancyClaim,
+			AdminClaim: cnf.TenancyAdminClaim,
+			AdminRole:  cnf.TenancyAdminRole,
+		},
 	}, logger)
 	if err != nil {
 		logger.Error("Failed to create Oauth2 configuration", "err", err)
 		panic(err)
 	}
 
+	// Health checks
+	healthChecks = append(healthChecks, handlers.HealthCheck{Name: "oidc", Check: oauth.CheckDiscovery})
+	hh := handlers.NewHealthHandler(db, logger, healthChecks...)
+
+	// Rate limiting, with separate budgets for reading and writing
+	trustedProxies, err := middleware.ParseTrustedProxies(cnf.TrustedProxies)
+	if err != nil {
+		logger.Error("Failed to parse trusted proxies", "err", err)
+		panic(err)
+	}
+	// the gRPC API shares the budgets
+	var rateLimiters rpc.RateLimiters
+	ipLimit := alice.New()
+	readLimit := alice.New()
+	writeLimit := alice.New()
+	if cnf.RateLimitEnable {
+		rateLimiters = rpc.RateLimiters{
+			IP:    middleware.NewIPRateLimiter("ip", cnf.RateLimitIPPerMinute, cnf.RateLimitIPBurst, trustedProxies),
+			Read:  middleware.NewRateLimiter("read", cnf.RateLimitReadPerMinute, cnf.RateLimitReadBurst, trustedProxies),
+			Write: middleware.NewRateLimiter("write", cnf.RateLimitWritePerMinute, cnf.RateLimitWriteBurst, trustedProxies),
+		}
+		ipLimit = ipLimit.Append(rateLimiters.IP.Middleware)
+		readLimit = readLimit.Append(rateLimiters.Read.Middleware)
+		writeLimit = writeLimit.Append(rateLimiters.Write.Middleware)
+	}
+
 	// Handler chains
-	defaultChain := alice.New(metrics.Prometheus)
-	jsonChain := defaultChain.Append(middleware.EnforceJsonContentType)
-	secureChain := defaultChain
+	baseChain := alice.New(tracing.Middleware, metrics.Prometheus, middleware.Timezone)
+	// the tokens are checked behind the limit per IP, so they cannot be guessed at the speed of the server
+	authChain := ipLimit.Append(oauth.Middleware)
+	// with tenancy, reads are scoped to the organization of the caller and need a token as well
+	readChain := baseChain
+	if cnf.TenancyEnable {
+		readChain = readChain.Extend(authChain)
+	}
+	defaultChain := readChain.Extend(readLimit).Append(cache.Headers(storeCache, cnf.CacheMaxAge, cnf.TenancyEnable))
+	jsonChain := baseChain.Append(middleware.EnforceJsonContentType)
+	secureChain := baseChain
 	secureJsonChain := jsonChain
 	if cnf.OAuthEnable {
-		secureJsonChain = secureJsonChain.Append(oauth.Middleware)
-		secureChain = secureChain.Append(oauth.Middleware)
+		secureJsonChain = secureJsonChain.Extend(authChain)
+		secureChain = secureChain.Extend(authChain)
+	}
+	// limited after authentication, so that callers are limited by subject rather than IP
+	secureChain = secureChain.Extend(writeLimit)
+	secureJsonChain = secureJsonChain.Extend(writeLimit)
+
+	// GraphQL, whose mutations are authenticated and limited like the secure chains; its clients are told to
+	// authenticate rather than redirected to the login page
+	mutationChain := alice.New()
+	if cnf.OAuthEnable {
+		mutationChain = mutationChain.Extend(ipLimit).Append(oauth.BearerMiddleware)
+	}
+	mutationChain = mutationChain.Extend(writeLimit)
+	gh, err := graph.NewHandler(graph.Stores{
+		Locations:     locationStore,
+		Events:        eventStore,
+		Organizations: organizationStore,
+		Persons:       personStore,
+		Rooms:         roomStore,
+		Topics:        topicStore,
+		Tracks:        trackStore,
+		Talks:         talkStore,
+		TalkDates:     talkDateStore,
+	}, graph.Limits{MaxDepth: cnf.GraphQLMaxDepth, MaxComplexity: cnf.GraphQLMaxComplexity}, mutationChain.Then, logger)
+	if err != nil {
+		logger.Error("Failed to create GraphQL schema", "err", err)
+		panic(err)
 	}
 
 	sm := mux.NewRouter()
-	sm.Use(middleware.AllowCORS)
+
+	// request ids, the access log and CORS wrap the router, so unmatched requests and preflights are covered as well
+	requestChain := alice.New(
+		middleware.RequestID(logger),
+		middleware.AccessLog(accessLogger, sm, trustedProxies),
+		middleware.CORS(middleware.CORSOptions{
+			AllowedOrigins:   cnf.CORSAllowedOrigins,
+			AllowedMethods:   cnf.CORSAllowedMethods,
+			AllowedHeaders:   cnf.CORSAllowedHeaders,
+			ExposedHeaders:   cnf.CORSExposedHeaders,
+			AllowCredentials: cnf.CORSAllowCredentials,
+			MaxAge:           cnf.CORSMaxAge,
+		}),
+		middleware.LimitBody(cnf.ServerMaxBodyBytes),
+	)
+	if cnf.CompressionEnable {
+		// inside the access log, so it reports the bytes actually sent
+		requestChain = requestChain.Append(middleware.Compress(cnf.CompressionMinSize))
+	}
 
 	// Register handlers
 	// Health
 	sm.HandleFunc("/", hh.Handle)
+	sm.HandleFunc("/healthz", hh.Live).Methods("GET")
+	sm.HandleFunc("/readyz", hh.Ready).Methods("GET")
+	sm.HandleFunc("/health", hh.Report).Methods("GET")
 	// Locations
 	sm.Handle("/locations", defaultChain.Then(http.HandlerFunc(lh.GetLocations))).Methods("GET")
+	sm.Handle("/locations/near", defaultChain.Then(http.HandlerFunc(lh.GetLocationsNear))).Methods("GET")
 	sm.Handle("/locations/{id:[0-9]+}", defaultChain.Then(http.HandlerFunc(lh.GetLocation))).Methods("GET")
-	sm.Handle("/locations", secureJsonChain.Then(http.HandlerFunc(lh.CreateLocation))).Methods("POST", "OPTIONS")
-	sm.Handle("/locations/{id:[0-9]+}", secureJsonChain.Then(http.HandlerFunc(lh.UpdateLocation))).Methods("PUT", "OPTIONS")
-	sm.Handle("/locations/{id:[0-9]+}", secureChain.Then(http.HandlerFunc(lh.DeleteLocation))).Methods("DELETE", "OPTIONS")
+	sm.Handle("/locations", secureJsonChain.Then(http.HandlerFunc(lh.CreateLocation))).Methods("POST")
+	sm.Handle("/locations/{id:[0-9]+}", secureJsonChain.Then(http.HandlerFunc(lh.UpdateLocation))).Methods("PUT")
+	sm.Handle("/locations/{id:[0-9]+}", secureChain.Then(http.HandlerFunc(lh.DeleteLocation))).Methods("DELETE")
+	sm.Handle("/locations/{id:[0-9]+}/restore", secureChain.Then(http.HandlerFunc(lh.RestoreLocation))).Methods("POST")
+	sm.Handle("/locations/batch", secureJsonChain.Then(bh.For(lh.BatchOperations()))).Methods("POST")
 	// Events
 	sm.Handle("/events", defaultChain.Then(http.HandlerFunc(eh.GetEvents))).Methods("GET")
 	sm.Handle("/events/talk/{id:[0-9]+}", defaultChain.Then(http.HandlerFunc(eh.GetEventsByTalkID))).Methods("GET")
 	sm.Handle("/events/{id:[0-9]+}", defaultChain.Then(http.HandlerFunc(eh.GetEvent))).Methods("GET")
-	sm.Handle("/events", secureJsonChain.Then(http.HandlerFunc(eh.CreateEvent))).Methods("POST", "OPTIONS")
-	sm.Handle("/events/{id:[0-9]+}", secureJsonChain.Then(http.HandlerFunc(eh.UpdateEvent))).Methods("PUT", "OPTIONS")
-	sm.Handle("/events/{id:[0-9]+}", secureChain.Then(http.HandlerFunc(eh.DeleteEvent))).Methods("DELETE", "OPTIONS")
+	sm.Handle("/events", secureJsonChain.Then(http.HandlerFunc(eh.CreateEvent))).Methods("POST")
+	sm.Handle("/events/{id:[0-9]+}", secureJsonChain.Then(http.HandlerFunc(eh.UpdateEvent))).Methods("PUT")
+	sm.Handle("/events/{id:[0-9]+}", secureChain.Then(http.HandlerFunc(eh.DeleteEvent))).Methods("DELETE")
+	sm.Handle("/events/{id:[0-9]+}/restore", secureChain.Then(http.HandlerFunc(eh.RestoreEvent))).Methods("POST")
+	sm.Handle("/events/batch", secureJsonChain.Then(bh.For(eh.BatchOperations()))).Methods("POST")
 	// Organizations
 	sm.Handle("/organizations", defaultChain.Then(http.HandlerFunc(oh.GetOrganizations))).Methods("GET")
 	sm.Handle("/organizations/{id:[0-9]+}", defaultChain.Then(http.HandlerFunc(oh.GetOrganization))).Methods("GET")
-	sm.Handle("/organizations", secureJsonChain.Then(http.HandlerFunc(oh.CreateOrganization))).Methods("POST", "OPTIONS")
-	sm.Handle("/organizations/{id:[0-9]+}", secureJsonChain.Then(http.HandlerFunc(oh.UpdateOrganization))).Methods("PUT", "OPTIONS")
-	sm.Handle("/organizations/{id:[0-9]+}", secureChain.Then(http.HandlerFunc(oh.DeleteOrganization))).Methods("DELETE", "OPTIONS")
+	sm.Handle("/organizations", secureJsonChain.Then(http.HandlerFunc(oh.CreateOrganization))).Methods("POST")
+	sm.Handle("/organizations/{id:[0-9]+}", secureJsonChain.Then(http.HandlerFunc(oh.UpdateOrganization))).Methods("PUT")
+	sm.Handle("/organizations/{id:[0-9]+}", secureChain.Then(http.HandlerFunc(oh.DeleteOrganization))).Methods("DELETE")
+	sm.Handle("/organizations/{id:[0-9]+}/restore", secureChain.Then(http.HandlerFunc(oh.RestoreOrganization))).Methods("POST")
+	sm.Handle("/organizations/batch", secureJsonChain.Then(bh.For(oh.BatchOperations()))).Methods("POST")
 	// Persons
 	sm.Handle("/persons", defaultChain.Then(http.HandlerFunc(ph.GetPersons))).Methods("GET")
 	sm.Handle("/persons/{id:[0-9]+}", defaultChain.Then(http.HandlerFunc(ph.GetPerson))).Methods("GET")
-	sm.Handle("/persons", secureJsonChain.Then(http.HandlerFunc(ph.CreatePerson))).Methods("POST", "OPTIONS")
-	sm.Handle("/persons/{id:[0-9]+}", secureJsonChain.Then(http.HandlerFunc(ph.UpdatePerson))).Methods("PUT", "OPTIONS")
-	sm.Handle("/persons/{id:[0-9]+}", secureChain.Then(http.HandlerFunc(ph.DeletePerson))).Methods("DELETE", "OPTIONS")
+	sm.Handle("/persons", secureJsonChain.Then(http.HandlerFunc(ph.CreatePerson))).Methods("POST")
+	sm.Handle("/persons/{id:[0-9]+}", secureJsonChain.Then(http.HandlerFunc(ph.UpdatePerson))).Methods("PUT")
+	sm.Handle("/persons/{id:[0-9]+}", secureChain.Then(http.HandlerFunc(ph.DeletePerson))).Methods("DELETE")
+	sm.Handle("/persons/{id:[0-9]+}/restore", secureChain.Then(http.HandlerFunc(ph.RestorePerson))).Methods("POST")
+	sm.Handle("/persons/batch", secureJsonChain.Then(bh.For(ph.BatchOperations()))).Methods("POST")
 	// Rooms
 	sm.Handle("/rooms", defaultChain.Then(http.HandlerFunc(rh.GetRooms))).Methods("GET")
 	sm.Handle("/rooms/{id:[0-9]+}", defaultChain.Then(http.HandlerFunc(rh.GetRoom))).Methods("GET")
-	sm.Handle("/rooms", secureJsonChain.Then(http.HandlerFunc(rh.CreateRoom))).Methods("POST", "OPTIONS")
-	sm.Handle("/rooms/{id:[0-9]+}", secureJsonChain.Then(http.HandlerFunc(rh.UpdateRoom))).Methods("PUT", "OPTIONS")
-	sm.Handle("/rooms/{id:[0-9]+}", secureChain.Then(http.HandlerFunc(rh.DeleteRoom))).Methods("DELETE", "OPTIONS")
+	sm.Handle("/rooms", secureJsonChain.Then(http.HandlerFunc(rh.CreateRoom))).Methods("POST")
+	sm.Handle("/rooms/{id:[0-9]+}", secureJsonChain.Then(http.HandlerFunc(rh.UpdateRoom))).Methods("PUT")
+	sm.Handle("/rooms/{id:[0-9]+}", secureChain.Then(http.HandlerFunc(rh.DeleteRoom))).Methods("DELETE")
+	sm.Handle("/rooms/{id:[0-9]+}/restore", secureChain.Then(http.HandlerFunc(rh.RestoreRoom))).Methods("POST")
+	sm.Handle("/rooms/batch", secureJsonChain.Then(bh.For(rh.BatchOperations()))).Methods("POST")
 	// Topics
 	sm.Handle("/topics", defaultChain.Then(http.HandlerFunc(th.GetTopics))).Methods("GET")
 	sm.Handle("/topics/event/{id:[0-9]+}", defaultChain.Then(http.HandlerFunc(th.GetTopicsByEventID))).Methods("GET")
 	sm.Handle("/topics/{id:[0-9]+}", defaultChain.Then(http.HandlerFunc(th.GetTopic))).Methods("GET")
-	sm.Handle("/topics", secureJsonChain.Then(http.HandlerFunc(th.CreateTopic))).Methods("POST", "OPTIONS")
-	sm.Handle("/topics/{id:[0-9]+}", secureJsonChain.Then(http.HandlerFunc(th.UpdateTopic))).Methods("PUT", "OPTIONS")
-	sm.Handle("/topics/{id:[0-9]+}", secureChain.Then(http.HandlerFunc(th.DeleteTopic))).Methods("DELETE", "OPTIONS")
+	sm.Handle("/topics", secureJsonChain.Then(http.HandlerFunc(th.CreateTopic))).Methods("POST")
+	sm.Handle("/topics/{id:[0-9]+}", secureJsonChain.Then(http.HandlerFunc(th.UpdateTopic))).Methods("PUT")
+	sm.Handle("/topics/{id:[0-9]+}", secureChain.Then(http.HandlerFunc(th.DeleteTopic))).Methods("DELETE")
+	sm.Handle("/topics/{id:[0-9]+}/restore", secureChain.Then(http.HandlerFunc(th.RestoreTopic))).Methods("POST")
+	sm.Handle("/topics/batch", secureJsonChain.Then(bh.For(th.BatchOperations()))).Methods("POST")
+	// Tracks
+	sm.Handle("/tracks", defaultChain.Then(http.HandlerFunc(trkh.GetTracks))).Methods("GET")
+	sm.Handle("/tracks/event/{id:[0-9]+}", defaultChain.Then(http.HandlerFunc(trkh.GetTracksByEventID))).Methods("GET")
+	sm.Handle("/tracks/{id:[0-9]+}", defaultChain.Then(http.HandlerFunc(trkh.GetTrack))).Methods("GET")
+	sm.Handle("/tracks", secureJsonChain.Then(http.HandlerFunc(trkh.CreateTrack))).Methods("POST")
+	sm.Handle("/tracks/{id:[0-9]+}", secureJsonChain.Then(http.HandlerFunc(trkh.UpdateTrack))).Methods("PUT")
+	sm.Handle("/tracks/{id:[0-9]+}", secureChain.Then(http.HandlerFunc(trkh.DeleteTrack))).Methods("DELETE")
+	sm.Handle("/tracks/{id:[0-9]+}/restore", secureChain.Then(http.HandlerFunc(trkh.RestoreTrack))).Methods("POST")
+	sm.Handle("/tracks/batch", secureJsonChain.Then(bh.For(trkh.BatchOperations()))).Methods("POST")
 	// Talks
 	sm.Handle("/talks", defaultChain.Then(http.HandlerFunc(tkh.GetTalks))).Methods("GET")
 	sm.Handle("/talks/event/{id:[0-9]+}", defaultChain.Then(http.HandlerFunc(tkh.GetTalksByEventID))).Methods("GET")
 	sm.Handle("/talks/person/{id:[0-9]+}", defaultChain.Then(http.HandlerFunc(tkh.GetTalksByPersonID))).Methods("GET")
 	sm.Handle("/talks/{id:[0-9]+}", defaultChain.Then(http.HandlerFunc(tkh.GetTalk))).Methods("GET")
-	sm.Handle("/talks", secureJsonChain.Then(http.HandlerFunc(tkh.CreateTalk))).Methods("POST", "OPTIONS")
-	sm.Handle("/talks/{id:[0-9]+}", secureJsonChain.Then(http.HandlerFunc(tkh.UpdateTalk))).Methods("PUT", "OPTIONS")
-	sm.Handle("/talks/{id:[0-9]+}", secureChain.Then(http.HandlerFunc(tkh.DeleteTalk))).Methods("DELETE", "OPTIONS")
+	sm.Handle("/talks", secureJsonChain.Then(http.HandlerFunc(tkh.CreateTalk))).Methods("POST")
+	sm.Handle("/talks/{id:[0-9]+}", secureJsonChain.Then(http.HandlerFunc(tkh.UpdateTalk))).Methods("PUT")
+	sm.Handle("/talks/{id:[0-9]+}", secureChain.Then(http.HandlerFunc(tkh.DeleteTalk))).Methods("DELETE")
+	sm.Handle("/talks/{id:[0-9]+}/restore", secureChain.Then(http.HandlerFunc(tkh.RestoreTalk))).Methods("POST")
+	sm.Handle("/talks/batch", secureJsonChain.Then(bh.For(tkh.BatchOperations()))).Methods("POST")
 	// Talk Dates
 	sm.Handle("/talkDates", defaultChain.Then(http.HandlerFunc(tdh.GetTalkDates))).Methods("GET")
 	sm.Handle("/talkDates/event/{id:[0-9]+}", defaultChain.Then(http.HandlerFunc(tdh.GetTalkDatesByEventID))).Methods("GET")
+	sm.Handle("/talkDates/event/{id:[0-9]+}/ical", defaultChain.Then(http.HandlerFunc(tdh.GetCalendarByEventID))).Methods("GET")
+	sm.Handle("/talkDates/series/{id:[0-9]+}", defaultChain.Then(http.HandlerFunc(tdh.GetTalkDatesBySeriesID))).Methods("GET")
 	sm.Handle("/talkDates/{id:[0-9]+}", defaultChain.Then(http.HandlerFunc(tdh.GetTalkDate))).Methods("GET")
-	sm.Handle("/talkDates", secureJsonChain.Then(http.HandlerFunc(tdh.CreateTalkDate))).Methods("POST", "OPTIONS")
-	sm.Handle("/talkDates/{id:[0-9]+}", secureJsonChain.Then(http.HandlerFunc(tdh.UpdateTalkDate))).Methods("PUT", "OPTIONS")
-	sm.Handle("/talkDates/{id:[0-9]+}", secureChain.Then(http.HandlerFunc(tdh.DeleteTalkDate))).Methods("DELETE", "OPTIONS")
+	sm.Handle("/talkDates", secureJsonChain.Then(http.HandlerFunc(tdh.CreateTalkDate))).Methods("POST")
+	sm.Handle("/talkDates/{id:[0-9]+}", secureJsonChain.Then(http.HandlerFunc(tdh.UpdateTalkDate))).Methods("PUT")
+	sm.Handle("/talkDates/{id:[0-9]+}", secureChain.Then(http.HandlerFunc(tdh.DeleteTalkDate))).Methods("DELETE")
+	sm.Handle("/talkDates/{id:[0-9]+}/restore", secureChain.Then(http.HandlerFunc(tdh.RestoreTalkDate))).Methods("POST")
+	sm.Handle("/talkDates/batch", secureJsonChain.Then(bh.For(tdh.BatchOperations()))).Methods("POST")
+	// Speaker self-service
+	sm.Handle("/me/speaker", secureChain.Then(http.HandlerFunc(mh.GetSpeaker))).Methods("GET")
+	sm.Handle("/me/speaker", secureJsonChain.Then(http.HandlerFunc(mh.UpdateSpeaker))).Methods("PUT")
+	sm.Handle("/me/talks", secureChain.Then(http.HandlerFunc(mh.GetTalks))).Methods("GET")
+	sm.Handle("/me/talks/{id:[0-9]+}", secureJsonChain.Then(http.HandlerFunc(mh.UpdateTalk))).Methods("PUT")
+	// Trash
+	sm.Handle("/trash", secureChain.Then(http.HandlerFunc(trh.GetTrash))).Methods("GET")
+	// Audit log
+	sm.Handle("/audit", secureChain.Then(http.HandlerFunc(ah.GetAuditEntries))).Methods("GET")
+	sm.Handle("/audit/export", secureChain.Then(http.HandlerFunc(ah.ExportAuditEntries))).Methods("GET")
+	// GraphQL
+	sm.Handle("/graphql", readChain.Extend(readLimit).Then(gh)).Methods("GET", "POST")
 
 	// OAuth2 callback
 	sm.Handle("/oauth2/callback", oauth.CallbackHandler())
@@ -162,38 +358,93 @@ func main() {
 	sm.Handle("/metrics", promhttp.Handler())
 
 	// Database init handler
-	sm.Handle("/initDB", http.HandlerFunc(ih.Handle)).Methods("POST")
+	sm.Handle("/initDB", baseChain.Extend(writeLimit).Then(http.HandlerFunc(ih.Handle))).Methods("POST")
+
+	// run the background workers until shutdown
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		// permanently remove entities which have been in the trash for too long
+		data.RunPurgeJob(workersCtx, trashStore, cnf.TrashRetention, cnf.TrashPurgeInterval, logger)
+	}()
 
 	// create Server
-	s := http.Server{
-		Addr:         cnf.BindAddress,
-		Handler:      sm,
-		ReadTimeout:  time.Second * 5,
-		WriteTimeout: time.Second * 10,
-		IdleTimeout:  time.Second * 120,
+	s, err := server.New(cnf, requestChain.Then(sm), logger)
+	if err != nil {
+		logger.Error("Failed to create server", "err", err)
+		panic(err)
 	}
 
 	go func() {
-		logger.Info("Starting server on " + s.Addr)
-
-		err := s.ListenAndServe()
-		if err != nil {
+		// certificates are watched for changes as long as the background workers run
+		err := s.ListenAndServe(workersCtx)
+		if err != nil && err != http.ErrServerClosed {
 			logger.Error("Error starting server", "error", err)
 			os.Exit(1)
 		}
 	}()
 
+	// create the gRPC server, sharing the stores, the certificates and the OAuth verifier with the HTTP server
+	var gs *rpc.Server
+	if cnf.GRPCEnable {
+		gs = rpc.NewServer(rpc.Config{
+			Address:        cnf.GRPCBindAddress,
+			TLSConfig:      s.TLSConfig,
+			MaxRecvMsgSize: int(cnf.ServerMaxBodyBytes),
+			Reflection:     cnf.GRPCReflection,
+			RateLimiters:   rateLimiters,
+		}, rpc.Stores{
+			Locations:     locationStore,
+			Events:        eventStore,
+			Organizations: organizationStore,
+			Persons:       personStore,
+			Rooms:         roomStore,
+			Topics:        topicStore,
+			Tracks:        trackStore,
+			Talks:         talkStore,
+			TalkDates:     talkDateStore,
+		}, schedule, oauth, logger)
+
+		go func() {
+			if err := gs.ListenAndServe(); err != nil {
+				logger.Error("Error starting gRPC server", "error", err)
+				os.Exit(1)
+			}
+		}()
+	}
+
 	// trap sigterm or interrupt and gracefully shutdown the server
 	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt)
-	signal.Notify(c, os.Kill)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 
 	// block until a signal is received.
 	sig := <-c
-	logger.Info("Received signal", sig)
+	logger.Info("Received signal", "signal", sig)
 
-	// gracefully shutdown the server, waiting max 30 seconds for current operations to complete
-	logger.Info("Shutting down server...")
-	ctx, _ := context.WithTimeout(context.Background(), 30*time.Second)
-	s.Shutdown(ctx)
+	// a second signal skips the graceful shutdown
+	go func() {
+		sig := <-c
+		logger.Warn("Received second signal, exiting immediately", "signal", sig)
+		os.Exit(1)
+	}()
+
+	// fail the readiness probe first, giving the load balancer time to stop routing requests to us, then wait for
+	// the current operations to complete at most the drain timeout
+	shutdown := server.Shutdown{
+		SetShuttingDown: hh.SetShuttingDown,
+		ReadinessDelay:  cnf.ShutdownReadinessDelay,
+		DrainTimeout:    cnf.ShutdownDrainTimeout,
+		Servers:         []server.Stopper{s},
+		StopWorkers: func() {
+			stopWorkers()
+			workers.Wait()
+		},
+		Closers: []io.Closer{db},
+	}
+	if gs != nil {
+		shutdown.Servers = append(shutdown.Servers, gs)
+	}
+	shutdown.Run(logger)
 }
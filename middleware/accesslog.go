@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/hashicorp/go-hclog"
+	"github.com/milutindzunic/pac-backend/logging"
+	"net"
+	"net/http"
+	"time"
+)
+
+// AccessLog writes one line per request to log. It wraps the router, so the route template is looked up
+// in routes, and unmatched requests are logged as well.
+func AccessLog(log hclog.Logger, routes *mux.Router, trustedProxies []*net.IPNet) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, subject := logging.WithSubjectSlot(r.Context())
+			aw := &accessLogResponseWriter{w, http.StatusOK, 0}
+
+			start := time.Now()
+			next.ServeHTTP(aw, r.WithContext(ctx))
+			latency := time.Since(start)
+
+			route := ""
+			var match mux.RouteMatch
+			if routes.Match(r, &match) && match.Route != nil {
+				route, _ = match.Route.GetPathTemplate()
+			}
+
+			log.Info("request",
+				"request_id", logging.RequestIDFromContext(r.Context()),
+				"method", r.Method,
+				"path", r.URL.Path,
+				"route", route,
+				"status", aw.statusCode,
+				"bytes", aw.bytesWritten,
+				"latency_ms", float64(latency.Microseconds())/1000,
+				"subject", *subject,
+				"remote_ip", ClientIP(r, trustedProxies),
+			)
+		})
+	}
+}
+
+// accessLogResponseWriter captures the status and the number of bytes written of the response
+type accessLogResponseWriter struct {
+	http.ResponseWriter
+	statusCode   int
+	bytesWritten int
+}
+
+func (aw *accessLogResponseWriter) Write(bytes []byte) (int, error) {
+	n, err := aw.ResponseWriter.Write(bytes)
+	aw.bytesWritten += n
+	return n, err
+}
+
+func (aw *accessLogResponseWriter) WriteHeader(code int) {
+	aw.statusCode = code
+	aw.ResponseWriter.WriteHeader(code)
+}
+
+func (aw *accessLogResponseWriter) Flush() {
+	if f, ok := aw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
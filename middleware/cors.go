@@ -2,20 +2,164 @@ package middleware
 
 import (
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 )
 
-func AllowCORS(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+type CORSOptions struct {
+	// AllowedOrigins are exact origins like https://pac.example.com, wildcard subdomains like https://*.example.com,
+	// or * for any origin
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+type cors struct {
+	CORSOptions
+	allowAllOrigins bool
+	allowAllHeaders bool
+}
+
+// CORS answers preflight requests and adds the CORS headers to the responses for allowed origins.
+// It wraps the router, so preflights are answered whatever methods the routes accept.
+func CORS(opts CORSOptions) func(http.Handler) http.Handler {
+	c := &cors{CORSOptions: opts}
+	for _, origin := range opts.AllowedOrigins {
+		if origin == "*" {
+			c.allowAllOrigins = true
+		}
+	}
+	for _, header := range opts.AllowedHeaders {
+		if header == "*" {
+			c.allowAllHeaders = true
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			isPreflight := r.Method == http.MethodOptions && origin != "" && r.Header.Get("Access-Control-Request-Method") != ""
+
+			if c.variesByOrigin() {
+				w.Header().Add("Vary", "Origin")
+			}
+
+			if isPreflight {
+				w.Header().Add("Vary", "Access-Control-Request-Method")
+				w.Header().Add("Vary", "Access-Control-Request-Headers")
+				c.handlePreflight(w, r, origin)
+				return
+			}
+
+			if origin != "" && c.isOriginAllowed(origin) {
+				c.setAllowOrigin(w, origin)
+				if len(c.ExposedHeaders) > 0 {
+					w.Header().Set("Access-Control-Expose-Headers", strings.Join(c.ExposedHeaders, ", "))
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// handlePreflight answers the preflight, leaving out the CORS headers when the request is not allowed,
+// which makes the browser reject the actual request
+func (c *cors) handlePreflight(w http.ResponseWriter, r *http.Request, origin string) {
+	defer w.WriteHeader(http.StatusNoContent)
+
+	method := r.Header.Get("Access-Control-Request-Method")
+	requestedHeaders := splitHeaderList(r.Header.Get("Access-Control-Request-Headers"))
+	if !c.isOriginAllowed(origin) || !c.isMethodAllowed(method) || !c.areHeadersAllowed(requestedHeaders) {
+		return
+	}
+
+	c.setAllowOrigin(w, origin)
+	w.Header().Set("Access-Control-Allow-Methods", strings.Join(c.AllowedMethods, ", "))
+	if len(requestedHeaders) > 0 {
+		w.Header().Set("Access-Control-Allow-Headers", strings.Join(requestedHeaders, ", "))
+	}
+	if c.MaxAge > 0 {
+		w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(c.MaxAge.Seconds())))
+	}
+}
+
+func (c *cors) setAllowOrigin(w http.ResponseWriter, origin string) {
+	if c.allowAllOrigins && !c.AllowCredentials {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Headers", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "*")
+		return
+	}
+	// credentialed requests require the origin itself, never the wildcard
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+	if c.AllowCredentials {
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// variesByOrigin reports whether the CORS headers of the response depend on the Origin of the request
+func (c *cors) variesByOrigin() bool {
+	return !c.allowAllOrigins || c.AllowCredentials
+}
+
+func (c *cors) isOriginAllowed(origin string) bool {
+	if c.allowAllOrigins {
+		return true
+	}
+	origin = strings.ToLower(origin)
+	for _, allowed := range c.AllowedOrigins {
+		allowed = strings.ToLower(allowed)
+		if i := strings.Index(allowed, "*"); i >= 0 {
+			// https://*.example.com matches any subdomain of example.com, but not example.com itself
+			prefix, suffix := allowed[:i], allowed[i+1:]
+			if len(origin) > len(prefix)+len(suffix) && strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) &&
+				!strings.ContainsAny(origin[len(prefix):len(origin)-len(suffix)], "/:") {
+				return true
+			}
+		} else if origin == allowed {
+			return true
+		}
+	}
+	return false
+}
 
-		// Handle preflight OPTIONS request
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
+func (c *cors) isMethodAllowed(method string) bool {
+	for _, allowed := range c.AllowedMethods {
+		if strings.EqualFold(method, allowed) {
+			return true
 		}
+	}
+	return false
+}
 
-		next.ServeHTTP(w, r)
-	})
+func (c *cors) areHeadersAllowed(headers []string) bool {
+	if c.allowAllHeaders {
+		return true
+	}
+	for _, header := range headers {
+		found := false
+		for _, allowed := range c.AllowedHeaders {
+			if strings.EqualFold(header, allowed) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func splitHeaderList(list string) []string {
+	var headers []string
+	for _, header := range strings.Split(list, ",") {
+		if header = strings.TrimSpace(header); header != "" {
+			headers = append(headers, header)
+		}
+	}
+	return headers
 }
@@ -0,0 +1,15 @@
+package middleware
+
+import (
+	"net/http"
+)
+
+// LimitBody makes reading more than max bytes of a request body fail with an *http.MaxBytesError
+func LimitBody(max int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.Body = http.MaxBytesReader(w, r.Body, max)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package metrics
+
+import (
+	"database/sql"
+	"github.com/jinzhu/gorm"
+	"github.com/prometheus/client_golang/prometheus"
+	"time"
+)
+
+const startedAtKey = "metrics:started_at"
+
+type queryMetrics struct {
+	duration *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+}
+
+// InstrumentDB registers gorm callbacks recording the duration and errors of the queries per table and operation,
+// and a collector exposing the statistics of the connection pool
+func InstrumentDB(db *gorm.DB) {
+	labels := []string{"table", "operation"}
+	m := &queryMetrics{
+		duration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "db_query_duration_seconds",
+				Help:      "Duration of the database queries, partitioned by table and operation",
+				Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+			},
+			labels,
+		),
+		errors: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "db_query_errors_total",
+				Help:      "How many database queries failed, partitioned by table and operation",
+			},
+			labels,
+		),
+	}
+	prometheus.MustRegister(m.duration, m.errors, newDBStatsCollector(db.DB()))
+
+	callbacks := db.Callback()
+	callbacks.Create().Before("gorm:create").Register("metrics:before_create", before)
+	callbacks.Create().After("gorm:create").Register("metrics:after_create", m.after("create"))
+	callbacks.Query().Before("gorm:query").Register("metrics:before_query", before)
+	callbacks.Query().After("gorm:query").Register("metrics:after_query", m.after("query"))
+	callbacks.RowQuery().Before("gorm:row_query").Register("metrics:before_row_query", before)
+	callbacks.RowQuery().After("gorm:row_query").Register("metrics:after_row_query", m.after("query"))
+	callbacks.Update().Before("gorm:update").Register("metrics:before_update", before)
+	callbacks.Update().After("gorm:update").Register("metrics:after_update", m.after("update"))
+	callbacks.Delete().Before("gorm:delete").Register("metrics:before_delete", before)
+	callbacks.Delete().After("gorm:delete").Register("metrics:after_delete", m.after("delete"))
+}
+
+func before(scope *gorm.Scope) {
+	scope.Set(startedAtKey, time.Now())
+}
+
+func (m *queryMetrics) after(operation string) func(scope *gorm.Scope) {
+	return func(scope *gorm.Scope) {
+		startedAt, ok := scope.Get(startedAtKey)
+		if !ok {
+			return
+		}
+
+		table := scope.TableName()
+		m.duration.WithLabelValues(table, operation).Observe(time.Since(startedAt.(time.Time)).Seconds())
+
+		// a missing record is an expected outcome, not a failed query
+		if scope.HasError() && !gorm.IsRecordNotFoundError(scope.DB().Error) {
+			m.errors.WithLabelValues(table, operation).Inc()
+		}
+	}
+}
+
+// dbStatsCollector exposes the statistics of the connection pool on every scrape
+type dbStatsCollector struct {
+	db *sql.DB
+
+	maxOpen      *prometheus.Desc
+	open         *prometheus.Desc
+	inUse        *prometheus.Desc
+	idle         *prometheus.Desc
+	waitCount    *prometheus.Desc
+	waitDuration *prometheus.Desc
+	closed       *prometheus.Desc
+}
+
+func newDBStatsCollector(db *sql.DB) *dbStatsCollector {
+	desc := func(name string, help string, labels ...string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db", name), help, labels, nil)
+	}
+	return &dbStatsCollector{
+		db:           db,
+		maxOpen:      desc("max_open_connections", "Maximum number of open connections to the database"),
+		open:         desc("open_connections", "The number of established connections, both in use and idle"),
+		inUse:        desc("in_use_connections", "The number of connections currently in use"),
+		idle:         desc("idle_connections", "The number of idle connections"),
+		waitCount:    desc("wait_count_total", "The total number of connections waited for"),
+		waitDuration: desc("wait_duration_seconds_total", "The total time blocked waiting for a new connection"),
+		closed:       desc("closed_connections_total", "The total number of connections closed, partitioned by reason", "reason"),
+	}
+}
+
+func (c *dbStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.maxOpen
+	ch <- c.open
+	ch <- c.inUse
+	ch <- c.idle
+	ch <- c.waitCount
+	ch <- c.waitDuration
+	ch <- c.closed
+}
+
+func (c *dbStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.db.Stats()
+	ch <- prometheus.MustNewConstMetric(c.maxOpen, prometheus.GaugeValue, float64(stats.MaxOpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.open, prometheus.GaugeValue, float64(stats.OpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.inUse, prometheus.GaugeValue, float64(stats.InUse))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(stats.Idle))
+	ch <- prometheus.MustNewConstMetric(c.waitCount, prometheus.CounterValue, float64(stats.WaitCount))
+	ch <- prometheus.MustNewConstMetric(c.waitDuration, prometheus.CounterValue, stats.WaitDuration.Seconds())
+	ch <- prometheus.MustNewConstMetric(c.closed, prometheus.CounterValue, float64(stats.MaxIdleClosed), "max_idle")
+	ch <- prometheus.MustNewConstMetric(c.closed, prometheus.CounterValue, float64(stats.MaxIdleTimeClosed), "max_idle_time")
+	ch <- prometheus.MustNewConstMetric(c.closed, prometheus.CounterValue, float64(stats.MaxLifetimeClosed), "max_lifetime")
+}
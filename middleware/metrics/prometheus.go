@@ -1,6 +1,7 @@
 package metrics
 
 import (
+	"github.com/gorilla/mux"
 	"github.com/prometheus/client_golang/prometheus"
 	"net/http"
 	"strconv"
@@ -8,78 +9,129 @@ import (
 	"time"
 )
 
-var summaryVector *prometheus.SummaryVec
-var counterVector *prometheus.CounterVec
-var creatorLock sync.Mutex
+const namespace = "pac_backend_api"
 
-func getSummaryVector() *prometheus.SummaryVec {
-	creatorLock.Lock()
-	defer creatorLock.Unlock()
+// unmatchedRoute labels requests which did not match any registered route, to keep the label cardinality bounded
+const unmatchedRoute = "unmatched"
 
-	if summaryVector != nil {
-		return summaryVector
-	} else {
-		summaryVector = prometheus.NewSummaryVec(
-			prometheus.SummaryOpts{
-				Namespace: "pac_backend_api",
-				Name:      "api_requests_summary",
-				Help:      "Info about the API requests processed total",
-				Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
-			},
-			[]string{"service"},
-		)
-		prometheus.MustRegister(summaryVector)
-		return summaryVector
-	}
+type httpMetrics struct {
+	requests     *prometheus.CounterVec
+	duration     *prometheus.HistogramVec
+	responseSize *prometheus.HistogramVec
+	inFlight     *prometheus.GaugeVec
 }
 
-func getRequestsCounterVec() *prometheus.CounterVec {
+var requestMetrics *httpMetrics
+var creatorLock sync.Mutex
+
+func getHTTPMetrics() *httpMetrics {
 	creatorLock.Lock()
 	defer creatorLock.Unlock()
 
-	if counterVector != nil {
-		return counterVector
-	} else {
-		counterVector = prometheus.NewCounterVec(
+	if requestMetrics != nil {
+		return requestMetrics
+	}
+
+	labels := []string{"route", "method", "status"}
+	requestMetrics = &httpMetrics{
+		requests: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
-				Namespace: "pac_backend_api",
-				Name: "api_requests_total",
-				Help: "How many API requests processed, partitioned by status",
+				Namespace: namespace,
+				Name:      "http_requests_total",
+				Help:      "How many HTTP requests processed, partitioned by route, method and status code",
 			},
-			[]string{"status"},
-		)
-
-		prometheus.MustRegister(counterVector)
-		return counterVector
+			labels,
+		),
+		duration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "http_request_duration_seconds",
+				Help:      "Duration of the HTTP requests, partitioned by route, method and status code",
+				Buckets:   prometheus.DefBuckets,
+			},
+			labels,
+		),
+		responseSize: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "http_response_size_bytes",
+				Help:      "Size of the HTTP response bodies, partitioned by route, method and status code",
+				Buckets:   prometheus.ExponentialBuckets(64, 4, 8),
+			},
+			labels,
+		),
+		inFlight: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "http_requests_in_flight",
+				Help:      "How many HTTP requests are currently being processed, partitioned by route and method",
+			},
+			[]string{"route", "method"},
+		),
 	}
+	prometheus.MustRegister(requestMetrics.requests, requestMetrics.duration, requestMetrics.responseSize, requestMetrics.inFlight)
+	return requestMetrics
 }
 
+// Prometheus records the rate, errors and duration of the requests, labelled by the mux route template
 func Prometheus(next http.Handler) http.Handler {
 
-	counterVec := getRequestsCounterVec()
-	summaryVec := getSummaryVector()
+	m := getHTTPMetrics()
 
 	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		route := routeTemplate(r)
+
+		inFlight := m.inFlight.WithLabelValues(route, r.Method)
+		inFlight.Inc()
+		defer inFlight.Dec()
+
 		lrw := NewLoggingResponseWriter(rw)
 
 		start := time.Now()
 		next.ServeHTTP(lrw, r) // the call to next in the chain (with the wrapped ResponseWriter!)
 		duration := time.Since(start)
 
-		// Store duration of request
-		summaryVec.WithLabelValues("duration").Observe(duration.Seconds())
-
-		// Store size of response, if possible
-		size, err := strconv.Atoi(rw.Header().Get("Content-Length"))
-		if err == nil {
-			summaryVec.WithLabelValues("size").Observe(float64(size))
-		}
-
-		// Store the request success/error count
-		if lrw.statusCode >= 200 && lrw.statusCode < 300 {
-			counterVec.WithLabelValues("success").Inc()
-		} else {
-			counterVec.WithLabelValues("error").Inc()
-		}
+		status := strconv.Itoa(lrw.statusCode)
+		m.requests.WithLabelValues(route, r.Method, status).Inc()
+		m.duration.WithLabelValues(route, r.Method, status).Observe(duration.Seconds())
+		m.responseSize.WithLabelValues(route, r.Method, status).Observe(float64(lrw.bytesWritten))
 	})
 }
+
+// routeTemplate returns the path template of the matched mux route, e.g. /events/{id:[0-9]+}
+func routeTemplate(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return unmatchedRoute
+	}
+	template, err := route.GetPathTemplate()
+	if err != nil {
+		return unmatchedRoute
+	}
+	return template
+}
+
+var rateLimitedVector *prometheus.CounterVec
+
+func getRateLimitedCounterVec() *prometheus.CounterVec {
+	creatorLock.Lock()
+	defer creatorLock.Unlock()
+
+	if rateLimitedVector == nil {
+		rateLimitedVector = prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "rate_limited_requests_total",
+				Help:      "How many requests were rejected by the rate limiters, partitioned by limiter and whether the caller was identified by subject or IP",
+			},
+			[]string{"limiter", "key"},
+		)
+		prometheus.MustRegister(rateLimitedVector)
+	}
+	return rateLimitedVector
+}
+
+// RateLimited counts a request rejected by the named rate limiter
+func RateLimited(limiter string, keyType string) {
+	getRateLimitedCounterVec().WithLabelValues(limiter, keyType).Inc()
+}
@@ -2,14 +2,15 @@ package metrics
 
 import "net/http"
 
-// Helper Response Writer that captures the status of the response
+// Helper Response Writer that captures the status and the number of bytes written of the response
 type loggingResponseWriter struct {
 	http.ResponseWriter
-	statusCode int
+	statusCode   int
+	bytesWritten int
 }
 
 func NewLoggingResponseWriter(w http.ResponseWriter) *loggingResponseWriter {
-	return &loggingResponseWriter{w, http.StatusOK}
+	return &loggingResponseWriter{w, http.StatusOK, 0}
 }
 
 func (lrw *loggingResponseWriter) Header() http.Header {
@@ -17,7 +18,9 @@ func (lrw *loggingResponseWriter) Header() http.Header {
 }
 
 func (lrw *loggingResponseWriter) Write(bytes []byte) (int, error) {
-	return lrw.ResponseWriter.Write(bytes)
+	n, err := lrw.ResponseWriter.Write(bytes)
+	lrw.bytesWritten += n
+	return n, err
 }
 
 func (lrw *loggingResponseWriter) WriteHeader(code int) {
@@ -25,8 +28,9 @@ func (lrw *loggingResponseWriter) WriteHeader(code int) {
 	lrw.ResponseWriter.WriteHeader(code)
 }
 
-
-
-
-
-
+// Flush lets streaming handlers flush through the wrapper
+func (lrw *loggingResponseWriter) Flush() {
+	if f, ok := lrw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,150 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"github.com/milutindzunic/pac-backend/auth"
+	"github.com/milutindzunic/pac-backend/middleware/metrics"
+	"github.com/milutindzunic/pac-backend/problem"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often buckets which have refilled completely, i.e. of idle clients, are dropped
+const sweepInterval = time.Minute
+
+// RateLimiter keeps a token bucket per authenticated subject, or per client IP for anonymous requests
+type RateLimiter struct {
+	name           string
+	perMinute      int
+	burst          int
+	trustedProxies []*net.IPNet
+	byIP           bool
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// NewRateLimiter creates a limiter allowing perMinute requests per client on average, and bursts of up to burst requests.
+// The name labels the rejected requests in the metrics.
+func NewRateLimiter(name string, perMinute int, burst int, trustedProxies []*net.IPNet) *RateLimiter {
+	return &RateLimiter{
+		name:           name,
+		perMinute:      perMinute,
+		burst:          burst,
+		trustedProxies: trustedProxies,
+		buckets:        map[string]*bucket{},
+		lastSweep:      time.Now(),
+	}
+}
+
+// NewIPRateLimiter creates a limiter like NewRateLimiter, which keeps a bucket per client IP even for authenticated
+// requests. It goes in front of the authentication middleware, so guessing tokens is limited as well.
+func NewIPRateLimiter(name string, perMinute int, burst int, trustedProxies []*net.IPNet) *RateLimiter {
+	l := NewRateLimiter(name, perMinute, burst, trustedProxies)
+	l.byIP = true
+	return l
+}
+
+// Middleware rejects requests exceeding the budget of the caller with 429 Too Many Requests.
+// Unless the limiter is by IP, it must come after the authentication middleware in the chain, so that callers
+// are limited by subject.
+func (l *RateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keyType, key := l.keyOf(r.Context(), ClientIP(r, l.trustedProxies))
+		allowed, remaining, retryAfter, reset := l.take(keyType + ":" + key)
+
+		w.Header().Set("RateLimit-Policy", fmt.Sprintf("%d;w=60;burst=%d", l.perMinute, l.burst))
+		w.Header().Set("RateLimit-Limit", strconv.Itoa(l.burst))
+		w.Header().Set("RateLimit-Remaining", strconv.Itoa(remaining))
+		w.Header().Set("RateLimit-Reset", strconv.Itoa(seconds(reset)))
+
+		if !allowed {
+			metrics.RateLimited(l.name, keyType)
+			w.Header().Set("Retry-After", strconv.Itoa(seconds(retryAfter)))
+			problem.Write(problem.Problem{Type: "/problems/rate-limited", Title: "Too many requests", Status: http.StatusTooManyRequests,
+				Detail: fmt.Sprintf("Rate limit exceeded, retry in %d seconds", seconds(retryAfter))}, w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Allow takes a token from the budget of the caller for calls other than HTTP requests, like the ones of the gRPC API.
+// Without a token left, it returns the time until the next one is available.
+func (l *RateLimiter) Allow(ctx context.Context, ip string) (bool, time.Duration) {
+	keyType, key := l.keyOf(ctx, ip)
+	allowed, _, retryAfter, _ := l.take(keyType + ":" + key)
+	if !allowed {
+		metrics.RateLimited(l.name, keyType)
+	}
+	return allowed, retryAfter
+}
+
+// keyOf returns the kind and the key of the bucket of the caller: the authenticated subject, unless the limiter
+// is by IP, or the IP of the client
+func (l *RateLimiter) keyOf(ctx context.Context, ip string) (string, string) {
+	if identity, ok := auth.IdentityFromContext(ctx); ok && !l.byIP {
+		return "subject", identity.Subject
+	}
+	return "ip", ip
+}
+
+// take removes a token from the bucket of the key if there is one. It returns the tokens left,
+// the time until the next token is available and the time until the bucket is full again.
+func (l *RateLimiter) take(key string) (allowed bool, remaining int, retryAfter time.Duration, reset time.Duration) {
+	now := time.Now()
+	perSecond := float64(l.perMinute) / 60
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now, perSecond)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.burst), updated: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(l.burst), b.tokens+now.Sub(b.updated).Seconds()*perSecond)
+	b.updated = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		allowed = true
+	} else {
+		retryAfter = time.Duration((1 - b.tokens) / perSecond * float64(time.Second))
+	}
+
+	reset = time.Duration((float64(l.burst) - b.tokens) / perSecond * float64(time.Second))
+	return allowed, int(b.tokens), retryAfter, reset
+}
+
+func (l *RateLimiter) sweep(now time.Time, perSecond float64) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.updated).Seconds()*perSecond >= float64(l.burst) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// seconds rounds up, so clients retrying after the given number of seconds are not rejected again
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
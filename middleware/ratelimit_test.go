@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"github.com/milutindzunic/pac-backend/auth"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRateLimiterKeys(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	tests := []struct {
+		name    string
+		limiter *RateLimiter
+		// statuses of two requests from the same IP, with the tokens of two different subjects
+		want [2]int
+	}{
+		{"by subject", NewRateLimiter("write", 60, 1, nil), [2]int{http.StatusOK, http.StatusOK}},
+		{"by IP", NewIPRateLimiter("ip", 60, 1, nil), [2]int{http.StatusOK, http.StatusTooManyRequests}},
+	}
+
+	for _, tt := range tests {
+		handler := tt.limiter.Middleware(ok)
+		for i, subject := range []string{"alice", "bob"} {
+			r := httptest.NewRequest("POST", "/talks", nil)
+			r.RemoteAddr = "192.0.2.1:1234"
+			r = r.WithContext(auth.WithIdentity(r.Context(), auth.Identity{Subject: subject}))
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, r)
+
+			if rw.Code != tt.want[i] {
+				t.Errorf("%s: request of %s = %d, want %d", tt.name, subject, rw.Code, tt.want[i])
+			}
+		}
+	}
+}
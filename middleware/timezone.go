@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"github.com/milutindzunic/pac-backend/data"
+	"github.com/milutindzunic/pac-backend/problem"
+	"net/http"
+)
+
+const TimezoneHeader = "Accept-Timezone"
+
+// Timezone reads the time zone the caller wants the times of the response in, from the tz query parameter or
+// else the Accept-Timezone header, as an IANA name like Europe/Belgrade. Without either, times are rendered in
+// the time zones of their events.
+func Timezone(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", TimezoneHeader)
+
+		param, name := "tz", r.URL.Query().Get("tz")
+		if name == "" {
+			param, name = TimezoneHeader, r.Header.Get(TimezoneHeader)
+		}
+		if name == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		zone, err := data.LoadTimezone(name)
+		if err != nil {
+			problem.Write(problem.For(&data.ValidationError{Entity: "Query", Errors: []data.FieldError{
+				{Field: param, Rule: "timezone", Message: "must be an IANA time zone like Europe/Belgrade"}}}), w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(data.WithTimezone(r.Context(), zone)))
+	})
+}
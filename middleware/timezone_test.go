@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"encoding/json"
+	"github.com/milutindzunic/pac-backend/data"
+	"github.com/milutindzunic/pac-backend/data/timezonetest"
+	"github.com/milutindzunic/pac-backend/problem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimezone(t *testing.T) {
+	// Belgrade switches from UTC+1 to UTC+2 at 01:00 UTC on 2021-03-28
+	times := []time.Time{
+		time.Date(2021, 3, 28, 0, 59, 0, 0, time.UTC),
+		time.Date(2021, 3, 28, 1, 0, 0, 0, time.UTC),
+	}
+	// renders the times in the requested zone, like the handlers do
+	handler := Timezone(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var rendered []string
+		for _, t := range times {
+			rendered = append(rendered, data.In(t.Local(), data.TimezoneOf(r.Context())).Format(time.RFC3339))
+		}
+		_, _ = w.Write([]byte(strings.Join(rendered, " ")))
+	}))
+
+	tests := []struct {
+		name   string
+		target string
+		header string
+		status int
+		want   string
+	}{
+		{"query parameter", "/talkDates?tz=Europe/Belgrade", "", http.StatusOK, "2021-03-28T01:59:00+01:00 2021-03-28T03:00:00+02:00"},
+		{"header", "/talkDates", "Europe/Belgrade", http.StatusOK, "2021-03-28T01:59:00+01:00 2021-03-28T03:00:00+02:00"},
+		{"query parameter before header", "/talkDates?tz=America/New_York", "Europe/Belgrade", http.StatusOK, "2021-03-27T20:59:00-04:00 2021-03-27T21:00:00-04:00"},
+		{"no zone", "/talkDates", "", http.StatusOK, "2021-03-28T00:59:00Z 2021-03-28T01:00:00Z"},
+		{"unknown zone", "/talkDates?tz=Europe/Novi_Sad", "", http.StatusUnprocessableEntity, "tz"},
+		{"zone of the server", "/talkDates", "Local", http.StatusUnprocessableEntity, TimezoneHeader},
+	}
+
+	timezonetest.Run(t, func(t *testing.T) {
+		for _, tt := range tests {
+			r := httptest.NewRequest("GET", tt.target, nil)
+			if tt.header != "" {
+				r.Header.Set(TimezoneHeader, tt.header)
+			}
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, r)
+
+			if rw.Code != tt.status {
+				t.Errorf("%s: status = %d, want %d", tt.name, rw.Code, tt.status)
+				continue
+			}
+			if tt.status == http.StatusOK {
+				if got := rw.Body.String(); got != tt.want {
+					t.Errorf("%s: rendered %s, want %s", tt.name, got, tt.want)
+				}
+				continue
+			}
+
+			var p problem.Problem
+			if err := json.Unmarshal(rw.Body.Bytes(), &p); err != nil {
+				t.Fatalf("%s: body is not a problem: %v", tt.name, err)
+			}
+			if rw.Header().Get("Content-Type") != problem.ContentType || len(p.Errors) != 1 || p.Errors[0].Field != tt.want ||
+				p.Errors[0].Rule != "timezone" || p.Instance != tt.target {
+				t.Errorf("%s: got %s %s, want a validation problem of %s", tt.name, rw.Header().Get("Content-Type"), rw.Body, tt.want)
+			}
+		}
+	})
+}
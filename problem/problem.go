@@ -0,0 +1,77 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/milutindzunic/pac-backend/data"
+	"net/http"
+	"strconv"
+)
+
+const ContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details object, extended with the list of failed fields for validation problems
+type Problem struct {
+	Type     string            `json:"type"`
+	Title    string            `json:"title"`
+	Status   int               `json:"status"`
+	Detail   string            `json:"detail,omitempty"`
+	Instance string            `json:"instance,omitempty"`
+	Errors   []data.FieldError `json:"errors,omitempty"`
+	// Dependents lists the entities preventing a delete
+	Dependents []data.Dependent `json:"dependents,omitempty"`
+}
+
+// For maps an error returned by the stores onto the problem describing it to the client.
+// Unknown errors are reported as internal server errors, without exposing their message.
+func For(err error) Problem {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return Problem{Type: "/problems/body-too-large", Title: "Request body too large", Status: http.StatusRequestEntityTooLarge,
+			Detail: "Request body must not exceed " + strconv.FormatInt(maxBytesErr.Limit, 10) + " bytes"}
+	}
+
+	var validationErr *data.ValidationError
+	if errors.As(err, &validationErr) {
+		return Problem{Type: "/problems/validation", Title: "Validation failed", Status: http.StatusUnprocessableEntity, Detail: err.Error(), Errors: validationErr.Errors}
+	}
+
+	var conflictErr *data.ConflictError
+	if errors.As(err, &conflictErr) {
+		return Problem{Type: "/problems/conflict", Title: "Conflict", Status: http.StatusConflict, Detail: err.Error(), Dependents: conflictErr.Dependents}
+	}
+
+	switch {
+	case errors.Is(err, data.ErrNotFound):
+		return Problem{Type: "/problems/not-found", Title: "Entity not found", Status: http.StatusNotFound, Detail: err.Error()}
+	case errors.Is(err, data.ErrConflict):
+		return Problem{Type: "/problems/conflict", Title: "Conflict", Status: http.StatusConflict, Detail: err.Error()}
+	case errors.Is(err, data.ErrForbidden):
+		return Problem{Type: "/problems/forbidden", Title: "Forbidden", Status: http.StatusForbidden, Detail: err.Error()}
+	case errors.Is(err, data.ErrUnavailable):
+		return Problem{Type: "/problems/unavailable", Title: "Service unavailable", Status: http.StatusServiceUnavailable, Detail: err.Error()}
+	default:
+		return Problem{Type: "about:blank", Title: "Internal server error", Status: http.StatusInternalServerError, Detail: "An unexpected error occurred"}
+	}
+}
+
+// Write is the single place where problems are turned into application/problem+json responses, for the
+// handlers and the middleware alike. The instance is the URI of the request.
+func Write(problem Problem, rw http.ResponseWriter, r *http.Request) {
+	problem.Instance = r.URL.RequestURI()
+
+	jsonBytes, err := json.Marshal(problem)
+	if err != nil {
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", ContentType)
+	rw.Header().Set("Content-Length", strconv.Itoa(len(jsonBytes)))
+	if problem.Status == http.StatusServiceUnavailable {
+		rw.Header().Set("Retry-After", "5")
+	}
+
+	rw.WriteHeader(problem.Status)
+	_, _ = rw.Write(jsonBytes)
+}
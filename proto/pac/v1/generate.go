@@ -0,0 +1,5 @@
+// Package pacv1 contains the protobuf messages and the gRPC services of the API, generated from the .proto files
+// of this directory
+package pacv1
+
+//go:generate protoc -I ../.. --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative pac/v1/entities.proto pac/v1/services.proto
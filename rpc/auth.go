@@ -0,0 +1,109 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"github.com/hashicorp/go-hclog"
+	"github.com/milutindzunic/pac-backend/auth"
+	"github.com/milutindzunic/pac-backend/logging"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"strings"
+)
+
+// readPrefixes are the prefixes of the methods of the pac.v1 services which only read data. Every other method of
+// them is taken to change data and requires authentication, so a new method is protected until it is listed here.
+var readPrefixes = []string{"Get", "List", "Watch"}
+
+// authenticator verifies the bearer token sent in the authorization metadata with the OIDC verifier of the HTTP API.
+// Like in the REST API, the methods changing data require a valid token while reads are open to everyone,
+// unless callers are scoped to tenants; an invalid token is rejected in any case.
+type authenticator struct {
+	provider *auth.OauthProvider
+	log      hclog.Logger
+}
+
+func (a authenticator) unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := a.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a authenticator) stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := a.authenticate(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &contextStream{ss, ctx})
+}
+
+// authenticate returns the context with the identity of the caller
+func (a authenticator) authenticate(ctx context.Context, fullMethod string) (context.Context, error) {
+	if !a.provider.Enabled() {
+		return ctx, nil
+	}
+
+	rawToken, err := bearerToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if rawToken == "" {
+		if isWrite(fullMethod) || a.provider.Tenancy() {
+			return nil, status.Error(codes.Unauthenticated, "a bearer token is required")
+		}
+		return ctx, nil
+	}
+
+	identity, err := a.provider.Verify(rawToken)
+	if errors.Is(err, auth.ErrNoTenant) {
+		logging.FromContext(ctx, a.log).Warn("Access token carries no organization", "err", err)
+		return nil, status.Error(codes.PermissionDenied, "the bearer token carries no organization")
+	}
+	if err != nil {
+		logging.FromContext(ctx, a.log).Warn("Access token invalid", "err", err)
+		return nil, status.Error(codes.Unauthenticated, "invalid bearer token")
+	}
+	return auth.WithIdentity(ctx, identity), nil
+}
+
+// bearerToken returns the token of the authorization metadata, or an empty string if there is none
+func bearerToken(ctx context.Context) (string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return "", nil
+	}
+
+	parts := strings.Fields(values[0])
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+		return "", status.Error(codes.Unauthenticated, "authorization must be a bearer token")
+	}
+	return parts[1], nil
+}
+
+func isWrite(fullMethod string) bool {
+	if !strings.HasPrefix(fullMethod, "/pac.v1.") {
+		return false
+	}
+	method := fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+	for _, prefix := range readPrefixes {
+		if strings.HasPrefix(method, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// contextStream replaces the context of a server stream
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,53 @@
+package rpc
+
+import (
+	"github.com/hashicorp/go-hclog"
+	"github.com/milutindzunic/pac-backend/auth"
+	"strings"
+	"testing"
+)
+
+func TestIsWrite(t *testing.T) {
+	// the methods of the pac.v1 services which only read data, all others change it
+	reads := map[string]bool{
+		"GetLocation": true, "ListLocations": true, "ListLocationsNear": true,
+		"GetEvent": true, "ListEvents": true,
+		"GetOrganization": true, "ListOrganizations": true,
+		"GetPerson": true, "ListPersons": true,
+		"GetRoom": true, "ListRooms": true,
+		"GetTopic": true, "ListTopics": true,
+		"GetTrack": true, "ListTracks": true,
+		"GetTalk": true, "ListTalks": true,
+		"GetTalkDate": true, "ListTalkDates": true,
+		"WatchSchedule": true,
+	}
+
+	provider, err := auth.NewProvider(auth.OauthConfig{}, hclog.NewNullLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer(Config{}, Stores{}, NewSchedule(), provider, hclog.NewNullLogger())
+
+	found := 0
+	for service, info := range s.GetServiceInfo() {
+		if !strings.HasPrefix(service, "pac.v1.") {
+			continue
+		}
+		for _, method := range info.Methods {
+			fullMethod := "/" + service + "/" + method.Name
+			if got := isWrite(fullMethod); got == reads[method.Name] {
+				t.Errorf("isWrite(%s) = %t, want %t", fullMethod, got, !reads[method.Name])
+			}
+			if reads[method.Name] {
+				found++
+			}
+		}
+	}
+	if found != len(reads) {
+		t.Errorf("found %d of the %d read methods among the registered ones", found, len(reads))
+	}
+
+	if isWrite("/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo") {
+		t.Error("the reflection service is taken to change data")
+	}
+}
@@ -0,0 +1,318 @@
+package rpc
+
+import (
+	"github.com/milutindzunic/pac-backend/data"
+	pacv1 "github.com/milutindzunic/pac-backend/proto/pac/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
+)
+
+// The to* functions convert the entities of the stores into messages, including the relations which are loaded.
+// The from* functions convert the messages of the requests into entities, taking only the ids of the relations.
+
+func toLocation(loc *data.Location) *pacv1.Location {
+	if loc == nil {
+		return nil
+	}
+	return &pacv1.Location{
+		Id:   uint32(loc.ID),
+		Name: loc.Name,
+		Address: &pacv1.Address{
+			Street:     loc.Address.Street,
+			PostalCode: loc.Address.PostalCode,
+			City:       loc.Address.City,
+			Country:    loc.Address.Country,
+		},
+		Latitude:    loc.Latitude,
+		Longitude:   loc.Longitude,
+		Timezone:    loc.Timezone,
+		Description: loc.Description,
+		Distance:    loc.Distance,
+	}
+}
+
+func fromLocation(loc *pacv1.Location) *data.Location {
+	return &data.Location{
+		Name: loc.GetName(),
+		Address: data.Address{
+			Street:     loc.GetAddress().GetStreet(),
+			PostalCode: loc.GetAddress().GetPostalCode(),
+			City:       loc.GetAddress().GetCity(),
+			Country:    loc.GetAddress().GetCountry(),
+		},
+		Latitude:    loc.Latitude,
+		Longitude:   loc.Longitude,
+		Timezone:    loc.GetTimezone(),
+		Description: loc.GetDescription(),
+	}
+}
+
+func toOrganization(org *data.Organization) *pacv1.Organization {
+	if org == nil {
+		return nil
+	}
+	return &pacv1.Organization{Id: uint32(org.ID), Name: org.Name}
+}
+
+func fromOrganization(org *pacv1.Organization) *data.Organization {
+	return &data.Organization{Name: org.GetName()}
+}
+
+func toEvent(event *data.Event) *pacv1.Event {
+	if event == nil {
+		return nil
+	}
+	return &pacv1.Event{
+		Id:           uint32(event.ID),
+		Name:         event.Name,
+		BeginDate:    toTimestamp(event.BeginDate),
+		EndDate:      toTimestamp(event.EndDate),
+		Location:     toLocation(event.Location),
+		Organization: toOrganization(event.Organization),
+		Timezone:     event.Timezone,
+	}
+}
+
+func fromEvent(event *pacv1.Event) *data.Event {
+	return &data.Event{
+		Name:           event.GetName(),
+		BeginDate:      fromTimestamp(event.GetBeginDate()),
+		EndDate:        fromTimestamp(event.GetEndDate()),
+		LocationID:     optionalID(event.GetLocation().GetId()),
+		OrganizationID: optionalID(event.GetOrganization().GetId()),
+		Timezone:       event.GetTimezone(),
+	}
+}
+
+func toPerson(person *data.Person) *pacv1.Person {
+	if person == nil {
+		return nil
+	}
+	return &pacv1.Person{
+		Id:           uint32(person.ID),
+		Name:         person.Name,
+		Organization: toOrganization(person.Organization),
+		JobTitle:     person.JobTitle,
+		Bio:          person.Bio,
+		PhotoUrl:     person.PhotoURL,
+		SocialLinks:  person.SocialLinks,
+	}
+}
+
+func fromPerson(person *pacv1.Person) *data.Person {
+	return &data.Person{
+		Name:           person.GetName(),
+		OrganizationID: uint(person.GetOrganization().GetId()),
+		JobTitle:       person.GetJobTitle(),
+		Bio:            person.GetBio(),
+		PhotoURL:       person.GetPhotoUrl(),
+		SocialLinks:    person.GetSocialLinks(),
+	}
+}
+
+func toRoom(room *data.Room) *pacv1.Room {
+	if room == nil {
+		return nil
+	}
+	return &pacv1.Room{
+		Id:           uint32(room.ID),
+		Name:         room.Name,
+		Organization: toOrganization(room.Organization),
+		Location:     toLocation(room.Location),
+	}
+}
+
+func fromRoom(room *pacv1.Room) *data.Room {
+	return &data.Room{
+		Name:           room.GetName(),
+		OrganizationID: uint(room.GetOrganization().GetId()),
+		LocationID:     optionalID(room.GetLocation().GetId()),
+	}
+}
+
+func toTopic(topic *data.Topic) *pacv1.Topic {
+	if topic == nil {
+		return nil
+	}
+	children := make([]*pacv1.Topic, 0, len(topic.Children))
+	for i := range topic.Children {
+		children = append(children, toTopic(&topic.Children[i]))
+	}
+	return &pacv1.Topic{Id: uint32(topic.ID), Name: topic.Name, Children: children}
+}
+
+// fromTopic leaves out the children, which are saved along with the topic and are loaded by the topic service
+func fromTopic(topic *pacv1.Topic) *data.Topic {
+	return &data.Topic{Name: topic.GetName()}
+}
+
+func toTrack(track *data.Track) *pacv1.Track {
+	if track == nil {
+		return nil
+	}
+	return &pacv1.Track{Id: uint32(track.ID), Name: track.Name, Color: track.Color, Event: toEvent(track.Event)}
+}
+
+func fromTrack(track *pacv1.Track) *data.Track {
+	return &data.Track{Name: track.GetName(), Color: track.GetColor(), EventID: uint(track.GetEvent().GetId())}
+}
+
+var talkLevels = map[data.TalkLevel]pacv1.TalkLevel{
+	data.BeginnerLevel: pacv1.TalkLevel_TALK_LEVEL_BEGINNER,
+	data.AdvancedLevel: pacv1.TalkLevel_TALK_LEVEL_ADVANCED,
+	data.ExpertLevel:   pacv1.TalkLevel_TALK_LEVEL_EXPERT,
+}
+
+var talkFormats = map[data.TalkFormat]pacv1.TalkFormat{
+	data.KeynoteFormat:   pacv1.TalkFormat_TALK_FORMAT_KEYNOTE,
+	data.RegularFormat:   pacv1.TalkFormat_TALK_FORMAT_TALK,
+	data.WorkshopFormat:  pacv1.TalkFormat_TALK_FORMAT_WORKSHOP,
+	data.PanelFormat:     pacv1.TalkFormat_TALK_FORMAT_PANEL,
+	data.LightningFormat: pacv1.TalkFormat_TALK_FORMAT_LIGHTNING,
+}
+
+// fromTalkLevel returns an empty level for an unspecified one
+func fromTalkLevel(level pacv1.TalkLevel) data.TalkLevel {
+	for l, pl := range talkLevels {
+		if pl == level {
+			return l
+		}
+	}
+	return ""
+}
+
+// fromTalkFormat returns an empty format for an unspecified one
+func fromTalkFormat(format pacv1.TalkFormat) data.TalkFormat {
+	for f, pf := range talkFormats {
+		if pf == format {
+			return f
+		}
+	}
+	return ""
+}
+
+func toTalk(talk *data.Talk) *pacv1.Talk {
+	if talk == nil {
+		return nil
+	}
+	persons := make([]*pacv1.Person, 0, len(talk.Persons))
+	for i := range talk.Persons {
+		persons = append(persons, toPerson(&talk.Persons[i]))
+	}
+	topics := make([]*pacv1.Topic, 0, len(talk.Topics))
+	for i := range talk.Topics {
+		topics = append(topics, toTopic(&talk.Topics[i]))
+	}
+	talkDates := make([]*pacv1.TalkDate, 0, len(talk.TalkDates))
+	for i := range talk.TalkDates {
+		talkDates = append(talkDates, toTalkDate(&talk.TalkDates[i]))
+	}
+	return &pacv1.Talk{
+		Id:                uint32(talk.ID),
+		Title:             talk.Title,
+		DurationInMinutes: uint32(talk.DurationInMinutes),
+		Language:          talk.Language,
+		Level:             talkLevels[talk.Level],
+		Format:            talkFormats[talk.Format],
+		Abstract:          talk.Abstract,
+		Prerequisites:     talk.Prerequisites,
+		SlidesUrl:         talk.SlidesURL,
+		VideoUrl:          talk.VideoURL,
+		RepositoryUrl:     talk.RepositoryURL,
+		Track:             toTrack(talk.Track),
+		Persons:           persons,
+		Topics:            topics,
+		TalkDates:         talkDates,
+		Organization:      toOrganization(talk.Organization),
+	}
+}
+
+func fromTalk(talk *pacv1.Talk) *data.Talk {
+	persons := make([]data.Person, 0, len(talk.GetPersons()))
+	for _, person := range talk.GetPersons() {
+		persons = append(persons, data.Person{ID: uint(person.GetId())})
+	}
+	topics := make([]data.Topic, 0, len(talk.GetTopics()))
+	for _, topic := range talk.GetTopics() {
+		topics = append(topics, data.Topic{ID: uint(topic.GetId())})
+	}
+	// an unspecified level is left empty, so that it is rejected by the validation of the store,
+	// while an unspecified format is left to the default of the store
+	return &data.Talk{
+		Title:             talk.GetTitle(),
+		DurationInMinutes: uint(talk.GetDurationInMinutes()),
+		Language:          talk.GetLanguage(),
+		Level:             fromTalkLevel(talk.GetLevel()),
+		Format:            fromTalkFormat(talk.GetFormat()),
+		Abstract:          talk.GetAbstract(),
+		Prerequisites:     talk.GetPrerequisites(),
+		SlidesURL:         talk.GetSlidesUrl(),
+		VideoURL:          talk.GetVideoUrl(),
+		RepositoryURL:     talk.GetRepositoryUrl(),
+		TrackID:           optionalID(talk.GetTrack().GetId()),
+		Persons:           persons,
+		Topics:            topics,
+		OrganizationID:    optionalID(talk.GetOrganization().GetId()),
+	}
+}
+
+func toTalkDate(talkDate *data.TalkDate) *pacv1.TalkDate {
+	if talkDate == nil {
+		return nil
+	}
+	td := &pacv1.TalkDate{
+		Id:         uint32(talkDate.ID),
+		BeginDate:  toTimestamp(talkDate.BeginDate),
+		Talk:       toTalk(talkDate.Talk),
+		Room:       toRoom(talkDate.Room),
+		Event:      toEvent(talkDate.Event),
+		Location:   toLocation(talkDate.Location),
+		Recurrence: talkDate.Recurrence,
+		Exceptions: talkDate.Exceptions,
+		Series:     toTalkDate(talkDate.Series),
+	}
+	if talkDate.RecurrenceDate != nil {
+		td.RecurrenceDate = toTimestamp(*talkDate.RecurrenceDate)
+	}
+	if td.Series == nil && talkDate.SeriesID != nil {
+		td.Series = &pacv1.TalkDate{Id: uint32(*talkDate.SeriesID)}
+	}
+	return td
+}
+
+func fromTalkDate(talkDate *pacv1.TalkDate) *data.TalkDate {
+	return &data.TalkDate{
+		BeginDate:  fromTimestamp(talkDate.GetBeginDate()),
+		TalkID:     uint(talkDate.GetTalk().GetId()),
+		RoomID:     optionalID(talkDate.GetRoom().GetId()),
+		EventID:    optionalID(talkDate.GetEvent().GetId()),
+		LocationID: optionalID(talkDate.GetLocation().GetId()),
+		Recurrence: talkDate.GetRecurrence(),
+		// an empty list is read as nil, which series updates take for no exceptions
+		Exceptions: talkDate.GetExceptions(),
+	}
+}
+
+func toTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+// fromTimestamp returns the zero time for a missing timestamp, which is rejected by the validation of the stores
+func fromTimestamp(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
+
+// optionalID returns nil for a missing reference, for the references which are optional
+func optionalID(id uint32) *uint {
+	if id == 0 {
+		return nil
+	}
+	ref := uint(id)
+	return &ref
+}
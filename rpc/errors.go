@@ -0,0 +1,69 @@
+package rpc
+
+import (
+	"errors"
+	"github.com/hashicorp/go-hclog"
+	"github.com/milutindzunic/pac-backend/data"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"strconv"
+)
+
+// statusOf maps an error returned by the stores onto the status reported to the client, like the problem types
+// of the REST API. Failed fields and dependents are attached as BadRequest and PreconditionFailure details.
+// Unknown errors are logged and reported as internal errors, without exposing their message.
+func statusOf(err error, log hclog.Logger) error {
+	if err == nil {
+		return nil
+	}
+
+	var validationErr *data.ValidationError
+	if errors.As(err, &validationErr) {
+		violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(validationErr.Errors))
+		for _, fe := range validationErr.Errors {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{Field: fe.Field, Description: fe.Message})
+		}
+		st := status.New(codes.InvalidArgument, err.Error())
+		if detailed, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations}); err == nil {
+			st = detailed
+		}
+		return st.Err()
+	}
+
+	var conflictErr *data.ConflictError
+	if errors.As(err, &conflictErr) {
+		st := status.New(codes.FailedPrecondition, err.Error())
+		if len(conflictErr.Dependents) == 0 {
+			return st.Err()
+		}
+		var violations []*errdetails.PreconditionFailure_Violation
+		for _, dependent := range conflictErr.Dependents {
+			for _, id := range dependent.IDs {
+				violations = append(violations, &errdetails.PreconditionFailure_Violation{
+					Type:        "DEPENDENT",
+					Subject:     dependent.Entity + "/" + strconv.FormatUint(uint64(id), 10),
+					Description: "refers to the " + conflictErr.Entity,
+				})
+			}
+		}
+		if detailed, err := st.WithDetails(&errdetails.PreconditionFailure{Violations: violations}); err == nil {
+			st = detailed
+		}
+		return st.Err()
+	}
+
+	switch {
+	case errors.Is(err, data.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, data.ErrConflict):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, data.ErrForbidden):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, data.ErrUnavailable):
+		return status.Error(codes.Unavailable, err.Error())
+	default:
+		log.Error("Unexpected error handling gRPC call", "err", err)
+		return status.Error(codes.Internal, "An unexpected error occurred")
+	}
+}
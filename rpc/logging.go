@@ -0,0 +1,31 @@
+package rpc
+
+import (
+	"context"
+	"github.com/hashicorp/go-hclog"
+	"github.com/milutindzunic/pac-backend/logging"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+// logger stores a logger tagged with the called method in the context, and logs the outcome of every call
+type logger struct {
+	log hclog.Logger
+}
+
+func (l logger) unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	log := l.log.With("grpc_method", info.FullMethod)
+	start := time.Now()
+	resp, err := handler(logging.WithLogger(ctx, log), req)
+	log.Debug("gRPC call finished", "code", status.Code(err), "duration", time.Since(start))
+	return resp, err
+}
+
+func (l logger) stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	log := l.log.With("grpc_method", info.FullMethod)
+	start := time.Now()
+	err := handler(srv, &contextStream{ss, logging.WithLogger(ss.Context(), log)})
+	log.Debug("gRPC stream finished", "code", status.Code(err), "duration", time.Since(start))
+	return err
+}
@@ -0,0 +1,76 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"github.com/milutindzunic/pac-backend/middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"math"
+	"net"
+)
+
+// RateLimiters limit the calls like the requests of the HTTP API, sharing their budgets when they are the same
+// limiters. A nil limiter lets every call through.
+type RateLimiters struct {
+	// IP limits the calls per peer IP, before the token is verified
+	IP *middleware.RateLimiter
+	// Read and Write limit the calls per subject, or per peer IP for anonymous callers
+	Read  *middleware.RateLimiter
+	Write *middleware.RateLimiter
+}
+
+// limiter rejects the calls exceeding the budget of the caller with ResourceExhausted
+type limiter struct {
+	limiters RateLimiters
+	// byIP tells whether the limiter goes before the authentication, and uses the IP limiter
+	byIP bool
+}
+
+func (l limiter) unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := l.allow(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (l limiter) stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := l.allow(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+func (l limiter) allow(ctx context.Context, fullMethod string) error {
+	limiter := l.limiters.Read
+	switch {
+	case l.byIP:
+		limiter = l.limiters.IP
+	case isWrite(fullMethod):
+		limiter = l.limiters.Write
+	}
+	if limiter == nil {
+		return nil
+	}
+
+	allowed, retryAfter := limiter.Allow(ctx, peerIP(ctx))
+	if !allowed {
+		return status.Error(codes.ResourceExhausted, fmt.Sprintf("rate limit exceeded, retry in %d seconds", int(math.Ceil(retryAfter.Seconds()))))
+	}
+	return nil
+}
+
+// peerIP returns the IP address of the caller, or the whole address if it has no port
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
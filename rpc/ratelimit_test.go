@@ -0,0 +1,53 @@
+package rpc
+
+import (
+	"context"
+	"github.com/milutindzunic/pac-backend/auth"
+	"github.com/milutindzunic/pac-backend/middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"net"
+	"testing"
+)
+
+func TestLimiter(t *testing.T) {
+	limiters := RateLimiters{
+		IP:    middleware.NewIPRateLimiter("ip", 60, 3, nil),
+		Read:  middleware.NewRateLimiter("read", 60, 2, nil),
+		Write: middleware.NewRateLimiter("write", 60, 1, nil),
+	}
+	// like the server chains them, with the authentication in between
+	ipLimit, limit := limiter{limiters, true}, limiter{limiters, false}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+	call := func(ctx context.Context, method string) codes.Code {
+		info := &grpc.UnaryServerInfo{FullMethod: method}
+		_, err := ipLimit.unary(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return limit.unary(ctx, req, info, handler)
+		})
+		return status.Code(err)
+	}
+
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 1234}})
+	alice := auth.WithIdentity(ctx, auth.Identity{Subject: "alice"})
+	bob := auth.WithIdentity(ctx, auth.Identity{Subject: "bob"})
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		method string
+		want   codes.Code
+	}{
+		{"first write of alice", alice, "/pac.v1.TalkService/UpdateTalk", codes.OK},
+		{"second write of alice", alice, "/pac.v1.TalkService/UpdateTalk", codes.ResourceExhausted},
+		{"read of alice", alice, "/pac.v1.TalkService/GetTalk", codes.OK},
+		// bob has budgets of their own, but shares the one of the IP with alice
+		{"write of bob beyond the budget of the IP", bob, "/pac.v1.TalkService/UpdateTalk", codes.ResourceExhausted},
+	}
+	for _, tt := range tests {
+		if got := call(tt.ctx, tt.method); got != tt.want {
+			t.Errorf("%s: code = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
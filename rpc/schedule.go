@@ -0,0 +1,259 @@
+package rpc
+
+import (
+	"context"
+	"github.com/milutindzunic/pac-backend/data"
+	pacv1 "github.com/milutindzunic/pac-backend/proto/pac/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"sync"
+)
+
+// subscriberBuffer is the number of changes buffered for a stream, a stream falling further behind is ended
+const subscriberBuffer = 64
+
+// Schedule fans out the changes of the talk dates made through this instance to the streams watching them
+type Schedule struct {
+	mu          sync.Mutex
+	subscribers map[*subscriber]struct{}
+	closed      bool
+}
+
+type subscriber struct {
+	// eventID filters the changes by event, all changes are received when 0
+	eventID uint
+	// organizationID filters the changes by tenant when scoped is set, for watchers scoped to a tenant
+	organizationID uint
+	scoped         bool
+	changes        chan *pacv1.ScheduleChange
+	// lagged is set when the subscriber is dropped for not keeping up with the changes
+	lagged bool
+}
+
+func NewSchedule() *Schedule {
+	return &Schedule{subscribers: map[*subscriber]struct{}{}}
+}
+
+// subscribe returns a subscriber receiving the changes published from now on, until it is unsubscribed
+// or the schedule is closed, which closes its channel
+func (s *Schedule) subscribe(ctx context.Context, eventID uint) *subscriber {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub := &subscriber{eventID: eventID, changes: make(chan *pacv1.ScheduleChange, subscriberBuffer)}
+	sub.organizationID, sub.scoped = data.TenantOf(ctx)
+	if s.closed {
+		close(sub.changes)
+		return sub
+	}
+	s.subscribers[sub] = struct{}{}
+	return sub
+}
+
+func (s *Schedule) unsubscribe(sub *subscriber) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.subscribers[sub]; ok {
+		delete(s.subscribers, sub)
+		close(sub.changes)
+	}
+}
+
+// publish sends the change to every subscriber without blocking, so a slow stream cannot hold up the stores.
+// Changes made in a transaction are sent once it is committed.
+func (s *Schedule) publish(ctx context.Context, kind pacv1.ScheduleChange_Kind, talkDate *data.TalkDate) {
+	data.AfterCommit(ctx, func() {
+		s.send(kind, talkDate)
+	})
+}
+
+func (s *Schedule) send(kind pacv1.ScheduleChange_Kind, talkDate *data.TalkDate) {
+	change := &pacv1.ScheduleChange{Kind: kind, TalkDate: toTalkDate(talkDate), Time: timestamppb.Now()}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for sub := range s.subscribers {
+		if sub.eventID != 0 && (talkDate.EventID == nil || *talkDate.EventID != sub.eventID) {
+			continue
+		}
+		// talk dates whose event is not loaded cannot be told apart by tenant, they are kept from scoped watchers
+		if sub.scoped && (talkDate.Event == nil || talkDate.Event.OrganizationID == nil || *talkDate.Event.OrganizationID != sub.organizationID) {
+			continue
+		}
+		select {
+		case sub.changes <- change:
+		default:
+			sub.lagged = true
+			delete(s.subscribers, sub)
+			close(sub.changes)
+		}
+	}
+}
+
+// Close ends all streams, and the ones subscribing afterwards, so that the server can stop gracefully
+func (s *Schedule) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	for sub := range s.subscribers {
+		delete(s.subscribers, sub)
+		close(sub.changes)
+	}
+}
+
+// ScheduleTalkDateStore publishes the changes made through the talk date store it decorates to the schedule
+type ScheduleTalkDateStore struct {
+	data.TalkDateStore
+	schedule *Schedule
+}
+
+func NewScheduleTalkDateStore(store data.TalkDateStore, schedule *Schedule) *ScheduleTalkDateStore {
+	return &ScheduleTalkDateStore{store, schedule}
+}
+
+func (s *ScheduleTalkDateStore) AddTalkDate(ctx context.Context, talkDate *data.TalkDate) (*data.TalkDate, error) {
+	talkDate, err := s.TalkDateStore.AddTalkDate(ctx, talkDate)
+	if err == nil {
+		for _, created := range s.occurrences(ctx, talkDate) {
+			s.schedule.publish(ctx, pacv1.ScheduleChange_KIND_CREATED, created)
+		}
+	}
+	return talkDate, err
+}
+
+func (s *ScheduleTalkDateStore) UpdateTalkDate(ctx context.Context, id uint, talkDate *data.TalkDate) (*data.TalkDate, error) {
+	talkDate, err := s.TalkDateStore.UpdateTalkDate(ctx, id, talkDate)
+	if err == nil {
+		s.schedule.publish(ctx, pacv1.ScheduleChange_KIND_UPDATED, s.load(ctx, talkDate))
+	}
+	return talkDate, err
+}
+
+// UpdateTalkDateSeries publishes the occurrences of the series as created, updated or deleted, as the update changed them
+func (s *ScheduleTalkDateStore) UpdateTalkDateSeries(ctx context.Context, id uint, talkDate *data.TalkDate) (*data.TalkDate, error) {
+	current, err := s.TalkDateStore.GetTalkDateByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	seriesID := id
+	if current.SeriesID != nil {
+		seriesID = *current.SeriesID
+	}
+	before, err := s.TalkDateStore.GetTalkDatesBySeriesID(ctx, seriesID)
+	if err != nil {
+		return nil, err
+	}
+
+	talkDate, err = s.TalkDateStore.UpdateTalkDateSeries(ctx, id, talkDate)
+	if err != nil {
+		return nil, err
+	}
+	after, err := s.TalkDateStore.GetTalkDatesBySeriesID(ctx, seriesID)
+	if err != nil {
+		after = []*data.TalkDate{talkDate}
+	}
+
+	deleted := map[uint]*data.TalkDate{}
+	for _, occurrence := range before {
+		deleted[occurrence.ID] = occurrence
+	}
+	for _, occurrence := range after {
+		if _, ok := deleted[occurrence.ID]; ok {
+			delete(deleted, occurrence.ID)
+			s.schedule.publish(ctx, pacv1.ScheduleChange_KIND_UPDATED, occurrence)
+		} else {
+			s.schedule.publish(ctx, pacv1.ScheduleChange_KIND_CREATED, occurrence)
+		}
+	}
+	for _, occurrence := range before {
+		if _, ok := deleted[occurrence.ID]; ok {
+			s.schedule.publish(ctx, pacv1.ScheduleChange_KIND_DELETED, occurrence)
+		}
+	}
+	return talkDate, nil
+}
+
+func (s *ScheduleTalkDateStore) DeleteTalkDateByID(ctx context.Context, id uint, cascade bool) error {
+	// loaded first, so that the watchers learn which event the deleted talk dates belonged to
+	talkDate, err := s.TalkDateStore.GetTalkDateByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	deleted := []*data.TalkDate{talkDate}
+	if cascade {
+		// the occurrences of a series are deleted along with its first talk date
+		deleted = s.occurrences(ctx, talkDate)
+	}
+	if err := s.TalkDateStore.DeleteTalkDateByID(ctx, id, cascade); err != nil {
+		return err
+	}
+	for _, occurrence := range deleted {
+		s.schedule.publish(ctx, pacv1.ScheduleChange_KIND_DELETED, occurrence)
+	}
+	return nil
+}
+
+func (s *ScheduleTalkDateStore) RestoreTalkDateByID(ctx context.Context, id uint) (*data.TalkDate, error) {
+	talkDate, err := s.TalkDateStore.RestoreTalkDateByID(ctx, id)
+	if err == nil {
+		for _, restored := range s.occurrences(ctx, talkDate) {
+			s.schedule.publish(ctx, pacv1.ScheduleChange_KIND_RESTORED, restored)
+		}
+	}
+	return talkDate, err
+}
+
+// load reloads the written talk date along with its relations, so that the watchers receive it as it is read.
+// The talk date is published as it was written if it cannot be reloaded.
+func (s *ScheduleTalkDateStore) load(ctx context.Context, talkDate *data.TalkDate) *data.TalkDate {
+	loaded, err := s.TalkDateStore.GetTalkDateByID(ctx, talkDate.ID)
+	if err != nil {
+		return talkDate
+	}
+	return loaded
+}
+
+// occurrences reloads the talk date along with the occurrences of the series it begins, if any
+func (s *ScheduleTalkDateStore) occurrences(ctx context.Context, talkDate *data.TalkDate) []*data.TalkDate {
+	if talkDate.Recurrence == "" {
+		return []*data.TalkDate{s.load(ctx, talkDate)}
+	}
+	occurrences, err := s.TalkDateStore.GetTalkDatesBySeriesID(ctx, talkDate.ID)
+	if err != nil || len(occurrences) == 0 {
+		return []*data.TalkDate{talkDate}
+	}
+	return occurrences
+}
+
+type scheduleService struct {
+	pacv1.UnimplementedScheduleServiceServer
+	schedule *Schedule
+}
+
+// WatchSchedule streams the changes until the client cancels, the server shuts down, or the client falls behind,
+// which ends the stream with RESOURCE_EXHAUSTED so that the client can reload the schedule and watch again
+func (s *scheduleService) WatchSchedule(req *pacv1.WatchScheduleRequest, stream pacv1.ScheduleService_WatchScheduleServer) error {
+	sub := s.schedule.subscribe(stream.Context(), uint(req.GetEventId()))
+	defer s.schedule.unsubscribe(sub)
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case change, ok := <-sub.changes:
+			if !ok {
+				if sub.lagged {
+					return status.Error(codes.ResourceExhausted, "the stream fell behind the schedule changes")
+				}
+				return status.Error(codes.Unavailable, "the server is shutting down")
+			}
+			if err := stream.Send(change); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package rpc
+
+import (
+	"context"
+	"crypto/tls"
+	"github.com/hashicorp/go-hclog"
+	"github.com/milutindzunic/pac-backend/auth"
+	pacv1 "github.com/milutindzunic/pac-backend/proto/pac/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/reflection"
+	"net"
+)
+
+// Config are the settings of the gRPC server
+type Config struct {
+	// Address is the TCP address to listen on
+	Address string
+	// TLSConfig is the TLS configuration of the HTTP server, nil to serve without TLS
+	TLSConfig *tls.Config
+	// MaxRecvMsgSize bounds the size of the received messages, like the body limit of the HTTP server
+	MaxRecvMsgSize int
+	// Reflection registers the server reflection service, so that tools like grpcurl can list the services
+	Reflection bool
+	// RateLimiters limit the calls, like the requests of the HTTP API
+	RateLimiters RateLimiters
+}
+
+// Server serves the gRPC API next to the HTTP server, through the same stores
+type Server struct {
+	*grpc.Server
+	address  string
+	schedule *Schedule
+	log      hclog.Logger
+}
+
+// NewServer creates a server for the services of the stores. The changes to be streamed by the schedule service
+// are published to schedule by the talk date store, see NewScheduleTalkDateStore.
+func NewServer(cnf Config, stores Stores, schedule *Schedule, provider *auth.OauthProvider, log hclog.Logger) *Server {
+	l := logger{log}
+	a := authenticator{provider, log}
+	// tokens are verified behind the limit per IP, the callers are limited by subject after that
+	ipLimit := limiter{cnf.RateLimiters, true}
+	limit := limiter{cnf.RateLimiters, false}
+	options := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(cnf.MaxRecvMsgSize),
+		grpc.ChainUnaryInterceptor(l.unary, ipLimit.unary, a.unary, limit.unary),
+		grpc.ChainStreamInterceptor(l.stream, ipLimit.stream, a.stream, limit.stream),
+	}
+	if cnf.TLSConfig != nil {
+		// the certificates are reloaded along with the ones of the HTTP server, as they share GetCertificate
+		tlsConfig := cnf.TLSConfig.Clone()
+		tlsConfig.NextProtos = []string{"h2"}
+		options = append(options, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	s := grpc.NewServer(options...)
+	pacv1.RegisterLocationServiceServer(s, &locationService{store: stores.Locations, log: log})
+	pacv1.RegisterEventServiceServer(s, &eventService{store: stores.Events, log: log})
+	pacv1.RegisterOrganizationServiceServer(s, &organizationService{store: stores.Organizations, log: log})
+	pacv1.RegisterPersonServiceServer(s, &personService{store: stores.Persons, log: log})
+	pacv1.RegisterRoomServiceServer(s, &roomService{store: stores.Rooms, log: log})
+	pacv1.RegisterTopicServiceServer(s, &topicService{store: stores.Topics, log: log})
+	pacv1.RegisterTrackServiceServer(s, &trackService{store: stores.Tracks, log: log})
+	pacv1.RegisterTalkServiceServer(s, &talkService{store: stores.Talks, log: log})
+	pacv1.RegisterTalkDateServiceServer(s, &talkDateService{store: stores.TalkDates, log: log})
+	pacv1.RegisterScheduleServiceServer(s, &scheduleService{schedule: schedule})
+	if cnf.Reflection {
+		reflection.Register(s)
+	}
+
+	return &Server{s, cnf.Address, schedule, log}
+}
+
+// ListenAndServe listens on the address and serves until the server is stopped
+func (s *Server) ListenAndServe() error {
+	listener, err := net.Listen("tcp", s.address)
+	if err != nil {
+		return err
+	}
+	s.log.Info("Starting gRPC server on " + listener.Addr().String())
+	return s.Serve(listener)
+}
+
+// Shutdown ends the schedule streams, which would otherwise never finish, and stops the server gracefully,
+// waiting for the running calls at most until ctx is done. The remaining calls are cancelled then.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.schedule.Close()
+
+	stopped := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.Stop()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,723 @@
+package rpc
+
+import (
+	"context"
+	"github.com/hashicorp/go-hclog"
+	"github.com/milutindzunic/pac-backend/data"
+	"github.com/milutindzunic/pac-backend/logging"
+	pacv1 "github.com/milutindzunic/pac-backend/proto/pac/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"math"
+)
+
+// Stores are the stores which the services read and write through
+type Stores struct {
+	Locations     data.LocationStore
+	Events        data.EventStore
+	Organizations data.OrganizationStore
+	Persons       data.PersonStore
+	Rooms         data.RoomStore
+	Topics        data.TopicStore
+	Tracks        data.TrackStore
+	Talks         data.TalkStore
+	TalkDates     data.TalkDateStore
+}
+
+type locationService struct {
+	pacv1.UnimplementedLocationServiceServer
+	store data.LocationStore
+	log   hclog.Logger
+}
+
+func (s *locationService) ListLocations(ctx context.Context, req *pacv1.ListLocationsRequest) (*pacv1.ListLocationsResponse, error) {
+	locations, err := s.store.GetLocations(ctx)
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+
+	resp := &pacv1.ListLocationsResponse{Locations: make([]*pacv1.Location, 0, len(locations))}
+	for _, loc := range locations {
+		resp.Locations = append(resp.Locations, toLocation(loc))
+	}
+	return resp, nil
+}
+
+func (s *locationService) ListLocationsNear(ctx context.Context, req *pacv1.ListLocationsNearRequest) (*pacv1.ListLocationsResponse, error) {
+	radius := req.GetRadius()
+	if radius == 0 {
+		radius = 10
+	}
+	if math.Abs(req.GetLatitude()) > 90 || math.Abs(req.GetLongitude()) > 180 || radius < 0 {
+		return nil, status.Error(codes.InvalidArgument, "latitude must be between -90 and 90, longitude between -180 and 180 and radius positive")
+	}
+
+	locations, err := s.store.GetLocationsNear(ctx, req.GetLatitude(), req.GetLongitude(), radius)
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+
+	resp := &pacv1.ListLocationsResponse{Locations: make([]*pacv1.Location, 0, len(locations))}
+	for _, loc := range locations {
+		resp.Locations = append(resp.Locations, toLocation(loc))
+	}
+	return resp, nil
+}
+
+func (s *locationService) GetLocation(ctx context.Context, req *pacv1.GetLocationRequest) (*pacv1.Location, error) {
+	loc, err := s.store.GetLocationByID(ctx, uint(req.GetId()))
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+	return toLocation(loc), nil
+}
+
+func (s *locationService) CreateLocation(ctx context.Context, req *pacv1.CreateLocationRequest) (*pacv1.Location, error) {
+	if req.GetLocation() == nil {
+		return nil, status.Error(codes.InvalidArgument, "location is required")
+	}
+
+	loc, err := s.store.AddLocation(ctx, fromLocation(req.GetLocation()))
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+	return toLocation(loc), nil
+}
+
+func (s *locationService) UpdateLocation(ctx context.Context, req *pacv1.UpdateLocationRequest) (*pacv1.Location, error) {
+	if req.GetLocation() == nil {
+		return nil, status.Error(codes.InvalidArgument, "location is required")
+	}
+
+	loc, err := s.store.UpdateLocation(ctx, uint(req.GetId()), fromLocation(req.GetLocation()))
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+	return toLocation(loc), nil
+}
+
+func (s *locationService) DeleteLocation(ctx context.Context, req *pacv1.DeleteLocationRequest) (*emptypb.Empty, error) {
+	if err := s.store.DeleteLocationByID(ctx, uint(req.GetId()), req.GetCascade()); err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *locationService) RestoreLocation(ctx context.Context, req *pacv1.RestoreLocationRequest) (*pacv1.Location, error) {
+	loc, err := s.store.RestoreLocationByID(ctx, uint(req.GetId()))
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+	return toLocation(loc), nil
+}
+
+type eventService struct {
+	pacv1.UnimplementedEventServiceServer
+	store data.EventStore
+	log   hclog.Logger
+}
+
+func (s *eventService) ListEvents(ctx context.Context, req *pacv1.ListEventsRequest) (*pacv1.ListEventsResponse, error) {
+	var events []*data.Event
+	var err error
+	if id := req.GetTalkId(); id != 0 {
+		events, err = s.store.GetEventsByTalkID(ctx, uint(id))
+	} else {
+		events, err = s.store.GetEvents(ctx)
+	}
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+
+	resp := &pacv1.ListEventsResponse{Events: make([]*pacv1.Event, 0, len(events))}
+	for _, event := range events {
+		resp.Events = append(resp.Events, toEvent(event))
+	}
+	return resp, nil
+}
+
+func (s *eventService) GetEvent(ctx context.Context, req *pacv1.GetEventRequest) (*pacv1.Event, error) {
+	event, err := s.store.GetEventByID(ctx, uint(req.GetId()))
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+	return toEvent(event), nil
+}
+
+func (s *eventService) CreateEvent(ctx context.Context, req *pacv1.CreateEventRequest) (*pacv1.Event, error) {
+	if req.GetEvent() == nil {
+		return nil, status.Error(codes.InvalidArgument, "event is required")
+	}
+
+	event, err := s.store.AddEvent(ctx, fromEvent(req.GetEvent()))
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+	return toEvent(event), nil
+}
+
+func (s *eventService) UpdateEvent(ctx context.Context, req *pacv1.UpdateEventRequest) (*pacv1.Event, error) {
+	if req.GetEvent() == nil {
+		return nil, status.Error(codes.InvalidArgument, "event is required")
+	}
+
+	event, err := s.store.UpdateEvent(ctx, uint(req.GetId()), fromEvent(req.GetEvent()))
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+	return toEvent(event), nil
+}
+
+func (s *eventService) DeleteEvent(ctx context.Context, req *pacv1.DeleteEventRequest) (*emptypb.Empty, error) {
+	if err := s.store.DeleteEventByID(ctx, uint(req.GetId()), req.GetCascade()); err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *eventService) RestoreEvent(ctx context.Context, req *pacv1.RestoreEventRequest) (*pacv1.Event, error) {
+	event, err := s.store.RestoreEventByID(ctx, uint(req.GetId()))
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+	return toEvent(event), nil
+}
+
+type organizationService struct {
+	pacv1.UnimplementedOrganizationServiceServer
+	store data.OrganizationStore
+	log   hclog.Logger
+}
+
+func (s *organizationService) ListOrganizations(ctx context.Context, req *pacv1.ListOrganizationsRequest) (*pacv1.ListOrganizationsResponse, error) {
+	organizations, err := s.store.GetOrganizations(ctx)
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+
+	resp := &pacv1.ListOrganizationsResponse{Organizations: make([]*pacv1.Organization, 0, len(organizations))}
+	for _, organization := range organizations {
+		resp.Organizations = append(resp.Organizations, toOrganization(organization))
+	}
+	return resp, nil
+}
+
+func (s *organizationService) GetOrganization(ctx context.Context, req *pacv1.GetOrganizationRequest) (*pacv1.Organization, error) {
+	organization, err := s.store.GetOrganizationByID(ctx, uint(req.GetId()))
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+	return toOrganization(organization), nil
+}
+
+func (s *organizationService) CreateOrganization(ctx context.Context, req *pacv1.CreateOrganizationRequest) (*pacv1.Organization, error) {
+	if req.GetOrganization() == nil {
+		return nil, status.Error(codes.InvalidArgument, "organization is required")
+	}
+
+	organization, err := s.store.AddOrganization(ctx, fromOrganization(req.GetOrganization()))
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+	return toOrganization(organization), nil
+}
+
+func (s *organizationService) UpdateOrganization(ctx context.Context, req *pacv1.UpdateOrganizationRequest) (*pacv1.Organization, error) {
+	if req.GetOrganization() == nil {
+		return nil, status.Error(codes.InvalidArgument, "organization is required")
+	}
+
+	organization, err := s.store.UpdateOrganization(ctx, uint(req.GetId()), fromOrganization(req.GetOrganization()))
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+	return toOrganization(organization), nil
+}
+
+func (s *organizationService) DeleteOrganization(ctx context.Context, req *pacv1.DeleteOrganizationRequest) (*emptypb.Empty, error) {
+	if err := s.store.DeleteOrganizationByID(ctx, uint(req.GetId()), req.GetCascade()); err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *organizationService) RestoreOrganization(ctx context.Context, req *pacv1.RestoreOrganizationRequest) (*pacv1.Organization, error) {
+	organization, err := s.store.RestoreOrganizationByID(ctx, uint(req.GetId()))
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+	return toOrganization(organization), nil
+}
+
+type personService struct {
+	pacv1.UnimplementedPersonServiceServer
+	store data.PersonStore
+	log   hclog.Logger
+}
+
+func (s *personService) ListPersons(ctx context.Context, req *pacv1.ListPersonsRequest) (*pacv1.ListPersonsResponse, error) {
+	persons, err := s.store.GetPersons(ctx)
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+
+	resp := &pacv1.ListPersonsResponse{Persons: make([]*pacv1.Person, 0, len(persons))}
+	for _, person := range persons {
+		resp.Persons = append(resp.Persons, toPerson(person))
+	}
+	return resp, nil
+}
+
+func (s *personService) GetPerson(ctx context.Context, req *pacv1.GetPersonRequest) (*pacv1.Person, error) {
+	person, err := s.store.GetPersonByID(ctx, uint(req.GetId()))
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+	return toPerson(person), nil
+}
+
+func (s *personService) CreatePerson(ctx context.Context, req *pacv1.CreatePersonRequest) (*pacv1.Person, error) {
+	if req.GetPerson() == nil {
+		return nil, status.Error(codes.InvalidArgument, "person is required")
+	}
+
+	person, err := s.store.AddPerson(ctx, fromPerson(req.GetPerson()))
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+	return toPerson(person), nil
+}
+
+func (s *personService) UpdatePerson(ctx context.Context, req *pacv1.UpdatePersonRequest) (*pacv1.Person, error) {
+	if req.GetPerson() == nil {
+		return nil, status.Error(codes.InvalidArgument, "person is required")
+	}
+
+	person, err := s.store.UpdatePerson(ctx, uint(req.GetId()), fromPerson(req.GetPerson()))
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+	return toPerson(person), nil
+}
+
+func (s *personService) DeletePerson(ctx context.Context, req *pacv1.DeletePersonRequest) (*emptypb.Empty, error) {
+	if err := s.store.DeletePersonByID(ctx, uint(req.GetId()), req.GetCascade()); err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *personService) RestorePerson(ctx context.Context, req *pacv1.RestorePersonRequest) (*pacv1.Person, error) {
+	person, err := s.store.RestorePersonByID(ctx, uint(req.GetId()))
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+	return toPerson(person), nil
+}
+
+type roomService struct {
+	pacv1.UnimplementedRoomServiceServer
+	store data.RoomStore
+	log   hclog.Logger
+}
+
+func (s *roomService) ListRooms(ctx context.Context, req *pacv1.ListRoomsRequest) (*pacv1.ListRoomsResponse, error) {
+	rooms, err := s.store.GetRooms(ctx)
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+
+	resp := &pacv1.ListRoomsResponse{Rooms: make([]*pacv1.Room, 0, len(rooms))}
+	for _, room := range rooms {
+		resp.Rooms = append(resp.Rooms, toRoom(room))
+	}
+	return resp, nil
+}
+
+func (s *roomService) GetRoom(ctx context.Context, req *pacv1.GetRoomRequest) (*pacv1.Room, error) {
+	room, err := s.store.GetRoomByID(ctx, uint(req.GetId()))
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+	return toRoom(room), nil
+}
+
+func (s *roomService) CreateRoom(ctx context.Context, req *pacv1.CreateRoomRequest) (*pacv1.Room, error) {
+	if req.GetRoom() == nil {
+		return nil, status.Error(codes.InvalidArgument, "room is required")
+	}
+
+	room, err := s.store.AddRoom(ctx, fromRoom(req.GetRoom()))
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+	return toRoom(room), nil
+}
+
+func (s *roomService) UpdateRoom(ctx context.Context, req *pacv1.UpdateRoomRequest) (*pacv1.Room, error) {
+	if req.GetRoom() == nil {
+		return nil, status.Error(codes.InvalidArgument, "room is required")
+	}
+
+	room, err := s.store.UpdateRoom(ctx, uint(req.GetId()), fromRoom(req.GetRoom()))
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+	return toRoom(room), nil
+}
+
+func (s *roomService) DeleteRoom(ctx context.Context, req *pacv1.DeleteRoomRequest) (*emptypb.Empty, error) {
+	if err := s.store.DeleteRoomByID(ctx, uint(req.GetId()), req.GetCascade()); err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *roomService) RestoreRoom(ctx context.Context, req *pacv1.RestoreRoomRequest) (*pacv1.Room, error) {
+	room, err := s.store.RestoreRoomByID(ctx, uint(req.GetId()))
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+	return toRoom(room), nil
+}
+
+type topicService struct {
+	pacv1.UnimplementedTopicServiceServer
+	store data.TopicStore
+	log   hclog.Logger
+}
+
+func (s *topicService) ListTopics(ctx context.Context, req *pacv1.ListTopicsRequest) (*pacv1.ListTopicsResponse, error) {
+	var topics []*data.Topic
+	var err error
+	if id := req.GetEventId(); id != 0 {
+		topics, err = s.store.GetTopicsByEventID(ctx, uint(id))
+	} else {
+		topics, err = s.store.GetTopics(ctx)
+	}
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+
+	resp := &pacv1.ListTopicsResponse{Topics: make([]*pacv1.Topic, 0, len(topics))}
+	for _, topic := range topics {
+		resp.Topics = append(resp.Topics, toTopic(topic))
+	}
+	return resp, nil
+}
+
+func (s *topicService) GetTopic(ctx context.Context, req *pacv1.GetTopicRequest) (*pacv1.Topic, error) {
+	topic, err := s.store.GetTopicByID(ctx, uint(req.GetId()))
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+	return toTopic(topic), nil
+}
+
+func (s *topicService) CreateTopic(ctx context.Context, req *pacv1.CreateTopicRequest) (*pacv1.Topic, error) {
+	if req.GetTopic() == nil {
+		return nil, status.Error(codes.InvalidArgument, "topic is required")
+	}
+	topic, err := s.fromTopic(ctx, req.GetTopic())
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+
+	topic, err = s.store.AddTopic(ctx, topic)
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+	return toTopic(topic), nil
+}
+
+func (s *topicService) UpdateTopic(ctx context.Context, req *pacv1.UpdateTopicRequest) (*pacv1.Topic, error) {
+	if req.GetTopic() == nil {
+		return nil, status.Error(codes.InvalidArgument, "topic is required")
+	}
+	topic, err := s.fromTopic(ctx, req.GetTopic())
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+
+	topic, err = s.store.UpdateTopic(ctx, uint(req.GetId()), topic)
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+	return toTopic(topic), nil
+}
+
+func (s *topicService) DeleteTopic(ctx context.Context, req *pacv1.DeleteTopicRequest) (*emptypb.Empty, error) {
+	if err := s.store.DeleteTopicByID(ctx, uint(req.GetId()), req.GetCascade()); err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *topicService) RestoreTopic(ctx context.Context, req *pacv1.RestoreTopicRequest) (*pacv1.Topic, error) {
+	topic, err := s.store.RestoreTopicByID(ctx, uint(req.GetId()))
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+	return toTopic(topic), nil
+}
+
+// fromTopic converts the topic of a request, loading its children, which are saved along with the topic
+// and must therefore be passed as they are stored
+func (s *topicService) fromTopic(ctx context.Context, topic *pacv1.Topic) (*data.Topic, error) {
+	converted := fromTopic(topic)
+	if len(topic.GetChildren()) == 0 {
+		return converted, nil
+	}
+
+	childIDs := make([]uint, 0, len(topic.GetChildren()))
+	for _, child := range topic.GetChildren() {
+		childIDs = append(childIDs, uint(child.GetId()))
+	}
+	children, err := s.store.GetTopicsByIDs(ctx, childIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(children) != len(childIDs) {
+		return nil, &data.ValidationError{Entity: "Topic", Errors: []data.FieldError{{Field: "children", Rule: "exists", Message: "must refer to existing topics"}}}
+	}
+	for _, child := range children {
+		converted.Children = append(converted.Children, *child)
+	}
+	return converted, nil
+}
+
+type trackService struct {
+	pacv1.UnimplementedTrackServiceServer
+	store data.TrackStore
+	log   hclog.Logger
+}
+
+func (s *trackService) ListTracks(ctx context.Context, req *pacv1.ListTracksRequest) (*pacv1.ListTracksResponse, error) {
+	var tracks []*data.Track
+	var err error
+	if id := req.GetEventId(); id != 0 {
+		tracks, err = s.store.GetTracksByEventID(ctx, uint(id))
+	} else {
+		tracks, err = s.store.GetTracks(ctx)
+	}
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+
+	resp := &pacv1.ListTracksResponse{Tracks: make([]*pacv1.Track, 0, len(tracks))}
+	for _, track := range tracks {
+		resp.Tracks = append(resp.Tracks, toTrack(track))
+	}
+	return resp, nil
+}
+
+func (s *trackService) GetTrack(ctx context.Context, req *pacv1.GetTrackRequest) (*pacv1.Track, error) {
+	track, err := s.store.GetTrackByID(ctx, uint(req.GetId()))
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+	return toTrack(track), nil
+}
+
+func (s *trackService) CreateTrack(ctx context.Context, req *pacv1.CreateTrackRequest) (*pacv1.Track, error) {
+	if req.GetTrack() == nil {
+		return nil, status.Error(codes.InvalidArgument, "track is required")
+	}
+
+	track, err := s.store.AddTrack(ctx, fromTrack(req.GetTrack()))
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+	return toTrack(track), nil
+}
+
+func (s *trackService) UpdateTrack(ctx context.Context, req *pacv1.UpdateTrackRequest) (*pacv1.Track, error) {
+	if req.GetTrack() == nil {
+		return nil, status.Error(codes.InvalidArgument, "track is required")
+	}
+
+	track, err := s.store.UpdateTrack(ctx, uint(req.GetId()), fromTrack(req.GetTrack()))
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+	return toTrack(track), nil
+}
+
+func (s *trackService) DeleteTrack(ctx context.Context, req *pacv1.DeleteTrackRequest) (*emptypb.Empty, error) {
+	if err := s.store.DeleteTrackByID(ctx, uint(req.GetId()), req.GetCascade()); err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *trackService) RestoreTrack(ctx context.Context, req *pacv1.RestoreTrackRequest) (*pacv1.Track, error) {
+	track, err := s.store.RestoreTrackByID(ctx, uint(req.GetId()))
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+	return toTrack(track), nil
+}
+
+type talkService struct {
+	pacv1.UnimplementedTalkServiceServer
+	store data.TalkStore
+	log   hclog.Logger
+}
+
+func (s *talkService) ListTalks(ctx context.Context, req *pacv1.ListTalksRequest) (*pacv1.ListTalksResponse, error) {
+	var talks []*data.Talk
+	var err error
+	filter := data.TalkFilter{
+		Format:   fromTalkFormat(req.GetFormat()),
+		Level:    fromTalkLevel(req.GetLevel()),
+		Language: req.GetLanguage(),
+		TrackID:  uint(req.GetTrackId()),
+	}
+	switch {
+	case req.GetEventId() != 0 && req.GetPersonId() != 0:
+		return nil, status.Error(codes.InvalidArgument, "at most one of event_id and person_id may be set")
+	case req.GetPersonId() != 0 && filter != data.TalkFilter{}:
+		return nil, status.Error(codes.InvalidArgument, "format, level, language and track_id cannot be combined with person_id")
+	case req.GetEventId() != 0:
+		talks, err = s.store.GetTalksByEventID(ctx, uint(req.GetEventId()), filter)
+	case req.GetPersonId() != 0:
+		talks, err = s.store.GetTalksByPersonID(ctx, uint(req.GetPersonId()))
+	default:
+		talks, err = s.store.GetTalks(ctx, filter)
+	}
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+
+	resp := &pacv1.ListTalksResponse{Talks: make([]*pacv1.Talk, 0, len(talks))}
+	for _, talk := range talks {
+		resp.Talks = append(resp.Talks, toTalk(talk))
+	}
+	return resp, nil
+}
+
+func (s *talkService) GetTalk(ctx context.Context, req *pacv1.GetTalkRequest) (*pacv1.Talk, error) {
+	talk, err := s.store.GetTalkByID(ctx, uint(req.GetId()))
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+	return toTalk(talk), nil
+}
+
+func (s *talkService) CreateTalk(ctx context.Context, req *pacv1.CreateTalkRequest) (*pacv1.Talk, error) {
+	if req.GetTalk() == nil {
+		return nil, status.Error(codes.InvalidArgument, "talk is required")
+	}
+
+	talk, err := s.store.AddTalk(ctx, fromTalk(req.GetTalk()))
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+	return toTalk(talk), nil
+}
+
+func (s *talkService) UpdateTalk(ctx context.Context, req *pacv1.UpdateTalkRequest) (*pacv1.Talk, error) {
+	if req.GetTalk() == nil {
+		return nil, status.Error(codes.InvalidArgument, "talk is required")
+	}
+
+	talk, err := s.store.UpdateTalk(ctx, uint(req.GetId()), fromTalk(req.GetTalk()))
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+	return toTalk(talk), nil
+}
+
+func (s *talkService) DeleteTalk(ctx context.Context, req *pacv1.DeleteTalkRequest) (*emptypb.Empty, error) {
+	if err := s.store.DeleteTalkByID(ctx, uint(req.GetId()), req.GetCascade()); err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *talkService) RestoreTalk(ctx context.Context, req *pacv1.RestoreTalkRequest) (*pacv1.Talk, error) {
+	talk, err := s.store.RestoreTalkByID(ctx, uint(req.GetId()))
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+	return toTalk(talk), nil
+}
+
+type talkDateService struct {
+	pacv1.UnimplementedTalkDateServiceServer
+	store data.TalkDateStore
+	log   hclog.Logger
+}
+
+func (s *talkDateService) ListTalkDates(ctx context.Context, req *pacv1.ListTalkDatesRequest) (*pacv1.ListTalkDatesResponse, error) {
+	var talkDates []*data.TalkDate
+	var err error
+	if id := req.GetEventId(); id != 0 {
+		talkDates, err = s.store.GetTalkDatesByEventID(ctx, uint(id))
+	} else if id := req.GetSeriesId(); id != 0 {
+		talkDates, err = s.store.GetTalkDatesBySeriesID(ctx, uint(id))
+	} else {
+		talkDates, err = s.store.GetTalkDates(ctx)
+	}
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+
+	resp := &pacv1.ListTalkDatesResponse{TalkDates: make([]*pacv1.TalkDate, 0, len(talkDates))}
+	for _, talkDate := range talkDates {
+		resp.TalkDates = append(resp.TalkDates, toTalkDate(talkDate))
+	}
+	return resp, nil
+}
+
+func (s *talkDateService) GetTalkDate(ctx context.Context, req *pacv1.GetTalkDateRequest) (*pacv1.TalkDate, error) {
+	talkDate, err := s.store.GetTalkDateByID(ctx, uint(req.GetId()))
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+	return toTalkDate(talkDate), nil
+}
+
+func (s *talkDateService) CreateTalkDate(ctx context.Context, req *pacv1.CreateTalkDateRequest) (*pacv1.TalkDate, error) {
+	if req.GetTalkDate() == nil {
+		return nil, status.Error(codes.InvalidArgument, "talk_date is required")
+	}
+
+	talkDate, err := s.store.AddTalkDate(ctx, fromTalkDate(req.GetTalkDate()))
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+	return toTalkDate(talkDate), nil
+}
+
+func (s *talkDateService) UpdateTalkDate(ctx context.Context, req *pacv1.UpdateTalkDateRequest) (*pacv1.TalkDate, error) {
+	if req.GetTalkDate() == nil {
+		return nil, status.Error(codes.InvalidArgument, "talk_date is required")
+	}
+
+	update := s.store.UpdateTalkDate
+	if req.GetSeries() {
+		update = s.store.UpdateTalkDateSeries
+	}
+	talkDate, err := update(ctx, uint(req.GetId()), fromTalkDate(req.GetTalkDate()))
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+	return toTalkDate(talkDate), nil
+}
+
+func (s *talkDateService) DeleteTalkDate(ctx context.Context, req *pacv1.DeleteTalkDateRequest) (*emptypb.Empty, error) {
+	if err := s.store.DeleteTalkDateByID(ctx, uint(req.GetId()), req.GetCascade()); err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *talkDateService) RestoreTalkDate(ctx context.Context, req *pacv1.RestoreTalkDateRequest) (*pacv1.TalkDate, error) {
+	talkDate, err := s.store.RestoreTalkDateByID(ctx, uint(req.GetId()))
+	if err != nil {
+		return nil, statusOf(err, logging.FromContext(ctx, s.log))
+	}
+	return toTalkDate(talkDate), nil
+}
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"github.com/hashicorp/go-hclog"
+	"github.com/milutindzunic/pac-backend/config"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+)
+
+// Server is an http.Server listening on a TCP address or a unix socket, optionally over TLS
+type Server struct {
+	*http.Server
+	cnf      *config.Config
+	reloader *certReloader
+	log      hclog.Logger
+}
+
+// New creates a server for handler, configured with the timeouts, limits and TLS settings from cnf.
+// The configuration is expected to be validated already.
+func New(cnf *config.Config, handler http.Handler, log hclog.Logger) (*Server, error) {
+	s := &Server{
+		Server: &http.Server{
+			Addr:              cnf.BindAddress,
+			Handler:           handler,
+			ReadTimeout:       cnf.ServerReadTimeout,
+			ReadHeaderTimeout: cnf.ServerReadHeaderTimeout,
+			WriteTimeout:      cnf.ServerWriteTimeout,
+			IdleTimeout:       cnf.ServerIdleTimeout,
+			MaxHeaderBytes:    cnf.ServerMaxHeaderBytes,
+			ErrorLog:          log.StandardLogger(&hclog.StandardLoggerOptions{InferLevels: true}),
+		},
+		cnf: cnf,
+		log: log,
+	}
+
+	if !cnf.ServerHTTP2 {
+		// a non-nil, empty map disables the automatic HTTP/2 support of net/http
+		s.TLSNextProto = map[string]func(*http.Server, *tls.Conn, http.Handler){}
+	}
+
+	if cnf.TLSCertFile == "" {
+		return s, nil
+	}
+
+	reloader, err := newCertReloader(cnf.TLSCertFile, cnf.TLSKeyFile, log)
+	if err != nil {
+		return nil, err
+	}
+	s.reloader = reloader
+	s.TLSConfig = &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.getCertificate,
+	}
+	if cnf.ServerHTTP2 {
+		s.TLSConfig.NextProtos = []string{"h2", "http/1.1"}
+	}
+
+	if cnf.TLSClientCAFile != "" {
+		pem, err := ioutil.ReadFile(cnf.TLSClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading client CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("client CA file %s contains no certificates", cnf.TLSClientCAFile)
+		}
+		s.TLSConfig.ClientCAs = pool
+		s.TLSConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		if cnf.TLSClientAuthOptional {
+			s.TLSConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		}
+	}
+
+	return s, nil
+}
+
+// ListenAndServe listens on the unix socket or the bind address, and serves plain HTTP or TLS.
+// Certificates are reloaded when the files change, until ctx is done.
+func (s *Server) ListenAndServe(ctx context.Context) error {
+	listener, err := s.listen()
+	if err != nil {
+		return err
+	}
+
+	if s.reloader == nil {
+		s.log.Info("Starting server on " + listener.Addr().String())
+		return s.Serve(listener)
+	}
+
+	go s.reloader.watch(ctx, s.cnf.TLSReloadInterval)
+	s.log.Info("Starting TLS server on "+listener.Addr().String(), "mtls", s.TLSConfig.ClientCAs != nil)
+	return s.ServeTLS(listener, "", "")
+}
+
+func (s *Server) listen() (net.Listener, error) {
+	if s.cnf.ServerUnixSocket == "" {
+		return net.Listen("tcp", s.Addr)
+	}
+
+	// a socket left behind by a previous run would make the listen fail
+	if err := os.Remove(s.cnf.ServerUnixSocket); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	listener, err := net.Listen("unix", s.cnf.ServerUnixSocket)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(s.cnf.ServerUnixSocket, 0660); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
@@ -0,0 +1,285 @@
+package server_test
+
+import (
+	"context"
+	"github.com/hashicorp/go-hclog"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	"github.com/milutindzunic/pac-backend/config"
+	"github.com/milutindzunic/pac-backend/handlers"
+	"github.com/milutindzunic/pac-backend/server"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestShutdownFinishesInFlightRequests(t *testing.T) {
+	started := make(chan struct{})
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		close(started)
+		// still busy when the shutdown begins
+		time.Sleep(500 * time.Millisecond)
+		rw.Write([]byte("done"))
+	})
+
+	s, err := server.New(&config.Config{
+		ServerReadTimeout:       5 * time.Second,
+		ServerReadHeaderTimeout: 5 * time.Second,
+		ServerWriteTimeout:      10 * time.Second,
+		ServerIdleTimeout:       time.Minute,
+		ServerMaxHeaderBytes:    1 << 20,
+	}, handler, hclog.NewNullLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// serve the configured server on the listener of the test server
+	ts := httptest.NewUnstartedServer(handler)
+	ts.Config = s.Server
+	ts.Start()
+	defer ts.Close()
+
+	type result struct {
+		status int
+		body   string
+		err    error
+	}
+	results := make(chan result, 1)
+	go func() {
+		resp, err := http.Get(ts.URL)
+		if err != nil {
+			results <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		results <- result{status: resp.StatusCode, body: string(body), err: err}
+	}()
+	<-started
+
+	shutdown := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		shutdown <- s.Shutdown(ctx)
+	}()
+
+	// the listener is closed right away, while the request is still running
+	addr := ts.Listener.Addr().String()
+	deadline := time.Now().Add(time.Second)
+	for {
+		conn, err := net.DialTimeout("tcp", addr, 100*time.Millisecond)
+		if err != nil {
+			break
+		}
+		conn.Close()
+		if time.Now().After(deadline) {
+			t.Fatal("new connections are still accepted during the shutdown")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	select {
+	case res := <-results:
+		if res.err != nil {
+			t.Fatalf("in-flight request failed: %v", res.err)
+		}
+		if res.status != http.StatusOK || res.body != "done" {
+			t.Errorf("in-flight request got %d %q, want 200 \"done\"", res.status, res.body)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("in-flight request did not complete")
+	}
+
+	if err := <-shutdown; err != nil {
+		t.Errorf("Shutdown() = %v, want the server drained before the timeout", err)
+	}
+}
+
+func TestShutdownTimesOutOnSlowRequests(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	})
+
+	s, err := server.New(&config.Config{ServerMaxHeaderBytes: 1 << 20}, handler, hclog.NewNullLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewUnstartedServer(handler)
+	ts.Config = s.Server
+	ts.Start()
+	defer ts.Close()
+	defer close(release)
+
+	go http.Get(ts.URL)
+	<-started
+
+	// the drain timeout expires while the request is running, after which the caller closes the connections
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := s.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Shutdown() = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+// recorder notes the steps of the shutdown in the order they happen
+type recorder struct {
+	mu    sync.Mutex
+	steps []string
+}
+
+func (r *recorder) note(step string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.steps = append(r.steps, step)
+}
+
+// closerFunc closes by calling the function
+type closerFunc func() error
+
+func (f closerFunc) Close() error { return f() }
+
+func TestShutdownSequence(t *testing.T) {
+	db, err := gorm.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hh := handlers.NewHealthHandler(db, hclog.NewNullLogger())
+	steps := &recorder{}
+
+	started := make(chan struct{})
+	sm := http.NewServeMux()
+	sm.HandleFunc("/readyz", hh.Ready)
+	sm.HandleFunc("/slow", func(rw http.ResponseWriter, r *http.Request) {
+		close(started)
+		// still busy when the readiness probe starts failing
+		time.Sleep(500 * time.Millisecond)
+		rw.Write([]byte("done"))
+		steps.note("request finished")
+	})
+
+	s, err := server.New(&config.Config{ServerMaxHeaderBytes: 1 << 20}, sm, hclog.NewNullLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewUnstartedServer(sm)
+	ts.Config = s.Server
+	ts.Start()
+	defer ts.Close()
+
+	if resp, err := http.Get(ts.URL + "/readyz"); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("readiness before the shutdown = %v %v, want 200", resp, err)
+	}
+
+	results := make(chan int, 1)
+	go func() {
+		resp, err := http.Get(ts.URL + "/slow")
+		if err != nil {
+			t.Errorf("in-flight request failed: %v", err)
+			results <- 0
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		if string(body) != "done" {
+			t.Errorf("in-flight request got %q, want \"done\"", body)
+		}
+		results <- resp.StatusCode
+	}()
+	<-started
+
+	workersStopped := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		server.Shutdown{
+			SetShuttingDown: hh.SetShuttingDown,
+			ReadinessDelay:  300 * time.Millisecond,
+			DrainTimeout:    5 * time.Second,
+			Servers:         []server.Stopper{s},
+			StopWorkers: func() {
+				steps.note("workers stopped")
+				close(workersStopped)
+			},
+			Closers: []io.Closer{closerFunc(func() error {
+				steps.note("database closed")
+				return db.Close()
+			})},
+		}.Run(hclog.NewNullLogger())
+		close(done)
+	}()
+
+	// the readiness probe fails during the delay, while new requests are still served
+	deadline := time.Now().Add(time.Second)
+	for {
+		resp, err := http.Get(ts.URL + "/readyz")
+		if err != nil {
+			t.Fatalf("readiness during the delay failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusServiceUnavailable {
+			steps.note("not ready")
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("readiness during the shutdown = %d, want 503", resp.StatusCode)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("shutdown did not complete")
+	}
+	if status := <-results; status != http.StatusOK {
+		t.Errorf("in-flight request got %d, want 200", status)
+	}
+	select {
+	case <-workersStopped:
+	default:
+		t.Error("workers were not stopped")
+	}
+
+	want := []string{"not ready", "request finished", "workers stopped", "database closed"}
+	if !reflect.DeepEqual(steps.steps, want) {
+		t.Errorf("shutdown steps = %v, want %v", steps.steps, want)
+	}
+	if err := db.DB().Ping(); err == nil {
+		t.Error("database is still open after the shutdown")
+	}
+}
+
+// stopperFunc shuts down by calling the function
+type stopperFunc func(ctx context.Context) error
+
+func (f stopperFunc) Shutdown(ctx context.Context) error { return f(ctx) }
+
+func TestShutdownStopsServersConcurrently(t *testing.T) {
+	// each server finishes only once the other one is shutting down as well
+	var started sync.WaitGroup
+	started.Add(2)
+	stopper := stopperFunc(func(ctx context.Context) error {
+		started.Done()
+		started.Wait()
+		return nil
+	})
+
+	done := make(chan struct{})
+	go func() {
+		server.Shutdown{DrainTimeout: time.Second, Servers: []server.Stopper{stopper, stopper}}.Run(hclog.NewNullLogger())
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the servers were shut down one after the other")
+	}
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"context"
+	"github.com/hashicorp/go-hclog"
+	"io"
+	"sync"
+	"time"
+)
+
+// Stopper is a server which stops accepting requests on Shutdown, waiting for the running ones until ctx is done
+type Stopper interface {
+	Shutdown(ctx context.Context) error
+}
+
+// Shutdown is the sequence stopping the instance gracefully
+type Shutdown struct {
+	// SetShuttingDown makes the readiness probe fail
+	SetShuttingDown func()
+	// ReadinessDelay gives the load balancer time to stop routing requests to the instance
+	ReadinessDelay time.Duration
+	// DrainTimeout bounds the wait for the running requests of the servers
+	DrainTimeout time.Duration
+	// Servers are shut down at the same time
+	Servers []Stopper
+	// StopWorkers stops the background workers and waits for them
+	StopWorkers func()
+	// Closers release the resources used by the requests and the workers, the database among them
+	Closers []io.Closer
+}
+
+// Run fails the readiness probe first and keeps serving during the readiness delay. It then shuts the servers
+// down, stops the workers and closes the resources last, once nothing uses them anymore.
+func (sd Shutdown) Run(log hclog.Logger) {
+	if sd.SetShuttingDown != nil {
+		sd.SetShuttingDown()
+	}
+	log.Info("Draining...", "delay", sd.ReadinessDelay)
+	time.Sleep(sd.ReadinessDelay)
+
+	// idle keep-alive connections are closed right away
+	log.Info("Shutting down server...")
+	ctx, cancel := context.WithTimeout(context.Background(), sd.DrainTimeout)
+	defer cancel()
+	// concurrently, so every server has the whole drain timeout
+	var servers sync.WaitGroup
+	for _, s := range sd.Servers {
+		servers.Add(1)
+		go func(s Stopper) {
+			defer servers.Done()
+			if err := s.Shutdown(ctx); err != nil {
+				log.Error("Error shutting down server, closing remaining connections", "err", err)
+				if c, ok := s.(io.Closer); ok {
+					_ = c.Close()
+				}
+			}
+		}(s)
+	}
+	servers.Wait()
+
+	// let a running purge finish before the database is closed
+	if sd.StopWorkers != nil {
+		sd.StopWorkers()
+	}
+	for _, c := range sd.Closers {
+		if err := c.Close(); err != nil {
+			log.Error("Error closing", "err", err)
+		}
+	}
+	log.Info("Server stopped")
+}
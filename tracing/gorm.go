@@ -0,0 +1,74 @@
+package tracing
+
+import (
+	"context"
+	"github.com/jinzhu/gorm"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	contextKey = "tracing:context"
+	spanKey    = "tracing:span"
+)
+
+// WithContext returns a copy of db whose statements are traced as children of the span in ctx.
+// gorm v1 has no notion of contexts, so the context travels along as a scope setting.
+func WithContext(db *gorm.DB, ctx context.Context) *gorm.DB {
+	return db.Set(contextKey, ctx)
+}
+
+// InstrumentDB registers gorm callbacks creating a span for every statement run through a db returned by WithContext
+func InstrumentDB(db *gorm.DB) {
+	callbacks := db.Callback()
+	callbacks.Create().Before("gorm:create").Register("tracing:before_create", before("create"))
+	callbacks.Create().After("gorm:create").Register("tracing:after_create", after)
+	callbacks.Query().Before("gorm:query").Register("tracing:before_query", before("query"))
+	callbacks.Query().After("gorm:query").Register("tracing:after_query", after)
+	callbacks.RowQuery().Before("gorm:row_query").Register("tracing:before_row_query", before("query"))
+	callbacks.RowQuery().After("gorm:row_query").Register("tracing:after_row_query", after)
+	callbacks.Update().Before("gorm:update").Register("tracing:before_update", before("update"))
+	callbacks.Update().After("gorm:update").Register("tracing:after_update", after)
+	callbacks.Delete().Before("gorm:delete").Register("tracing:before_delete", before("delete"))
+	callbacks.Delete().After("gorm:delete").Register("tracing:after_delete", after)
+}
+
+func before(operation string) func(scope *gorm.Scope) {
+	return func(scope *gorm.Scope) {
+		value, ok := scope.Get(contextKey)
+		if !ok {
+			return
+		}
+
+		table := scope.TableName()
+		_, span := otel.Tracer(instrumentationName).Start(value.(context.Context), "gorm."+operation+" "+table,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("db.system", scope.Dialect().GetName()),
+				attribute.String("db.operation", operation),
+				attribute.String("db.sql.table", table),
+			),
+		)
+		scope.Set(spanKey, span)
+	}
+}
+
+func after(scope *gorm.Scope) {
+	value, ok := scope.Get(spanKey)
+	if !ok {
+		return
+	}
+	span := value.(trace.Span)
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("db.statement", scope.SQL),
+		attribute.Int64("db.rows_affected", scope.DB().RowsAffected),
+	)
+	if scope.HasError() && !gorm.IsRecordNotFoundError(scope.DB().Error) {
+		span.RecordError(scope.DB().Error)
+		span.SetStatus(codes.Error, scope.DB().Error.Error())
+	}
+}
@@ -0,0 +1,63 @@
+package tracing
+
+import (
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+)
+
+// Middleware starts a server span per request, continuing the trace of the caller when the request carries
+// a traceparent header, and returns the trace id in the traceparent response header
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+		route := r.URL.Path
+		if current := mux.CurrentRoute(r); current != nil {
+			if template, err := current.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+
+		ctx, span := otel.Tracer(instrumentationName).Start(ctx, r.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.method", r.Method),
+				attribute.String("http.route", route),
+				attribute.String("http.target", r.URL.RequestURI()),
+			),
+		)
+		defer span.End()
+
+		otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(rw.Header()))
+
+		srw := &statusResponseWriter{rw, http.StatusOK}
+		next.ServeHTTP(srw, r.WithContext(ctx))
+
+		span.SetAttributes(attribute.Int("http.status_code", srw.statusCode))
+		if srw.statusCode >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(srw.statusCode))
+		}
+	})
+}
+
+// statusResponseWriter captures the status code of the response
+type statusResponseWriter struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (srw *statusResponseWriter) WriteHeader(code int) {
+	srw.statusCode = code
+	srw.ResponseWriter.WriteHeader(code)
+}
+
+func (srw *statusResponseWriter) Flush() {
+	if f, ok := srw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,16 @@
+package tracing
+
+import (
+	"context"
+	"github.com/hashicorp/go-hclog"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Logger returns log with the trace and span ids of the span in ctx attached to every line, if there is one
+func Logger(ctx context.Context, log hclog.Logger) hclog.Logger {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return log
+	}
+	return log.With("trace_id", spanContext.TraceID().String(), "span_id", spanContext.SpanID().String())
+}
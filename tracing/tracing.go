@@ -0,0 +1,85 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"os"
+)
+
+const instrumentationName = "github.com/milutindzunic/pac-backend"
+
+// Exporters supported by Setup
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOtlp   = "otlp"
+)
+
+type Config struct {
+	// Exporter is one of none, stdout or otlp
+	Exporter string
+	// OtlpEndpoint is the host:port of the OTLP/HTTP collector
+	OtlpEndpoint string
+	OtlpInsecure bool
+	ServiceName  string
+	// SampleRatio is the fraction of root traces which are recorded; sampled parents are always honored
+	SampleRatio float64
+}
+
+// Setup installs the tracer provider exporting spans as configured, along with the W3C trace context propagator.
+// The returned function flushes and stops the exporter.
+func Setup(cnf Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	switch cnf.Exporter {
+	case "", ExporterNone:
+		// the default global provider discards all spans, but trace context is still propagated
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		var err error
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		if err != nil {
+			return nil, err
+		}
+	case ExporterOtlp:
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cnf.OtlpEndpoint)}
+		if cnf.OtlpInsecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		var err error
+		exporter, err = otlptracehttp.New(context.Background(), opts...)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("error! Tracing exporter must be one of: [none, stdout, otlp], was %s", cnf.Exporter)
+	}
+
+	provider := NewTracerProvider(cnf.ServiceName, cnf.SampleRatio, sdktrace.WithBatcher(exporter))
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// NewTracerProvider creates a provider processing the spans with the given options, e.g.
+// sdktrace.WithSyncer(tracetest.NewInMemoryExporter()) to inspect the spans in tests
+func NewTracerProvider(serviceName string, sampleRatio float64, opts ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	opts = append(opts,
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", serviceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+	)
+	return sdktrace.NewTracerProvider(opts...)
+}
+
+// StartSpan starts a child span of the span in ctx, using the globally installed tracer provider
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
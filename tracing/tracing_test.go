@@ -0,0 +1,190 @@
+package tracing_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"github.com/hashicorp/go-hclog"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	"github.com/milutindzunic/pac-backend/data"
+	"github.com/milutindzunic/pac-backend/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// setup installs a tracer provider recording all spans in memory, and returns a router serving GET /locations
+// from a location store on the empty in-memory database it also returns
+func setup(t *testing.T) (*tracetest.InMemoryExporter, http.Handler, *gorm.DB) {
+	t.Helper()
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := tracing.NewTracerProvider("test", 1, sdktrace.WithSyncer(exporter))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		_ = provider.Shutdown(context.Background())
+	})
+
+	db, err := gorm.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	// every connection to :memory: opens a database of its own
+	db.DB().SetMaxOpenConns(1)
+	db.SingularTable(true)
+	if err := db.AutoMigrate(&data.Location{}).Error; err != nil {
+		t.Fatal(err)
+	}
+	tracing.InstrumentDB(db)
+
+	store := data.NewLocationDBStore(db, hclog.NewNullLogger())
+	router := mux.NewRouter()
+	router.Use(tracing.Middleware)
+	router.HandleFunc("/locations", func(rw http.ResponseWriter, r *http.Request) {
+		locations, err := store.GetLocations(r.Context())
+		if err != nil {
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(rw).Encode(locations)
+	}).Methods("GET")
+	return exporter, router, db
+}
+
+func TestSpansOfRequest(t *testing.T) {
+	exporter, router, _ := setup(t)
+
+	rw := httptest.NewRecorder()
+	router.ServeHTTP(rw, httptest.NewRequest("GET", "/locations?limit=1", nil))
+	if rw.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rw.Code)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 3 {
+		t.Fatalf("got %d spans %v, want the HTTP, store and gorm spans", len(spans), names(spans))
+	}
+	// spans are exported as they end, the innermost first
+	statement, call, request := spans[0], spans[1], spans[2]
+
+	if request.Name != "GET /locations" || request.SpanKind != trace.SpanKindServer || request.Parent.IsValid() {
+		t.Errorf("request span = %s %v parent %v, want the root server span GET /locations", request.Name, request.SpanKind, request.Parent)
+	}
+	expectAttributes(t, request, map[string]interface{}{
+		"http.method":      "GET",
+		"http.route":       "/locations",
+		"http.target":      "/locations?limit=1",
+		"http.status_code": int64(200),
+	})
+
+	if call.Name != "LocationDBStore.GetLocations" || call.Parent.SpanID() != request.SpanContext.SpanID() {
+		t.Errorf("store span = %s with parent %s, want LocationDBStore.GetLocations with parent %s",
+			call.Name, call.Parent.SpanID(), request.SpanContext.SpanID())
+	}
+
+	if statement.Name != "gorm.query location" || statement.SpanKind != trace.SpanKindClient ||
+		statement.Parent.SpanID() != call.SpanContext.SpanID() {
+		t.Errorf("statement span = %s %v with parent %s, want the client span gorm.query location with parent %s",
+			statement.Name, statement.SpanKind, statement.Parent.SpanID(), call.SpanContext.SpanID())
+	}
+	expectAttributes(t, statement, map[string]interface{}{
+		"db.system":        "sqlite3",
+		"db.operation":     "query",
+		"db.sql.table":     "location",
+		"db.rows_affected": int64(0),
+	})
+	if statement := attributes(statement)["db.statement"]; statement == "" || statement == nil {
+		t.Error("statement span has no db.statement")
+	}
+
+	for _, span := range spans {
+		if span.SpanContext.TraceID() != request.SpanContext.TraceID() {
+			t.Errorf("span %s is in trace %s, want %s", span.Name, span.SpanContext.TraceID(), request.SpanContext.TraceID())
+		}
+	}
+}
+
+func TestContinuesTraceOfCaller(t *testing.T) {
+	exporter, router, _ := setup(t)
+
+	const traceID, parentID = "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"
+	r := httptest.NewRequest("GET", "/locations", nil)
+	r.Header.Set("traceparent", "00-"+traceID+"-"+parentID+"-01")
+	rw := httptest.NewRecorder()
+	router.ServeHTTP(rw, r)
+
+	spans := exporter.GetSpans()
+	if len(spans) == 0 {
+		t.Fatal("no spans recorded")
+	}
+	request := spans[len(spans)-1]
+	if request.SpanContext.TraceID().String() != traceID || request.Parent.SpanID().String() != parentID {
+		t.Errorf("request span is in trace %s with parent %s, want trace %s with parent %s",
+			request.SpanContext.TraceID(), request.Parent.SpanID(), traceID, parentID)
+	}
+	want := "00-" + traceID + "-" + request.SpanContext.SpanID().String() + "-01"
+	if got := rw.Header().Get("traceparent"); got != want {
+		t.Errorf("traceparent response header = %q, want %q", got, want)
+	}
+}
+
+func TestSpansOfFailedStatement(t *testing.T) {
+	exporter, router, db := setup(t)
+	db.DropTable(&data.Location{})
+	exporter.Reset()
+
+	rw := httptest.NewRecorder()
+	router.ServeHTTP(rw, httptest.NewRequest("GET", "/locations", nil))
+	if rw.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", rw.Code)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 3 {
+		t.Fatalf("got %d spans %v, want the HTTP, store and gorm spans", len(spans), names(spans))
+	}
+	statement, request := spans[0], spans[2]
+	if statement.Status.Code != codes.Error || len(statement.Events) == 0 {
+		t.Errorf("statement span has status %v and %d events, want an error and its event", statement.Status.Code, len(statement.Events))
+	}
+	if request.Status.Code != codes.Error || attributes(request)["http.status_code"] != int64(500) {
+		t.Errorf("request span has status %v and code %v, want an error with code 500", request.Status.Code, attributes(request)["http.status_code"])
+	}
+}
+
+func names(spans tracetest.SpanStubs) []string {
+	var names []string
+	for _, span := range spans {
+		names = append(names, span.Name)
+	}
+	return names
+}
+
+func attributes(span tracetest.SpanStub) map[string]interface{} {
+	values := map[string]interface{}{}
+	for _, kv := range span.Attributes {
+		values[string(kv.Key)] = kv.Value.AsInterface()
+	}
+	return values
+}
+
+func expectAttributes(t *testing.T, span tracetest.SpanStub, want map[string]interface{}) {
+	t.Helper()
+	got := attributes(span)
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("span %s has %s = %v, want %v", span.Name, key, got[key], value)
+		}
+	}
+}